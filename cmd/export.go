@@ -1,21 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"syspulse/internal/collector/builtin"
 	"syspulse/internal/export"
-	ui "syspulse/internal/services/UI"
-	"syspulse/internal/services/battery"
-	"syspulse/internal/services/disk"
-	"syspulse/internal/services/gpu"
-	"syspulse/internal/services/load"
-	"syspulse/internal/services/memory"
-	"syspulse/internal/services/network"
-	"syspulse/internal/services/sysinfo"
-	"syspulse/internal/services/temperature"
+	"syspulse/internal/plugins"
+	"syspulse/internal/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -29,20 +24,30 @@ var (
 	exportSamples   int
 	exportAll       bool
 	exportQuiet     bool
+	exportPush      string
+	exportCSVLayout string
+	exportPlugins   bool
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
-	Short: "Export system metrics to CSV or JSON format",
-	Long: `Export system metrics to CSV or JSON format without opening the UI.
+	Short: "Export system metrics to CSV, JSON, InfluxDB or Graphite format",
+	Long: `Export system metrics to CSV, JSON, InfluxDB line protocol or Graphite
+plaintext format without opening the UI.
 This command allows you to collect system metrics and export them directly
-to files for analysis or integration with other tools.
+to files for analysis or integration with other tools. With --push the
+influx and graphite formats are sent to a metrics backend instead.
+The data of the plugins enabled in plugins_config.json is included in CSV
+and JSON exports unless --plugins=false is given.
 
 Examples:
   syspulse export --format csv --output metrics.csv
   syspulse export --format json --output metrics.json --samples 10
   syspulse export --format csv --directory exports --duration 60
-  syspulse export --format json --samples 5 --interval 2`,
+  syspulse export --format json --samples 5 --interval 2
+  syspulse export --format csv --csv-layout long --samples 10
+  syspulse export --format influx --push http://localhost:8086/write?db=syspulse
+  syspulse export --format graphite --push tcp://graphite:2003 --duration 300 --interval 10`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runExport(); err != nil {
 			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
@@ -54,7 +59,7 @@ Examples:
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Export format (csv, json)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Export format (csv, json, influx, graphite)")
 	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output filename (default: auto-generated)")
 	exportCmd.Flags().StringVarP(&exportDirectory, "directory", "d", "exports", "Export directory")
 	exportCmd.Flags().IntVar(&exportDuration, "duration", 0, "Collection duration in seconds (0 = single snapshot)")
@@ -62,14 +67,47 @@ func init() {
 	exportCmd.Flags().IntVar(&exportSamples, "samples", 1, "Number of samples to collect")
 	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Export both CSV and JSON formats")
 	exportCmd.Flags().BoolVarP(&exportQuiet, "quiet", "q", false, "Quiet mode - minimal output")
+	exportCmd.Flags().StringVar(&exportCSVLayout, "csv-layout", "wide", "CSV layout: wide (one row per sample) or long (one row per metric)")
+	exportCmd.Flags().StringVar(&exportPush, "push", "", "Send samples to a tcp://, udp:// or http(s):// endpoint instead of a file (influx or graphite)")
+	exportCmd.Flags().BoolVar(&exportPlugins, "plugins", true, "Include the data of enabled plugins")
 }
 
 func runExport() error {
-	if !exportAll && exportFormat != "csv" && exportFormat != "json" {
-		return fmt.Errorf("invalid format: %s (must be 'csv' or 'json')", exportFormat)
+	if _, err := export.ParseFormat(exportFormat); !exportAll && err != nil {
+		return fmt.Errorf("invalid format: %s (must be 'csv', 'json', 'influx' or 'graphite')", exportFormat)
 	}
 
-	dashboard := ui.NewDashboard()
+	csvLayout, err := export.ParseCSVLayout(exportCSVLayout)
+	if err != nil {
+		return err
+	}
+
+	var pusher *export.Pusher
+	if exportPush != "" {
+		config, err := export.NewPusherConfig(utils.PushConfig{URL: exportPush, Format: exportFormat})
+		if err != nil {
+			return err
+		}
+		if pusher, err = export.NewPusher(config); err != nil {
+			return err
+		}
+	}
+
+	registry := builtin.NewRegistry()
+	ctx := context.Background()
+
+	dashboard := &utils.Dashboard{}
+	if exportPlugins {
+		if err := plugins.InitializeHeadless(dashboard); err != nil && !exportQuiet {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		defer plugins.ShutdownPluginSystem(dashboard)
+	}
+	collect := func() export.DataPoint {
+		snapshot := export.NewDataPoint(registry.CollectAll(ctx))
+		snapshot.Plugins = plugins.ExportPluginData(dashboard)
+		return snapshot
+	}
 
 	if !exportQuiet {
 		fmt.Printf("Collecting system metrics...\n")
@@ -87,17 +125,7 @@ func runExport() error {
 		sampleCount := 0
 
 		for time.Now().Before(endTime) {
-			sysinfo.UpdateCPU(dashboard)
-			memory.UpdateVMem(dashboard)
-			disk.UpdateDisk(dashboard)
-			network.UpdateNetwork(dashboard)
-			battery.UpdateBatteryStatus(dashboard)
-			temperature.UpdateTemperatures(dashboard)
-			gpu.UpdateGPU(dashboard)
-			load.UpdateLoadAverage(dashboard)
-
-			snapshot := export.CreateSnapshot(dashboard)
-			dataPoints = append(dataPoints, snapshot)
+			dataPoints = append(dataPoints, collect())
 			sampleCount++
 
 			if !exportQuiet {
@@ -112,17 +140,7 @@ func runExport() error {
 		}
 
 		for i := 0; i < exportSamples; i++ {
-			sysinfo.UpdateCPU(dashboard)
-			memory.UpdateVMem(dashboard)
-			disk.UpdateDisk(dashboard)
-			network.UpdateNetwork(dashboard)
-			battery.UpdateBatteryStatus(dashboard)
-			temperature.UpdateTemperatures(dashboard)
-			gpu.UpdateGPU(dashboard)
-			load.UpdateLoadAverage(dashboard)
-
-			snapshot := export.CreateSnapshot(dashboard)
-			dataPoints = append(dataPoints, snapshot)
+			dataPoints = append(dataPoints, collect())
 
 			if !exportQuiet {
 				fmt.Printf("Collected sample %d/%d\n", i+1, exportSamples)
@@ -134,6 +152,19 @@ func runExport() error {
 		}
 	}
 
+	if pusher != nil {
+		if !exportQuiet {
+			fmt.Printf("Pushing %d data points to %s...\n", len(dataPoints), exportPush)
+		}
+		if err := pusher.Push(ctx, dataPoints...); err != nil {
+			return fmt.Errorf("failed to push %s data: %v", exportFormat, err)
+		}
+		if !exportQuiet {
+			fmt.Printf("✓ Successfully pushed %d data points to %s\n", len(dataPoints), exportPush)
+		}
+		return nil
+	}
+
 	formats := []string{}
 	if exportAll {
 		formats = []string{"csv", "json"}
@@ -160,19 +191,21 @@ func runExport() error {
 			outputPath = fmt.Sprintf("%s/%s", exportDirectory, outputFile)
 		}
 
-		var exportFormatEnum export.ExportFormat
-		switch format {
-		case "csv":
-			exportFormatEnum = export.CSV
-		case "json":
-			exportFormatEnum = export.JSON
+		exportFormatEnum, err := export.ParseFormat(format)
+		if err != nil {
+			return err
 		}
 
 		if !exportQuiet {
 			fmt.Printf("Exporting %d data points to %s...\n", len(dataPoints), outputPath)
 		}
 
-		if err := export.ExportData(dataPoints, outputPath, exportFormatEnum); err != nil {
+		if exportFormatEnum == export.CSV && csvLayout == export.CSVLong {
+			err = export.ExportLongCSV(dataPoints, outputPath)
+		} else {
+			err = export.ExportData(dataPoints, outputPath, exportFormatEnum)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to export %s data: %v", format, err)
 		}
 
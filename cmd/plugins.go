@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"syspulse/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+var pluginsConfigPath string
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Inspect the plugins available to SysPulse",
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered and external plugins",
+	Long: `List the built-in plugins and the executables found in the plugin
+directory, with their version and whether plugins_config.json enables them.
+External plugins are not started, so their version is not shown.
+
+Config entries that name no known plugin are reported after the list, and make
+the command exit with status 1.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPluginsList(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+
+	pluginsCmd.PersistentFlags().StringVarP(&pluginsConfigPath, "config", "c", "plugins_config.json", "Plugin configuration file")
+}
+
+func runPluginsList() error {
+	config, err := plugins.LoadPluginConfig(pluginsConfigPath)
+	if err != nil {
+		return err
+	}
+
+	listings, listErr := plugins.List(config)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tTYPE\tSTATE\tDESCRIPTION")
+	for _, listing := range listings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", listing.Name, listing.Version, listing.Kind, listing.State, listing.Description)
+	}
+	w.Flush()
+
+	return listErr
+}
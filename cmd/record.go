@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"syspulse/internal/collector/builtin"
+	"syspulse/internal/recording"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordOutput   string
+	recordInterval int
+	recordDuration int
+	recordQuiet    bool
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record system metrics for replaying in the dashboard later",
+	Long: `Run the collectors without the UI and write every sample, including the
+process tree and network connections, to a compact recording file. Samples
+that did not change since the previous frame are not stored again.
+
+Recording stops after --duration seconds, or on Ctrl-C when no duration is
+given. A recording cut short by a crash stays readable up to its last frame.
+Play it back with "syspulse replay".
+
+Examples:
+  syspulse record
+  syspulse record --output incident.rec.gz --interval 1
+  syspulse record --duration 3600 --interval 5`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRecord(); err != nil {
+			fmt.Fprintf(os.Stderr, "Record failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().StringVarP(&recordOutput, "output", "o", "", "Output filename (default: auto-generated)")
+	recordCmd.Flags().IntVarP(&recordInterval, "interval", "i", 2, "Collection interval in seconds")
+	recordCmd.Flags().IntVar(&recordDuration, "duration", 0, "Recording duration in seconds (0 = until interrupted)")
+	recordCmd.Flags().BoolVarP(&recordQuiet, "quiet", "q", false, "Quiet mode - minimal output")
+}
+
+func runRecord() error {
+	if recordInterval <= 0 {
+		return fmt.Errorf("invalid interval: %d (must be greater than 0)", recordInterval)
+	}
+	if recordDuration < 0 {
+		return fmt.Errorf("invalid duration: %d (must not be negative)", recordDuration)
+	}
+
+	if recordOutput == "" {
+		recordOutput = fmt.Sprintf("syspulse_%s.rec.gz", time.Now().Format("2006-01-02_15-04-05"))
+	}
+
+	host, _ := os.Hostname()
+	interval := time.Duration(recordInterval) * time.Second
+	w, err := recording.Create(recordOutput, recording.Header{Host: host, Started: time.Now(), Interval: interval})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if recordDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(recordDuration)*time.Second)
+		defer cancel()
+	}
+
+	if !recordQuiet {
+		fmt.Printf("Recording to %s every %s (Ctrl-C to stop)\n", recordOutput, interval)
+	}
+
+	registry := builtin.NewRegistry()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		snapshot := registry.CollectAll(ctx)
+		// A collection interrupted halfway is left out of the recording.
+		if ctx.Err() != nil {
+			break
+		}
+		if err := w.WriteSnapshot(time.Now(), snapshot); err != nil {
+			w.Close()
+			return err
+		}
+		if !recordQuiet {
+			fmt.Printf("\rRecorded %d frames", w.Frames())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish recording: %v", err)
+	}
+	if !recordQuiet {
+		fmt.Printf("\n✓ Recorded %d frames to %s\n", w.Frames(), recordOutput)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"syspulse/internal/recording"
+	ui "syspulse/internal/services/UI"
+
+	"github.com/spf13/cobra"
+)
+
+var replaySpeed int
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Play back a recording in the dashboard",
+	Long: `Open the dashboard with the samples of a recording made by "syspulse record"
+instead of the live collectors. Nothing is collected, exported or sent while
+replaying, and processes cannot be killed.
+
+Playback keys:
+  SPACE      pause or resume
+  , and .    seek 10 seconds back or forward
+  [ and ]    seek 1 minute back or forward
+  HOME/END   jump to the start or end
+  X          cycle the speed through 1x, 4x and 16x
+
+Examples:
+  syspulse replay incident.rec.gz
+  syspulse replay incident.rec.gz --speed 16`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReplay(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().IntVarP(&replaySpeed, "speed", "s", 1, "Playback speed (1, 4 or 16)")
+}
+
+func runReplay(path string) error {
+	if !slices.Contains(ui.ReplaySpeeds, replaySpeed) {
+		return fmt.Errorf("invalid speed: %d (must be 1, 4 or 16)", replaySpeed)
+	}
+
+	rec, err := recording.Open(path)
+	if err != nil {
+		return err
+	}
+	if rec.Truncated {
+		fmt.Fprintf(os.Stderr, "Recording ends abruptly, replaying the %d complete frames\n", rec.Len())
+	}
+
+	return ui.RunReplay(ui.NewDashboard(), rec, replaySpeed)
+}
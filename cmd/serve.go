@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector/builtin"
+	"syspulse/internal/history"
+	loggerv2 "syspulse/internal/logger/v2"
+	"syspulse/internal/server"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen      string
+	serveInterval    int
+	serveTokenFile   string
+	serveAllowRemote bool
+	serveQuiet       bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve system metrics over HTTP for Prometheus and a JSON API",
+	Long: `Run the collectors without the UI and expose the latest metrics in the
+OpenMetrics text format on /metrics, ready to be scraped by Prometheus, along
+with a JSON API under /api/v1 for snapshots, history, processes and connections.
+
+The server only listens on loopback addresses unless --allow-remote is given.
+Sending signals to processes is disabled unless a token file is configured;
+requests must then carry "Authorization: Bearer <token>".
+
+The settings are read from the "server" section of config.json and can be
+overridden with flags.
+
+Examples:
+  syspulse serve
+  syspulse serve --listen 127.0.0.1:9100 --interval 10
+  syspulse serve --listen 0.0.0.0:9273 --allow-remote
+  syspulse serve --token-file ~/.config/syspulse/token`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Serve failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVarP(&serveListen, "listen", "l", utils.DefaultServerConfig.Listen, "Listen address")
+	serveCmd.Flags().IntVarP(&serveInterval, "interval", "i", utils.DefaultServerConfig.Interval, "Collection interval in seconds")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "File containing the bearer token that enables process actions")
+	serveCmd.Flags().BoolVar(&serveAllowRemote, "allow-remote", false, "Allow listening on non-loopback addresses")
+	serveCmd.Flags().BoolVarP(&serveQuiet, "quiet", "q", false, "Quiet mode - minimal output")
+}
+
+func runServe(cmd *cobra.Command) error {
+	config := utils.DefaultServerConfig
+	historyConfig := history.DefaultConfig
+	alertsConfig := alerts.Config{}
+	if theme, err := utils.LoadTheme(); err == nil {
+		if theme.Server.Listen != "" {
+			config.Listen = theme.Server.Listen
+		}
+		if theme.Server.Interval > 0 {
+			config.Interval = theme.Server.Interval
+		}
+		config.AllowRemote = theme.Server.AllowRemote
+		config.TokenFile = theme.Server.TokenFile
+		historyConfig = theme.History
+		alertsConfig = theme.Alerts
+		processes.Configure(theme.Performance)
+	} else if !serveQuiet {
+		fmt.Fprintf(os.Stderr, "Using default server settings: %v\n", err)
+	}
+
+	if cmd.Flags().Changed("listen") {
+		config.Listen = serveListen
+	}
+	if cmd.Flags().Changed("interval") {
+		if serveInterval <= 0 {
+			return fmt.Errorf("invalid interval: %d (must be greater than 0)", serveInterval)
+		}
+		config.Interval = serveInterval
+	}
+	if cmd.Flags().Changed("token-file") {
+		config.TokenFile = serveTokenFile
+	}
+	if cmd.Flags().Changed("allow-remote") {
+		config.AllowRemote = serveAllowRemote
+	}
+
+	var store *history.Store
+	if historyConfig.Enabled {
+		store = history.NewStore(historyConfig)
+	}
+
+	srv, err := server.New(config, builtin.NewRegistry(), store)
+	if err != nil {
+		return err
+	}
+
+	if alertsConfig.Enabled {
+		engine, err := alerts.NewEngine(alertsConfig.Rules)
+		if err != nil {
+			return err
+		}
+
+		var logger alerts.Logger
+		for _, notifier := range alertsConfig.Notifiers {
+			if notifier.Type == alerts.NotifierLog {
+				l, err := loggerv2.New("logs", loggerv2.INFO)
+				if err != nil {
+					return err
+				}
+				defer l.Close()
+				logger = l
+				break
+			}
+		}
+
+		dispatcher, err := alerts.NewDispatcher(alertsConfig, logger)
+		if err != nil {
+			return err
+		}
+		srv.SetAlerts(engine, dispatcher)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !serveQuiet {
+		fmt.Printf("Serving metrics on http://%s/metrics (collecting every %ds)\n", config.Listen, config.Interval)
+		fmt.Printf("JSON API available under http://%s/api/v1/\n", config.Listen)
+		if config.TokenFile == "" {
+			fmt.Printf("Process actions disabled (no token file configured)\n")
+		}
+	}
+
+	return srv.Run(ctx)
+}
@@ -5,6 +5,7 @@ go 1.24.2
 require (
 	github.com/StackExchange/wmi v1.2.1
 	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/godbus/dbus/v5 v5.2.2
 	github.com/rivo/tview v0.0.0-20250625164341-a4a78f1e05cb
 	github.com/shirou/gopsutil v3.21.11+incompatible
 	github.com/spf13/cobra v1.9.1
@@ -0,0 +1,281 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+)
+
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+
+	maxRecent = 50
+)
+
+type Alert struct {
+	Rule        string            `json:"rule"`
+	Severity    string            `json:"severity"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	State       State             `json:"state"`
+	Value       float64           `json:"value"`
+	ActiveSince time.Time         `json:"active_since"`
+	FiredAt     time.Time         `json:"fired_at,omitzero"`
+	ResolvedAt  time.Time         `json:"resolved_at,omitzero"`
+}
+
+// Key identifies an alert instance; one rule matching two disks yields two
+// alerts with different keys.
+func (a Alert) Key() string {
+	return history.SeriesKey(a.Rule, a.Labels)
+}
+
+func (a Alert) Summary() string {
+	var b strings.Builder
+	b.WriteString(a.Rule)
+	if len(a.Labels) > 0 {
+		keys := make([]string, 0, len(a.Labels))
+		for k := range a.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]string, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, a.Labels[k])
+		}
+		fmt.Fprintf(&b, "[%s]", strings.Join(values, ","))
+	}
+	fmt.Fprintf(&b, " = %.2f", a.Value)
+	return b.String()
+}
+
+// Event reports a state change: an alert starting to fire or resolving.
+type Event struct {
+	Alert Alert
+	Time  time.Time
+}
+
+type Engine struct {
+	mu     sync.RWMutex
+	rules  []*CompiledRule
+	alerts map[string]*Alert
+	recent []Alert
+}
+
+func NewEngine(rules []Rule) (*Engine, error) {
+	e := &Engine{alerts: make(map[string]*Alert)}
+	for _, rule := range rules {
+		compiled, err := Compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Evaluate runs every rule against the snapshot and returns the alerts that
+// started firing or resolved. A rule whose metrics are missing from the
+// snapshot leaves its alerts untouched, so a failed collection does not
+// resolve anything.
+func (e *Engine) Evaluate(snapshot *collector.Snapshot, now time.Time) []Event {
+	if e == nil || snapshot == nil {
+		return nil
+	}
+
+	ctx := newEvalContext(snapshot)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var events []Event
+	for _, rule := range e.rules {
+		events = append(events, e.evaluateRule(rule, ctx, now)...)
+	}
+	return events
+}
+
+func (e *Engine) evaluateRule(rule *CompiledRule, ctx *evalContext, now time.Time) []Event {
+	ctx.hysteresis = 0
+	result := rule.expr.eval(ctx, true)
+	if len(result) == 0 {
+		return nil
+	}
+
+	matches := make(map[string]Alert)
+	for _, s := range result {
+		if s.value == 0 {
+			continue
+		}
+		alert := Alert{Rule: rule.Name, Severity: rule.Severity, Description: rule.Description, Labels: s.labels, Value: s.observed}
+		matches[alert.Key()] = alert
+	}
+
+	// Alerts that are already active only end once the value has moved back
+	// past the hysteresis margin.
+	if rule.Hysteresis > 0 {
+		ctx.hysteresis = rule.Hysteresis
+		for _, s := range rule.expr.eval(ctx, true) {
+			if s.value == 0 {
+				continue
+			}
+			alert := Alert{Rule: rule.Name, Severity: rule.Severity, Description: rule.Description, Labels: s.labels, Value: s.observed}
+			key := alert.Key()
+			if _, active := e.alerts[key]; active {
+				if _, matched := matches[key]; !matched {
+					matches[key] = alert
+				}
+			}
+		}
+		ctx.hysteresis = 0
+	}
+
+	var events []Event
+	for key, match := range matches {
+		alert, exists := e.alerts[key]
+		if !exists {
+			match.State = StatePending
+			match.ActiveSince = now
+			alert = &match
+			e.alerts[key] = alert
+		}
+		alert.Value = match.Value
+
+		if alert.State == StatePending && now.Sub(alert.ActiveSince) >= rule.For {
+			alert.State = StateFiring
+			alert.FiredAt = now
+			events = append(events, Event{Alert: *alert, Time: now})
+		}
+	}
+
+	for key, alert := range e.alerts {
+		if alert.Rule != rule.Name {
+			continue
+		}
+		if _, matched := matches[key]; matched {
+			continue
+		}
+
+		delete(e.alerts, key)
+		if alert.State != StateFiring {
+			continue
+		}
+
+		alert.State = StateResolved
+		alert.ResolvedAt = now
+		events = append(events, Event{Alert: *alert, Time: now})
+		e.recent = append([]Alert{*alert}, e.recent...)
+		if len(e.recent) > maxRecent {
+			e.recent = e.recent[:maxRecent]
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Alert.Key() < events[j].Alert.Key()
+	})
+	return events
+}
+
+// Active returns the firing alerts, most severe first.
+func (e *Engine) Active() []Alert {
+	return e.list(StateFiring)
+}
+
+// Pending returns alerts whose condition holds but whose "for" duration has
+// not elapsed yet.
+func (e *Engine) Pending() []Alert {
+	return e.list(StatePending)
+}
+
+// Recent returns resolved alerts, newest first.
+func (e *Engine) Recent() []Alert {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]Alert(nil), e.recent...)
+}
+
+func (e *Engine) Rules() []CompiledRule {
+	if e == nil {
+		return nil
+	}
+
+	rules := make([]CompiledRule, 0, len(e.rules))
+	for _, rule := range e.rules {
+		rules = append(rules, *rule)
+	}
+	return rules
+}
+
+// Collectors returns the sorted names of the collectors the rules read.
+func (e *Engine) Collectors() []string {
+	if e == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range e.rules {
+		walk(rule.expr, func(n node) {
+			if ref, ok := n.(*refNode); ok {
+				seen[namespaceCollectors[ref.namespace]] = true
+			}
+		})
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Engine) list(state State) []Alert {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var alerts []Alert
+	for _, alert := range e.alerts {
+		if alert.State == state {
+			alerts = append(alerts, *alert)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if ri, rj := severityRank(alerts[i].Severity), severityRank(alerts[j].Severity); ri != rj {
+			return ri > rj
+		}
+		if !alerts[i].ActiveSince.Equal(alerts[j].ActiveSince) {
+			return alerts[i].ActiveSince.Before(alerts[j].ActiveSince)
+		}
+		return alerts[i].Key() < alerts[j].Key()
+	})
+	return alerts
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
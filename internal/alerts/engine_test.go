@@ -0,0 +1,155 @@
+package alerts
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+)
+
+func cpuSnapshot(value float64) *collector.Snapshot {
+	return snapshotOf(collector.GaugePoint("cpu_usage_percent", value, nil))
+}
+
+func TestEngine(t *testing.T) {
+	start := time.Now()
+
+	t.Run("For Duration", func(t *testing.T) {
+		engine, err := NewEngine([]Rule{{Name: "high_cpu", Expr: "cpu.total > 90 for 2m"}})
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+
+		if events := engine.Evaluate(cpuSnapshot(95), start); len(events) != 0 {
+			t.Fatalf("Expected no events while pending, got %v", events)
+		}
+		if len(engine.Pending()) != 1 {
+			t.Fatalf("Expected one pending alert, got %v", engine.Pending())
+		}
+
+		events := engine.Evaluate(cpuSnapshot(96), start.Add(2*time.Minute))
+		if len(events) != 1 || events[0].Alert.State != StateFiring {
+			t.Fatalf("Expected firing event, got %v", events)
+		}
+		if active := engine.Active(); len(active) != 1 || active[0].Value != 96 {
+			t.Errorf("Expected one active alert with value 96, got %v", active)
+		}
+
+		// Already firing: no duplicate event.
+		if events := engine.Evaluate(cpuSnapshot(97), start.Add(3*time.Minute)); len(events) != 0 {
+			t.Errorf("Expected deduplicated events, got %v", events)
+		}
+
+		events = engine.Evaluate(cpuSnapshot(10), start.Add(4*time.Minute))
+		if len(events) != 1 || events[0].Alert.State != StateResolved {
+			t.Fatalf("Expected resolved event, got %v", events)
+		}
+		if len(engine.Active()) != 0 || len(engine.Recent()) != 1 {
+			t.Errorf("Expected alert to move to recent, active=%v recent=%v", engine.Active(), engine.Recent())
+		}
+	})
+
+	t.Run("Pending Reset", func(t *testing.T) {
+		engine, _ := NewEngine([]Rule{{Name: "high_cpu", Expr: "cpu.total > 90 for 1m"}})
+		engine.Evaluate(cpuSnapshot(95), start)
+		if events := engine.Evaluate(cpuSnapshot(50), start.Add(30*time.Second)); len(events) != 0 {
+			t.Errorf("Expected pending alert to clear silently, got %v", events)
+		}
+		if events := engine.Evaluate(cpuSnapshot(95), start.Add(70*time.Second)); len(events) != 0 {
+			t.Errorf("Expected timer to restart, got %v", events)
+		}
+	})
+
+	t.Run("Hysteresis", func(t *testing.T) {
+		engine, _ := NewEngine([]Rule{{Name: "high_cpu", Expr: "cpu.total > 90", Hysteresis: 5}})
+		if events := engine.Evaluate(cpuSnapshot(88), start); len(events) != 0 {
+			t.Fatalf("Expected hysteresis not to lower the firing threshold, got %v", events)
+		}
+		if events := engine.Evaluate(cpuSnapshot(91), start); len(events) != 1 {
+			t.Fatalf("Expected alert to fire, got %v", events)
+		}
+		if events := engine.Evaluate(cpuSnapshot(87), start.Add(time.Second)); len(events) != 0 {
+			t.Errorf("Expected alert to stay firing within the margin, got %v", events)
+		}
+		if events := engine.Evaluate(cpuSnapshot(84), start.Add(2*time.Second)); len(events) != 1 {
+			t.Errorf("Expected alert to resolve past the margin, got %v", events)
+		}
+	})
+
+	t.Run("Hysteresis Under Not", func(t *testing.T) {
+		engine, _ := NewEngine([]Rule{{Name: "low_battery", Expr: "not battery.level >= 15", Hysteresis: 2}})
+		level := func(v float64) *collector.Snapshot {
+			return snapshotOf(collector.GaugePoint("battery_level_percent", v, nil))
+		}
+		if events := engine.Evaluate(level(14), start); len(events) != 1 {
+			t.Fatalf("Expected alert to fire, got %v", events)
+		}
+		if events := engine.Evaluate(level(16), start.Add(time.Second)); len(events) != 0 {
+			t.Errorf("Expected alert to stay firing within the margin, got %v", events)
+		}
+		if events := engine.Evaluate(level(18), start.Add(2*time.Second)); len(events) != 1 {
+			t.Errorf("Expected alert to resolve past the margin, got %v", events)
+		}
+	})
+
+	t.Run("Missing Data Keeps State", func(t *testing.T) {
+		engine, _ := NewEngine([]Rule{{Name: "high_cpu", Expr: "cpu.total > 90"}})
+		engine.Evaluate(cpuSnapshot(95), start)
+		if events := engine.Evaluate(collector.NewSnapshot(), start.Add(time.Second)); len(events) != 0 {
+			t.Errorf("Expected no events without data, got %v", events)
+		}
+		if len(engine.Active()) != 1 {
+			t.Error("Expected alert to remain active")
+		}
+	})
+
+	t.Run("Per Label Alerts", func(t *testing.T) {
+		engine, _ := NewEngine([]Rule{{Name: "disk_full", Expr: "disk.used_percent > 85", Severity: SeverityCritical}})
+		snapshot := snapshotOf(
+			collector.GaugePoint("disk_used_percent", 90, map[string]string{"mountpoint": "/"}),
+			collector.GaugePoint("disk_used_percent", 95, map[string]string{"mountpoint": "/home"}),
+		)
+		if events := engine.Evaluate(snapshot, start); len(events) != 2 {
+			t.Fatalf("Expected one alert per mount, got %v", events)
+		}
+		active := engine.Active()
+		if active[0].Summary() != "disk_full[/] = 90.00" {
+			t.Errorf("Unexpected summary %q", active[0].Summary())
+		}
+	})
+
+	t.Run("Invalid Rule", func(t *testing.T) {
+		if _, err := NewEngine([]Rule{{Name: "bad", Expr: "cpu.total >"}}); err == nil {
+			t.Error("Expected error for invalid rule")
+		}
+	})
+
+	t.Run("Collectors", func(t *testing.T) {
+		engine, err := NewEngine([]Rule{
+			{Name: "low_battery", Expr: "battery.level < 15 and not charging"},
+			{Name: "busy", Expr: "cpu.total > 90 or core[0].usage > 99 or process[nginx].count < 1"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create engine: %v", err)
+		}
+
+		want := []string{collector.Battery, collector.CPU, collector.ProcessTree}
+		if got := engine.Collectors(); !slices.Equal(got, want) {
+			t.Errorf("Expected collectors %v, got %v", want, got)
+		}
+
+		for namespace := range namespaces {
+			if namespaceCollectors[namespace] == "" {
+				t.Errorf("Namespace %s has no collector", namespace)
+			}
+		}
+	})
+
+	t.Run("Nil Engine", func(t *testing.T) {
+		var engine *Engine
+		if engine.Evaluate(cpuSnapshot(95), start) != nil || engine.Active() != nil || engine.Recent() != nil {
+			t.Error("Expected nil engine to be inert")
+		}
+	})
+}
@@ -0,0 +1,519 @@
+package alerts
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syspulse/internal/collector"
+)
+
+// Process is the per-process view rules can match with process[name].field.
+type Process struct {
+	PID        int32
+	Name       string
+	CPUPercent float64
+	Memory     uint64
+}
+
+// ProcessSource is implemented by samples that can list individual
+// processes, such as the process tree.
+type ProcessSource interface {
+	AlertProcesses() []Process
+}
+
+type sample struct {
+	labels   map[string]string
+	value    float64
+	observed float64
+}
+
+type vector []sample
+
+type evalContext struct {
+	points     map[string][]collector.Point
+	processes  []Process
+	hysteresis float64
+}
+
+func newEvalContext(snapshot *collector.Snapshot) *evalContext {
+	ctx := &evalContext{points: make(map[string][]collector.Point)}
+	for _, record := range snapshot.Records() {
+		if record.Sample == nil {
+			continue
+		}
+		for _, point := range record.Sample.Points() {
+			ctx.points[point.Name] = append(ctx.points[point.Name], point)
+		}
+		if source, ok := record.Sample.(ProcessSource); ok {
+			ctx.processes = append(ctx.processes, source.AlertProcesses()...)
+		}
+	}
+	return ctx
+}
+
+type node interface {
+	eval(ctx *evalContext, positive bool) vector
+}
+
+type numberNode struct {
+	value float64
+}
+
+type refNode struct {
+	namespace string
+	selector  string
+	field     string
+	pos       int
+	resolve   resolver
+}
+
+type arithNode struct {
+	op          string
+	left, right node
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *numberNode) eval(ctx *evalContext, positive bool) vector {
+	return vector{{value: n.value, observed: n.value}}
+}
+
+func (n *refNode) eval(ctx *evalContext, positive bool) vector {
+	return n.resolve(ctx, n.selector)
+}
+
+func (n *arithNode) eval(ctx *evalContext, positive bool) vector {
+	return join(n.left.eval(ctx, positive), n.right.eval(ctx, positive), func(l, r sample) (sample, bool) {
+		var value float64
+		switch n.op {
+		case "+":
+			value = l.value + r.value
+		case "-":
+			value = l.value - r.value
+		case "*":
+			value = l.value * r.value
+		case "/":
+			if r.value == 0 {
+				return sample{}, false
+			}
+			value = l.value / r.value
+		}
+		return sample{value: value, observed: value}, true
+	})
+}
+
+// eval compares both sides. With hysteresis set, the threshold is shifted so
+// that a condition which already holds keeps holding until the value crosses
+// back by the margin; positive is false under a "not", flipping the shift.
+func (n *compareNode) eval(ctx *evalContext, positive bool) vector {
+	margin := ctx.hysteresis
+	if !positive {
+		margin = -margin
+	}
+
+	return join(n.left.eval(ctx, positive), n.right.eval(ctx, positive), func(l, r sample) (sample, bool) {
+		var result bool
+		switch n.op {
+		case ">":
+			result = l.value > r.value-margin
+		case ">=":
+			result = l.value >= r.value-margin
+		case "<":
+			result = l.value < r.value+margin
+		case "<=":
+			result = l.value <= r.value+margin
+		case "==":
+			result = l.value == r.value
+		case "!=":
+			result = l.value != r.value
+		}
+		return sample{value: boolValue(result), observed: l.value}, true
+	})
+}
+
+func (n *logicalNode) eval(ctx *evalContext, positive bool) vector {
+	left := n.left.eval(ctx, positive)
+	right := n.right.eval(ctx, positive)
+
+	combined := join(left, right, func(l, r sample) (sample, bool) {
+		var result bool
+		if n.op == "and" {
+			result = l.value != 0 && r.value != 0
+		} else {
+			result = l.value != 0 || r.value != 0
+		}
+		observed := l.observed
+		if l.value == 0 && r.value != 0 {
+			observed = r.observed
+		}
+		return sample{value: boolValue(result), observed: observed}, true
+	})
+
+	if n.op == "and" {
+		return combined
+	}
+
+	// "or" keeps the sides that found no partner, so a missing metric on one
+	// side does not hide the other.
+	for _, side := range []vector{left, right} {
+		for _, s := range side {
+			if !hasMatch(combined, s.labels) {
+				combined = append(combined, sample{labels: s.labels, value: boolValue(s.value != 0), observed: s.observed})
+			}
+		}
+	}
+	return combined
+}
+
+func (n *notNode) eval(ctx *evalContext, positive bool) vector {
+	operand := n.operand.eval(ctx, !positive)
+	result := make(vector, 0, len(operand))
+	for _, s := range operand {
+		result = append(result, sample{labels: s.labels, value: boolValue(s.value == 0), observed: s.observed})
+	}
+	return result
+}
+
+// join pairs samples whose labels agree on every key they share. A side
+// without labels, such as a number literal, matches everything.
+func join(left, right vector, combine func(l, r sample) (sample, bool)) vector {
+	var result vector
+	for _, l := range left {
+		for _, r := range right {
+			if !compatible(l.labels, r.labels) {
+				continue
+			}
+			s, ok := combine(l, r)
+			if !ok {
+				continue
+			}
+			s.labels = mergeLabels(l.labels, r.labels)
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func compatible(a, b map[string]string) bool {
+	for key, value := range a {
+		if other, exists := b[key]; exists && other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func hasMatch(v vector, labels map[string]string) bool {
+	for _, s := range v {
+		if compatible(s.labels, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeLabels(a, b map[string]string) map[string]string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make(map[string]string, len(a)+len(b))
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range b {
+		merged[key] = value
+	}
+	return merged
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type resolver func(ctx *evalContext, selector string) vector
+
+// metric reads a point by name. When label is set the namespace is indexed
+// by it and a selector keeps only the matching entity.
+func metric(name, label string) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		var result vector
+		for _, point := range ctx.points[name] {
+			labels := map[string]string(nil)
+			if label != "" {
+				entity := point.Labels[label]
+				if selector != "" && !matchSelector(selector, entity) {
+					continue
+				}
+				labels = map[string]string{label: entity}
+			}
+			result = append(result, sample{labels: labels, value: point.Value, observed: point.Value})
+		}
+		return result
+	}
+}
+
+// maxOf returns the highest value of a labelled metric, keeping the label of
+// the entity it came from so that "temperature.max > sensor.high" compares
+// the hottest sensor against its own threshold.
+func maxOf(name, label string) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		var best *collector.Point
+		for i, point := range ctx.points[name] {
+			if best == nil || point.Value > best.Value {
+				best = &ctx.points[name][i]
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		return vector{{labels: map[string]string{label: best.Labels[label]}, value: best.Value, observed: best.Value}}
+	}
+}
+
+func ratio(numerator, denominator, label string) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		return join(metric(numerator, label)(ctx, selector), metric(denominator, label)(ctx, selector), func(n, d sample) (sample, bool) {
+			if d.value == 0 {
+				return sample{}, false
+			}
+			value := n.value / d.value * 100
+			return sample{value: value, observed: value}, true
+		})
+	}
+}
+
+func fallback(resolvers ...resolver) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		for _, r := range resolvers {
+			if v := r(ctx, selector); len(v) > 0 {
+				return v
+			}
+		}
+		return nil
+	}
+}
+
+func processField(value func(Process) float64) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		var result vector
+		for _, p := range ctx.processes {
+			if selector != "" && !matchSelector(selector, p.Name) {
+				continue
+			}
+			v := value(p)
+			result = append(result, sample{
+				labels:   map[string]string{"name": p.Name, "pid": strconv.Itoa(int(p.PID))},
+				value:    v,
+				observed: v,
+			})
+		}
+		return result
+	}
+}
+
+// processCount counts processes per name. With a selector the count is
+// reported even when nothing matches, so "process[nginx].count < 1" fires
+// once nginx is gone.
+func processCount(ctx *evalContext, selector string) vector {
+	counts := make(map[string]float64)
+	for _, p := range ctx.processes {
+		if selector != "" && !matchSelector(selector, p.Name) {
+			continue
+		}
+		name := p.Name
+		if selector != "" {
+			name = selector
+		}
+		counts[name]++
+	}
+	if selector != "" && len(counts) == 0 && len(ctx.processes) > 0 {
+		counts[selector] = 0
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(vector, 0, len(names))
+	for _, name := range names {
+		result = append(result, sample{labels: map[string]string{"name": name}, value: counts[name], observed: counts[name]})
+	}
+	return result
+}
+
+func matchSelector(selector, value string) bool {
+	if strings.ContainsAny(selector, "*?[") {
+		matched, err := path.Match(selector, value)
+		return err == nil && matched
+	}
+	return selector == value
+}
+
+var namespaces = map[string]map[string]resolver{
+	"cpu": {
+		"total": metric("cpu_usage_percent", ""),
+	},
+	"core": {
+		"usage": metric("cpu_core_usage_percent", "core"),
+	},
+	"memory": {
+		"used_percent": metric("memory_used_percent", ""),
+		"used":         metric("memory_used_bytes", ""),
+		"available":    metric("memory_available_bytes", ""),
+		"total":        metric("memory_total_bytes", ""),
+	},
+	"swap": {
+		"used_percent": metric("swap_used_percent", ""),
+		"used":         metric("swap_used_bytes", ""),
+		"total":        metric("swap_total_bytes", ""),
+	},
+	"disk": {
+		"used_percent": metric("disk_used_percent", "mountpoint"),
+		"used":         metric("disk_used_bytes", "mountpoint"),
+		"free":         metric("disk_free_bytes", "mountpoint"),
+		"total":        metric("disk_total_bytes", "mountpoint"),
+	},
+	"disk_io": {
+		"read_bytes_per_sec":  metric("disk_read_bytes_per_second", "device"),
+		"write_bytes_per_sec": metric("disk_write_bytes_per_second", "device"),
+		"read_ops_per_sec":    metric("disk_read_ops_per_second", "device"),
+		"write_ops_per_sec":   metric("disk_write_ops_per_second", "device"),
+		"utilization":         metric("disk_utilization_percent", "device"),
+	},
+	"network": {
+		"sent_per_sec": metric("network_aggregate_transmit_bytes_per_second", ""),
+		"recv_per_sec": metric("network_aggregate_receive_bytes_per_second", ""),
+	},
+	"interface": {
+		"sent_per_sec": metric("network_transmit_bytes_per_second", "interface"),
+		"recv_per_sec": metric("network_receive_bytes_per_second", "interface"),
+	},
+	"connections": {
+		"total":       metric("network_connections_count", ""),
+		"established": stateCount("established"),
+		"listen":      stateCount("listen"),
+		"time_wait":   stateCount("time_wait"),
+		"close_wait":  stateCount("close_wait"),
+		"syn_sent":    stateCount("syn_sent"),
+		"syn_recv":    stateCount("syn_recv"),
+	},
+	"load": {
+		"load1":  metric("load1", ""),
+		"load5":  metric("load5", ""),
+		"load15": metric("load15", ""),
+	},
+	"temperature": {
+		"max": fallback(maxOf("temperature_celsius", "sensor"), metric("temperature_max_celsius", "")),
+		"avg": metric("temperature_avg_celsius", ""),
+		"cpu": metric("temperature_cpu_celsius", ""),
+		"gpu": metric("temperature_gpu_celsius", ""),
+	},
+	"sensor": {
+		"current":  metric("temperature_celsius", "sensor"),
+		"high":     metric("temperature_high_celsius", "sensor"),
+		"critical": metric("temperature_critical_celsius", "sensor"),
+	},
+	"battery": {
+		"present":  metric("battery_present", ""),
+		"level":    metric("battery_level_percent", ""),
+		"charging": metric("battery_charging", ""),
+		"voltage":  metric("battery_voltage_volts", ""),
+	},
+	"gpu": {
+		"usage":          metric("gpu_usage_percent", "index"),
+		"temperature":    metric("gpu_temperature_celsius", "index"),
+		"memory_used":    metric("gpu_memory_used_bytes", "index"),
+		"memory_percent": ratio("gpu_memory_used_bytes", "gpu_memory_total_bytes", "index"),
+		"power":          metric("gpu_power_draw_watts", "index"),
+	},
+	"pressure": {
+		"some_avg10":  metric("pressure_some_avg10", "resource"),
+		"some_avg60":  metric("pressure_some_avg60", "resource"),
+		"some_avg300": metric("pressure_some_avg300", "resource"),
+		"some_rate":   metric("pressure_some_stalled_percent", "resource"),
+		"full_avg10":  metric("pressure_full_avg10", "resource"),
+		"full_avg60":  metric("pressure_full_avg60", "resource"),
+		"full_avg300": metric("pressure_full_avg300", "resource"),
+		"full_rate":   metric("pressure_full_stalled_percent", "resource"),
+	},
+	"processes": {
+		"count": metric("processes_count", ""),
+	},
+	"process": {
+		"cpu":    processField(func(p Process) float64 { return p.CPUPercent }),
+		"memory": processField(func(p Process) float64 { return float64(p.Memory) }),
+		"count":  processCount,
+	},
+}
+
+// namespaceCollectors names the collector whose samples each namespace reads.
+var namespaceCollectors = map[string]string{
+	"cpu":         collector.CPU,
+	"core":        collector.CPU,
+	"memory":      collector.Memory,
+	"swap":        collector.Memory,
+	"disk":        collector.Disk,
+	"disk_io":     collector.DiskIO,
+	"network":     collector.Network,
+	"interface":   collector.Network,
+	"connections": collector.NetworkConnections,
+	"load":        collector.Load,
+	"temperature": collector.Temperature,
+	"sensor":      collector.Temperature,
+	"battery":     collector.Battery,
+	"gpu":         collector.GPU,
+	"pressure":    collector.Pressure,
+	"processes":   collector.ProcessTree,
+	"process":     collector.ProcessTree,
+}
+
+func stateCount(state string) resolver {
+	return func(ctx *evalContext, selector string) vector {
+		for _, point := range ctx.points["network_connections"] {
+			if point.Labels["state"] == state {
+				return vector{{value: point.Value, observed: point.Value}}
+			}
+		}
+		return nil
+	}
+}
+
+// walk visits the expression left to right.
+func walk(n node, visit func(node)) {
+	visit(n)
+	switch n := n.(type) {
+	case *arithNode:
+		walk(n.left, visit)
+		walk(n.right, visit)
+	case *compareNode:
+		walk(n.left, visit)
+		walk(n.right, visit)
+	case *logicalNode:
+		walk(n.left, visit)
+		walk(n.right, visit)
+	case *notNode:
+		walk(n.operand, visit)
+	}
+}
@@ -0,0 +1,480 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	NotifierWebhook = "webhook"
+	NotifierExec    = "exec"
+	NotifierLog     = "log"
+	NotifierDesktop = "desktop"
+
+	DefaultRateLimit      = 300
+	DefaultWebhookRetries = 3
+	DefaultNotifyTimeout  = 10
+	defaultWebhookBackoff = time.Second
+	defaultDesktopCommand = "notify-send"
+)
+
+// NotifierConfig describes one named notifier. Rules refer to notifiers by
+// name through their "notify" list.
+type NotifierConfig struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	URL       string            `json:"url,omitempty"`     // webhook
+	Headers   map[string]string `json:"headers,omitempty"` // webhook
+	Retries   int               `json:"retries,omitempty"` // webhook, attempts after the first; 0 uses the default, negative disables
+	Command   string            `json:"command,omitempty"` // exec, desktop
+	Args      []string          `json:"args,omitempty"`    // exec
+	Timeout   int               `json:"timeout,omitempty"` // Seconds per attempt
+	RateLimit int               `json:"rate_limit"`        // Minimum seconds between notifications for one alert; 0 uses the default, negative disables
+}
+
+func (c NotifierConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultNotifyTimeout * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+func (c NotifierConfig) rateLimit() time.Duration {
+	switch {
+	case c.RateLimit < 0:
+		return 0
+	case c.RateLimit == 0:
+		return DefaultRateLimit * time.Second
+	}
+	return time.Duration(c.RateLimit) * time.Second
+}
+
+// Notifier delivers alert events outside the dashboard.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// Logger is the subset of the logger/v2 logger used by the log notifier.
+type Logger interface {
+	Info(message string)
+	Warn(message string)
+}
+
+func ValidateNotifier(c NotifierConfig) error {
+	if c.Name == "" {
+		return fmt.Errorf("notifier name must be specified")
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("notifier %s: timeout cannot be negative", c.Name)
+	}
+
+	switch c.Type {
+	case NotifierWebhook:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifier %s: webhook url must be an http or https URL", c.Name)
+		}
+	case NotifierExec:
+		if c.Command == "" {
+			return fmt.Errorf("notifier %s: exec command must be specified", c.Name)
+		}
+	case NotifierLog, NotifierDesktop:
+	default:
+		return fmt.Errorf("notifier %s: unknown type %q (must be webhook, exec, log or desktop)", c.Name, c.Type)
+	}
+	return nil
+}
+
+// NewNotifier builds a notifier from its configuration. logger is only used
+// by the log notifier.
+func NewNotifier(c NotifierConfig, logger Logger) (Notifier, error) {
+	if err := ValidateNotifier(c); err != nil {
+		return nil, err
+	}
+
+	switch c.Type {
+	case NotifierWebhook:
+		return NewWebhookNotifier(c), nil
+	case NotifierExec:
+		return &ExecNotifier{name: c.Name, command: c.Command, args: c.Args, timeout: c.timeout()}, nil
+	case NotifierLog:
+		if logger == nil {
+			return nil, fmt.Errorf("notifier %s: no logger available", c.Name)
+		}
+		return &LogNotifier{name: c.Name, logger: logger}, nil
+	default:
+		command := c.Command
+		if command == "" {
+			command = defaultDesktopCommand
+		}
+		return &DesktopNotifier{name: c.Name, command: command, timeout: c.timeout()}, nil
+	}
+}
+
+type webhookPayload struct {
+	Status  State  `json:"status"`
+	Summary string `json:"summary"`
+	Host    string `json:"host,omitempty"`
+	Alert   Alert  `json:"alert"`
+	Time    string `json:"time"`
+}
+
+// WebhookNotifier posts the event as JSON. Network errors and 5xx/429
+// responses are retried with exponential backoff.
+type WebhookNotifier struct {
+	name    string
+	url     string
+	headers map[string]string
+	retries int
+	backoff time.Duration
+	client  *http.Client
+}
+
+func NewWebhookNotifier(c NotifierConfig) *WebhookNotifier {
+	retries := c.Retries
+	switch {
+	case retries == 0:
+		retries = DefaultWebhookRetries
+	case retries < 0:
+		retries = 0
+	}
+	return &WebhookNotifier{
+		name:    c.Name,
+		url:     c.URL,
+		headers: c.Headers,
+		retries: retries,
+		backoff: defaultWebhookBackoff,
+		client:  &http.Client{Timeout: c.timeout()},
+	}
+}
+
+func (n *WebhookNotifier) Name() string {
+	return n.name
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	host, _ := os.Hostname()
+	body, err := json.Marshal(webhookPayload{
+		Status:  event.Alert.State,
+		Summary: event.Alert.Summary(),
+		Host:    host,
+		Alert:   event.Alert,
+		Time:    event.Time.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.retries {
+			return fmt.Errorf("webhook %s: %w", n.name, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("webhook %s: %w", n.name, ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// ExecNotifier runs a command with the alert in SYSPULSE_ALERT_* environment
+// variables. The command is not run through a shell.
+type ExecNotifier struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+func (n *ExecNotifier) Name() string {
+	return n.name
+}
+
+func (n *ExecNotifier) Notify(ctx context.Context, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, n.command, n.args...)
+	cmd.Env = append(os.Environ(), alertEnv(event)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("exec %s: %w: %s", n.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func alertEnv(event Event) []string {
+	alert := event.Alert
+	env := []string{
+		"SYSPULSE_ALERT_RULE=" + alert.Rule,
+		"SYSPULSE_ALERT_STATE=" + string(alert.State),
+		"SYSPULSE_ALERT_SEVERITY=" + alert.Severity,
+		"SYSPULSE_ALERT_VALUE=" + strconv.FormatFloat(alert.Value, 'f', -1, 64),
+		"SYSPULSE_ALERT_SUMMARY=" + alert.Summary(),
+		"SYSPULSE_ALERT_DESCRIPTION=" + alert.Description,
+		"SYSPULSE_ALERT_TIME=" + event.Time.Format(time.RFC3339),
+		"SYSPULSE_ALERT_LABELS=" + formatLabels(alert.Labels),
+	}
+	for key, value := range alert.Labels {
+		env = append(env, "SYSPULSE_ALERT_LABEL_"+envName(key)+"="+value)
+	}
+	return env
+}
+
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// LogNotifier writes one key=value line per event.
+type LogNotifier struct {
+	name   string
+	logger Logger
+}
+
+func (n *LogNotifier) Name() string {
+	return n.name
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, event Event) error {
+	alert := event.Alert
+	line := fmt.Sprintf("alert=%s state=%s severity=%s value=%s labels=%q since=%s",
+		alert.Rule, alert.State, alert.Severity,
+		strconv.FormatFloat(alert.Value, 'f', -1, 64), formatLabels(alert.Labels),
+		alert.ActiveSince.Format(time.RFC3339))
+	if alert.Description != "" {
+		line += fmt.Sprintf(" description=%q", alert.Description)
+	}
+
+	if alert.State == StateFiring {
+		n.logger.Warn(line)
+	} else {
+		n.logger.Info(line)
+	}
+	return nil
+}
+
+// DesktopNotifier shows a desktop notification through notify-send or a
+// compatible command taking [-u urgency] <summary> <body>.
+type DesktopNotifier struct {
+	name    string
+	command string
+	timeout time.Duration
+}
+
+func (n *DesktopNotifier) Name() string {
+	return n.name
+}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	alert := event.Alert
+	title := fmt.Sprintf("SysPulse: %s %s", alert.Rule, alert.State)
+	body := alert.Summary()
+	if alert.Description != "" {
+		body += "\n" + alert.Description
+	}
+
+	cmd := exec.CommandContext(ctx, n.command, "-u", desktopUrgency(alert), "-a", "SysPulse", title, body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop %s: %w: %s", n.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func desktopUrgency(alert Alert) string {
+	if alert.State == StateResolved {
+		return "low"
+	}
+	switch alert.Severity {
+	case SeverityCritical:
+		return "critical"
+	case SeverityInfo:
+		return "low"
+	}
+	return "normal"
+}
+
+// Dispatcher routes events to the notifiers listed on their rule and drops
+// repeats of the same alert state inside each notifier's rate limit.
+type Dispatcher struct {
+	notifiers map[string]Notifier
+	limits    map[string]time.Duration
+	routes    map[string][]string
+	now       func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]sentNotification
+}
+
+type sentNotification struct {
+	notifier string
+	time     time.Time
+}
+
+func NewDispatcher(config Config, logger Logger) (*Dispatcher, error) {
+	d := &Dispatcher{
+		notifiers: make(map[string]Notifier),
+		limits:    make(map[string]time.Duration),
+		routes:    make(map[string][]string),
+		now:       time.Now,
+		sent:      make(map[string]sentNotification),
+	}
+
+	for _, c := range config.Notifiers {
+		if _, exists := d.notifiers[c.Name]; exists {
+			return nil, fmt.Errorf("duplicate notifier: %s", c.Name)
+		}
+		notifier, err := NewNotifier(c, logger)
+		if err != nil {
+			return nil, err
+		}
+		d.Add(notifier, c.rateLimit())
+	}
+
+	for _, rule := range config.Rules {
+		for _, name := range rule.Notify {
+			if _, exists := d.notifiers[name]; !exists {
+				return nil, fmt.Errorf("alert rule %s: unknown notifier %q", rule.Name, name)
+			}
+		}
+		d.routes[rule.Name] = rule.Notify
+	}
+	return d, nil
+}
+
+// Add registers a notifier; rules still have to list it by name.
+func (d *Dispatcher) Add(notifier Notifier, rateLimit time.Duration) {
+	d.notifiers[notifier.Name()] = notifier
+	d.limits[notifier.Name()] = rateLimit
+}
+
+// Route sets the notifiers used for a rule.
+func (d *Dispatcher) Route(rule string, notifiers ...string) {
+	d.routes[rule] = notifiers
+}
+
+// Dispatch delivers the events and waits for every notifier to finish. The
+// returned error joins all delivery failures.
+func (d *Dispatcher) Dispatch(ctx context.Context, events []Event) error {
+	if d == nil || len(events) == 0 {
+		return nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, event := range events {
+		for _, name := range d.routes[event.Alert.Rule] {
+			notifier, exists := d.notifiers[name]
+			if !exists || !d.allow(name, event) {
+				continue
+			}
+
+			wg.Add(1)
+			go func(notifier Notifier, event Event) {
+				defer wg.Done()
+				if err := notifier.Notify(ctx, event); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}(notifier, event)
+		}
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) allow(notifier string, event Event) bool {
+	limit := d.limits[notifier]
+	if limit <= 0 {
+		return true
+	}
+
+	key := notifier + "|" + event.Alert.Key() + "|" + string(event.Alert.State)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, exists := d.sent[key]; exists && now.Sub(last.time) < limit {
+		return false
+	}
+
+	// Alert keys carry labels such as pids and mounts, so entries past their
+	// notifier's rate limit are dropped rather than kept for good.
+	for k, sent := range d.sent {
+		if now.Sub(sent.time) >= d.limits[sent.notifier] {
+			delete(d.sent, k)
+		}
+	}
+	d.sent[key] = sentNotification{notifier: notifier, time: now}
+	return true
+}
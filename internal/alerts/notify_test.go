@@ -0,0 +1,276 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testEvent(state State) Event {
+	now := time.Now()
+	return Event{
+		Alert: Alert{
+			Rule:        "root_disk_full",
+			Severity:    SeverityCritical,
+			Description: "Root filesystem is almost full",
+			Labels:      map[string]string{"mountpoint": "/"},
+			State:       state,
+			Value:       91.5,
+			ActiveSince: now,
+		},
+		Time: now,
+	}
+}
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	name   string
+	events []Event
+}
+
+func (n *recordingNotifier) Name() string {
+	return n.name
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+	return nil
+}
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Info(message string) { l.lines = append(l.lines, "INFO "+message) }
+func (l *recordingLogger) Warn(message string) { l.lines = append(l.lines, "WARN "+message) }
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("Retries Server Errors", func(t *testing.T) {
+		var attempts atomic.Int32
+		var payload webhookPayload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "secret" {
+				t.Errorf("Unexpected headers %v", r.Header)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("Failed to decode payload: %v", err)
+			}
+		}))
+		defer server.Close()
+
+		notifier := NewWebhookNotifier(NotifierConfig{Name: "hook", URL: server.URL, Headers: map[string]string{"X-Token": "secret"}})
+		notifier.backoff = time.Millisecond
+
+		if err := notifier.Notify(context.Background(), testEvent(StateFiring)); err != nil {
+			t.Fatalf("Expected delivery after retries, got %v", err)
+		}
+		if attempts.Load() != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+		}
+		if payload.Status != StateFiring || payload.Alert.Rule != "root_disk_full" || payload.Alert.Labels["mountpoint"] != "/" {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+	})
+
+	t.Run("Gives Up", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		notifier := NewWebhookNotifier(NotifierConfig{Name: "hook", URL: server.URL, Retries: 2})
+		notifier.backoff = time.Millisecond
+
+		if err := notifier.Notify(context.Background(), testEvent(StateFiring)); err == nil || !strings.Contains(err.Error(), "502") {
+			t.Errorf("Expected status error, got %v", err)
+		}
+		if attempts.Load() != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+		}
+	})
+
+	t.Run("Client Errors Are Not Retried", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		notifier := NewWebhookNotifier(NotifierConfig{Name: "hook", URL: server.URL})
+		notifier.backoff = time.Millisecond
+
+		if err := notifier.Notify(context.Background(), testEvent(StateFiring)); err == nil {
+			t.Error("Expected error for 400 response")
+		}
+		if attempts.Load() != 1 {
+			t.Errorf("Expected a single attempt, got %d", attempts.Load())
+		}
+	})
+}
+
+func TestExecNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec test uses sh")
+	}
+
+	output := filepath.Join(t.TempDir(), "env")
+	notifier, err := NewNotifier(NotifierConfig{
+		Name:    "script",
+		Type:    NotifierExec,
+		Command: "sh",
+		Args:    []string{"-c", "env > " + output},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), testEvent(StateFiring)); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	for _, want := range []string{
+		"SYSPULSE_ALERT_RULE=root_disk_full",
+		"SYSPULSE_ALERT_STATE=firing",
+		"SYSPULSE_ALERT_SEVERITY=critical",
+		"SYSPULSE_ALERT_VALUE=91.5",
+		"SYSPULSE_ALERT_LABELS=mountpoint=/",
+		"SYSPULSE_ALERT_LABEL_MOUNTPOINT=/",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected environment to contain %s", want)
+		}
+	}
+
+	failing, _ := NewNotifier(NotifierConfig{Name: "fail", Type: NotifierExec, Command: "sh", Args: []string{"-c", "echo boom; exit 3"}}, nil)
+	if err := failing.Notify(context.Background(), testEvent(StateFiring)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected command output in error, got %v", err)
+	}
+}
+
+func TestLogNotifier(t *testing.T) {
+	if _, err := NewNotifier(NotifierConfig{Name: "log", Type: NotifierLog}, nil); err == nil {
+		t.Error("Expected error without a logger")
+	}
+
+	logger := &recordingLogger{}
+	notifier, _ := NewNotifier(NotifierConfig{Name: "log", Type: NotifierLog}, logger)
+	notifier.Notify(context.Background(), testEvent(StateFiring))
+	notifier.Notify(context.Background(), testEvent(StateResolved))
+
+	if len(logger.lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %v", logger.lines)
+	}
+	if !strings.HasPrefix(logger.lines[0], "WARN alert=root_disk_full state=firing severity=critical value=91.5 labels=\"mountpoint=/\"") {
+		t.Errorf("Unexpected firing line %q", logger.lines[0])
+	}
+	if !strings.HasPrefix(logger.lines[1], "INFO alert=root_disk_full state=resolved") {
+		t.Errorf("Unexpected resolved line %q", logger.lines[1])
+	}
+}
+
+func TestDesktopNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("desktop test uses a shell script")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "notify-send")
+	if err := os.WriteFile(script, []byte(fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$@\" > %s\n", output)), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	notifier, _ := NewNotifier(NotifierConfig{Name: "desktop", Type: NotifierDesktop, Command: script}, nil)
+	if err := notifier.Notify(context.Background(), testEvent(StateFiring)); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(output)
+	args := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(args) < 6 || args[1] != "critical" || args[4] != "SysPulse: root_disk_full firing" || args[5] != "root_disk_full[/] = 91.50" {
+		t.Errorf("Unexpected notify-send arguments %q", args)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	config := Config{
+		Rules: []Rule{
+			{Name: "root_disk_full", Expr: "disk[/].used_percent > 85", Notify: []string{"ops"}},
+			{Name: "high_cpu", Expr: "cpu.total > 90"},
+		},
+		Notifiers: []NotifierConfig{{Name: "ops", Type: NotifierLog, RateLimit: 60}},
+	}
+
+	if _, err := NewDispatcher(Config{Rules: []Rule{{Name: "x", Notify: []string{"missing"}}}}, nil); err == nil {
+		t.Error("Expected error for unknown notifier")
+	}
+	if _, err := NewDispatcher(Config{Notifiers: []NotifierConfig{{Name: "a", Type: "pager"}}}, nil); err == nil {
+		t.Error("Expected error for unknown notifier type")
+	}
+
+	dispatcher, err := NewDispatcher(config, &recordingLogger{})
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+
+	recorder := &recordingNotifier{name: "ops"}
+	dispatcher.Add(recorder, time.Minute)
+
+	now := time.Now()
+	dispatcher.now = func() time.Time { return now }
+
+	cpuEvent := testEvent(StateFiring)
+	cpuEvent.Alert.Rule = "high_cpu"
+
+	dispatcher.Dispatch(context.Background(), []Event{testEvent(StateFiring), cpuEvent})
+	if len(recorder.events) != 1 || recorder.events[0].Alert.Rule != "root_disk_full" {
+		t.Fatalf("Expected only the routed rule to notify, got %v", recorder.events)
+	}
+
+	dispatcher.Dispatch(context.Background(), []Event{testEvent(StateFiring)})
+	if len(recorder.events) != 1 {
+		t.Errorf("Expected repeat to be rate limited, got %d events", len(recorder.events))
+	}
+
+	dispatcher.Dispatch(context.Background(), []Event{testEvent(StateResolved)})
+	if len(recorder.events) != 2 {
+		t.Errorf("Expected resolution to be delivered, got %d events", len(recorder.events))
+	}
+
+	now = now.Add(2 * time.Minute)
+	dispatcher.Dispatch(context.Background(), []Event{testEvent(StateFiring)})
+	if len(recorder.events) != 3 {
+		t.Errorf("Expected delivery after the rate limit, got %d events", len(recorder.events))
+	}
+	if len(dispatcher.sent) != 1 {
+		t.Errorf("Expected notifications past the rate limit to be forgotten, got %d", len(dispatcher.sent))
+	}
+
+	var nilDispatcher *Dispatcher
+	if err := nilDispatcher.Dispatch(context.Background(), []Event{testEvent(StateFiring)}); err != nil {
+		t.Errorf("Expected nil dispatcher to be inert, got %v", err)
+	}
+}
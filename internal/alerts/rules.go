@@ -0,0 +1,455 @@
+package alerts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+
+	DefaultInterval = 5
+)
+
+// Rule is a single alert definition. Expr is a boolean expression over the
+// collected metrics, optionally followed by "for <duration>", e.g.
+// "cpu.total > 90 for 2m" or "disk[/].used_percent > 85".
+type Rule struct {
+	Name        string   `json:"name"`
+	Expr        string   `json:"expr"`
+	Severity    string   `json:"severity,omitempty"`
+	Hysteresis  float64  `json:"hysteresis,omitempty"` // Margin the value must cross back before resolving
+	Description string   `json:"description,omitempty"`
+	Notify      []string `json:"notify,omitempty"` // Names of the notifiers to send events to
+}
+
+type Config struct {
+	Enabled   bool             `json:"enabled"`
+	Interval  int              `json:"interval"` // Evaluation interval in seconds
+	Rules     []Rule           `json:"rules"`
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Enabled:  true,
+		Interval: DefaultInterval,
+		Rules: []Rule{
+			{Name: "high_cpu", Expr: "cpu.total > 90 for 2m", Severity: SeverityWarning, Hysteresis: 5},
+			{Name: "root_disk_full", Expr: "disk[/].used_percent > 85", Severity: SeverityWarning, Hysteresis: 1},
+			{Name: "overheating", Expr: "temperature.max > sensor.high for 30s", Severity: SeverityCritical, Hysteresis: 3},
+			{Name: "low_battery", Expr: "battery.level < 15 and not charging", Severity: SeverityWarning, Hysteresis: 2},
+		},
+	}
+)
+
+func ValidSeverity(severity string) bool {
+	switch severity {
+	case "", SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	}
+	return false
+}
+
+// CompiledRule is a parsed rule ready for evaluation.
+type CompiledRule struct {
+	Rule
+	For  time.Duration
+	expr node
+}
+
+func Compile(rule Rule) (*CompiledRule, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("alert rule name must be specified")
+	}
+	if !ValidSeverity(rule.Severity) {
+		return nil, fmt.Errorf("alert rule %s: invalid severity %q (must be info, warning or critical)", rule.Name, rule.Severity)
+	}
+	if rule.Hysteresis < 0 {
+		return nil, fmt.Errorf("alert rule %s: hysteresis cannot be negative", rule.Name)
+	}
+
+	expr, duration, err := Parse(rule.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("alert rule %s: %w", rule.Name, err)
+	}
+
+	compiled := &CompiledRule{Rule: rule, For: duration, expr: expr}
+	if compiled.Severity == "" {
+		compiled.Severity = SeverityWarning
+	}
+	return compiled, nil
+}
+
+// Parse parses a rule expression and its optional trailing "for" clause.
+func Parse(input string) (node, time.Duration, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(tokens) == 1 {
+		return nil, 0, fmt.Errorf("empty expression")
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var duration time.Duration
+	if p.peek().isKeyword("for") {
+		p.next()
+		tok := p.next()
+		if tok.kind != tokenIdent && tok.kind != tokenNumber {
+			return nil, 0, fmt.Errorf("expected duration after 'for' at position %d", tok.pos)
+		}
+		duration, err = time.ParseDuration(tok.text)
+		if err != nil || duration <= 0 {
+			return nil, 0, fmt.Errorf("invalid duration %q", tok.text)
+		}
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, 0, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	if err := resolveReferences(expr); err != nil {
+		return nil, 0, err
+	}
+	return expr, duration, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenSelector
+	tokenOp
+	tokenDot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || unicode.IsLetter(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+			if i < len(runes) && runes[i] == '%' {
+				i++
+			}
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated selector at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenSelector, text: strings.TrimSpace(string(runes[i+1 : end])), pos: i})
+			i = end + 1
+		case r == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case strings.ContainsRune("<>=!", r):
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{kind: tokenOp, text: string(runes[i : i+2]), pos: i})
+				i += 2
+				continue
+			}
+			if r == '=' || r == '!' {
+				return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: string(r), pos: i})
+			i++
+		case strings.ContainsRune("+-*/", r):
+			tokens = append(tokens, token{kind: tokenOp, text: string(r), pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().isKeyword("not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind == tokenOp && isComparison(tok.text) {
+		p.next()
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: tok.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokenOp && (tok.text == "+" || tok.text == "-"); tok = p.peek() {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithNode{op: tok.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokenOp && (tok.text == "*" || tok.text == "/"); tok = p.peek() {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithNode{op: tok.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.pos)
+		}
+		return expr, nil
+	case tok.kind == tokenOp && tok.text == "-":
+		operand, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &arithNode{op: "-", left: &numberNode{value: 0}, right: operand}, nil
+	case tok.kind == tokenNumber:
+		value, err := parseNumber(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return &numberNode{value: value}, nil
+	case tok.kind == tokenIdent && !isKeyword(tok.text):
+		ref := &refNode{pos: tok.pos}
+		if p.peek().kind == tokenSelector {
+			ref.namespace = tok.text
+			ref.selector = p.next().text
+			if p.peek().kind != tokenDot {
+				return nil, fmt.Errorf("expected '.' after selector at position %d", p.peek().pos)
+			}
+		}
+		if p.peek().kind == tokenDot {
+			ref.namespace = tok.text
+			p.next()
+			field := p.next()
+			if field.kind != tokenIdent {
+				return nil, fmt.Errorf("expected field name at position %d", field.pos)
+			}
+			ref.field = field.text
+		} else {
+			ref.field = tok.text
+		}
+		return ref, nil
+	case tok.kind == tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// parseNumber accepts plain numbers and byte sizes such as 512MB or 2GiB.
+func parseNumber(text string) (float64, error) {
+	end := len(text)
+	for end > 0 && unicode.IsLetter(rune(text[end-1])) {
+		end--
+	}
+
+	value, err := strconv.ParseFloat(text[:end], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	suffix := strings.ToUpper(text[end:])
+	multipliers := map[string]float64{
+		"": 1, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12,
+		"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12,
+		"KIB": 1 << 10, "MIB": 1 << 20, "GIB": 1 << 30, "TIB": 1 << 40,
+	}
+	multiplier, exists := multipliers[suffix]
+	if !exists {
+		return 0, fmt.Errorf("unknown suffix %q", suffix)
+	}
+	return value * multiplier, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+func isKeyword(text string) bool {
+	switch strings.ToLower(text) {
+	case "and", "or", "not", "for":
+		return true
+	}
+	return false
+}
+
+// resolveReferences checks every reference against the known namespaces. A
+// bare field such as "charging" inherits the namespace of the closest
+// reference before it, so "battery.level < 15 and not charging" reads
+// battery.charging.
+func resolveReferences(expr node) error {
+	namespace := ""
+	var err error
+
+	walk(expr, func(n node) {
+		ref, ok := n.(*refNode)
+		if !ok || err != nil {
+			return
+		}
+		if ref.namespace == "" {
+			if namespace == "" {
+				err = fmt.Errorf("reference %q at position %d needs a namespace, e.g. cpu.total", ref.field, ref.pos)
+				return
+			}
+			ref.namespace = namespace
+		}
+
+		fields, exists := namespaces[ref.namespace]
+		if !exists {
+			err = fmt.Errorf("unknown namespace %q at position %d", ref.namespace, ref.pos)
+			return
+		}
+		resolver, exists := fields[ref.field]
+		if !exists {
+			err = fmt.Errorf("unknown field %s.%s at position %d", ref.namespace, ref.field, ref.pos)
+			return
+		}
+		ref.resolve = resolver
+		namespace = ref.namespace
+	})
+	return err
+}
@@ -0,0 +1,215 @@
+package alerts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+)
+
+type testSample struct {
+	points    []collector.Point
+	processes []Process
+}
+
+func (s *testSample) Points() []collector.Point {
+	return s.points
+}
+
+func (s *testSample) AlertProcesses() []Process {
+	return s.processes
+}
+
+func snapshotOf(points ...collector.Point) *collector.Snapshot {
+	snapshot := collector.NewSnapshot()
+	snapshot.Set("test", &testSample{points: points})
+	return snapshot
+}
+
+func evalRule(t *testing.T, expr string, snapshot *collector.Snapshot) vector {
+	t.Helper()
+	rule, err := Compile(Rule{Name: "test", Expr: expr})
+	if err != nil {
+		t.Fatalf("Failed to compile %q: %v", expr, err)
+	}
+	return rule.expr.eval(newEvalContext(snapshot), true)
+}
+
+func firing(v vector) int {
+	count := 0
+	for _, s := range v {
+		if s.value != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr     string
+		duration time.Duration
+		wantErr  string
+	}{
+		{expr: "cpu.total > 90 for 2m", duration: 2 * time.Minute},
+		{expr: "disk[/].used_percent > 85"},
+		{expr: "temperature.max > sensor.high"},
+		{expr: "battery.level < 15 and not charging"},
+		{expr: "process[chrome*].memory > 2GB or (load.load1 / 4) >= 1.5"},
+		{expr: "memory.used_percent > 90% for 1h30m", duration: 90 * time.Minute},
+		{expr: "", wantErr: "empty expression"},
+		{expr: "cpu.total >", wantErr: "unexpected end"},
+		{expr: "cpu.bogus > 1", wantErr: "unknown field cpu.bogus"},
+		{expr: "nothing.total > 1", wantErr: "unknown namespace"},
+		{expr: "charging", wantErr: "needs a namespace"},
+		{expr: "disk[/ > 1", wantErr: "unterminated selector"},
+		{expr: "cpu.total > 90 for soon", wantErr: "invalid duration"},
+		{expr: "cpu.total > 5XB", wantErr: "invalid number"},
+		{expr: "cpu.total = 5", wantErr: "unexpected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, duration, err := Parse(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if duration != tt.duration {
+				t.Errorf("Expected duration %v, got %v", tt.duration, duration)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile(Rule{Expr: "cpu.total > 1"}); err == nil {
+		t.Error("Expected error for missing name")
+	}
+	if _, err := Compile(Rule{Name: "x", Expr: "cpu.total > 1", Severity: "panic"}); err == nil {
+		t.Error("Expected error for invalid severity")
+	}
+	if _, err := Compile(Rule{Name: "x", Expr: "cpu.total > 1", Hysteresis: -1}); err == nil {
+		t.Error("Expected error for negative hysteresis")
+	}
+
+	rule, err := Compile(Rule{Name: "x", Expr: "cpu.total > 1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rule.Severity != SeverityWarning {
+		t.Errorf("Expected default severity warning, got %s", rule.Severity)
+	}
+
+	for _, rule := range DefaultConfig.Rules {
+		if _, err := Compile(rule); err != nil {
+			t.Errorf("Default rule %s does not compile: %v", rule.Name, err)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	mounts := func(root, home float64) *collector.Snapshot {
+		return snapshotOf(
+			collector.GaugePoint("disk_used_percent", root, map[string]string{"mountpoint": "/", "device": "sda1"}),
+			collector.GaugePoint("disk_used_percent", home, map[string]string{"mountpoint": "/home", "device": "sda2"}),
+		)
+	}
+
+	t.Run("Selector", func(t *testing.T) {
+		if got := firing(evalRule(t, "disk[/].used_percent > 85", mounts(90, 10))); got != 1 {
+			t.Errorf("Expected root disk to match, got %d", got)
+		}
+		if got := firing(evalRule(t, "disk[/].used_percent > 85", mounts(10, 90))); got != 0 {
+			t.Errorf("Expected /home to be ignored, got %d", got)
+		}
+		if got := firing(evalRule(t, "disk.used_percent > 85", mounts(90, 90))); got != 2 {
+			t.Errorf("Expected both mounts to match, got %d", got)
+		}
+	})
+
+	t.Run("Sensor Threshold", func(t *testing.T) {
+		snapshot := snapshotOf(
+			collector.GaugePoint("temperature_celsius", 85, map[string]string{"sensor": "cpu"}),
+			collector.GaugePoint("temperature_celsius", 60, map[string]string{"sensor": "nvme"}),
+			collector.GaugePoint("temperature_high_celsius", 80, map[string]string{"sensor": "cpu"}),
+			collector.GaugePoint("temperature_high_celsius", 50, map[string]string{"sensor": "nvme"}),
+		)
+		result := evalRule(t, "temperature.max > sensor.high", snapshot)
+		if firing(result) != 1 || result[0].labels["sensor"] != "cpu" || result[0].observed != 85 {
+			t.Errorf("Expected the hottest sensor to exceed its own limit, got %+v", result)
+		}
+	})
+
+	t.Run("Battery", func(t *testing.T) {
+		battery := func(level, charging float64) *collector.Snapshot {
+			return snapshotOf(
+				collector.GaugePoint("battery_level_percent", level, nil),
+				collector.GaugePoint("battery_charging", charging, nil),
+			)
+		}
+		if firing(evalRule(t, "battery.level < 15 and not charging", battery(10, 0))) != 1 {
+			t.Error("Expected low battery to fire while discharging")
+		}
+		if firing(evalRule(t, "battery.level < 15 and not charging", battery(10, 1))) != 0 {
+			t.Error("Expected low battery not to fire while charging")
+		}
+	})
+
+	t.Run("Pressure", func(t *testing.T) {
+		snapshot := snapshotOf(
+			collector.GaugePoint("pressure_some_avg10", 30, map[string]string{"resource": "memory"}),
+			collector.GaugePoint("pressure_some_avg10", 2, map[string]string{"resource": "io"}),
+			collector.GaugePoint("pressure_full_stalled_percent", 12, map[string]string{"resource": "memory"}),
+		)
+		result := evalRule(t, "pressure.some_avg10 > 20", snapshot)
+		if firing(result) != 1 || result[0].labels["resource"] != "memory" {
+			t.Errorf("Expected memory pressure to fire, got %+v", result)
+		}
+		if firing(evalRule(t, "pressure[memory].full_rate > 10", snapshot)) != 1 {
+			t.Error("Expected the memory stall rate to fire")
+		}
+	})
+
+	t.Run("Processes", func(t *testing.T) {
+		snapshot := collector.NewSnapshot()
+		snapshot.Set("test", &testSample{processes: []Process{
+			{PID: 1, Name: "init", CPUPercent: 0.1, Memory: 1 << 20},
+			{PID: 10, Name: "chrome", CPUPercent: 40, Memory: 3 << 30},
+			{PID: 11, Name: "chrome-gpu", CPUPercent: 5, Memory: 1 << 30},
+		}})
+
+		if got := firing(evalRule(t, "process[chrome*].memory > 2GiB", snapshot)); got != 1 {
+			t.Errorf("Expected one chrome process over 2GiB, got %d", got)
+		}
+		if got := firing(evalRule(t, "process[nginx].count < 1", snapshot)); got != 1 {
+			t.Errorf("Expected missing nginx to fire, got %d", got)
+		}
+		if got := firing(evalRule(t, "process[chrome*].count >= 2", snapshot)); got != 1 {
+			t.Errorf("Expected chrome count to match, got %d", got)
+		}
+	})
+
+	t.Run("Arithmetic", func(t *testing.T) {
+		snapshot := snapshotOf(
+			collector.GaugePoint("memory_used_bytes", 6<<30, nil),
+			collector.GaugePoint("memory_total_bytes", 8<<30, nil),
+		)
+		if firing(evalRule(t, "memory.used / memory.total * 100 > 70", snapshot)) != 1 {
+			t.Error("Expected computed usage to exceed 70")
+		}
+	})
+
+	t.Run("Or Keeps Unmatched Sides", func(t *testing.T) {
+		snapshot := snapshotOf(collector.GaugePoint("load1", 8, nil))
+		if firing(evalRule(t, "cpu.total > 90 or load.load1 > 4", snapshot)) != 1 {
+			t.Error("Expected load to fire even though cpu is missing")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package builtin
+
+import (
+	"syspulse/internal/collector"
+	"syspulse/internal/services/battery"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/services/disk"
+	"syspulse/internal/services/gpu"
+	"syspulse/internal/services/load"
+	"syspulse/internal/services/memory"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/services/sysinfo"
+	"syspulse/internal/services/temperature"
+)
+
+func Collectors() []collector.Collector {
+	return []collector.Collector{
+		sysinfo.NewCPUCollector(),
+		memory.NewCollector(),
+		disk.NewUsageCollector(),
+		disk.NewIOCollector(),
+		network.NewIOCollector(),
+		network.NewConnectionsCollector(),
+		load.NewCollector(),
+		temperature.NewCollector(),
+		battery.NewCollector(),
+		gpu.NewCollector(),
+		processes.NewTreeCollector(),
+		cgroups.NewCollector(),
+		pressure.NewCollector(),
+	}
+}
+
+// NewRegistry returns a registry with every built-in collector, or only the
+// named ones when names are given.
+func NewRegistry(names ...string) *collector.Registry {
+	registry := collector.NewRegistry()
+	for _, c := range Collectors() {
+		if len(names) == 0 || containsName(names, c.Name()) {
+			registry.Register(c)
+		}
+	}
+	return registry
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSample returns an empty sample of the type the named built-in collector
+// produces, for decoding recorded samples.
+func NewSample(name string) (collector.Sample, bool) {
+	switch name {
+	case collector.CPU:
+		return &sysinfo.CPUSample{}, true
+	case collector.Memory:
+		return &memory.MemorySample{}, true
+	case collector.Disk:
+		return &disk.UsageSample{}, true
+	case collector.DiskIO:
+		return &disk.DiskIOData{}, true
+	case collector.Network:
+		return &network.IOSample{}, true
+	case collector.NetworkConnections:
+		return &network.ConnectionStats{}, true
+	case collector.Load:
+		return &load.LoadAverage{}, true
+	case collector.Temperature:
+		return &temperature.TemperatureData{}, true
+	case collector.Battery:
+		return &battery.BatteryInfo{}, true
+	case collector.GPU:
+		return &gpu.GPUSample{}, true
+	case collector.ProcessTree:
+		return &processes.ProcessTree{}, true
+	case collector.Cgroups:
+		return &cgroups.CgroupSample{}, true
+	case collector.Pressure:
+		return &pressure.PressureSample{}, true
+	}
+	return nil, false
+}
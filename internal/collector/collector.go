@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	CPU                = "cpu"
+	Memory             = "memory"
+	Disk               = "disk"
+	DiskIO             = "disk_io"
+	Network            = "network"
+	NetworkConnections = "network_connections"
+	Load               = "load"
+	Temperature        = "temperature"
+	Battery            = "battery"
+	GPU                = "gpu"
+	ProcessTree        = "process_tree"
+	Cgroups            = "cgroups"
+	Pressure           = "pressure"
+	Watch              = "watch"
+)
+
+type MetricType string
+
+const (
+	Gauge   MetricType = "gauge"
+	Counter MetricType = "counter"
+)
+
+type Point struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	Type   MetricType        `json:"type"`
+}
+
+func GaugePoint(name string, value float64, labels map[string]string) Point {
+	return Point{Name: name, Labels: labels, Value: value, Type: Gauge}
+}
+
+func CounterPoint(name string, value float64, labels map[string]string) Point {
+	return Point{Name: name, Labels: labels, Value: value, Type: Counter}
+}
+
+// Sample is the typed result of a single collection. Every subsystem defines
+// its own sample struct; Points flattens it into labelled numeric values.
+type Sample interface {
+	Points() []Point
+}
+
+// Collector gathers data for one subsystem without touching any widget, so
+// the dashboard and the headless exporters can share the same code path.
+type Collector interface {
+	Name() string
+	Collect(ctx context.Context) (Sample, error)
+}
+
+type Record struct {
+	Collector string        `json:"collector"`
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Sample    Sample        `json:"sample,omitempty"`
+	Err       error         `json:"-"`
+}
+
+type Snapshot struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		records: make(map[string]Record),
+	}
+}
+
+func (s *Snapshot) Set(name string, sample Sample) {
+	s.Put(Record{Collector: name, Time: time.Now(), Sample: sample})
+}
+
+func (s *Snapshot) Put(record Record) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.Sample == nil {
+		if previous, exists := s.records[record.Collector]; exists {
+			record.Sample = previous.Sample
+		}
+	}
+	s.records[record.Collector] = record
+}
+
+func (s *Snapshot) Get(name string) (Sample, bool) {
+	record, exists := s.Record(name)
+	if !exists || record.Sample == nil {
+		return nil, false
+	}
+	return record.Sample, true
+}
+
+func (s *Snapshot) Record(name string) (Record, bool) {
+	if s == nil {
+		return Record{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, exists := s.records[name]
+	return record, exists
+}
+
+func (s *Snapshot) Records() []Record {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	records := make([]Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Collector < records[j].Collector
+	})
+	return records
+}
+
+func (s *Snapshot) Points() []Point {
+	var points []Point
+	for _, record := range s.Records() {
+		if record.Sample != nil {
+			points = append(points, record.Sample.Points()...)
+		}
+	}
+	return points
+}
+
+func (s *Snapshot) Time() time.Time {
+	var latest time.Time
+	for _, record := range s.Records() {
+		if record.Time.After(latest) {
+			latest = record.Time
+		}
+	}
+	return latest
+}
+
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+func NewRegistry(collectors ...Collector) *Registry {
+	r := &Registry{}
+	for _, c := range collectors {
+		r.Register(c)
+	}
+	return r
+}
+
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.collectors {
+		if existing.Name() == c.Name() {
+			r.collectors[i] = c
+			return
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.collectors {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) Collectors() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	return collectors
+}
+
+func (r *Registry) CollectAll(ctx context.Context) *Snapshot {
+	snapshot := NewSnapshot()
+	r.CollectInto(ctx, snapshot)
+	return snapshot
+}
+
+func (r *Registry) CollectInto(ctx context.Context, snapshot *Snapshot) {
+	for _, c := range r.Collectors() {
+		if ctx.Err() != nil {
+			return
+		}
+		snapshot.Put(Run(ctx, c))
+	}
+}
+
+func Run(ctx context.Context, c Collector) Record {
+	start := time.Now()
+	sample, err := c.Collect(ctx)
+	record := Record{
+		Collector: c.Name(),
+		Time:      time.Now(),
+		Duration:  time.Since(start),
+		Sample:    sample,
+		Err:       err,
+	}
+	if err != nil {
+		record.Sample = nil
+		record.Err = fmt.Errorf("%s collector: %w", c.Name(), err)
+	}
+	return record
+}
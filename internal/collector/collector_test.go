@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testSample struct {
+	value float64
+}
+
+func (s *testSample) Points() []Point {
+	return []Point{GaugePoint("test_value", s.value, map[string]string{"source": "test"})}
+}
+
+type testCollector struct {
+	name  string
+	value float64
+	err   error
+}
+
+func (c *testCollector) Name() string {
+	return c.name
+}
+
+func (c *testCollector) Collect(ctx context.Context) (Sample, error) {
+	if c.err != nil {
+		return (*testSample)(nil), c.err
+	}
+	return &testSample{value: c.value}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Collect All", func(t *testing.T) {
+		r := NewRegistry(&testCollector{name: "a", value: 1}, &testCollector{name: "b", value: 2})
+		snapshot := r.CollectAll(context.Background())
+
+		sample, ok := snapshot.Get("b")
+		if !ok {
+			t.Fatal("Expected sample for collector b")
+		}
+		if sample.(*testSample).value != 2 {
+			t.Errorf("Expected value 2, got %v", sample.(*testSample).value)
+		}
+
+		points := snapshot.Points()
+		if len(points) != 2 {
+			t.Errorf("Expected 2 points, got %d", len(points))
+		}
+	})
+
+	t.Run("Register Replaces By Name", func(t *testing.T) {
+		r := NewRegistry(&testCollector{name: "a", value: 1})
+		r.Register(&testCollector{name: "a", value: 5})
+
+		if len(r.Collectors()) != 1 {
+			t.Fatalf("Expected 1 collector, got %d", len(r.Collectors()))
+		}
+		c, ok := r.Get("a")
+		if !ok || c.(*testCollector).value != 5 {
+			t.Error("Expected replacement collector to be registered")
+		}
+	})
+
+	t.Run("Errors Keep Previous Sample", func(t *testing.T) {
+		c := &testCollector{name: "a", value: 3}
+		r := NewRegistry(c)
+		snapshot := NewSnapshot()
+		r.CollectInto(context.Background(), snapshot)
+
+		c.err = errors.New("boom")
+		r.CollectInto(context.Background(), snapshot)
+
+		record, ok := snapshot.Record("a")
+		if !ok {
+			t.Fatal("Expected record for collector a")
+		}
+		if record.Err == nil {
+			t.Error("Expected error to be recorded")
+		}
+		if record.Sample == nil || record.Sample.(*testSample).value != 3 {
+			t.Error("Expected previous sample to be kept after an error")
+		}
+	})
+
+	t.Run("Cancelled Context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		snapshot := NewRegistry(&testCollector{name: "a"}).CollectAll(ctx)
+		if len(snapshot.Records()) != 0 {
+			t.Errorf("Expected no records, got %d", len(snapshot.Records()))
+		}
+	})
+}
+
+func TestNilSnapshot(t *testing.T) {
+	var snapshot *Snapshot
+	snapshot.Set("a", &testSample{})
+
+	if _, ok := snapshot.Get("a"); ok {
+		t.Error("Expected nil snapshot to be empty")
+	}
+}
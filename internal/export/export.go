@@ -6,15 +6,36 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"syspulse/internal/collector"
+	"syspulse/internal/services/battery"
+	"syspulse/internal/services/disk"
+	"syspulse/internal/services/gpu"
+	"syspulse/internal/services/load"
+	"syspulse/internal/services/memory"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/services/sysinfo"
+	"syspulse/internal/services/temperature"
 	"syspulse/internal/utils"
 )
 
+// SchemaVersion identifies the layout of DataPoint in JSON exports. Version 1
+// exports had no schema_version field and only carried the summary fields;
+// version 2 added every mount, disk I/O device, interface and sensor,
+// version 3 the data of enabled plugins and version 4 pressure stall
+// information.
+const SchemaVersion = 4
+
 type DataPoint struct {
-	Timestamp time.Time
-	CPU       []float64
-	Memory    struct {
+	SchemaVersion int `json:"schema_version"`
+	Timestamp     time.Time
+	CPU           []float64
+	CPUTotal      float64 `json:"cpu_total"`
+	Memory        struct {
 		Total     uint64
 		Used      uint64
 		SwapTotal uint64
@@ -76,6 +97,17 @@ type DataPoint struct {
 		Usage       float64 `json:"usage"`
 		Available   bool    `json:"available"`
 	} `json:"gpu"`
+
+	Mounts      []disk.PartitionUsage           `json:"mounts"`
+	DiskDevices []*disk.DiskIODevice            `json:"disk_devices"`
+	Interfaces  []network.InterfaceIO           `json:"interfaces"`
+	Sensors     []temperature.TemperatureSensor `json:"sensors"`
+	Pressure    []pressure.Resource             `json:"pressure,omitempty"`
+
+	// Plugins holds the ExportData of every enabled plugin by name.
+	Plugins map[string]interface{} `json:"plugins,omitempty"`
+
+	points []collector.Point
 }
 
 type ExportFormat int
@@ -83,8 +115,42 @@ type ExportFormat int
 const (
 	CSV ExportFormat = iota
 	JSON
+	Influx   // InfluxDB line protocol
+	Graphite // Graphite plaintext protocol
 )
 
+func ParseFormat(name string) (ExportFormat, error) {
+	switch name {
+	case "csv":
+		return CSV, nil
+	case "json":
+		return JSON, nil
+	case "influx":
+		return Influx, nil
+	case "graphite":
+		return Graphite, nil
+	}
+	return 0, fmt.Errorf("unsupported export format: %s", name)
+}
+
+// CSVLayout selects how data points map onto CSV rows.
+type CSVLayout string
+
+const (
+	CSVWide CSVLayout = "wide" // One row per data point with fixed summary columns
+	CSVLong CSVLayout = "long" // One row per metric: timestamp, metric, labels, value
+)
+
+func ParseCSVLayout(name string) (CSVLayout, error) {
+	switch name {
+	case "", string(CSVWide):
+		return CSVWide, nil
+	case string(CSVLong):
+		return CSVLong, nil
+	}
+	return "", fmt.Errorf("unsupported CSV layout: %s", name)
+}
+
 func ExportData(data []DataPoint, filename string, format ExportFormat) error {
 	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -93,15 +159,47 @@ func ExportData(data []DataPoint, filename string, format ExportFormat) error {
 
 	switch format {
 	case CSV:
-		return exportToCSV(data, filename)
+		return exportToCSV(data, filename, CSVWide)
 	case JSON:
 		return exportToJSON(data, filename)
+	case Influx, Graphite:
+		return exportToLines(data, filename, format)
 	default:
 		return fmt.Errorf("unsupported export format")
 	}
 }
 
-func exportToCSV(data []DataPoint, filename string) error {
+// ExportLongCSV writes one row per metric of every data point, so every mount,
+// device, interface, sensor, GPU and core keeps its own row.
+func ExportLongCSV(data []DataPoint, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %v", err)
+	}
+	return exportToCSV(data, filename, CSVLong)
+}
+
+var csvHeader = []string{
+	"Timestamp",
+	"CPU_Total",
+	"Memory_Total", "Memory_Used",
+	"Swap_Total", "Swap_Used",
+	"Disk_Path", "Disk_Total", "Disk_Used", "Disk_UsedPerc",
+	"Disk_IOReads", "Disk_IOWrites",
+	"Net_BytesSent", "Net_BytesReceived",
+	"Net_PacketsSent", "Net_PacketsReceived",
+	"Load_1", "Load_5", "Load_15",
+	"Temp_CPU", "Temp_GPU",
+	"NetConn_Total", "NetConn_Established", "NetConn_Listening",
+	"DiskIO_ReadCount", "DiskIO_WriteCount", "DiskIO_ReadBytes", "DiskIO_WriteBytes",
+	"Processes_Count", "Processes_Top",
+	"Battery_Level", "Battery_Status", "Battery_Charging", "Battery_TimeRemaining",
+	"GPU_Count", "GPU_Primary_Name", "GPU_Primary_Vendor", "GPU_Primary_MemoryTotal", "GPU_Primary_MemoryUsed", "GPU_Primary_Usage",
+	"PSI_CPU_Some_Avg10", "PSI_Memory_Some_Avg10", "PSI_Memory_Full_Avg10", "PSI_IO_Some_Avg10", "PSI_IO_Full_Avg10",
+}
+
+var csvLongHeader = []string{"Timestamp", "Metric", "Labels", "Value"}
+
+func exportToCSV(data []DataPoint, filename string, layout CSVLayout) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -111,99 +209,150 @@ func exportToCSV(data []DataPoint, filename string) error {
 	writer := csv.NewWriter(file)
 	defer writer.Flush()
 
-	header := []string{
-		"Timestamp",
-		"CPU_Total",
-		"Memory_Total", "Memory_Used",
-		"Swap_Total", "Swap_Used",
-		"Disk_Path", "Disk_Total", "Disk_Used", "Disk_UsedPerc",
-		"Disk_IOReads", "Disk_IOWrites",
-		"Net_BytesSent", "Net_BytesReceived",
-		"Net_PacketsSent", "Net_PacketsReceived",
-		"Load_1", "Load_5", "Load_15",
-		"Temp_CPU", "Temp_GPU",
-		"NetConn_Total", "NetConn_Established", "NetConn_Listening",
-		"DiskIO_ReadCount", "DiskIO_WriteCount", "DiskIO_ReadBytes", "DiskIO_WriteBytes",
-		"Processes_Count", "Processes_Top",
-		"Battery_Level", "Battery_Status", "Battery_Charging", "Battery_TimeRemaining",
-		"GPU_Count", "GPU_Primary_Name", "GPU_Primary_Vendor", "GPU_Primary_MemoryTotal", "GPU_Primary_MemoryUsed", "GPU_Primary_Usage",
-	}
-
-	if err := writer.Write(header); err != nil {
+	columns := pluginColumns(data...)
+	if err := writer.Write(csvHeaderFor(layout, columns)); err != nil {
 		return err
 	}
 
 	for _, d := range data {
-		cpuTotal := 0.0
-		for _, cpu := range d.CPU {
-			cpuTotal += cpu
-		}
-		cpuTotal /= float64(len(d.CPU))
-
-		gpuCount := len(d.GPU)
-		primaryGPUName := ""
-		primaryGPUVendor := ""
-		primaryGPUMemoryTotal := uint64(0)
-		primaryGPUMemoryUsed := uint64(0)
-		primaryGPUUsage := 0.0
-
-		if gpuCount > 0 {
-			primaryGPUName = d.GPU[0].Name
-			primaryGPUVendor = d.GPU[0].Vendor
-			primaryGPUMemoryTotal = d.GPU[0].MemoryTotal
-			primaryGPUMemoryUsed = d.GPU[0].MemoryUsed
-			primaryGPUUsage = d.GPU[0].Usage
+		if err := writer.WriteAll(csvRows(d, layout, columns)); err != nil {
+			return err
 		}
+	}
 
-		row := []string{
-			d.Timestamp.Format(time.RFC3339),
-			fmt.Sprintf("%.2f", cpuTotal),
-			fmt.Sprintf("%d", d.Memory.Total),
-			fmt.Sprintf("%d", d.Memory.Used),
-			fmt.Sprintf("%d", d.Memory.SwapTotal),
-			fmt.Sprintf("%d", d.Memory.SwapUsed),
-			d.Disk.Path,
-			fmt.Sprintf("%d", d.Disk.Total),
-			fmt.Sprintf("%d", d.Disk.Used),
-			fmt.Sprintf("%.2f", d.Disk.UsedPerc),
-			fmt.Sprintf("%d", d.Disk.IOReads),
-			fmt.Sprintf("%d", d.Disk.IOWrites),
-			fmt.Sprintf("%d", d.Network.BytesSent),
-			fmt.Sprintf("%d", d.Network.BytesReceived),
-			fmt.Sprintf("%d", d.Network.PacketsSent),
-			fmt.Sprintf("%d", d.Network.PacketsRecv),
-			fmt.Sprintf("%.2f", d.Load.Load1),
-			fmt.Sprintf("%.2f", d.Load.Load5),
-			fmt.Sprintf("%.2f", d.Load.Load15),
-			fmt.Sprintf("%.2f", d.Temperature.CPUTemp),
-			fmt.Sprintf("%.2f", d.Temperature.GPUTemp),
-			fmt.Sprintf("%d", d.NetworkConnections.Total),
-			fmt.Sprintf("%d", d.NetworkConnections.Established),
-			fmt.Sprintf("%d", d.NetworkConnections.Listening),
-			fmt.Sprintf("%d", d.DiskIO.ReadCount),
-			fmt.Sprintf("%d", d.DiskIO.WriteCount),
-			fmt.Sprintf("%d", d.DiskIO.ReadBytes),
-			fmt.Sprintf("%d", d.DiskIO.WriteBytes),
-			fmt.Sprintf("%d", d.ProcessTree.ProcessCount),
-			fmt.Sprintf("%v", d.ProcessTree.TopProcesses),
-			fmt.Sprintf("%.2f", d.Battery.Level),
-			d.Battery.Status,
-			fmt.Sprintf("%t", d.Battery.IsCharging),
-			d.Battery.TimeRemaining,
-			fmt.Sprintf("%d", gpuCount),
-			primaryGPUName,
-			primaryGPUVendor,
-			fmt.Sprintf("%d", primaryGPUMemoryTotal),
-			fmt.Sprintf("%d", primaryGPUMemoryUsed),
-			fmt.Sprintf("%.2f", primaryGPUUsage),
-		}
+	return nil
+}
 
-		if err := writer.Write(row); err != nil {
-			return err
+// csvHeaderFor returns the header of a CSV export. Wide exports get a column
+// per plugin value after the fixed columns, long exports a row.
+func csvHeaderFor(layout CSVLayout, pluginColumns []string) []string {
+	if layout == CSVLong {
+		return csvLongHeader
+	}
+	return append(append([]string{}, csvHeader...), pluginColumns...)
+}
+
+func csvRows(d DataPoint, layout CSVLayout, pluginColumns []string) [][]string {
+	if layout == CSVLong {
+		return csvLongRows(d)
+	}
+
+	row := csvRow(d)
+	values := d.pluginValues()
+	for _, column := range pluginColumns {
+		row = append(row, values[column])
+	}
+	return [][]string{row}
+}
+
+func csvLongRows(d DataPoint) [][]string {
+	timestamp := d.Timestamp.Format(time.RFC3339)
+	points := d.Points()
+
+	rows := make([][]string, 0, len(points))
+	for _, point := range points {
+		rows = append(rows, []string{timestamp, point.Name, formatLabels(point.Labels), formatMetricValue(point.Value)})
+	}
+	return append(rows, pluginRows(d, timestamp)...)
+}
+
+func csvRow(d DataPoint) []string {
+	cpuTotal := d.cpuTotal()
+
+	gpuCount := len(d.GPU)
+	primaryGPUName := ""
+	primaryGPUVendor := ""
+	primaryGPUMemoryTotal := uint64(0)
+	primaryGPUMemoryUsed := uint64(0)
+	primaryGPUUsage := 0.0
+
+	if gpuCount > 0 {
+		primaryGPUName = d.GPU[0].Name
+		primaryGPUVendor = d.GPU[0].Vendor
+		primaryGPUMemoryTotal = d.GPU[0].MemoryTotal
+		primaryGPUMemoryUsed = d.GPU[0].MemoryUsed
+		primaryGPUUsage = d.GPU[0].Usage
+	}
+
+	return []string{
+		d.Timestamp.Format(time.RFC3339),
+		fmt.Sprintf("%.2f", cpuTotal),
+		fmt.Sprintf("%d", d.Memory.Total),
+		fmt.Sprintf("%d", d.Memory.Used),
+		fmt.Sprintf("%d", d.Memory.SwapTotal),
+		fmt.Sprintf("%d", d.Memory.SwapUsed),
+		d.Disk.Path,
+		fmt.Sprintf("%d", d.Disk.Total),
+		fmt.Sprintf("%d", d.Disk.Used),
+		fmt.Sprintf("%.2f", d.Disk.UsedPerc),
+		fmt.Sprintf("%d", d.Disk.IOReads),
+		fmt.Sprintf("%d", d.Disk.IOWrites),
+		fmt.Sprintf("%d", d.Network.BytesSent),
+		fmt.Sprintf("%d", d.Network.BytesReceived),
+		fmt.Sprintf("%d", d.Network.PacketsSent),
+		fmt.Sprintf("%d", d.Network.PacketsRecv),
+		fmt.Sprintf("%.2f", d.Load.Load1),
+		fmt.Sprintf("%.2f", d.Load.Load5),
+		fmt.Sprintf("%.2f", d.Load.Load15),
+		fmt.Sprintf("%.2f", d.Temperature.CPUTemp),
+		fmt.Sprintf("%.2f", d.Temperature.GPUTemp),
+		fmt.Sprintf("%d", d.NetworkConnections.Total),
+		fmt.Sprintf("%d", d.NetworkConnections.Established),
+		fmt.Sprintf("%d", d.NetworkConnections.Listening),
+		fmt.Sprintf("%d", d.DiskIO.ReadCount),
+		fmt.Sprintf("%d", d.DiskIO.WriteCount),
+		fmt.Sprintf("%d", d.DiskIO.ReadBytes),
+		fmt.Sprintf("%d", d.DiskIO.WriteBytes),
+		fmt.Sprintf("%d", d.ProcessTree.ProcessCount),
+		fmt.Sprintf("%v", d.ProcessTree.TopProcesses),
+		fmt.Sprintf("%.2f", d.Battery.Level),
+		d.Battery.Status,
+		fmt.Sprintf("%t", d.Battery.IsCharging),
+		d.Battery.TimeRemaining,
+		fmt.Sprintf("%d", gpuCount),
+		primaryGPUName,
+		primaryGPUVendor,
+		fmt.Sprintf("%d", primaryGPUMemoryTotal),
+		fmt.Sprintf("%d", primaryGPUMemoryUsed),
+		fmt.Sprintf("%.2f", primaryGPUUsage),
+		d.pressureValue("cpu", "some"),
+		d.pressureValue("memory", "some"),
+		d.pressureValue("memory", "full"),
+		d.pressureValue("io", "some"),
+		d.pressureValue("io", "full"),
+	}
+}
+
+// pressureValue returns the avg10 of a resource's some or full pressure, or
+// "" when the data point has none.
+func (d DataPoint) pressureValue(resource, kind string) string {
+	for _, r := range d.Pressure {
+		if r.Name != resource {
+			continue
 		}
+		if kind == "full" {
+			if !r.HasFull {
+				return ""
+			}
+			return fmt.Sprintf("%.2f", r.Full.Avg10)
+		}
+		return fmt.Sprintf("%.2f", r.Some.Avg10)
 	}
+	return ""
+}
 
-	return nil
+// cpuTotal returns the measured total CPU usage. Version 1 data points only
+// carry per-core values, so their average stands in.
+func (d DataPoint) cpuTotal() float64 {
+	if d.SchemaVersion >= 2 || len(d.CPU) == 0 {
+		return d.CPUTotal
+	}
+
+	total := 0.0
+	for _, usage := range d.CPU {
+		total += usage
+	}
+	return total / float64(len(d.CPU))
 }
 
 func exportToJSON(data []DataPoint, filename string) error {
@@ -218,164 +367,257 @@ func exportToJSON(data []DataPoint, filename string) error {
 	return encoder.Encode(data)
 }
 
-func CreateSnapshot(d *utils.Dashboard) DataPoint {
-	dp := DataPoint{
-		Timestamp: time.Now(),
-		CPU:       d.CpuData,
+// Points returns the labelled metrics behind the data point. Data points that
+// were not built from a snapshot, such as ones decoded from an earlier JSON
+// export, carry no points, so they are rebuilt from the exported fields.
+func (d DataPoint) Points() []collector.Point {
+	if len(d.points) > 0 {
+		return d.points
 	}
 
-	if d.VMemData != nil {
-		dp.Memory.Total = d.VMemData.Total
-		dp.Memory.Used = d.VMemData.Used
+	var points []collector.Point
+	if len(d.CPU) > 0 {
+		points = append(points, (&sysinfo.CPUSample{PerCore: d.CPU, Total: d.cpuTotal()}).Points()...)
 	}
 
-	if d.SMemData != nil {
-		dp.Memory.SwapTotal = d.SMemData.Total
-		dp.Memory.SwapUsed = d.SMemData.Used
+	points = append(points,
+		collector.GaugePoint("memory_total_bytes", float64(d.Memory.Total), nil),
+		collector.GaugePoint("memory_used_bytes", float64(d.Memory.Used), nil),
+		collector.GaugePoint("swap_total_bytes", float64(d.Memory.SwapTotal), nil),
+		collector.GaugePoint("swap_used_bytes", float64(d.Memory.SwapUsed), nil),
+	)
+
+	if len(d.Mounts) > 0 {
+		points = append(points, (&disk.UsageSample{Partitions: d.Mounts}).Points()...)
+	} else if d.Disk.Path != "" {
+		labels := map[string]string{"mountpoint": d.Disk.Path}
+		points = append(points,
+			collector.GaugePoint("disk_total_bytes", float64(d.Disk.Total), labels),
+			collector.GaugePoint("disk_used_bytes", float64(d.Disk.Used), labels),
+			collector.GaugePoint("disk_used_percent", d.Disk.UsedPerc, labels),
+		)
 	}
 
-	if len(d.DiskData) > 0 {
-		dp.Disk.Path = d.DiskData[0].Path
-		dp.Disk.Total = d.DiskData[0].Total
-		dp.Disk.Used = d.DiskData[0].Used
-		dp.Disk.UsedPerc = d.DiskData[0].UsedPercent
+	if len(d.DiskDevices) > 0 {
+		points = append(points, (&disk.DiskIOData{Disks: d.DiskDevices}).Points()...)
+	} else {
+		points = append(points,
+			collector.CounterPoint("disk_read_bytes_total", float64(d.DiskIO.ReadBytes), nil),
+			collector.CounterPoint("disk_written_bytes_total", float64(d.DiskIO.WriteBytes), nil),
+			collector.CounterPoint("disk_reads_completed_total", float64(d.DiskIO.ReadCount), nil),
+			collector.CounterPoint("disk_writes_completed_total", float64(d.DiskIO.WriteCount), nil),
+		)
 	}
 
-	if d.NetData != nil {
-		dp.Network.BytesSent = d.NetData.BytesSent
-		dp.Network.BytesReceived = d.NetData.BytesRecv
-		dp.Network.PacketsSent = d.NetData.PacketsSent
-		dp.Network.PacketsRecv = d.NetData.PacketsRecv
+	if len(d.Interfaces) > 0 {
+		sample := network.IOSample{Interfaces: d.Interfaces}
+		for _, iface := range d.Interfaces {
+			sample.Total.SentPerSec += iface.SentPerSec
+			sample.Total.RecvPerSec += iface.RecvPerSec
+		}
+		points = append(points, sample.Points()...)
+	} else {
+		points = append(points,
+			collector.CounterPoint("network_transmit_bytes_total", float64(d.Network.BytesSent), nil),
+			collector.CounterPoint("network_receive_bytes_total", float64(d.Network.BytesReceived), nil),
+			collector.CounterPoint("network_transmit_packets_total", float64(d.Network.PacketsSent), nil),
+			collector.CounterPoint("network_receive_packets_total", float64(d.Network.PacketsRecv), nil),
+		)
 	}
 
-	if d.LoadData != nil {
-		if loadData, ok := d.LoadData.(map[string]interface{}); ok {
-			if load1, ok := loadData["load1"].(float64); ok {
-				dp.Load.Load1 = load1
-			}
-			if load5, ok := loadData["load5"].(float64); ok {
-				dp.Load.Load5 = load5
-			}
-			if load15, ok := loadData["load15"].(float64); ok {
-				dp.Load.Load15 = load15
-			}
+	points = append(points,
+		collector.GaugePoint("network_connections_count", float64(d.NetworkConnections.Total), nil),
+		collector.GaugePoint("load1", d.Load.Load1, nil),
+		collector.GaugePoint("load5", d.Load.Load5, nil),
+		collector.GaugePoint("load15", d.Load.Load15, nil),
+		collector.GaugePoint("processes_count", float64(d.ProcessTree.ProcessCount), nil),
+	)
+
+	if d.Temperature.CPUTemp > 0 {
+		points = append(points, collector.GaugePoint("temperature_cpu_celsius", d.Temperature.CPUTemp, nil))
+	}
+	if d.Temperature.GPUTemp > 0 {
+		points = append(points, collector.GaugePoint("temperature_gpu_celsius", d.Temperature.GPUTemp, nil))
+	}
+	for _, sensor := range d.Sensors {
+		labels := map[string]string{"sensor": sensor.SensorKey}
+		points = append(points, collector.GaugePoint("temperature_celsius", sensor.Temperature, labels))
+		if sensor.High > 0 {
+			points = append(points, collector.GaugePoint("temperature_high_celsius", sensor.High, labels))
+		}
+		if sensor.Critical > 0 {
+			points = append(points, collector.GaugePoint("temperature_critical_celsius", sensor.Critical, labels))
+		}
+	}
+
+	for i, g := range d.GPU {
+		labels := map[string]string{"index": strconv.Itoa(i), "name": g.Name, "vendor": g.Vendor}
+		points = append(points,
+			collector.GaugePoint("gpu_usage_percent", g.Usage, labels),
+			collector.GaugePoint("gpu_memory_total_bytes", float64(g.MemoryTotal), labels),
+			collector.GaugePoint("gpu_memory_used_bytes", float64(g.MemoryUsed), labels),
+			collector.GaugePoint("gpu_temperature_celsius", g.Temperature, labels),
+		)
+	}
+
+	if d.Battery.Status != "" {
+		points = append(points, collector.GaugePoint("battery_level_percent", d.Battery.Level, nil))
+	}
+
+	if len(d.Pressure) > 0 {
+		points = append(points, (&pressure.PressureSample{Resources: d.Pressure}).Points()...)
+	}
+	return points
+}
+
+func CreateSnapshot(d *utils.Dashboard) DataPoint {
+	return NewDataPoint(d.Samples)
+}
+
+func NewDataPoint(snapshot *collector.Snapshot) DataPoint {
+	dp := DataPoint{
+		SchemaVersion: SchemaVersion,
+		Timestamp:     snapshot.Time(),
+		points:        snapshot.Points(),
+	}
+	if dp.Timestamp.IsZero() {
+		dp.Timestamp = time.Now()
+	}
+
+	if sample, ok := snapshot.Get(collector.CPU); ok {
+		if cpuSample, ok := sample.(*sysinfo.CPUSample); ok {
+			dp.CPU = cpuSample.PerCore
+			dp.CPUTotal = cpuSample.Total
 		}
 	}
 
-	if d.TemperatureData != nil {
-		if tempData, ok := d.TemperatureData.(map[string]interface{}); ok {
-			if cpuTemp, ok := tempData["cpu_temp"].(float64); ok {
-				dp.Temperature.CPUTemp = cpuTemp
+	if sample, ok := snapshot.Get(collector.Memory); ok {
+		if memSample, ok := sample.(*memory.MemorySample); ok {
+			if memSample.Virtual != nil {
+				dp.Memory.Total = memSample.Virtual.Total
+				dp.Memory.Used = memSample.Virtual.Used
 			}
-			if gpuTemp, ok := tempData["gpu_temp"].(float64); ok {
-				dp.Temperature.GPUTemp = gpuTemp
+			if memSample.Swap != nil {
+				dp.Memory.SwapTotal = memSample.Swap.Total
+				dp.Memory.SwapUsed = memSample.Swap.Used
 			}
 		}
 	}
 
-	if d.NetworkConnsData != nil {
-		if connData, ok := d.NetworkConnsData.(map[string]interface{}); ok {
-			if summary, ok := connData["summary"].(map[string]interface{}); ok {
-				if total, ok := summary["total"].(int); ok {
-					dp.NetworkConnections.Total = total
-				}
-				if established, ok := summary["established"].(int); ok {
-					dp.NetworkConnections.Established = established
-				}
-				if listening, ok := summary["listening"].(int); ok {
-					dp.NetworkConnections.Listening = listening
+	if sample, ok := snapshot.Get(collector.Disk); ok {
+		if usageSample, ok := sample.(*disk.UsageSample); ok && len(usageSample.Partitions) > 0 {
+			dp.Mounts = usageSample.Partitions
+			primary := usageSample.Partitions[0]
+			for _, p := range usageSample.Partitions {
+				if p.Path == "/" {
+					primary = p
+					break
 				}
 			}
+			dp.Disk.Path = primary.Path
+			dp.Disk.Total = primary.Total
+			dp.Disk.Used = primary.Used
+			dp.Disk.UsedPerc = primary.UsedPercent
 		}
 	}
 
-	if d.DiskIOData != nil {
-		if diskIOData, ok := d.DiskIOData.(map[string]interface{}); ok {
-			if readCount, ok := diskIOData["read_count"].(uint64); ok {
-				dp.DiskIO.ReadCount = readCount
-			}
-			if writeCount, ok := diskIOData["write_count"].(uint64); ok {
-				dp.DiskIO.WriteCount = writeCount
-			}
-			if readBytes, ok := diskIOData["read_bytes"].(uint64); ok {
-				dp.DiskIO.ReadBytes = readBytes
-			}
-			if writeBytes, ok := diskIOData["write_bytes"].(uint64); ok {
-				dp.DiskIO.WriteBytes = writeBytes
+	if sample, ok := snapshot.Get(collector.DiskIO); ok {
+		if ioData, ok := sample.(*disk.DiskIOData); ok {
+			dp.DiskDevices = ioData.Disks
+			for _, device := range ioData.Disks {
+				dp.DiskIO.ReadCount += device.Stats.ReadCount
+				dp.DiskIO.WriteCount += device.Stats.WriteCount
+				dp.DiskIO.ReadBytes += device.Stats.ReadBytes
+				dp.DiskIO.WriteBytes += device.Stats.WriteBytes
 			}
+			dp.Disk.IOReads = dp.DiskIO.ReadCount
+			dp.Disk.IOWrites = dp.DiskIO.WriteCount
 		}
 	}
 
-	if d.ProcessTreeData != nil {
-		if processData, ok := d.ProcessTreeData.(map[string]interface{}); ok {
-			if processCount, ok := processData["process_count"].(int); ok {
-				dp.ProcessTree.ProcessCount = processCount
-			}
-			if topProcesses, ok := processData["top_processes"].([]string); ok {
-				dp.ProcessTree.TopProcesses = topProcesses
-			}
+	if sample, ok := snapshot.Get(collector.Network); ok {
+		if ioSample, ok := sample.(*network.IOSample); ok {
+			dp.Network.BytesSent = ioSample.Total.BytesSent
+			dp.Network.BytesReceived = ioSample.Total.BytesRecv
+			dp.Network.PacketsSent = ioSample.Total.PacketsSent
+			dp.Network.PacketsRecv = ioSample.Total.PacketsRecv
+			dp.Interfaces = ioSample.Interfaces
 		}
 	}
 
-	if d.BatteryData != nil {
-		if batteryData, ok := d.BatteryData.(map[string]interface{}); ok {
-			if level, ok := batteryData["level"].(float64); ok {
-				dp.Battery.Level = level
-			}
-			if status, ok := batteryData["status"].(string); ok {
-				dp.Battery.Status = status
-			}
-			if isCharging, ok := batteryData["is_charging"].(bool); ok {
-				dp.Battery.IsCharging = isCharging
-			}
-			if timeRemaining, ok := batteryData["time_remaining"].(string); ok {
-				dp.Battery.TimeRemaining = timeRemaining
+	if sample, ok := snapshot.Get(collector.Load); ok {
+		if loadAvg, ok := sample.(*load.LoadAverage); ok {
+			dp.Load.Load1 = loadAvg.Load1
+			dp.Load.Load5 = loadAvg.Load5
+			dp.Load.Load15 = loadAvg.Load15
+		}
+	}
+
+	if sample, ok := snapshot.Get(collector.Temperature); ok {
+		if tempData, ok := sample.(*temperature.TemperatureData); ok {
+			dp.Temperature.CPUTemp = tempData.CPUTemp
+			dp.Temperature.GPUTemp = tempData.GPUTemp
+			dp.Sensors = tempData.Sensors
+		}
+	}
+
+	if sample, ok := snapshot.Get(collector.NetworkConnections); ok {
+		if connStats, ok := sample.(*network.ConnectionStats); ok {
+			dp.NetworkConnections.Total = connStats.Summary.Total
+			dp.NetworkConnections.Established = connStats.Summary.Established
+			dp.NetworkConnections.Listening = connStats.Summary.Listen
+			dp.NetworkConnections.CloseWait = connStats.Summary.CloseWait
+			dp.NetworkConnections.TimeWait = connStats.Summary.TimeWait
+		}
+	}
+
+	if sample, ok := snapshot.Get(collector.ProcessTree); ok {
+		if tree, ok := sample.(*processes.ProcessTree); ok {
+			dp.ProcessTree.ProcessCount = tree.TotalCount
+			for _, node := range tree.TopByCPU(5) {
+				dp.ProcessTree.TopProcesses = append(dp.ProcessTree.TopProcesses, node.Name)
 			}
 		}
 	}
 
-	if d.GPUData != nil {
-		if gpuData, ok := d.GPUData.([]interface{}); ok && len(gpuData) > 0 {
-			for _, gpuInterface := range gpuData {
-				if gpuMap, ok := gpuInterface.(map[string]interface{}); ok {
-					var gpuInfo struct {
-						Name        string  `json:"name"`
-						Vendor      string  `json:"vendor"`
-						MemoryTotal uint64  `json:"memory_total"`
-						MemoryUsed  uint64  `json:"memory_used"`
-						MemoryFree  uint64  `json:"memory_free"`
-						Temperature float64 `json:"temperature"`
-						Usage       float64 `json:"usage"`
-						Available   bool    `json:"available"`
-					}
-
-					if name, ok := gpuMap["name"].(string); ok {
-						gpuInfo.Name = name
-					}
-					if vendor, ok := gpuMap["vendor"].(string); ok {
-						gpuInfo.Vendor = vendor
-					}
-					if memoryTotal, ok := gpuMap["memory_total"].(uint64); ok {
-						gpuInfo.MemoryTotal = memoryTotal
-					}
-					if memoryUsed, ok := gpuMap["memory_used"].(uint64); ok {
-						gpuInfo.MemoryUsed = memoryUsed
-					}
-					if memoryFree, ok := gpuMap["memory_free"].(uint64); ok {
-						gpuInfo.MemoryFree = memoryFree
-					}
-					if temperature, ok := gpuMap["temperature"].(float64); ok {
-						gpuInfo.Temperature = temperature
-					}
-					if usage, ok := gpuMap["usage"].(float64); ok {
-						gpuInfo.Usage = usage
-					}
-					if available, ok := gpuMap["available"].(bool); ok {
-						gpuInfo.Available = available
-					}
-
-					dp.GPU = append(dp.GPU, gpuInfo)
+	if sample, ok := snapshot.Get(collector.Battery); ok {
+		if batteryInfo, ok := sample.(*battery.BatteryInfo); ok {
+			dp.Battery.Level = batteryInfo.Level
+			dp.Battery.Status = batteryInfo.Status
+			dp.Battery.IsCharging = batteryInfo.IsCharging
+			dp.Battery.TimeRemaining = batteryInfo.TimeRemaining
+		}
+	}
+
+	if sample, ok := snapshot.Get(collector.Pressure); ok {
+		if pressureSample, ok := sample.(*pressure.PressureSample); ok {
+			dp.Pressure = pressureSample.Resources
+		}
+	}
+
+	if sample, ok := snapshot.Get(collector.GPU); ok {
+		if gpuSample, ok := sample.(*gpu.GPUSample); ok {
+			for _, g := range gpuSample.GPUs {
+				var gpuInfo struct {
+					Name        string  `json:"name"`
+					Vendor      string  `json:"vendor"`
+					MemoryTotal uint64  `json:"memory_total"`
+					MemoryUsed  uint64  `json:"memory_used"`
+					MemoryFree  uint64  `json:"memory_free"`
+					Temperature float64 `json:"temperature"`
+					Usage       float64 `json:"usage"`
+					Available   bool    `json:"available"`
 				}
+				gpuInfo.Name = g.Name
+				gpuInfo.Vendor = g.Vendor
+				gpuInfo.MemoryTotal = g.MemoryTotal
+				gpuInfo.MemoryUsed = g.MemoryUsed
+				gpuInfo.MemoryFree = g.MemoryFree
+				gpuInfo.Temperature = g.Temperature
+				gpuInfo.Usage = g.Usage
+				gpuInfo.Available = g.Available
+
+				dp.GPU = append(dp.GPU, gpuInfo)
 			}
 		}
 	}
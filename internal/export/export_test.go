@@ -5,8 +5,20 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+	"syspulse/internal/services/disk"
+	"syspulse/internal/services/load"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
+	"syspulse/internal/services/sysinfo"
+	"syspulse/internal/services/temperature"
+
+	gopsutildisk "github.com/shirou/gopsutil/disk"
 )
 
 func createTestData() []DataPoint {
@@ -117,3 +129,272 @@ func TestExportData(t *testing.T) {
 		}
 	})
 }
+
+func TestNewDataPoint(t *testing.T) {
+	snapshot := collector.NewSnapshot()
+	snapshot.Set(collector.CPU, &sysinfo.CPUSample{PerCore: []float64{10, 30}, Total: 20})
+	snapshot.Set(collector.Load, &load.LoadAverage{Load1: 1.5, Load5: 1.0, Load15: 0.5})
+	snapshot.Set(collector.NetworkConnections, &network.ConnectionStats{
+		Summary: network.ConnectionSummary{Total: 4, Established: 2, Listen: 1},
+	})
+	snapshot.Set(collector.DiskIO, &disk.DiskIOData{
+		Disks: []*disk.DiskIODevice{
+			{Name: "sda", Stats: &disk.DiskIOStats{ReadCount: 3, WriteCount: 4}},
+			{Name: "sdb", Stats: &disk.DiskIOStats{ReadCount: 1, WriteCount: 1}},
+		},
+	})
+
+	dp := NewDataPoint(snapshot)
+
+	if len(dp.CPU) != 2 {
+		t.Errorf("Expected 2 CPU values, got %d", len(dp.CPU))
+	}
+	if dp.Load.Load1 != 1.5 {
+		t.Errorf("Expected load1 1.5, got %v", dp.Load.Load1)
+	}
+	if dp.NetworkConnections.Listening != 1 || dp.NetworkConnections.Established != 2 {
+		t.Errorf("Unexpected connection summary: %+v", dp.NetworkConnections)
+	}
+	if dp.DiskIO.ReadCount != 4 || dp.DiskIO.WriteCount != 5 {
+		t.Errorf("Expected disk I/O totals 4/5, got %d/%d", dp.DiskIO.ReadCount, dp.DiskIO.WriteCount)
+	}
+}
+
+func fullSchemaSnapshot() *collector.Snapshot {
+	snapshot := collector.NewSnapshot()
+	snapshot.Set(collector.CPU, &sysinfo.CPUSample{PerCore: []float64{10, 50}, Total: 25})
+	snapshot.Set(collector.Disk, &disk.UsageSample{Partitions: []disk.PartitionUsage{
+		{Device: "/dev/sda2", UsageStat: gopsutildisk.UsageStat{Path: "/home", Total: 200, Used: 50, UsedPercent: 25}},
+		{Device: "/dev/sda1", UsageStat: gopsutildisk.UsageStat{Path: "/", Total: 100, Used: 90, UsedPercent: 90}},
+	}})
+	snapshot.Set(collector.DiskIO, &disk.DiskIOData{Disks: []*disk.DiskIODevice{
+		{Name: "sda", Stats: &disk.DiskIOStats{ReadBytes: 4096, ReadBytesPerSec: 512}},
+		{Name: "nvme0n1", Stats: &disk.DiskIOStats{WriteBytes: 8192, WriteBytesPerSec: 1024}},
+	}})
+	snapshot.Set(collector.Network, &network.IOSample{Interfaces: []network.InterfaceIO{
+		{Name: "eth0", BytesRecv: 1000, RecvPerSec: 10},
+		{Name: "wlan0", BytesSent: 2000, SentPerSec: 20},
+	}})
+	snapshot.Set(collector.Temperature, &temperature.TemperatureData{
+		CPUTemp: 55,
+		Sensors: []temperature.TemperatureSensor{
+			{SensorKey: "coretemp_core0", Temperature: 55, High: 80, Critical: 100},
+			{SensorKey: "nvme_composite", Temperature: 40},
+		},
+	})
+	snapshot.Set(collector.Pressure, &pressure.PressureSample{Resources: []pressure.Resource{
+		{Name: "cpu", Some: pressure.Stall{Avg10: 3.18, Total: 1000}},
+		{Name: "memory", Some: pressure.Stall{Avg10: 1.5}, Full: pressure.Stall{Avg10: 0.75, Rate: 2}, HasFull: true},
+	}})
+	return snapshot
+}
+
+func TestNewDataPointFullSchema(t *testing.T) {
+	dp := NewDataPoint(fullSchemaSnapshot())
+
+	if dp.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", SchemaVersion, dp.SchemaVersion)
+	}
+	if dp.CPUTotal != 25 {
+		t.Errorf("Expected measured CPU total 25, got %v", dp.CPUTotal)
+	}
+	if len(dp.Mounts) != 2 || len(dp.DiskDevices) != 2 || len(dp.Interfaces) != 2 || len(dp.Sensors) != 2 {
+		t.Fatalf("Expected every mount, device, interface and sensor, got %d/%d/%d/%d",
+			len(dp.Mounts), len(dp.DiskDevices), len(dp.Interfaces), len(dp.Sensors))
+	}
+	if dp.Disk.Path != "/" {
+		t.Errorf("Expected the summary disk to stay the root mount, got %s", dp.Disk.Path)
+	}
+	if row := csvRow(dp); row[1] != "25.00" {
+		t.Errorf("Expected CSV CPU_Total to use the measured total, got %s", row[1])
+	}
+	if psi := csvRow(dp)[len(csvHeader)-5:]; strings.Join(psi, ",") != "3.18,1.50,0.75,," {
+		t.Errorf("Expected the pressure columns, got %v", psi)
+	}
+}
+
+func TestDataPointJSONRoundTrip(t *testing.T) {
+	dp := NewDataPoint(fullSchemaSnapshot())
+
+	encoded, err := json.Marshal(dp)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"schema_version":4`) {
+		t.Errorf("Expected schema_version in JSON, got %s", encoded)
+	}
+
+	var decoded DataPoint
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	// A decoded data point has no snapshot behind it, so its points are
+	// rebuilt from the exported fields and must keep every labelled series.
+	want := map[string]bool{
+		history.SeriesKey("disk_used_percent", map[string]string{"mountpoint": "/home", "device": "/dev/sda2", "fstype": ""}): true,
+		history.SeriesKey("disk_write_bytes_per_second", map[string]string{"device": "nvme0n1"}):                              true,
+		history.SeriesKey("network_transmit_bytes_per_second", map[string]string{"interface": "wlan0"}):                       true,
+		history.SeriesKey("temperature_critical_celsius", map[string]string{"sensor": "coretemp_core0"}):                      true,
+		history.SeriesKey("cpu_core_usage_percent", map[string]string{"core": "1"}):                                           true,
+		history.SeriesKey("pressure_full_stalled_percent", map[string]string{"resource": "memory"}):                           true,
+	}
+	for _, point := range decoded.Points() {
+		delete(want, history.SeriesKey(point.Name, point.Labels))
+	}
+	for key := range want {
+		t.Errorf("Decoded data point lost series %s", key)
+	}
+}
+
+func TestExportLongCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.csv")
+	if err := ExportLongCSV([]DataPoint{NewDataPoint(fullSchemaSnapshot())}, path); err != nil {
+		t.Fatalf("Failed to export long CSV: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if strings.Join(records[0], ",") != "Timestamp,Metric,Labels,Value" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+
+	found := false
+	for _, record := range records[1:] {
+		if record[1] == "disk_used_percent" && record[2] == "device=/dev/sda2;fstype=;mountpoint=/home" {
+			found = record[3] == "25"
+		}
+	}
+	if !found {
+		t.Error("Expected a long CSV row for the /home mount")
+	}
+}
+
+func TestExportHistory(t *testing.T) {
+	tmpDir := filepath.Join(os.TempDir(), "syspulse_test_history")
+	defer os.RemoveAll(tmpDir)
+
+	store := history.NewStore(history.DefaultConfig)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		store.Add(now.Add(time.Duration(i-3)*time.Second),
+			collector.GaugePoint("cpu_core_usage_percent", float64(i), map[string]string{"core": "0"}),
+			collector.GaugePoint("load1", float64(i), nil))
+	}
+
+	t.Run("CSV Export", func(t *testing.T) {
+		csvPath := filepath.Join(tmpDir, "history.csv")
+		if err := ExportHistory(store, []string{"cpu_core_usage_percent"}, now.Add(-time.Minute), now, csvPath, CSV); err != nil {
+			t.Fatalf("Failed to export history CSV: %v", err)
+		}
+
+		file, err := os.Open(csvPath)
+		if err != nil {
+			t.Fatalf("Failed to open CSV file: %v", err)
+		}
+		defer file.Close()
+
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read CSV: %v", err)
+		}
+		if len(records) != 4 {
+			t.Errorf("Expected 4 CSV records, got %d", len(records))
+		}
+		if records[1][2] != "core=0" {
+			t.Errorf("Expected labels 'core=0', got '%s'", records[1][2])
+		}
+	})
+
+	t.Run("JSON Export", func(t *testing.T) {
+		jsonPath := filepath.Join(tmpDir, "history.json")
+		if err := ExportHistory(store, nil, now.Add(-time.Minute), now, jsonPath, JSON); err != nil {
+			t.Fatalf("Failed to export history JSON: %v", err)
+		}
+
+		data, err := os.ReadFile(jsonPath)
+		if err != nil {
+			t.Fatalf("Failed to read JSON file: %v", err)
+		}
+
+		var series []history.Series
+		if err := json.Unmarshal(data, &series); err != nil {
+			t.Fatalf("Failed to decode JSON: %v", err)
+		}
+		if len(series) != 2 {
+			t.Errorf("Expected 2 series, got %d", len(series))
+		}
+	})
+
+	t.Run("Disabled History", func(t *testing.T) {
+		if err := ExportHistory(nil, nil, now, now, filepath.Join(tmpDir, "x.json"), JSON); err == nil {
+			t.Error("Expected error for nil history store")
+		}
+	})
+}
+
+type testContainer struct {
+	Name  string   `json:"name"`
+	CPU   float64  `json:"cpu_percent"`
+	Ports []string `json:"ports"`
+}
+
+func TestPluginData(t *testing.T) {
+	first, second := createTestData()[0], createTestData()[0]
+	first.Plugins = map[string]interface{}{
+		"docker": map[string]interface{}{
+			"containers": []testContainer{{Name: "web", CPU: 12.5, Ports: []string{"80/tcp"}}},
+			"running":    1,
+		},
+	}
+	second.Plugins = map[string]interface{}{
+		"docker":  map[string]interface{}{"running": 0, "error": nil},
+		"example": map[string]interface{}{"initialized": true},
+	}
+
+	dir := t.TempDir()
+	if err := ExportData([]DataPoint{first, second}, filepath.Join(dir, "wide.csv"), CSV); err != nil {
+		t.Fatalf("Failed to export CSV: %v", err)
+	}
+	file, _ := os.Open(filepath.Join(dir, "wide.csv"))
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	wantColumns := []string{
+		"plugins.docker.containers.0.cpu_percent", "plugins.docker.containers.0.name", "plugins.docker.containers.0.ports.0",
+		"plugins.docker.error", "plugins.docker.running", "plugins.example.initialized",
+	}
+	if got := records[0][len(csvHeader):]; strings.Join(got, ",") != strings.Join(wantColumns, ",") {
+		t.Errorf("Expected the plugin columns of every row, got %v", got)
+	}
+	if got := records[1][len(csvHeader):]; strings.Join(got, ",") != "12.5,web,80/tcp,,1," {
+		t.Errorf("Unexpected first row %v", got)
+	}
+	if got := records[2][len(csvHeader):]; strings.Join(got, ",") != ",,,,0,true" {
+		t.Errorf("Unexpected second row %v", got)
+	}
+
+	rows := csvLongRows(second)
+	if got := rows[len(rows)-1]; got[1] != "plugins.example.initialized" || got[2] != "" || got[3] != "true" {
+		t.Errorf("Expected plugin values as long CSV rows, got %v", got)
+	}
+
+	encoded, _ := json.Marshal(first)
+	if !strings.Contains(string(encoded), `"plugins":{"docker":{"containers":[{"name":"web"`) {
+		t.Errorf("Expected the plugins namespace in JSON, got %s", encoded)
+	}
+	if encoded, _ := json.Marshal(createTestData()[0]); strings.Contains(string(encoded), `"plugins"`) {
+		t.Error("Expected no plugins key without plugin data")
+	}
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"syspulse/internal/history"
+)
+
+func ExportHistory(store *history.Store, names []string, from, to time.Time, filename string, format ExportFormat) error {
+	if store == nil {
+		return fmt.Errorf("history is disabled")
+	}
+
+	if len(names) == 0 {
+		names = store.Names()
+	}
+
+	var series []history.Series
+	for _, name := range names {
+		series = append(series, store.RangeAll(name, from, to)...)
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %v", err)
+	}
+
+	switch format {
+	case CSV:
+		return exportHistoryToCSV(series, filename)
+	case JSON:
+		return exportHistoryToJSON(series, filename)
+	default:
+		return fmt.Errorf("unsupported export format")
+	}
+}
+
+func exportHistoryToCSV(series []history.Series, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Timestamp", "Metric", "Labels", "Value", "Min", "Max"}); err != nil {
+		return err
+	}
+
+	for _, s := range series {
+		labels := formatLabels(s.Labels)
+		for _, p := range s.Points {
+			row := []string{
+				p.Time.Format(time.RFC3339),
+				s.Name,
+				labels,
+				fmt.Sprintf("%g", p.Value),
+				fmt.Sprintf("%g", p.Min),
+				fmt.Sprintf("%g", p.Max),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportHistoryToJSON(series []history.Series, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(series)
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var hostname = sync.OnceValue(func() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "localhost"
+	}
+	return name
+})
+
+// Lines renders a data point as newline-free records of a line-oriented
+// format: InfluxDB line protocol or Graphite plaintext.
+func Lines(d DataPoint, format ExportFormat) ([]string, error) {
+	switch format {
+	case Influx:
+		return influxLines(d), nil
+	case Graphite:
+		return graphiteLines(d), nil
+	}
+	return nil, fmt.Errorf("export format does not produce lines")
+}
+
+func writeLines(w io.Writer, data []DataPoint, format ExportFormat) error {
+	bw := bufio.NewWriter(w)
+	for _, d := range data {
+		lines, err := Lines(d, format)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+func exportToLines(data []DataPoint, filename string, format ExportFormat) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeLines(file, data, format)
+}
+
+// influxLines writes one line per point:
+//
+//	syspulse_disk_used_percent,host=web1,mountpoint=/ value=42.5 1700000000000000000
+func influxLines(d DataPoint) []string {
+	timestamp := strconv.FormatInt(d.Timestamp.UnixNano(), 10)
+	host := hostname()
+
+	var lines []string
+	for _, point := range d.Points() {
+		if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+			continue
+		}
+
+		var b strings.Builder
+		b.WriteString(influxMeasurementReplacer.Replace(MetricsNamespace + "_" + point.Name))
+
+		tags := map[string]string{"host": host}
+		for key, value := range point.Labels {
+			tags[key] = value
+		}
+		for _, key := range sortedKeys(tags) {
+			// Line protocol has no representation for an empty tag value.
+			if tags[key] == "" {
+				continue
+			}
+			b.WriteByte(',')
+			b.WriteString(influxTagReplacer.Replace(key))
+			b.WriteByte('=')
+			b.WriteString(influxTagReplacer.Replace(tags[key]))
+		}
+
+		b.WriteString(" value=")
+		b.WriteString(strconv.FormatFloat(point.Value, 'f', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(timestamp)
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+var (
+	influxMeasurementReplacer = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", "")
+	influxTagReplacer         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", "")
+)
+
+// graphiteLines writes one line per point, with labels folded into the path
+// in key order:
+//
+//	syspulse.web1.disk_used_percent.mountpoint__ 42.5 1700000000
+func graphiteLines(d DataPoint) []string {
+	timestamp := strconv.FormatInt(d.Timestamp.Unix(), 10)
+	prefix := MetricsNamespace + "." + graphiteComponent(hostname())
+
+	var lines []string
+	for _, point := range d.Points() {
+		if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+			continue
+		}
+
+		var b strings.Builder
+		b.WriteString(prefix)
+		b.WriteByte('.')
+		b.WriteString(graphiteComponent(point.Name))
+		for _, key := range sortedKeys(point.Labels) {
+			b.WriteByte('.')
+			b.WriteString(graphiteComponent(key + "_" + point.Labels[key]))
+		}
+
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(point.Value, 'f', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(timestamp)
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// graphiteComponent replaces everything but letters, digits, '-' and '_' so
+// a label value such as "/" or "sda1.p" cannot add path segments.
+func graphiteComponent(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
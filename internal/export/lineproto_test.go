@@ -0,0 +1,138 @@
+package export
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+)
+
+func lineTestData() DataPoint {
+	return DataPoint{
+		Timestamp: time.Unix(1700000000, 500),
+		points: []collector.Point{
+			collector.GaugePoint("cpu_usage_percent", 12.5, nil),
+			collector.GaugePoint("disk_used_percent", 42, map[string]string{"mountpoint": "/mnt/my disk", "fstype": ""}),
+			collector.CounterPoint("network_receive_bytes_total", 1e9, map[string]string{"interface": "eth0"}),
+			collector.GaugePoint("temperature_celsius", math.NaN(), nil),
+		},
+	}
+}
+
+func TestInfluxLines(t *testing.T) {
+	lines, err := Lines(lineTestData(), Influx)
+	if err != nil {
+		t.Fatalf("Lines failed: %v", err)
+	}
+
+	host := influxTagReplacer.Replace(hostname())
+	expected := []string{
+		"syspulse_cpu_usage_percent,host=" + host + " value=12.5 1700000000000000500",
+		"syspulse_disk_used_percent,host=" + host + `,mountpoint=/mnt/my\ disk value=42 1700000000000000500`,
+		"syspulse_network_receive_bytes_total,host=" + host + ",interface=eth0 value=1000000000 1700000000000000500",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d:\nexpected %s\n     got %s", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestGraphiteLines(t *testing.T) {
+	lines, err := Lines(lineTestData(), Graphite)
+	if err != nil {
+		t.Fatalf("Lines failed: %v", err)
+	}
+
+	prefix := "syspulse." + graphiteComponent(hostname()) + "."
+	expected := []string{
+		prefix + "cpu_usage_percent 12.5 1700000000",
+		prefix + "disk_used_percent.fstype_.mountpoint__mnt_my_disk 42 1700000000",
+		prefix + "network_receive_bytes_total.interface_eth0 1000000000 1700000000",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d:\nexpected %s\n     got %s", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestLinesFromSummaryFields(t *testing.T) {
+	lines, err := Lines(createTestData()[0], Influx)
+	if err != nil {
+		t.Fatalf("Lines failed: %v", err)
+	}
+
+	joined := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"syspulse_cpu_core_usage_percent,core=2,",
+		"syspulse_memory_total_bytes,host=",
+		"syspulse_disk_used_percent,host=" + influxTagReplacer.Replace(hostname()) + ",mountpoint=/ value=50 ",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected output to contain %q", want)
+		}
+	}
+
+	if _, err := Lines(createTestData()[0], CSV); err == nil {
+		t.Error("Expected CSV to be rejected as a line format")
+	}
+}
+
+func TestExportLineFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, format := range []ExportFormat{Influx, Graphite} {
+		path := filepath.Join(dir, "export.txt")
+		if err := ExportData([]DataPoint{lineTestData(), lineTestData()}, path, format); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read export: %v", err)
+		}
+		if lines := strings.Count(string(content), "\n"); lines != 6 {
+			t.Errorf("Expected 6 lines for format %d, got %d", format, lines)
+		}
+	}
+}
+
+func TestStreamLineFormats(t *testing.T) {
+	stream, _ := newTestStream(t, StreamConfig{Format: Graphite})
+	stream.Write(lineTestData())
+	path := stream.Path()
+	stream.Close()
+
+	if filepath.Ext(path) != ".graphite" {
+		t.Errorf("Expected .graphite segment, got %s", path)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "syspulse.") || strings.Count(string(content), "\n") != 3 {
+		t.Errorf("Unexpected segment content %q", content)
+	}
+}
+
+func TestGraphiteComponent(t *testing.T) {
+	tests := map[string]string{
+		"eth0":        "eth0",
+		"/":           "_",
+		"sda1.p":      "sda1_p",
+		"web-1.local": "web-1_local",
+	}
+	for input, expected := range tests {
+		if got := graphiteComponent(input); got != expected {
+			t.Errorf("graphiteComponent(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
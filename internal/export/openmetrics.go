@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/metrics"
+)
+
+const (
+	MetricsNamespace = "syspulse"
+
+	OpenMetricsContentType    = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	PrometheusTextContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+type metricFamily struct {
+	name    string
+	kind    collector.MetricType
+	samples []collector.Point
+}
+
+// WriteOpenMetrics writes every point of the snapshot in the OpenMetrics text
+// format, followed by the collector self-metrics when m is not nil.
+func WriteOpenMetrics(w io.Writer, snapshot *collector.Snapshot, m *metrics.Metrics) error {
+	return writeMetricsText(w, snapshot, m, true)
+}
+
+// WritePrometheusText writes the same families in the classic Prometheus text
+// exposition format for scrapers that do not negotiate OpenMetrics.
+func WritePrometheusText(w io.Writer, snapshot *collector.Snapshot, m *metrics.Metrics) error {
+	return writeMetricsText(w, snapshot, m, false)
+}
+
+func writeMetricsText(w io.Writer, snapshot *collector.Snapshot, m *metrics.Metrics, openMetrics bool) error {
+	points := snapshot.Points()
+	if m != nil {
+		points = append(points, selfMetricPoints(snapshot, m)...)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, family := range groupFamilies(points) {
+		familyName := family.name
+		if openMetrics && family.kind == collector.Counter {
+			familyName = strings.TrimSuffix(familyName, "_total")
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", familyName, family.kind)
+
+		for _, point := range family.samples {
+			bw.WriteString(family.name)
+			writeLabels(bw, point.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatMetricValue(point.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func selfMetricPoints(snapshot *collector.Snapshot, m *metrics.Metrics) []collector.Point {
+	var points []collector.Point
+	for _, record := range snapshot.Records() {
+		metricType := metrics.ForCollector(record.Collector)
+		labels := map[string]string{"collector": record.Collector}
+		points = append(points,
+			collector.GaugePoint("collector_duration_seconds", m.GetAverageUpdateDuration(metricType).Seconds(), labels),
+			collector.CounterPoint("collector_errors_total", float64(m.GetErrorCount(metricType)), labels),
+		)
+		if last := m.GetLastUpdate(metricType); !last.IsZero() {
+			points = append(points, collector.GaugePoint("collector_last_success_timestamp_seconds", float64(last.UnixNano())/1e9, labels))
+		}
+	}
+	return points
+}
+
+func groupFamilies(points []collector.Point) []*metricFamily {
+	var families []*metricFamily
+	byName := make(map[string]*metricFamily)
+
+	for _, point := range points {
+		name := MetricsNamespace + "_" + point.Name
+		if point.Type == collector.Counter && !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+
+		family, exists := byName[name]
+		if !exists {
+			family = &metricFamily{name: name, kind: point.Type}
+			byName[name] = family
+			families = append(families, family)
+		}
+		family.samples = append(family.samples, point)
+	}
+	return families
+}
+
+func writeLabels(bw *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bw.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(key)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(labels[key]))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
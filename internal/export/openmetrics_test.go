@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/metrics"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/sysinfo"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	snapshot := collector.NewSnapshot()
+	snapshot.Set(collector.CPU, &sysinfo.CPUSample{PerCore: []float64{10, 30}, Total: 20})
+	snapshot.Set(collector.Network, &network.IOSample{
+		Total: network.InterfaceIO{Name: "all", SentPerSec: 100},
+		Interfaces: []network.InterfaceIO{
+			{Name: `eth"0`, BytesSent: 1024, SentPerSec: 100},
+		},
+	})
+
+	m := metrics.New(time.Hour)
+	m.RecordUpdateDuration(metrics.CPUUpdate, 250*time.Millisecond)
+	m.RecordError(metrics.NetworkUpdate)
+
+	t.Run("OpenMetrics", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteOpenMetrics(&buf, snapshot, m); err != nil {
+			t.Fatalf("WriteOpenMetrics failed: %v", err)
+		}
+		out := buf.String()
+
+		expected := []string{
+			"# TYPE syspulse_cpu_usage_percent gauge\nsyspulse_cpu_usage_percent 20\n",
+			`syspulse_cpu_core_usage_percent{core="1"} 30`,
+			"# TYPE syspulse_network_transmit_bytes counter\n",
+			`syspulse_network_transmit_bytes_total{interface="eth\"0"} 1024`,
+			"# TYPE syspulse_collector_duration_seconds gauge\n",
+			`syspulse_collector_duration_seconds{collector="cpu"} 0.25`,
+			`syspulse_collector_errors_total{collector="network"} 1`,
+		}
+		for _, want := range expected {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected output to contain %q\n%s", want, out)
+			}
+		}
+
+		if !strings.HasSuffix(out, "# EOF\n") {
+			t.Error("Expected output to end with # EOF")
+		}
+		if strings.Count(out, "# TYPE syspulse_cpu_core_usage_percent ") != 1 {
+			t.Error("Expected a single TYPE line per family")
+		}
+	})
+
+	t.Run("Prometheus text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WritePrometheusText(&buf, snapshot, nil); err != nil {
+			t.Fatalf("WritePrometheusText failed: %v", err)
+		}
+		out := buf.String()
+
+		if !strings.Contains(out, "# TYPE syspulse_network_transmit_bytes_total counter\n") {
+			t.Errorf("Expected counter TYPE line with _total suffix\n%s", out)
+		}
+		if strings.Contains(out, "# EOF") {
+			t.Error("Prometheus text format must not contain # EOF")
+		}
+		if strings.Contains(out, "collector_duration_seconds") {
+			t.Error("Expected no self-metrics without a metrics instance")
+		}
+	})
+}
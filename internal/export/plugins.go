@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// pluginValues flattens the plugin data of a data point into CSV values named
+// after their JSON path: plugins.<plugin>.<key>, with the index of list
+// items as a path element. Values keep their JSON text, null becomes empty.
+func (d DataPoint) pluginValues() map[string]string {
+	if len(d.Plugins) == 0 {
+		return nil
+	}
+
+	// Plugins export arbitrary Go values, so they are normalized through
+	// JSON to flatten exactly what the JSON export holds.
+	encoded, err := json.Marshal(d.Plugins)
+	if err != nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil
+	}
+
+	values := make(map[string]string)
+	flattenPluginValue("plugins", decoded, values)
+	return values
+}
+
+func flattenPluginValue(path string, value interface{}, values map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			flattenPluginValue(path+"."+key, item, values)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenPluginValue(path+"."+strconv.Itoa(i), item, values)
+		}
+	case nil:
+		values[path] = ""
+	default:
+		values[path] = fmt.Sprint(v)
+	}
+}
+
+// pluginColumns returns the sorted plugin columns of all data points.
+func pluginColumns(data ...DataPoint) []string {
+	seen := make(map[string]bool)
+	for _, d := range data {
+		for column := range d.pluginValues() {
+			seen[column] = true
+		}
+	}
+
+	columns := make([]string, 0, len(seen))
+	for column := range seen {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// pluginRows returns the long CSV rows of the plugin values, sorted by
+// column.
+func pluginRows(d DataPoint, timestamp string) [][]string {
+	values := d.pluginValues()
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	rows := make([][]string, 0, len(columns))
+	for _, column := range columns {
+		rows = append(rows, []string{timestamp, column, "", values[column]})
+	}
+	return rows
+}
+
+// hasColumns reports whether every plugin value of the data point has one of
+// the columns.
+func (d DataPoint) hasColumns(columns []string) bool {
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+	for column := range d.pluginValues() {
+		if !known[column] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,268 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"syspulse/internal/utils"
+)
+
+const (
+	defaultPushBatchSize = 500
+	defaultPushRetries   = 3
+	defaultPushTimeout   = 10 * time.Second
+
+	// Batches that could not be delivered are kept and retried with the next
+	// push, up to this many.
+	maxPendingBatches = 10
+
+	// Keeps every UDP datagram below a typical Ethernet MTU.
+	maxDatagramSize = 1400
+)
+
+// PusherConfig describes where a Pusher sends metric lines.
+type PusherConfig struct {
+	URL       *url.URL
+	Format    ExportFormat
+	BatchSize int
+	Retries   int
+	Timeout   time.Duration
+	Headers   map[string]string
+}
+
+// NewPusherConfig maps the export push section of the configuration onto a
+// pusher, filling in defaults for zero values.
+func NewPusherConfig(config utils.PushConfig) (PusherConfig, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil {
+		return PusherConfig{}, fmt.Errorf("invalid push url: %v", err)
+	}
+
+	format, err := ParseFormat(config.Format)
+	if err != nil {
+		return PusherConfig{}, err
+	}
+
+	c := PusherConfig{
+		URL:       target,
+		Format:    format,
+		BatchSize: config.BatchSize,
+		Retries:   config.Retries,
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+		Headers:   config.Headers,
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultPushBatchSize
+	}
+	if c.Retries == 0 {
+		c.Retries = defaultPushRetries
+	} else if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultPushTimeout
+	}
+	return c, nil
+}
+
+// Pusher sends data points to a metrics backend as InfluxDB line protocol or
+// Graphite plaintext over TCP, UDP or HTTP. Lines are sent in batches of
+// BatchSize; a batch that still fails after the retries stays queued and is
+// sent ahead of the next push.
+type Pusher struct {
+	config  PusherConfig
+	client  *http.Client
+	backoff time.Duration
+
+	mu      sync.Mutex
+	pending [][]string
+}
+
+func NewPusher(config PusherConfig) (*Pusher, error) {
+	if config.Format != Influx && config.Format != Graphite {
+		return nil, fmt.Errorf("push format must be influx or graphite")
+	}
+	if config.URL == nil || config.URL.Host == "" {
+		return nil, fmt.Errorf("push url must include a host")
+	}
+
+	switch config.URL.Scheme {
+	case "tcp", "udp", "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported push protocol: %s", config.URL.Scheme)
+	}
+
+	return &Pusher{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		backoff: time.Second,
+	}, nil
+}
+
+// Push queues the lines of every data point and sends all queued batches.
+func (p *Pusher) Push(ctx context.Context, data ...DataPoint) error {
+	var lines []string
+	for _, d := range data {
+		dpLines, err := Lines(d, p.config.Format)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, dpLines...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(lines) > 0 {
+		n := min(p.config.BatchSize, len(lines))
+		p.pending = append(p.pending, lines[:n])
+		lines = lines[n:]
+	}
+	if len(p.pending) > maxPendingBatches {
+		p.pending = p.pending[len(p.pending)-maxPendingBatches:]
+	}
+
+	for len(p.pending) > 0 {
+		retry, err := p.sendWithRetry(ctx, p.pending[0])
+		if err != nil && retry {
+			return err
+		}
+		p.pending = p.pending[1:]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the number of batches waiting to be sent.
+func (p *Pusher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+// sendWithRetry reports whether a failed batch should be kept for the next
+// push; batches the endpoint rejected outright are dropped.
+func (p *Pusher) sendWithRetry(ctx context.Context, batch []string) (bool, error) {
+	payload := []byte(strings.Join(batch, "\n") + "\n")
+	delay := p.backoff
+
+	var (
+		retry bool
+		err   error
+	)
+	for attempt := 0; attempt <= p.config.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return true, ctx.Err()
+			}
+		}
+
+		if retry, err = p.send(ctx, payload); err == nil || !retry {
+			return retry, err
+		}
+	}
+	return true, err
+}
+
+// send delivers one batch and reports whether a failure is worth retrying.
+func (p *Pusher) send(ctx context.Context, payload []byte) (bool, error) {
+	switch p.config.URL.Scheme {
+	case "tcp":
+		return true, p.sendTCP(ctx, payload)
+	case "udp":
+		return true, p.sendUDP(ctx, payload)
+	}
+	return p.sendHTTP(ctx, payload)
+}
+
+func (p *Pusher) sendTCP(ctx context.Context, payload []byte) error {
+	dialer := net.Dialer{Timeout: p.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.config.URL.Host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(p.config.Timeout))
+	_, err = conn.Write(payload)
+	return err
+}
+
+func (p *Pusher) sendUDP(ctx context.Context, payload []byte) error {
+	dialer := net.Dialer{Timeout: p.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "udp", p.config.URL.Host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, datagram := range splitDatagrams(payload, maxDatagramSize) {
+		if _, err := conn.Write(datagram); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitDatagrams cuts the payload on line boundaries so no line is split
+// across datagrams. A single line longer than size is sent on its own.
+func splitDatagrams(payload []byte, size int) [][]byte {
+	var datagrams [][]byte
+	for len(payload) > 0 {
+		if len(payload) <= size {
+			datagrams = append(datagrams, payload)
+			break
+		}
+
+		cut := bytes.LastIndexByte(payload[:size], '\n') + 1
+		if cut == 0 {
+			cut = bytes.IndexByte(payload, '\n') + 1
+			if cut == 0 {
+				cut = len(payload)
+			}
+		}
+		datagrams = append(datagrams, payload[:cut])
+		payload = payload[cut:]
+	}
+	return datagrams
+}
+
+func (p *Pusher) sendHTTP(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL.String(), bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	for key, value := range p.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// A rejected batch will be rejected again; only server-side failures and
+	// throttling are retried.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("push endpoint returned %s", resp.Status)
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"syspulse/internal/utils"
+)
+
+func newTestPusher(t *testing.T, rawURL string, format string, batchSize int) *Pusher {
+	t.Helper()
+	config, err := NewPusherConfig(utils.PushConfig{URL: rawURL, Format: format, BatchSize: batchSize, Timeout: 2})
+	if err != nil {
+		t.Fatalf("Invalid push config: %v", err)
+	}
+	pusher, err := NewPusher(config)
+	if err != nil {
+		t.Fatalf("Failed to create pusher: %v", err)
+	}
+	pusher.backoff = time.Millisecond
+	return pusher
+}
+
+func TestPushTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			var lines []string
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				lines = append(lines, scanner.Text())
+			}
+			conn.Close()
+			received <- lines
+		}
+	}()
+
+	pusher := newTestPusher(t, "tcp://"+listener.Addr().String(), "graphite", 2)
+	if err := pusher.Push(context.Background(), lineTestData()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	// Three lines in batches of two arrive over two connections.
+	var lines []string
+	for i := 0; i < 2; i++ {
+		select {
+		case batch := <-received:
+			lines = append(lines, batch...)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for batch %d", i+1)
+		}
+	}
+	if len(lines) != 3 || !strings.Contains(lines[0], ".cpu_usage_percent 12.5 1700000000") {
+		t.Errorf("Unexpected lines %v", lines)
+	}
+}
+
+func TestPushUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	pusher := newTestPusher(t, "udp://"+conn.LocalAddr().String(), "influx", 0)
+	if err := pusher.Push(context.Background(), lineTestData()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("No datagram received: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(buf[:n])), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "syspulse_cpu_usage_percent,host=") {
+		t.Errorf("Unexpected datagram %q", buf[:n])
+	}
+}
+
+func TestPushHTTPRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		body     string
+		auth     string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config, _ := NewPusherConfig(utils.PushConfig{
+		URL:     server.URL + "/api/v2/write?bucket=syspulse&precision=ns",
+		Format:  "influx",
+		Headers: map[string]string{"Authorization": "Token secret"},
+	})
+	pusher, _ := NewPusher(config)
+	pusher.backoff = time.Millisecond
+
+	if err := pusher.Push(context.Background(), lineTestData()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 3 {
+		t.Errorf("Expected 3 attempts, got %d", requests)
+	}
+	if auth != "Token secret" {
+		t.Errorf("Expected Authorization header, got %q", auth)
+	}
+	if strings.Count(body, "\n") != 3 {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestPushKeepsFailedBatches(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		status  = http.StatusBadGateway
+		batches int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if status == http.StatusNoContent {
+			batches++
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	pusher := newTestPusher(t, server.URL, "graphite", 0)
+	pusher.config.Retries = 1
+
+	if err := pusher.Push(context.Background(), lineTestData()); err == nil {
+		t.Fatal("Expected push to a failing endpoint to fail")
+	}
+	if pusher.Pending() != 1 {
+		t.Fatalf("Expected the failed batch to stay queued, got %d", pusher.Pending())
+	}
+
+	mu.Lock()
+	status = http.StatusNoContent
+	mu.Unlock()
+
+	if err := pusher.Push(context.Background(), lineTestData()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if pusher.Pending() != 0 || batches != 2 {
+		t.Errorf("Expected both batches delivered, got %d sent and %d pending", batches, pusher.Pending())
+	}
+
+	mu.Lock()
+	status = http.StatusBadRequest
+	mu.Unlock()
+
+	if err := pusher.Push(context.Background(), lineTestData()); err == nil {
+		t.Fatal("Expected a rejected batch to fail")
+	}
+	if pusher.Pending() != 0 {
+		t.Errorf("Expected a rejected batch to be dropped, got %d pending", pusher.Pending())
+	}
+}
+
+func TestSplitDatagrams(t *testing.T) {
+	payload := []byte("aaaa\nbbbb\ncccccccccc\ndd\n")
+	datagrams := splitDatagrams(payload, 10)
+
+	expected := []string{"aaaa\nbbbb\n", "cccccccccc\n", "dd\n"}
+	if len(datagrams) != len(expected) {
+		t.Fatalf("Expected %d datagrams, got %q", len(expected), datagrams)
+	}
+	for i := range expected {
+		if string(datagrams[i]) != expected[i] {
+			t.Errorf("Datagram %d: expected %q, got %q", i, expected[i], datagrams[i])
+		}
+	}
+}
+
+func TestNewPusherRejectsInvalidTargets(t *testing.T) {
+	tests := []PusherConfig{
+		{URL: &url.URL{Scheme: "ftp", Host: "x:21"}, Format: Influx},
+		{URL: &url.URL{Scheme: "tcp"}, Format: Graphite},
+		{URL: &url.URL{Scheme: "tcp", Host: "x:2003"}, Format: JSON},
+	}
+	for _, config := range tests {
+		if _, err := NewPusher(config); err == nil {
+			t.Errorf("Expected %v to be rejected", config)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"syspulse/internal/utils"
+)
+
+const segmentTimeFormat = "2006-01-02_15-04-05"
+
+// StreamConfig controls where a Stream writes and when it starts a new
+// segment. Zero values disable the matching limit.
+type StreamConfig struct {
+	Directory string
+	Prefix    string
+	Format    ExportFormat
+	CSVLayout CSVLayout
+	MaxSize   int64         // Rotate once the active segment reaches this many bytes
+	MaxAge    time.Duration // Rotate once the active segment is this old
+	Compress  bool          // Gzip segments once they are closed
+	Retention int           // Number of closed segments to keep
+}
+
+// NewStreamConfig maps the export section of the configuration onto a stream
+// for one format.
+func NewStreamConfig(config utils.ExportConfig, format ExportFormat) StreamConfig {
+	layout, _ := ParseCSVLayout(config.CSVLayout)
+	return StreamConfig{
+		Directory: config.Directory,
+		Prefix:    config.FilenamePrefix,
+		Format:    format,
+		CSVLayout: layout,
+		MaxSize:   int64(config.MaxSizeMB) << 20,
+		MaxAge:    time.Duration(config.RotateInterval) * time.Second,
+		Compress:  config.Compress,
+		Retention: config.Retention,
+	}
+}
+
+// Stream appends one NDJSON line, CSV row or batch of metric lines per data
+// point to an open segment file instead of rewriting the whole history on
+// every export.
+type Stream struct {
+	config StreamConfig
+	now    func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	csv    *csv.Writer
+	path   string
+	size   int64
+	opened time.Time
+	closed bool
+
+	// columns are the plugin columns in the header of a wide CSV segment.
+	columns []string
+}
+
+func NewStream(config StreamConfig) (*Stream, error) {
+	switch config.Format {
+	case CSV, JSON, Influx, Graphite:
+	default:
+		return nil, fmt.Errorf("unsupported export format")
+	}
+	if err := os.MkdirAll(config.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	return &Stream{config: config, now: time.Now}, nil
+}
+
+// Extension returns the file extension of the stream's segments.
+func (s *Stream) Extension() string {
+	switch s.config.Format {
+	case CSV:
+		return "csv"
+	case Influx:
+		return "influx"
+	case Graphite:
+		return "graphite"
+	}
+	return "ndjson"
+}
+
+// Path returns the active segment, or "" before the first write.
+func (s *Stream) Path() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.path
+}
+
+func (s *Stream) Write(dp DataPoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("export stream is closed")
+	}
+
+	if s.file != nil && (s.shouldRotate() || !s.fits(dp)) {
+		if err := s.closeSegment(); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		if err := s.openSegment(dp); err != nil {
+			return err
+		}
+	}
+
+	switch s.config.Format {
+	case CSV:
+		return s.writeCSV(csvRows(dp, s.config.CSVLayout, s.columns)...)
+	case Influx, Graphite:
+		return s.writeLines(dp)
+	}
+	return s.writeJSON(dp)
+}
+
+// Rotate closes the active segment; the next write starts a new one.
+func (s *Stream) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	return s.closeSegment()
+}
+
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	return s.closeSegment()
+}
+
+func (s *Stream) shouldRotate() bool {
+	if s.config.MaxSize > 0 && s.size >= s.config.MaxSize {
+		return true
+	}
+	if s.config.MaxAge > 0 && s.now().Sub(s.opened) >= s.config.MaxAge {
+		return true
+	}
+	return false
+}
+
+// fits reports whether the data point can go into the active segment. The
+// header of a wide CSV segment only has the plugin columns of its first row,
+// so a row with new plugin values starts a new segment.
+func (s *Stream) fits(dp DataPoint) bool {
+	if s.config.Format != CSV || s.config.CSVLayout == CSVLong {
+		return true
+	}
+	return dp.hasColumns(s.columns)
+}
+
+func (s *Stream) openSegment(first DataPoint) error {
+	opened := s.now()
+	base := fmt.Sprintf("%s_%s", s.config.Prefix, opened.Format(segmentTimeFormat))
+
+	// Segments rotated within the same second get a numeric suffix so an
+	// older segment is never appended to after it has been closed.
+	path := filepath.Join(s.config.Directory, base+"."+s.Extension())
+	for i := 1; segmentExists(path); i++ {
+		path = filepath.Join(s.config.Directory, fmt.Sprintf("%s_%d.%s", base, i, s.Extension()))
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open export segment: %v", err)
+	}
+
+	s.file = file
+	s.path = path
+	s.size = 0
+	s.opened = opened
+	s.csv = nil
+	s.columns = nil
+
+	if s.config.Format == CSV {
+		if s.config.CSVLayout != CSVLong {
+			s.columns = pluginColumns(first)
+		}
+		s.csv = csv.NewWriter(&countingWriter{w: file, n: &s.size})
+		if err := s.writeCSV(csvHeaderFor(s.config.CSVLayout, s.columns)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func segmentExists(path string) bool {
+	for _, candidate := range []string{path, path + ".gz"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Stream) writeCSV(records ...[]string) error {
+	for _, record := range records {
+		if err := s.csv.Write(record); err != nil {
+			return err
+		}
+	}
+	s.csv.Flush()
+	return s.csv.Error()
+}
+
+func (s *Stream) writeJSON(dp DataPoint) error {
+	line, err := json.Marshal(dp)
+	if err != nil {
+		return err
+	}
+	n, err := s.file.Write(append(line, '\n'))
+	s.size += int64(n)
+	return err
+}
+
+func (s *Stream) writeLines(dp DataPoint) error {
+	lines, err := Lines(dp, s.config.Format)
+	if err != nil || len(lines) == 0 {
+		return err
+	}
+	n, err := io.WriteString(s.file, strings.Join(lines, "\n")+"\n")
+	s.size += int64(n)
+	return err
+}
+
+func (s *Stream) closeSegment() error {
+	path := s.path
+	err := s.file.Close()
+	s.file = nil
+	s.csv = nil
+	if err != nil {
+		return err
+	}
+
+	if s.config.Compress {
+		if err := compressFile(path); err != nil {
+			return err
+		}
+	}
+	return s.enforceRetention()
+}
+
+// enforceRetention removes the oldest closed segments beyond the retention
+// count.
+func (s *Stream) enforceRetention() error {
+	if s.config.Retention <= 0 {
+		return nil
+	}
+
+	segments, err := s.Segments()
+	if err != nil {
+		return err
+	}
+
+	for len(segments) > s.config.Retention {
+		if err := os.Remove(segments[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+// Segments lists the closed segments of this stream, oldest first.
+func (s *Stream) Segments() ([]string, error) {
+	entries, err := os.ReadDir(s.config.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := s.config.Prefix + "_"
+	ext := "." + s.Extension()
+
+	var segments []string
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(s.config.Directory, name)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || path == s.path && s.file != nil {
+			continue
+		}
+		if strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz") {
+			segments = append(segments, path)
+		}
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return s.segmentKey(segments[i]) < s.segmentKey(segments[j])
+	})
+	return segments, nil
+}
+
+// segmentKey makes "x_<time>.csv" sort before "x_<time>_2.csv" and
+// "x_<time>_2.csv" before "x_<time>_10.csv".
+func (s *Stream) segmentKey(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".gz")
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.TrimPrefix(name, s.config.Prefix+"_")
+	if len(name) < len(segmentTimeFormat) {
+		return name
+	}
+
+	stamp, suffix := name[:len(segmentTimeFormat)], strings.TrimPrefix(name[len(segmentTimeFormat):], "_")
+	return fmt.Sprintf("%s_%06s", stamp, suffix)
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,243 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStream(t *testing.T, config StreamConfig) (*Stream, *time.Time) {
+	t.Helper()
+	config.Directory = t.TempDir()
+	if config.Prefix == "" {
+		config.Prefix = "syspulse"
+	}
+
+	stream, err := NewStream(config)
+	if err != nil {
+		t.Fatalf("Failed to create stream: %v", err)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stream.now = func() time.Time { return now }
+	return stream, &now
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	stream, _ := newTestStream(t, StreamConfig{Format: JSON})
+	data := createTestData()[0]
+
+	for i := 0; i < 3; i++ {
+		if err := stream.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	path := stream.Path()
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if filepath.Base(path) != "syspulse_2024-05-01_12-00-00.ndjson" {
+		t.Errorf("Unexpected segment name %s", filepath.Base(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open segment: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var dp DataPoint
+		if err := json.Unmarshal(scanner.Bytes(), &dp); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", lines+1, err)
+		}
+		if dp.Memory.Total != data.Memory.Total {
+			t.Errorf("Expected memory total %d, got %d", data.Memory.Total, dp.Memory.Total)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("Expected 3 lines, got %d", lines)
+	}
+
+	if err := stream.Write(data); err == nil {
+		t.Error("Expected write after close to fail")
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	stream, _ := newTestStream(t, StreamConfig{Format: CSV})
+	data := createTestData()[0]
+
+	stream.Write(data)
+	stream.Write(data)
+	path := stream.Path()
+	stream.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open segment: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	if records[0][0] != "Timestamp" || records[1][6] != "/" {
+		t.Errorf("Unexpected CSV content %v", records[:2])
+	}
+}
+
+func TestStreamLongCSV(t *testing.T) {
+	stream, _ := newTestStream(t, StreamConfig{Format: CSV, CSVLayout: CSVLong})
+	data := createTestData()[0]
+
+	stream.Write(data)
+	stream.Write(data)
+	path := stream.Path()
+	stream.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open segment: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	perSample := len(csvLongRows(data))
+	if len(records) != 1+2*perSample {
+		t.Fatalf("Expected header and %d rows, got %d records", 2*perSample, len(records))
+	}
+	if records[0][1] != "Metric" || records[1][1] != "cpu_usage_percent" {
+		t.Errorf("Unexpected CSV content %v", records[:2])
+	}
+}
+
+func TestStreamRotation(t *testing.T) {
+	data := createTestData()[0]
+
+	t.Run("By Size", func(t *testing.T) {
+		stream, _ := newTestStream(t, StreamConfig{Format: JSON, MaxSize: 1})
+		for i := 0; i < 3; i++ {
+			stream.Write(data)
+		}
+		stream.Close()
+
+		segments, _ := stream.Segments()
+		if len(segments) != 3 {
+			t.Fatalf("Expected 3 segments, got %v", segments)
+		}
+		if filepath.Base(segments[1]) != "syspulse_2024-05-01_12-00-00_1.ndjson" {
+			t.Errorf("Expected numbered segment, got %s", filepath.Base(segments[1]))
+		}
+	})
+
+	t.Run("By Age", func(t *testing.T) {
+		stream, now := newTestStream(t, StreamConfig{Format: CSV, MaxAge: time.Hour})
+		stream.Write(data)
+		*now = now.Add(30 * time.Minute)
+		stream.Write(data)
+		*now = now.Add(31 * time.Minute)
+		stream.Write(data)
+		stream.Close()
+
+		segments, _ := stream.Segments()
+		if len(segments) != 2 {
+			t.Fatalf("Expected 2 segments, got %v", segments)
+		}
+		if filepath.Base(segments[1]) != "syspulse_2024-05-01_13-01-00.csv" {
+			t.Errorf("Unexpected second segment %s", filepath.Base(segments[1]))
+		}
+	})
+
+	t.Run("Compress And Retain", func(t *testing.T) {
+		stream, now := newTestStream(t, StreamConfig{Format: JSON, MaxAge: time.Minute, Compress: true, Retention: 2})
+		for i := 0; i < 5; i++ {
+			stream.Write(data)
+			*now = now.Add(time.Minute)
+		}
+		active := stream.Path()
+
+		segments, _ := stream.Segments()
+		if len(segments) != 2 {
+			t.Fatalf("Expected 2 retained segments, got %v", segments)
+		}
+		for _, segment := range segments {
+			if !strings.HasSuffix(segment, ".ndjson.gz") {
+				t.Errorf("Expected closed segment to be compressed, got %s", segment)
+			}
+		}
+		if filepath.Base(segments[0]) != "syspulse_2024-05-01_12-02-00.ndjson.gz" {
+			t.Errorf("Expected oldest segments to be removed, got %s", filepath.Base(segments[0]))
+		}
+		if _, err := os.Stat(active); err != nil {
+			t.Errorf("Expected active segment to stay uncompressed: %v", err)
+		}
+
+		file, _ := os.Open(segments[0])
+		defer file.Close()
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("Invalid gzip segment: %v", err)
+		}
+		content, _ := io.ReadAll(gz)
+		var dp DataPoint
+		if err := json.Unmarshal(content, &dp); err != nil {
+			t.Errorf("Compressed segment does not hold a JSON line: %v", err)
+		}
+	})
+}
+
+func TestStreamPluginColumns(t *testing.T) {
+	stream, _ := newTestStream(t, StreamConfig{Format: CSV})
+	data := createTestData()[0]
+	data.Plugins = map[string]interface{}{"docker": map[string]interface{}{"running": 2}}
+
+	stream.Write(data)
+	data.Plugins = map[string]interface{}{}
+	stream.Write(data)
+	first := stream.Path()
+
+	// A new plugin value needs a column the first segment does not have.
+	data.Plugins = map[string]interface{}{"docker": map[string]interface{}{"running": 1, "images": 4}}
+	stream.Write(data)
+	stream.Close()
+
+	segments, _ := stream.Segments()
+	if len(segments) != 2 || segments[0] != first {
+		t.Fatalf("Expected a second segment for the new column, got %v", segments)
+	}
+
+	read := func(path string) [][]string {
+		file, _ := os.Open(path)
+		defer file.Close()
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read CSV: %v", err)
+		}
+		return records
+	}
+	if records := read(segments[0]); len(records) != 3 || records[0][len(csvHeader)] != "plugins.docker.running" || records[1][len(csvHeader)] != "2" || records[2][len(csvHeader)] != "" {
+		t.Errorf("Unexpected first segment %v", records)
+	}
+	if records := read(segments[1]); strings.Join(records[0][len(csvHeader):], ",") != "plugins.docker.images,plugins.docker.running" {
+		t.Errorf("Unexpected second segment header %v", records[0])
+	}
+}
@@ -0,0 +1,552 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"time"
+)
+
+type Tier struct {
+	Name       string `json:"name"`
+	Resolution int    `json:"resolution"`
+	Retention  int    `json:"retention"`
+}
+
+func (t Tier) resolution() time.Duration {
+	return time.Duration(t.Resolution) * time.Second
+}
+
+func (t Tier) retention() time.Duration {
+	return time.Duration(t.Retention) * time.Second
+}
+
+func (t Tier) capacity() int {
+	step := t.Resolution
+	if step <= 0 {
+		step = 1
+	}
+	capacity := t.Retention / step
+	if capacity < 1 {
+		capacity = 1
+	}
+	return capacity
+}
+
+type Config struct {
+	Enabled   bool   `json:"enabled"`
+	MaxSeries int    `json:"max_series"`
+	Tiers     []Tier `json:"tiers"`
+}
+
+var DefaultTiers = []Tier{
+	{Name: "raw", Resolution: 0, Retention: 300},
+	{Name: "10s", Resolution: 10, Retention: 3600},
+	{Name: "1m", Resolution: 60, Retention: 86400},
+}
+
+const DefaultMaxSeries = 1000
+
+var DefaultConfig = Config{
+	Enabled:   true,
+	MaxSeries: DefaultMaxSeries,
+	Tiers:     DefaultTiers,
+}
+
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+}
+
+type Series struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Points []Point           `json:"points"`
+}
+
+type Summary struct {
+	Count int
+	Min   float64
+	Max   float64
+	Avg   float64
+	Last  float64
+}
+
+func Summarize(points []Point) Summary {
+	var summary Summary
+	if len(points) == 0 {
+		return summary
+	}
+
+	summary.Count = len(points)
+	summary.Min = points[0].Min
+	summary.Max = points[0].Max
+	var sum float64
+	for _, p := range points {
+		if p.Min < summary.Min {
+			summary.Min = p.Min
+		}
+		if p.Max > summary.Max {
+			summary.Max = p.Max
+		}
+		sum += p.Value
+	}
+	summary.Avg = sum / float64(len(points))
+	summary.Last = points[len(points)-1].Value
+	return summary
+}
+
+// SeriesKey builds the canonical identity of a series from its metric name and
+// labels, e.g. cpu_core_usage_percent{core="0"}.
+func SeriesKey(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", k, labels[k])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ring keeps the latest capacity points. It grows as points arrive, so
+// short-lived series do not hold a full tier of memory.
+type ring struct {
+	points   []Point
+	capacity int
+	start    int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{capacity: capacity}
+}
+
+func (r *ring) push(p Point) {
+	if len(r.points) < r.capacity {
+		r.points = append(r.points, p)
+		return
+	}
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+}
+
+func (r *ring) last() (Point, bool) {
+	if len(r.points) == 0 {
+		return Point{}, false
+	}
+	return r.points[(r.start+len(r.points)-1)%len(r.points)], true
+}
+
+func (r *ring) rangeOf(from, to time.Time) []Point {
+	var points []Point
+	for i := range r.points {
+		p := r.points[(r.start+i)%len(r.points)]
+		if p.Time.Before(from) || p.Time.After(to) {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+type tierBuffer struct {
+	tier        Tier
+	ring        *ring
+	bucketStart time.Time
+	sum         float64
+	min         float64
+	max         float64
+	count       int
+}
+
+func (b *tierBuffer) add(t time.Time, value float64) {
+	if b.tier.Resolution <= 0 {
+		b.ring.push(Point{Time: t, Value: value, Min: value, Max: value})
+		return
+	}
+
+	bucket := t.Truncate(b.tier.resolution())
+	if b.count > 0 && !bucket.Equal(b.bucketStart) {
+		b.flush()
+	}
+	if b.count == 0 {
+		b.bucketStart = bucket
+		b.min = value
+		b.max = value
+	}
+	b.sum += value
+	b.count++
+	if value < b.min {
+		b.min = value
+	}
+	if value > b.max {
+		b.max = value
+	}
+}
+
+func (b *tierBuffer) pending() (Point, bool) {
+	if b.count == 0 {
+		return Point{}, false
+	}
+	return Point{Time: b.bucketStart, Value: b.sum / float64(b.count), Min: b.min, Max: b.max}, true
+}
+
+func (b *tierBuffer) flush() {
+	if p, ok := b.pending(); ok {
+		b.ring.push(p)
+	}
+	b.sum = 0
+	b.count = 0
+}
+
+func (b *tierBuffer) rangeOf(from, to time.Time) []Point {
+	points := b.ring.rangeOf(from, to)
+	if p, ok := b.pending(); ok && !p.Time.Before(from) && !p.Time.After(to) {
+		points = append(points, p)
+	}
+	return points
+}
+
+type series struct {
+	name     string
+	labels   map[string]string
+	lastTime time.Time
+	tiers    []*tierBuffer
+}
+
+func (s *series) add(t time.Time, value float64) {
+	if !t.After(s.lastTime) {
+		return
+	}
+	s.lastTime = t
+	for _, tier := range s.tiers {
+		tier.add(t, value)
+	}
+}
+
+// Store keeps a bounded history of every collected metric, downsampled into
+// the configured tiers. All methods are safe to call on a nil Store.
+type Store struct {
+	mu        sync.RWMutex
+	tiers     []Tier
+	maxSeries int
+	series    map[string]*series
+	byName    map[string][]*series
+	dropped   int
+}
+
+func NewStore(config Config) *Store {
+	tiers := config.Tiers
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+	tiers = append([]Tier(nil), tiers...)
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].Resolution < tiers[j].Resolution
+	})
+
+	maxSeries := config.MaxSeries
+	if maxSeries <= 0 {
+		maxSeries = DefaultMaxSeries
+	}
+
+	return &Store{
+		tiers:     tiers,
+		maxSeries: maxSeries,
+		series:    make(map[string]*series),
+		byName:    make(map[string][]*series),
+	}
+}
+
+func (s *Store) Tiers() []Tier {
+	if s == nil {
+		return nil
+	}
+	return append([]Tier(nil), s.tiers...)
+}
+
+func (s *Store) Add(t time.Time, points ...collector.Point) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range points {
+		key := SeriesKey(p.Name, p.Labels)
+		ser, exists := s.series[key]
+		if !exists {
+			if len(s.series) >= s.maxSeries && !s.makeRoom(t) {
+				s.dropped++
+				continue
+			}
+			ser = s.newSeries(p)
+			s.series[key] = ser
+			s.byName[p.Name] = append(s.byName[p.Name], ser)
+		}
+		ser.add(t, p.Value)
+	}
+}
+
+// makeRoom frees a series slot when the store is full. It first expires the
+// series with no points left in any tier, e.g. of exited processes, and
+// otherwise evicts the least recently updated one. Series already updated at
+// t are kept, so one large batch cannot evict itself.
+func (s *Store) makeRoom(t time.Time) bool {
+	var retention time.Duration
+	for _, tier := range s.tiers {
+		retention = max(retention, tier.retention())
+	}
+
+	var oldestKey string
+	var oldest *series
+	for key, ser := range s.series {
+		if t.Sub(ser.lastTime) > retention {
+			s.remove(key, ser)
+			continue
+		}
+		if oldest == nil || ser.lastTime.Before(oldest.lastTime) {
+			oldestKey, oldest = key, ser
+		}
+	}
+
+	if len(s.series) < s.maxSeries {
+		return true
+	}
+	if oldest == nil || !oldest.lastTime.Before(t) {
+		return false
+	}
+	s.remove(oldestKey, oldest)
+	return true
+}
+
+func (s *Store) remove(key string, ser *series) {
+	delete(s.series, key)
+	siblings := s.byName[ser.name]
+	for i, sibling := range siblings {
+		if sibling == ser {
+			siblings = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+	if len(siblings) == 0 {
+		delete(s.byName, ser.name)
+	} else {
+		s.byName[ser.name] = siblings
+	}
+}
+
+func (s *Store) newSeries(p collector.Point) *series {
+	labels := make(map[string]string, len(p.Labels))
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+
+	ser := &series{name: p.Name, labels: labels}
+	for _, tier := range s.tiers {
+		ser.tiers = append(ser.tiers, &tierBuffer{tier: tier, ring: newRing(tier.capacity())})
+	}
+	return ser
+}
+
+func (s *Store) AddRecord(record collector.Record) {
+	if record.Sample == nil || record.Err != nil {
+		return
+	}
+	s.Add(record.Time, record.Sample.Points()...)
+}
+
+// AddSnapshot adds the collectors with the fewest points first, so that a
+// full store keeps the system-wide metrics over per-process and per-cgroup
+// ones.
+func (s *Store) AddSnapshot(snapshot *collector.Snapshot) {
+	type batch struct {
+		time   time.Time
+		points []collector.Point
+	}
+
+	var batches []batch
+	for _, record := range snapshot.Records() {
+		if record.Sample != nil && record.Err == nil {
+			batches = append(batches, batch{record.Time, record.Sample.Points()})
+		}
+	}
+	sort.SliceStable(batches, func(i, j int) bool {
+		return len(batches[i].points) < len(batches[j].points)
+	})
+	for _, b := range batches {
+		s.Add(b.time, b.points...)
+	}
+}
+
+// Range returns the points of one series between from and to, read from the
+// finest tier whose retention still covers from.
+func (s *Store) Range(name string, labels map[string]string, from, to time.Time) []Point {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser, exists := s.series[SeriesKey(name, labels)]
+	if !exists {
+		return nil
+	}
+	return ser.tiers[s.tierFor(from)].rangeOf(from, to)
+}
+
+func (s *Store) RangeTier(tier string, name string, labels map[string]string, from, to time.Time) ([]Point, error) {
+	if s == nil {
+		return nil, fmt.Errorf("history is disabled")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index := s.tierIndex(tier)
+	if index < 0 {
+		return nil, fmt.Errorf("unknown history tier: %s", tier)
+	}
+
+	ser, exists := s.series[SeriesKey(name, labels)]
+	if !exists {
+		return nil, nil
+	}
+	return ser.tiers[index].rangeOf(from, to), nil
+}
+
+// RangeAll returns every series of a metric, whatever its labels, ordered by
+// series key.
+func (s *Store) RangeAll(name string, from, to time.Time) []Series {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.rangeAll(s.tierFor(from), name, from, to)
+}
+
+func (s *Store) RangeAllTier(tier string, name string, from, to time.Time) ([]Series, error) {
+	if s == nil {
+		return nil, fmt.Errorf("history is disabled")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index := s.tierIndex(tier)
+	if index < 0 {
+		return nil, fmt.Errorf("unknown history tier: %s", tier)
+	}
+	return s.rangeAll(index, name, from, to), nil
+}
+
+func (s *Store) rangeAll(tier int, name string, from, to time.Time) []Series {
+	result := make([]Series, 0, len(s.byName[name]))
+	for _, ser := range s.byName[name] {
+		result = append(result, Series{
+			Name:   ser.name,
+			Labels: ser.labels,
+			Points: ser.tiers[tier].rangeOf(from, to),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return SeriesKey(result[i].Name, result[i].Labels) < SeriesKey(result[j].Name, result[j].Labels)
+	})
+	return result
+}
+
+func (s *Store) Latest(name string, labels map[string]string) (Point, bool) {
+	if s == nil {
+		return Point{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ser, exists := s.series[SeriesKey(name, labels)]
+	if !exists || len(ser.tiers) == 0 {
+		return Point{}, false
+	}
+	if p, ok := ser.tiers[0].pending(); ok {
+		return p, true
+	}
+	return ser.tiers[0].ring.last()
+}
+
+func (s *Store) Names() []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.byName))
+	for name := range s.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Store) SeriesCount() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.series)
+}
+
+func (s *Store) Dropped() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dropped
+}
+
+func (s *Store) tierIndex(name string) int {
+	for i, t := range s.tiers {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) tierFor(from time.Time) int {
+	age := time.Since(from)
+	for i, tier := range s.tiers {
+		if age <= tier.retention() {
+			return i
+		}
+	}
+	return len(s.tiers) - 1
+}
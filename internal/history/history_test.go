@@ -0,0 +1,168 @@
+package history
+
+import (
+	"strconv"
+	"syspulse/internal/collector"
+	"testing"
+	"time"
+)
+
+func TestSeriesKey(t *testing.T) {
+	key := SeriesKey("disk_used_percent", map[string]string{"mountpoint": "/", "device": "sda1"})
+	expected := `disk_used_percent{device="sda1",mountpoint="/"}`
+	if key != expected {
+		t.Errorf("Expected %s, got %s", expected, key)
+	}
+
+	if SeriesKey("load1", nil) != "load1" {
+		t.Error("Expected unlabelled key to equal the metric name")
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Run("Raw Range", func(t *testing.T) {
+		store := NewStore(Config{Tiers: []Tier{{Name: "raw", Retention: 60}}})
+		now := time.Now()
+		for i := 0; i < 5; i++ {
+			store.Add(now.Add(time.Duration(i-5)*time.Second), collector.GaugePoint("load1", float64(i), nil))
+		}
+
+		points := store.Range("load1", nil, now.Add(-time.Minute), now)
+		if len(points) != 5 {
+			t.Fatalf("Expected 5 points, got %d", len(points))
+		}
+		if points[4].Value != 4 {
+			t.Errorf("Expected last value 4, got %v", points[4].Value)
+		}
+	})
+
+	t.Run("Ring Is Bounded", func(t *testing.T) {
+		store := NewStore(Config{Tiers: []Tier{{Name: "raw", Retention: 3}}})
+		now := time.Now()
+		for i := 0; i < 10; i++ {
+			store.Add(now.Add(time.Duration(i-10)*time.Second), collector.GaugePoint("load1", float64(i), nil))
+		}
+
+		points := store.Range("load1", nil, now.Add(-time.Minute), now)
+		if len(points) != 3 {
+			t.Fatalf("Expected 3 points, got %d", len(points))
+		}
+		if points[0].Value != 7 {
+			t.Errorf("Expected oldest retained value 7, got %v", points[0].Value)
+		}
+	})
+
+	t.Run("Ring Grows On Demand", func(t *testing.T) {
+		r := newRing(3600)
+		r.push(Point{Value: 1})
+		if cap(r.points) > 8 {
+			t.Errorf("Expected a ring with one point to stay small, got capacity %d", cap(r.points))
+		}
+		if p, ok := r.last(); !ok || p.Value != 1 {
+			t.Errorf("Expected last value 1, got %v", p)
+		}
+	})
+
+	t.Run("Downsampling", func(t *testing.T) {
+		store := NewStore(Config{Tiers: []Tier{{Name: "raw", Retention: 60}, {Name: "10s", Resolution: 10, Retention: 600}}})
+		base := time.Now().Truncate(10 * time.Second).Add(-30 * time.Second)
+		for i := 0; i < 20; i++ {
+			store.Add(base.Add(time.Duration(i)*time.Second), collector.GaugePoint("cpu_usage_percent", float64(i), nil))
+		}
+
+		points, err := store.RangeTier("10s", "cpu_usage_percent", nil, base, time.Now())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(points) != 2 {
+			t.Fatalf("Expected 2 buckets, got %d", len(points))
+		}
+		if points[0].Value != 4.5 || points[0].Min != 0 || points[0].Max != 9 {
+			t.Errorf("Unexpected first bucket: %+v", points[0])
+		}
+	})
+
+	t.Run("Labels And Duplicates", func(t *testing.T) {
+		store := NewStore(DefaultConfig)
+		now := time.Now()
+		core0 := map[string]string{"core": "0"}
+		core1 := map[string]string{"core": "1"}
+		store.Add(now, collector.GaugePoint("cpu_core_usage_percent", 10, core0), collector.GaugePoint("cpu_core_usage_percent", 20, core1))
+		store.Add(now, collector.GaugePoint("cpu_core_usage_percent", 99, core0))
+
+		series := store.RangeAll("cpu_core_usage_percent", now.Add(-time.Minute), now)
+		if len(series) != 2 {
+			t.Fatalf("Expected 2 series, got %d", len(series))
+		}
+		if len(series[0].Points) != 1 || series[0].Points[0].Value != 10 {
+			t.Errorf("Expected duplicate timestamp to be ignored, got %+v", series[0].Points)
+		}
+	})
+
+	t.Run("Max Series", func(t *testing.T) {
+		store := NewStore(Config{MaxSeries: 1})
+		now := time.Now()
+		store.Add(now, collector.GaugePoint("a", 1, nil), collector.GaugePoint("b", 1, nil))
+
+		if store.SeriesCount() != 1 || store.Dropped() != 1 {
+			t.Errorf("Expected 1 series and 1 dropped, got %d and %d", store.SeriesCount(), store.Dropped())
+		}
+	})
+
+	t.Run("Stale Series Make Room", func(t *testing.T) {
+		store := NewStore(Config{MaxSeries: 3, Tiers: []Tier{{Name: "raw", Retention: 60}}})
+		start := time.Now().Add(-10 * time.Minute)
+		for pid := 1; pid <= 3; pid++ {
+			store.Add(start, collector.GaugePoint("process_cpu", 1, map[string]string{"pid": strconv.Itoa(pid)}))
+		}
+
+		// The processes have exited and their points are past the retention.
+		store.Add(start.Add(5*time.Minute), collector.GaugePoint("load1", 1, nil))
+		if store.SeriesCount() != 1 || store.Dropped() != 0 {
+			t.Fatalf("Expected the stale series to be expired, got %d series and %d dropped", store.SeriesCount(), store.Dropped())
+		}
+		if series := store.RangeAll("process_cpu", start, time.Now()); len(series) != 0 {
+			t.Errorf("Expected no process series, got %+v", series)
+		}
+
+		// Without stale series the least recently updated one is evicted.
+		now := start.Add(6 * time.Minute)
+		store.Add(now, collector.GaugePoint("load5", 1, nil), collector.GaugePoint("load15", 1, nil))
+		store.Add(now.Add(time.Second), collector.GaugePoint("mem", 1, nil))
+		if _, ok := store.Latest("load1", nil); ok || store.SeriesCount() != 3 {
+			t.Errorf("Expected load1 to be evicted, got %v", store.Names())
+		}
+	})
+
+	t.Run("Unknown Tier", func(t *testing.T) {
+		store := NewStore(DefaultConfig)
+		if _, err := store.RangeTier("5m", "load1", nil, time.Now(), time.Now()); err == nil {
+			t.Error("Expected error for unknown tier")
+		}
+		if _, err := store.RangeAllTier("5m", "load1", time.Now(), time.Now()); err == nil {
+			t.Error("Expected error for unknown tier")
+		}
+		if _, err := store.RangeAllTier("raw", "load1", time.Now(), time.Now()); err != nil {
+			t.Errorf("Expected no error for known tier, got %v", err)
+		}
+	})
+
+	t.Run("Nil Store", func(t *testing.T) {
+		var store *Store
+		store.Add(time.Now(), collector.GaugePoint("load1", 1, nil))
+		if store.Range("load1", nil, time.Now().Add(-time.Minute), time.Now()) != nil {
+			t.Error("Expected nil store to return no points")
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]Point{
+		{Value: 2, Min: 1, Max: 3},
+		{Value: 4, Min: 4, Max: 4},
+	})
+
+	if summary.Min != 1 || summary.Max != 4 || summary.Avg != 3 || summary.Last != 4 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
@@ -21,8 +21,36 @@ const (
 	DiskIOUpdate       MetricType = "disk_io_update"
 	ProcessTreeUpdate  MetricType = "process_tree_update"
 	BatteryUpdate      MetricType = "battery_update"
+	CgroupsUpdate      MetricType = "cgroups_update"
+	PressureUpdate     MetricType = "pressure_update"
 )
 
+var collectorMetricTypes = map[string]MetricType{
+	"cpu":                 CPUUpdate,
+	"memory":              MemoryUpdate,
+	"disk":                DiskUpdate,
+	"network":             NetworkUpdate,
+	"process":             ProcessUpdate,
+	"gpu":                 GPUUpdate,
+	"load":                LoadUpdate,
+	"temperature":         TemperatureUpdate,
+	"network_connections": NetworkConnsUpdate,
+	"disk_io":             DiskIOUpdate,
+	"process_tree":        ProcessTreeUpdate,
+	"battery":             BatteryUpdate,
+	"cgroups":             CgroupsUpdate,
+	"pressure":            PressureUpdate,
+}
+
+// ForCollector maps a collector name to the metric type its update timings
+// are recorded under.
+func ForCollector(name string) MetricType {
+	if metricType, exists := collectorMetricTypes[name]; exists {
+		return metricType
+	}
+	return MetricType(name + "_update")
+}
+
 type Metrics struct {
 	mu              sync.RWMutex
 	updateDurations map[MetricType][]time.Duration
@@ -73,4 +73,13 @@ func TestMetrics(t *testing.T) {
 			t.Error("Expected non-empty stats string")
 		}
 	})
+
+	t.Run("ForCollector", func(t *testing.T) {
+		if got := ForCollector("network_connections"); got != NetworkConnsUpdate {
+			t.Errorf("Expected %s, got %s", NetworkConnsUpdate, got)
+		}
+		if got := ForCollector("docker"); got != MetricType("docker_update") {
+			t.Errorf("Expected docker_update, got %s", got)
+		}
+	})
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type PluginSystemConfig struct {
@@ -17,6 +18,8 @@ type PluginSettings struct {
 	UpdateInterval      int    `json:"update_interval"`
 	EnableNotifications bool   `json:"enable_notifications"`
 	PluginDirectory     string `json:"plugin_directory"`
+	CallTimeout         int    `json:"call_timeout"`
+	MaxFailures         int    `json:"max_failures"`
 }
 
 func LoadPluginConfig(configPath string) (*PluginSystemConfig, error) {
@@ -32,6 +35,8 @@ func LoadPluginConfig(configPath string) (*PluginSystemConfig, error) {
 				UpdateInterval:      2,
 				EnableNotifications: false,
 				PluginDirectory:     "./plugins",
+				CallTimeout:         int(DefaultCallTimeout / time.Second),
+				MaxFailures:         DefaultMaxFailures,
 			},
 		}, nil
 	}
@@ -52,6 +57,13 @@ func LoadPluginConfig(configPath string) (*PluginSystemConfig, error) {
 	if config.PluginSettings.PluginDirectory == "" {
 		config.PluginSettings.PluginDirectory = "./plugins"
 	}
+	if config.PluginSettings.CallTimeout <= 0 {
+		config.PluginSettings.CallTimeout = int(DefaultCallTimeout / time.Second)
+	}
+	// A negative max_failures keeps failing plugins enabled.
+	if config.PluginSettings.MaxFailures == 0 {
+		config.PluginSettings.MaxFailures = DefaultMaxFailures
+	}
 
 	for pluginName, pluginConfig := range config.Plugins {
 		if pluginConfig.Layout.UpdateInterval == 0 {
@@ -102,3 +114,30 @@ func GetPluginConfigFromFile(config *PluginSystemConfig, pluginName string) (Plu
 	pluginConfig, exists := config.Plugins[pluginName]
 	return pluginConfig, exists
 }
+
+// boolSetting, intSetting and stringSetting read a plugin setting, falling
+// back when it is missing or has the wrong type. Numbers decoded from JSON
+// are float64.
+func boolSetting(config PluginConfig, key string, fallback bool) bool {
+	if value, ok := config.Settings[key].(bool); ok {
+		return value
+	}
+	return fallback
+}
+
+func intSetting(config PluginConfig, key string, fallback int) int {
+	switch value := config.Settings[key].(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return fallback
+}
+
+func stringSetting(config PluginConfig, key string, fallback string) string {
+	if value, ok := config.Settings[key].(string); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,23 @@
+package plugins
+
+import "time"
+
+// confirmWindow is how long a second press confirms an action.
+const confirmWindow = 3 * time.Second
+
+// pendingAction is an action on a target, such as stopping a container,
+// waiting for its confirming press.
+type pendingAction struct {
+	action   string
+	target   string
+	deadline time.Time
+}
+
+func newPendingAction(action, target string, now time.Time) pendingAction {
+	return pendingAction{action: action, target: target, deadline: now.Add(confirmWindow)}
+}
+
+// confirms reports whether asking for action on target at now confirms p.
+func (p pendingAction) confirms(action, target string, now time.Time) bool {
+	return p.action != "" && p.action == action && p.target == target && now.Before(p.deadline)
+}
@@ -1,50 +1,95 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"sort"
 	"strings"
+	"sync"
 	"syspulse/internal/utils"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+const dockerKeys = "[gray]o start | x stop | r restart | l logs[white]"
+
 type DockerPlugin struct {
-	config     PluginConfig
+	redrawer
+
+	config PluginConfig
+	client *dockerClient
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
 	containers []DockerContainer
 	images     []DockerImage
 	stats      DockerStats
+	usage      map[string]ContainerStats
+	streams    map[string]*dockerStream
+	logsFor    string
+	logs       []string
+	status     string
+	pending    pendingAction
 }
 
 type DockerContainer struct {
-	ID      string
-	Name    string
-	Image   string
-	Status  string
-	Ports   string
-	Created string
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Image   string          `json:"image"`
+	State   string          `json:"state"`
+	Status  string          `json:"status"`
+	Ports   string          `json:"ports"`
+	Created time.Time       `json:"created"`
+	Stats   *ContainerStats `json:"stats,omitempty"`
 }
 
 type DockerImage struct {
-	ID      string
-	Repo    string
-	Tag     string
-	Size    string
-	Created string
+	ID      string    `json:"id"`
+	Repo    string    `json:"repository"`
+	Tag     string    `json:"tag"`
+	Size    uint64    `json:"size"`
+	Created time.Time `json:"created"`
 }
 
 type DockerStats struct {
-	ContainersRunning int
-	ContainersStopped int
-	Images            int
-	SystemDFUsage     string
+	ContainersRunning int `json:"containers_running"`
+	ContainersStopped int `json:"containers_stopped"`
+	Images            int `json:"images"`
+}
+
+type dockerStream struct {
+	cancel context.CancelFunc
+}
+
+// dockerWidget is a summary over the container table. The table shows the
+// logs of a container instead while they are open, so it keeps the focus.
+type dockerWidget struct {
+	*tview.Flex
+	summary *tview.TextView
+	table   *tview.Table
+	ids     []string
+}
+
+func init() {
+	Register("docker", func() Plugin { return NewDockerPlugin() },
+		SettingSchema{Key: "host", Type: SettingString, Default: "", Description: "Docker Engine address like unix:///var/run/docker.sock or tcp://host:2375; empty uses DOCKER_HOST or the default socket"},
+		SettingSchema{Key: "all_containers", Type: SettingBool, Default: true, Description: "List stopped containers too"},
+		SettingSchema{Key: "show_stats", Type: SettingBool, Default: true, Description: "Show container and image counts"},
+		SettingSchema{Key: "show_images", Type: SettingBool, Default: true, Description: "List images"},
+		SettingSchema{Key: "image_limit", Type: SettingInt, Default: 5, Description: "Images listed at most"},
+		SettingSchema{Key: "logs_tail", Type: SettingInt, Default: 100, Description: "Log lines shown for a container"},
+	)
 }
 
 func NewDockerPlugin() *DockerPlugin {
 	return &DockerPlugin{
 		containers: make([]DockerContainer, 0),
 		images:     make([]DockerImage, 0),
+		usage:      make(map[string]ContainerStats),
+		streams:    make(map[string]*dockerStream),
 	}
 }
 
@@ -53,11 +98,11 @@ func (p *DockerPlugin) Name() string {
 }
 
 func (p *DockerPlugin) Version() string {
-	return "1.0.0"
+	return "2.0.0"
 }
 
 func (p *DockerPlugin) Description() string {
-	return "Monitor Docker containers, images, and system statistics"
+	return "Monitor and control Docker containers with live CPU, memory, network and block I/O"
 }
 
 func (p *DockerPlugin) Author() string {
@@ -65,56 +110,87 @@ func (p *DockerPlugin) Author() string {
 }
 
 func (p *DockerPlugin) Initialize(config PluginConfig) error {
-	p.config = config
-
-	if !p.isDockerAvailable() {
-		return fmt.Errorf("Docker is not available or not running")
+	client, err := newDockerClient(stringSetting(config, "host", ""))
+	if err != nil {
+		return err
 	}
+	if err := client.Ping(context.Background()); err != nil {
+		return fmt.Errorf("Docker is not available: %w", err)
+	}
+
+	p.Shutdown()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
+	p.config = config
+	p.client = client
+	p.ctx, p.cancel = context.WithCancel(context.Background())
 	return nil
 }
 
+// Shutdown stops the stats streams.
 func (p *DockerPlugin) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.streams = make(map[string]*dockerStream)
+	p.usage = make(map[string]ContainerStats)
 	return nil
 }
 
 func (p *DockerPlugin) CreateWidget() (tview.Primitive, error) {
-	textView := tview.NewTextView()
-	utils.SetBorderStyle(textView.Box)
-	textView.SetTitle(p.config.Layout.Title)
-	textView.SetDynamicColors(true)
-	textView.SetScrollable(true)
+	w := &dockerWidget{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		summary: tview.NewTextView().SetDynamicColors(true),
+		table:   tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+	}
+	w.AddItem(w.summary, 3, 0, false).
+		AddItem(w.table, 0, 1, true)
 
+	utils.SetBorderStyle(w.Box)
+	w.SetTitle(p.config.Layout.Title)
 	if p.config.Layout.BorderColor != "" {
-		textView.SetBorderColor(utils.GetColorFromName(p.config.Layout.BorderColor))
+		w.SetBorderColor(utils.GetColorFromName(p.config.Layout.BorderColor))
 	}
-
 	if p.config.Layout.ForegroundColor != "" {
-		textView.SetTitleColor(utils.GetColorFromName(p.config.Layout.ForegroundColor))
+		w.SetTitleColor(utils.GetColorFromName(p.config.Layout.ForegroundColor))
 	}
 
-	if err := p.UpdateWidget(textView); err != nil {
+	w.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return p.handleKey(w, event)
+	})
+
+	if err := p.refresh(); err != nil {
 		return nil, err
 	}
+	p.render(w)
 
-	return textView, nil
+	return w, nil
 }
 
 func (p *DockerPlugin) UpdateWidget(widget tview.Primitive) error {
-	textView, ok := widget.(*tview.TextView)
+	w, ok := widget.(*dockerWidget)
 	if !ok {
-		return fmt.Errorf("widget is not a TextView")
+		return fmt.Errorf("widget is not a Docker widget")
 	}
 
-	if err := p.updateDockerData(); err != nil {
-		textView.SetText(fmt.Sprintf("[red]Error updating Docker data: %v[white]", err))
-		return err
+	err := p.refresh()
+	if err == nil {
+		err = p.refreshLogs()
 	}
 
-	content := p.buildDisplayContent()
-	textView.SetText(content)
-
-	return nil
+	p.queueDraw(func() {
+		if err != nil {
+			w.summary.SetText(fmt.Sprintf("[red]Error updating Docker data: %s[white]", tview.Escape(err.Error())))
+			return
+		}
+		p.render(w)
+	})
+	return err
 }
 
 func (p *DockerPlugin) GetWidgetConfig() WidgetConfig {
@@ -122,11 +198,15 @@ func (p *DockerPlugin) GetWidgetConfig() WidgetConfig {
 }
 
 func (p *DockerPlugin) CollectData() (map[string]interface{}, error) {
-	if err := p.updateDockerData(); err != nil {
+	if err := p.refresh(); err != nil {
 		return nil, err
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	data := map[string]interface{}{
+		"host":         p.client.host,
 		"containers":   p.containers,
 		"images":       p.images,
 		"stats":        p.stats,
@@ -145,161 +225,366 @@ func (p *DockerPlugin) UpdateInterval() time.Duration {
 	return 5 * time.Second
 }
 
-func (p *DockerPlugin) isDockerAvailable() bool {
-	cmd := exec.Command("docker", "version", "--format", "{{.Client.Version}}")
-	return cmd.Run() == nil
-}
+// refresh lists containers and images and keeps a stats stream open for
+// every running container.
+func (p *DockerPlugin) refresh() error {
+	p.mu.Lock()
+	client, ctx := p.client, p.ctx
+	all := boolSetting(p.config, "all_containers", true)
+	needImages := boolSetting(p.config, "show_images", true) || boolSetting(p.config, "show_stats", true)
+	p.mu.Unlock()
+
+	if client == nil {
+		return fmt.Errorf("Docker plugin is not initialized")
+	}
 
-func (p *DockerPlugin) updateDockerData() error {
-	if err := p.updateContainers(); err != nil {
+	listed, err := client.Containers(ctx, all)
+	if err != nil {
 		return err
 	}
+	var images []apiImage
+	if needImages {
+		if images, err = client.Images(ctx); err != nil {
+			return err
+		}
+	}
 
-	if err := p.updateImages(); err != nil {
-		return err
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	running := make(map[string]bool)
+	p.containers = make([]DockerContainer, 0, len(listed))
+	p.stats = DockerStats{Images: len(images)}
+	for _, c := range listed {
+		container := DockerContainer{
+			ID:      c.ID,
+			Name:    strings.TrimPrefix(firstOr(c.Names, c.ID), "/"),
+			Image:   c.Image,
+			State:   c.State,
+			Status:  c.Status,
+			Created: time.Unix(c.Created, 0),
+		}
+
+		var ports []string
+		for _, port := range c.Ports {
+			if port.PublicPort != 0 {
+				ports = append(ports, fmt.Sprintf("%d->%d/%s", port.PublicPort, port.PrivatePort, port.Type))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.PrivatePort, port.Type))
+			}
+		}
+		container.Ports = strings.Join(ports, ", ")
+
+		if c.State == "running" {
+			running[c.ID] = true
+			p.stats.ContainersRunning++
+			if usage, exists := p.usage[c.ID]; exists {
+				container.Stats = &usage
+			}
+		} else {
+			p.stats.ContainersStopped++
+		}
+		p.containers = append(p.containers, container)
 	}
 
-	if err := p.updateStats(); err != nil {
-		return err
+	p.images = make([]DockerImage, 0, len(images))
+	sort.Slice(images, func(i, j int) bool { return images[i].Created > images[j].Created })
+	for _, image := range images {
+		repo, tag := "<none>", "<none>"
+		if len(image.RepoTags) > 0 {
+			if i := strings.LastIndex(image.RepoTags[0], ":"); i > 0 {
+				repo, tag = image.RepoTags[0][:i], image.RepoTags[0][i+1:]
+			}
+		}
+		p.images = append(p.images, DockerImage{
+			ID:      shortDockerID(strings.TrimPrefix(image.ID, "sha256:")),
+			Repo:    repo,
+			Tag:     tag,
+			Size:    uint64(max(image.Size, 0)),
+			Created: time.Unix(image.Created, 0),
+		})
+	}
+
+	for id := range running {
+		if _, streaming := p.streams[id]; !streaming {
+			streamCtx, cancel := context.WithCancel(ctx)
+			stream := &dockerStream{cancel: cancel}
+			p.streams[id] = stream
+			go p.streamStats(streamCtx, client, id, stream)
+		}
+	}
+	for id, stream := range p.streams {
+		if !running[id] {
+			stream.cancel()
+			delete(p.streams, id)
+			delete(p.usage, id)
+		}
 	}
 
 	return nil
 }
 
-func (p *DockerPlugin) updateContainers() error {
-	cmd := exec.Command("docker", "ps", "-a", "--format", "table {{.ID}}\t{{.Names}}\t{{.Image}}\t{{.Status}}\t{{.Ports}}\t{{.CreatedAt}}")
-	output, err := cmd.Output()
+func (p *DockerPlugin) streamStats(ctx context.Context, client *dockerClient, id string, stream *dockerStream) {
+	client.StreamStats(ctx, id, func(raw apiStats) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.streams[id] == stream {
+			p.usage[id] = nextContainerStats(p.usage[id], raw)
+		}
+	})
+
+	// The next refresh opens a new stream if the container still runs.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.streams[id] == stream {
+		delete(p.streams, id)
+	}
+}
+
+func (p *DockerPlugin) refreshLogs() error {
+	p.mu.Lock()
+	client, ctx, id := p.client, p.ctx, p.logsFor
+	tail := intSetting(p.config, "logs_tail", 100)
+	p.mu.Unlock()
+
+	if id == "" {
+		return nil
+	}
+
+	lines, err := client.Logs(ctx, id, tail)
 	if err != nil {
 		return err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	p.containers = make([]DockerContainer, 0)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.logsFor == id {
+		p.logs = lines
+	}
+	return nil
+}
 
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == "" {
-			continue
-		}
+// render draws the plugin's state into its widget. It runs on the UI
+// goroutine and keeps the selected container selected.
+func (p *DockerPlugin) render(w *dockerWidget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-		parts := strings.Split(lines[i], "\t")
-		if len(parts) >= 6 {
-			container := DockerContainer{
-				ID:      parts[0],
-				Name:    parts[1],
-				Image:   parts[2],
-				Status:  parts[3],
-				Ports:   parts[4],
-				Created: parts[5],
-			}
-			p.containers = append(p.containers, container)
+	var summary strings.Builder
+	if boolSetting(p.config, "show_stats", true) {
+		fmt.Fprintf(&summary, "Running: [green]%d[white]  Stopped: [red]%d[white]  Images: [blue]%d[white]\n",
+			p.stats.ContainersRunning, p.stats.ContainersStopped, p.stats.Images)
+	}
+	if limit := intSetting(p.config, "image_limit", 5); boolSetting(p.config, "show_images", true) && limit > 0 && len(p.images) > 0 {
+		var images []string
+		for _, image := range p.images[:min(limit, len(p.images))] {
+			images = append(images, fmt.Sprintf("[blue]%s:%s[white] (%s)", tview.Escape(image.Repo), tview.Escape(image.Tag), formatBytes(image.Size)))
+		}
+		summary.WriteString(strings.Join(images, ", ") + "\n")
+	}
+	if p.status != "" {
+		summary.WriteString(p.status)
+	} else {
+		summary.WriteString(dockerKeys)
+	}
+	w.summary.SetText(summary.String())
+
+	if p.logsFor != "" {
+		w.table.Clear().SetSelectable(false, false)
+		w.table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("Logs of %s (l or ESC to close)", tview.Escape(p.containerName(p.logsFor)))).
+			SetTextColor(tcell.ColorYellow))
+		for i, line := range p.logs {
+			w.table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(line)))
 		}
+		return
 	}
 
-	return nil
-}
+	selected := ""
+	if row, _ := w.table.GetSelection(); row > 0 && row <= len(w.ids) {
+		selected = w.ids[row-1]
+	}
 
-func (p *DockerPlugin) updateImages() error {
-	cmd := exec.Command("docker", "images", "--format", "table {{.ID}}\t{{.Repository}}\t{{.Tag}}\t{{.Size}}\t{{.CreatedAt}}")
-	output, err := cmd.Output()
-	if err != nil {
-		return err
+	w.table.Clear().SetSelectable(true, false)
+	for column, header := range []string{"NAME", "STATE", "CPU%", "MEM", "NET RX/TX", "BLOCK R/W", "IMAGE"} {
+		w.table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
 	}
 
-	lines := strings.Split(string(output), "\n")
-	p.images = make([]DockerImage, 0)
+	w.ids = w.ids[:0]
+	selectRow := 1
+	for i, container := range p.containers {
+		row := i + 1
+		w.ids = append(w.ids, container.ID)
+		if container.ID == selected {
+			selectRow = row
+		}
 
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == "" {
-			continue
+		stateColor := tcell.ColorRed
+		if container.State == "running" {
+			stateColor = tcell.ColorGreen
 		}
 
-		parts := strings.Split(lines[i], "\t")
-		if len(parts) >= 5 {
-			image := DockerImage{
-				ID:      parts[0],
-				Repo:    parts[1],
-				Tag:     parts[2],
-				Size:    parts[3],
-				Created: parts[4],
-			}
-			p.images = append(p.images, image)
+		cpu, mem, network, block := "-", "-", "-", "-"
+		if stats := container.Stats; stats != nil {
+			cpu = fmt.Sprintf("%.1f", stats.CPUPercent)
+			mem = fmt.Sprintf("%s (%.0f%%)", formatBytes(stats.MemoryUsage), stats.MemoryPercent)
+			network = formatRate(stats.NetRxRate) + " / " + formatRate(stats.NetTxRate)
+			block = formatRate(stats.BlockReadRate) + " / " + formatRate(stats.BlockWriteRate)
 		}
+
+		w.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(container.Name)).SetExpansion(1))
+		w.table.SetCell(row, 1, tview.NewTableCell(container.State).SetTextColor(stateColor))
+		w.table.SetCell(row, 2, tview.NewTableCell(cpu).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 3, tview.NewTableCell(mem).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 4, tview.NewTableCell(network).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 5, tview.NewTableCell(block).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 6, tview.NewTableCell(tview.Escape(container.Image)).SetExpansion(1))
+	}
+	if len(p.containers) > 0 {
+		w.table.Select(selectRow, 0)
 	}
+}
 
-	return nil
+func (p *DockerPlugin) containerName(id string) string {
+	for _, container := range p.containers {
+		if container.ID == id {
+			return container.Name
+		}
+	}
+	return shortDockerID(id)
 }
 
-func (p *DockerPlugin) updateStats() error {
-	runningCount := 0
-	stoppedCount := 0
+func (p *DockerPlugin) handleKey(w *dockerWidget, event *tcell.EventKey) *tcell.EventKey {
+	p.mu.Lock()
+	showingLogs := p.logsFor != ""
+	p.mu.Unlock()
 
-	for _, container := range p.containers {
-		if strings.Contains(container.Status, "Up") {
-			runningCount++
-		} else {
-			stoppedCount++
+	if showingLogs {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'l' || event.Rune() == 'L' {
+			p.mu.Lock()
+			p.logsFor, p.logs = "", nil
+			p.mu.Unlock()
+
+			w.table.ScrollToBeginning()
+			p.render(w)
+			return nil
 		}
+		return event
 	}
 
-	cmd := exec.Command("docker", "system", "df", "--format", "table {{.Type}}\t{{.Total}}\t{{.Active}}\t{{.Size}}")
-	output, err := cmd.Output()
-	dfUsage := "N/A"
-	if err == nil {
-		dfUsage = string(output)
+	row, _ := w.table.GetSelection()
+	if row < 1 || row > len(w.ids) {
+		return event
 	}
+	id := w.ids[row-1]
 
-	p.stats = DockerStats{
-		ContainersRunning: runningCount,
-		ContainersStopped: stoppedCount,
-		Images:            len(p.images),
-		SystemDFUsage:     dfUsage,
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.setStatus(w, "")
+		return nil
 	}
 
-	return nil
+	switch event.Rune() {
+	case 'o', 'O':
+		p.runAction(w, id, "start")
+		return nil
+	case 'x', 'X':
+		p.confirmAction(w, id, "stop")
+		return nil
+	case 'r', 'R':
+		p.confirmAction(w, id, "restart")
+		return nil
+	case 'l', 'L':
+		p.mu.Lock()
+		p.logsFor, p.logs = id, []string{"Loading..."}
+		p.mu.Unlock()
+
+		p.render(w)
+		go func() {
+			err := p.refreshLogs()
+			p.queueDraw(func() {
+				if err != nil {
+					p.mu.Lock()
+					p.logs = []string{"Failed to read logs: " + err.Error()}
+					p.mu.Unlock()
+				}
+				p.render(w)
+				w.table.ScrollToEnd()
+			})
+		}()
+		return nil
+	}
+
+	return event
 }
 
-func (p *DockerPlugin) buildDisplayContent() string {
-	content := "[green]Docker Monitor[white]\n\n"
+// confirmAction asks for a second press before stopping or restarting.
+func (p *DockerPlugin) confirmAction(w *dockerWidget, id, action string) {
+	p.mu.Lock()
+	pending := p.pending
+	name := p.containerName(id)
+	p.mu.Unlock()
+
+	if pending.confirms(action, id, time.Now()) {
+		p.runAction(w, id, action)
+		return
+	}
 
-	content += "[yellow]Statistics:[white]\n"
-	content += fmt.Sprintf("Running Containers: [green]%d[white]\n", p.stats.ContainersRunning)
-	content += fmt.Sprintf("Stopped Containers: [red]%d[white]\n", p.stats.ContainersStopped)
-	content += fmt.Sprintf("Images: [blue]%d[white]\n\n", p.stats.Images)
+	p.mu.Lock()
+	p.pending = newPendingAction(action, id, time.Now())
+	p.mu.Unlock()
 
-	content += "[yellow]Recent Containers:[white]\n"
-	containerCount := len(p.containers)
-	if containerCount > 5 {
-		containerCount = 5
+	key := "x"
+	if action == "restart" {
+		key = "r"
 	}
+	p.setStatus(w, fmt.Sprintf("[yellow]Press %s again to %s %s[white]", key, action, tview.Escape(name)))
+}
 
-	for i := 0; i < containerCount; i++ {
-		container := p.containers[i]
-		statusColor := "[red]"
-		if strings.Contains(container.Status, "Up") {
-			statusColor = "[green]"
+// runAction starts, stops or restarts a container in the background.
+func (p *DockerPlugin) runAction(w *dockerWidget, id, action string) {
+	p.mu.Lock()
+	client, ctx := p.client, p.ctx
+	name := p.containerName(id)
+	p.pending = pendingAction{}
+	p.mu.Unlock()
+
+	p.setStatus(w, fmt.Sprintf("Running %s on %s...", action, tview.Escape(name)))
+	go func() {
+		err := client.Action(ctx, id, action)
+		status := fmt.Sprintf("[green]%s: %s done[white]", tview.Escape(name), action)
+		if err != nil {
+			status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+		} else if err := p.refresh(); err != nil {
+			status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
 		}
 
-		content += fmt.Sprintf("• %s%s[white] (%s)\n", statusColor, container.Name, container.Image)
-	}
+		p.queueDraw(func() {
+			p.setStatus(w, status)
+		})
+	}()
+}
 
-	if len(p.containers) > 5 {
-		content += fmt.Sprintf("... and %d more\n", len(p.containers)-5)
-	}
+func (p *DockerPlugin) setStatus(w *dockerWidget, status string) {
+	p.mu.Lock()
+	p.status = status
+	p.mu.Unlock()
 
-	content += "\n[yellow]Recent Images:[white]\n"
-	imageCount := len(p.images)
-	if imageCount > 5 {
-		imageCount = 5
-	}
+	p.render(w)
+}
 
-	for i := 0; i < imageCount; i++ {
-		image := p.images[i]
-		content += fmt.Sprintf("• [blue]%s:%s[white] (%s)\n", image.Repo, image.Tag, image.Size)
+func firstOr(values []string, fallback string) string {
+	if len(values) > 0 {
+		return values[0]
 	}
+	return fallback
+}
 
-	if len(p.images) > 5 {
-		content += fmt.Sprintf("... and %d more\n", len(p.images)-5)
+func shortDockerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
 	}
-
-	return content
+	return id
 }
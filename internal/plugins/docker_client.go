@@ -0,0 +1,367 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDockerHost = "unix:///var/run/docker.sock"
+
+	dockerRequestTimeout = 5 * time.Second
+	// dockerStopTimeout is the grace period before Docker kills a container
+	// that is stopped or restarted.
+	dockerStopTimeout = 10
+)
+
+// dockerClient speaks to the Docker Engine API over a unix socket or TCP.
+// Paths are unversioned, so the daemon answers with its own API version.
+type dockerClient struct {
+	host string
+	base string
+	http *http.Client
+}
+
+// newDockerClient connects to host, or to $DOCKER_HOST and then the default
+// socket when host is empty.
+func newDockerClient(host string) (*dockerClient, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = DefaultDockerHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+
+	transport := &http.Transport{}
+	client := &dockerClient{host: host, http: &http.Client{Transport: transport}}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		client.base = "http://docker"
+	case "tcp", "http":
+		client.base = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker host %q: use unix:// or tcp://", host)
+	}
+	return client, nil
+}
+
+// dockerAPIError is the error body the Engine API sends with 4xx and 5xx.
+type dockerAPIError struct {
+	Message string `json:"message"`
+}
+
+func (c *dockerClient) do(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	target := c.base + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("docker did not answer %s %s in time", method, path)
+		}
+		return nil, fmt.Errorf("cannot reach docker at %s: %w", c.host, err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		var apiErr dockerAPIError
+		json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return nil, fmt.Errorf("docker %s %s: %s", method, path, apiErr.Message)
+	}
+	return resp, nil
+}
+
+func (c *dockerClient) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, dockerRequestTimeout)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid docker response to %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *dockerClient) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, dockerRequestTimeout)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodGet, "/_ping", nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+type apiContainer struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"`
+	Image   string   `json:"Image"`
+	State   string   `json:"State"`
+	Status  string   `json:"Status"`
+	Created int64    `json:"Created"`
+	Ports   []struct {
+		IP          string `json:"IP"`
+		PrivatePort uint16 `json:"PrivatePort"`
+		PublicPort  uint16 `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+}
+
+func (c *dockerClient) Containers(ctx context.Context, all bool) ([]apiContainer, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "1")
+	}
+
+	var containers []apiContainer
+	err := c.getJSON(ctx, "/containers/json", query, &containers)
+	return containers, err
+}
+
+type apiImage struct {
+	ID       string   `json:"Id"`
+	RepoTags []string `json:"RepoTags"`
+	Size     int64    `json:"Size"`
+	Created  int64    `json:"Created"`
+}
+
+func (c *dockerClient) Images(ctx context.Context) ([]apiImage, error) {
+	var images []apiImage
+	err := c.getJSON(ctx, "/images/json", nil, &images)
+	return images, err
+}
+
+// Action starts, stops or restarts a container. Stopping a stopped container
+// or starting a running one is not an error.
+func (c *dockerClient) Action(ctx context.Context, id, action string) error {
+	query := url.Values{}
+	switch action {
+	case "start":
+	case "stop", "restart":
+		query.Set("t", fmt.Sprint(dockerStopTimeout))
+	default:
+		return fmt.Errorf("unknown container action %q", action)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dockerRequestTimeout+dockerStopTimeout*time.Second)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/"+action, query)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Logs returns the last tail lines a container wrote to stdout and stderr.
+func (c *dockerClient) Logs(ctx context.Context, id string, tail int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerRequestTimeout)
+	defer cancel()
+
+	query := url.Values{"stdout": {"1"}, "stderr": {"1"}, "tail": {fmt.Sprint(tail)}}
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/logs", query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	return splitDockerLogs(body), nil
+}
+
+// splitDockerLogs splits a log response into lines. Containers without a TTY
+// send stdout and stderr multiplexed in frames with an 8 byte header: the
+// stream (1 or 2), three zero bytes and the big-endian payload size.
+func splitDockerLogs(body []byte) []string {
+	var text bytes.Buffer
+	rest := body
+	for len(rest) >= 8 && rest[0] <= 2 && rest[1] == 0 && rest[2] == 0 && rest[3] == 0 {
+		size := int(binary.BigEndian.Uint32(rest[4:8]))
+		if len(rest) < 8+size {
+			break
+		}
+		text.Write(rest[8 : 8+size])
+		rest = rest[8+size:]
+	}
+	text.Write(rest)
+
+	var lines []string
+	scanner := bufio.NewScanner(&text)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines
+}
+
+type apiCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+type apiStats struct {
+	Read        time.Time   `json:"read"`
+	CPUStats    apiCPUStats `json:"cpu_stats"`
+	PreCPUStats apiCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+}
+
+// StreamStats calls fn with every stats sample Docker streams for a container,
+// about one a second, until ctx is cancelled or the container stops.
+func (c *dockerClient) StreamStats(ctx context.Context, id string, fn func(apiStats)) error {
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/stats", url.Values{"stream": {"1"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var stats apiStats
+		if err := decoder.Decode(&stats); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fn(stats)
+	}
+}
+
+// ContainerStats is the resource usage of a running container. Rates are per
+// second, between the last two samples.
+type ContainerStats struct {
+	CPUPercent     float64   `json:"cpu_percent"`
+	MemoryUsage    uint64    `json:"memory_usage"`
+	MemoryLimit    uint64    `json:"memory_limit"`
+	MemoryPercent  float64   `json:"memory_percent"`
+	NetRx          uint64    `json:"net_rx_bytes"`
+	NetTx          uint64    `json:"net_tx_bytes"`
+	NetRxRate      float64   `json:"net_rx_rate"`
+	NetTxRate      float64   `json:"net_tx_rate"`
+	BlockRead      uint64    `json:"block_read_bytes"`
+	BlockWrite     uint64    `json:"block_write_bytes"`
+	BlockReadRate  float64   `json:"block_read_rate"`
+	BlockWriteRate float64   `json:"block_write_rate"`
+	Updated        time.Time `json:"updated"`
+}
+
+// nextContainerStats computes the usage from a sample, with rates against the
+// previous one. CPU is relative to one core, like `docker stats`, and memory
+// excludes the page cache.
+func nextContainerStats(prev ContainerStats, raw apiStats) ContainerStats {
+	next := ContainerStats{Updated: raw.Read}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	cpus := float64(raw.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		next.CPUPercent = cpuDelta / systemDelta * cpus * 100
+	}
+
+	// cgroup v2 reports the cache as inactive_file, v1 as total_inactive_file
+	// or cache.
+	cache := raw.MemoryStats.Stats["inactive_file"]
+	if cache == 0 {
+		cache = raw.MemoryStats.Stats["total_inactive_file"]
+	}
+	if cache == 0 {
+		cache = raw.MemoryStats.Stats["cache"]
+	}
+	next.MemoryUsage = raw.MemoryStats.Usage
+	if cache < next.MemoryUsage {
+		next.MemoryUsage -= cache
+	}
+	next.MemoryLimit = raw.MemoryStats.Limit
+	if next.MemoryLimit > 0 {
+		next.MemoryPercent = float64(next.MemoryUsage) / float64(next.MemoryLimit) * 100
+	}
+
+	for _, network := range raw.Networks {
+		next.NetRx += network.RxBytes
+		next.NetTx += network.TxBytes
+	}
+	for _, entry := range raw.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			next.BlockRead += entry.Value
+		case "write":
+			next.BlockWrite += entry.Value
+		}
+	}
+
+	if elapsed := raw.Read.Sub(prev.Updated).Seconds(); !prev.Updated.IsZero() && elapsed > 0 {
+		rate := func(now, before uint64) float64 {
+			if now < before {
+				return 0
+			}
+			return float64(now-before) / elapsed
+		}
+		next.NetRxRate = rate(next.NetRx, prev.NetRx)
+		next.NetTxRate = rate(next.NetTx, prev.NetTx)
+		next.BlockReadRate = rate(next.BlockRead, prev.BlockRead)
+		next.BlockWriteRate = rate(next.BlockWrite, prev.BlockWrite)
+	}
+	return next
+}
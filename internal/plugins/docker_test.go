@@ -0,0 +1,345 @@
+package plugins
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// fakeEngine answers the Docker Engine API calls the plugin makes on a unix
+// socket. web runs and streams stats, db is stopped.
+type fakeEngine struct {
+	mu      sync.Mutex
+	actions []string
+	running map[string]bool
+}
+
+func startFakeEngine(t *testing.T) (*fakeEngine, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake engine listens on a unix socket")
+	}
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	engine := &fakeEngine{running: map[string]bool{"web0123456789abcdef": true}}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(engine.serve))
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return engine, "unix://" + socket
+}
+
+func (e *fakeEngine) serve(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	write := func(v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.URL.Path == "/_ping":
+		w.Write([]byte("OK"))
+	case r.URL.Path == "/containers/json":
+		state := func(id string) string {
+			if e.running[id] {
+				return "running"
+			}
+			return "exited"
+		}
+		containers := []map[string]interface{}{
+			{"Id": "web0123456789abcdef", "Names": []string{"/web"}, "Image": "nginx:1.27", "State": state("web0123456789abcdef"), "Status": "Up 2 hours", "Created": 1700000000,
+				"Ports": []map[string]interface{}{{"PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}}},
+		}
+		if r.URL.Query().Get("all") == "1" {
+			containers = append(containers, map[string]interface{}{"Id": "db0123456789abcdef", "Names": []string{"/db"}, "Image": "postgres:16", "State": state("db0123456789abcdef"), "Status": "Exited (0) 1 hour ago", "Created": 1700000100})
+		}
+		write(containers)
+	case r.URL.Path == "/images/json":
+		write([]map[string]interface{}{
+			{"Id": "sha256:aaaabbbbccccdddd", "RepoTags": []string{"nginx:1.27"}, "Size": 190 << 20, "Created": 1700000000},
+			{"Id": "sha256:eeeeffff00001111", "RepoTags": []string{"registry:5000/postgres:16"}, "Size": 400 << 20, "Created": 1700000500},
+		})
+	case r.URL.Path == "/containers/web0123456789abcdef/stats":
+		flusher := w.(http.Flusher)
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 2; i++ {
+			write(fakeStats(start.Add(time.Duration(i)*time.Second), uint64(i)))
+			flusher.Flush()
+		}
+		// Keep the stream open like Docker does for running containers.
+		e.mu.Unlock()
+		<-r.Context().Done()
+		e.mu.Lock()
+	case strings.HasSuffix(r.URL.Path, "/logs"):
+		for _, frame := range []struct {
+			stream byte
+			text   string
+		}{{1, "listening on :80\n"}, {2, "warning: slow request\n"}} {
+			header := make([]byte, 8)
+			header[0] = frame.stream
+			binary.BigEndian.PutUint32(header[4:], uint32(len(frame.text)))
+			w.Write(append(header, frame.text...))
+		}
+	case r.Method == http.MethodPost:
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 3 || (parts[1] != "web0123456789abcdef" && parts[1] != "db0123456789abcdef") {
+			w.WriteHeader(http.StatusNotFound)
+			write(map[string]string{"message": "No such container: " + parts[1]})
+			return
+		}
+		e.actions = append(e.actions, parts[2]+" "+parts[1][:2]+" t="+r.URL.Query().Get("t"))
+		e.running[parts[1]] = parts[2] != "stop"
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		write(map[string]string{"message": "page not found"})
+	}
+}
+
+// fakeStats is a sample one second after the previous one with 50% of one of
+// two cores, 100MB of memory of which 20MB is cache, and 1MB more network
+// and disk traffic per step.
+func fakeStats(read time.Time, step uint64) map[string]interface{} {
+	const mb = 1 << 20
+	return map[string]interface{}{
+		"read": read,
+		"cpu_stats": map[string]interface{}{
+			"cpu_usage":        map[string]interface{}{"total_usage": (step + 1) * 500_000_000},
+			"system_cpu_usage": (step + 1) * 2_000_000_000,
+			"online_cpus":      2,
+		},
+		"precpu_stats": map[string]interface{}{
+			"cpu_usage":        map[string]interface{}{"total_usage": step * 500_000_000},
+			"system_cpu_usage": step * 2_000_000_000,
+		},
+		"memory_stats": map[string]interface{}{
+			"usage": 100 * mb,
+			"limit": 400 * mb,
+			"stats": map[string]interface{}{"inactive_file": 20 * mb},
+		},
+		"networks": map[string]interface{}{
+			"eth0": map[string]interface{}{"rx_bytes": (step + 1) * mb, "tx_bytes": step * mb},
+		},
+		"blkio_stats": map[string]interface{}{
+			"io_service_bytes_recursive": []map[string]interface{}{
+				{"op": "read", "value": (step + 1) * mb},
+				{"op": "write", "value": 0},
+			},
+		},
+	}
+}
+
+func startDockerPlugin(t *testing.T, host string) *DockerPlugin {
+	t.Helper()
+	plugin := NewDockerPlugin()
+	config := withDefaults("docker", PluginConfig{Name: "docker", Enabled: true, Settings: map[string]interface{}{"host": host}})
+	if err := plugin.Initialize(config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	t.Cleanup(func() { plugin.Shutdown() })
+	return plugin
+}
+
+func TestDockerPluginUnavailable(t *testing.T) {
+	plugin := NewDockerPlugin()
+	err := plugin.Initialize(PluginConfig{Settings: map[string]interface{}{"host": "unix://" + filepath.Join(t.TempDir(), "missing.sock")}})
+	if err == nil || !strings.Contains(err.Error(), "Docker is not available") {
+		t.Errorf("Expected an unavailable daemon to fail Initialize, got %v", err)
+	}
+
+	if _, err := newDockerClient("npipe:////./pipe/docker_engine"); err == nil {
+		t.Error("Expected an unsupported host to be rejected")
+	}
+}
+
+func TestDockerPluginCollectsContainers(t *testing.T) {
+	_, host := startFakeEngine(t)
+	plugin := startDockerPlugin(t, host)
+
+	if _, err := plugin.CollectData(); err != nil {
+		t.Fatalf("CollectData failed: %v", err)
+	}
+
+	// Stats arrive on the stream opened by the first refresh.
+	var web DockerContainer
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		data, err := plugin.CollectData()
+		if err != nil {
+			t.Fatalf("CollectData failed: %v", err)
+		}
+		web = data["containers"].([]DockerContainer)[0]
+		if web.Stats != nil && web.Stats.NetRxRate > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	data, _ := plugin.CollectData()
+	containers := data["containers"].([]DockerContainer)
+	if len(containers) != 2 || web.Name != "web" || web.Ports != "8080->80/tcp" || containers[1].State != "exited" || containers[1].Stats != nil {
+		t.Fatalf("Unexpected containers %+v", containers)
+	}
+	if web.Stats == nil {
+		t.Fatal("Expected stats for the running container")
+	}
+
+	stats := *web.Stats
+	if stats.CPUPercent != 50 || stats.MemoryUsage != 80<<20 || stats.MemoryPercent != 20 {
+		t.Errorf("Unexpected CPU and memory %+v", stats)
+	}
+	if stats.NetRxRate != 1<<20 || stats.NetTxRate != 1<<20 || stats.BlockReadRate != 1<<20 || stats.BlockWriteRate != 0 {
+		t.Errorf("Unexpected rates %+v", stats)
+	}
+
+	images := data["images"].([]DockerImage)
+	if len(images) != 2 || images[0].Repo != "registry:5000/postgres" || images[0].Tag != "16" || images[0].ID != "eeeeffff0000" {
+		t.Errorf("Expected images newest first with registry ports kept, got %+v", images)
+	}
+	if got := data["stats"].(DockerStats); got != (DockerStats{ContainersRunning: 1, ContainersStopped: 1, Images: 2}) {
+		t.Errorf("Unexpected counts %+v", got)
+	}
+}
+
+func TestDockerPluginWidget(t *testing.T) {
+	engine, host := startFakeEngine(t)
+	plugin := startDockerPlugin(t, host)
+
+	// Background updates are queued like the UI's QueueUpdateDraw and run by
+	// waitFor on the test goroutine.
+	draws := make(chan func(), 16)
+	plugin.SetRedraw(func(f func()) { draws <- f })
+
+	widget, err := plugin.CreateWidget()
+	if err != nil {
+		t.Fatalf("CreateWidget failed: %v", err)
+	}
+	w := widget.(*dockerWidget)
+
+	cell := func(row, column int) string { return w.table.GetCell(row, column).Text }
+	if cell(1, 0) != "web" || cell(1, 1) != "running" || cell(2, 0) != "db" || cell(2, 6) != "postgres:16" {
+		t.Errorf("Unexpected table rows %q %q %q %q", cell(1, 0), cell(1, 1), cell(2, 0), cell(2, 6))
+	}
+
+	key := func(r rune) {
+		w.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+	}
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for !done() {
+			select {
+			case f := <-draws:
+				f()
+			case <-time.After(10 * time.Millisecond):
+			case <-deadline:
+				t.Fatalf("Timed out waiting for %s", what)
+			}
+		}
+	}
+	actions := func() string {
+		engine.mu.Lock()
+		defer engine.mu.Unlock()
+		return strings.Join(engine.actions, ",")
+	}
+
+	// Stopping needs a second press; the selection follows the container
+	// when the table is redrawn.
+	w.table.Select(2, 0)
+	key('x')
+	if actions() != "" || !strings.Contains(w.summary.GetText(true), "Press x again to stop db") {
+		t.Fatalf("Expected a confirmation prompt, got %q", w.summary.GetText(true))
+	}
+	key('x')
+	waitFor("stop", func() bool { return actions() == "stop db t=10" })
+	waitFor("status", func() bool { return strings.Contains(w.summary.GetText(true), "db: stop done") })
+	if row, _ := w.table.GetSelection(); row != 2 {
+		t.Errorf("Expected db to stay selected, got row %d", row)
+	}
+
+	key('o')
+	waitFor("start", func() bool { return actions() == "stop db t=10,start db t=" })
+
+	key('l')
+	waitFor("logs", func() bool { return cell(2, 0) == "warning: slow request" })
+	if cell(0, 0) != "Logs of db (l or ESC to close)" || cell(1, 0) != "listening on :80" {
+		t.Errorf("Unexpected logs %q %q", cell(0, 0), cell(1, 0))
+	}
+	key('l')
+	if cell(1, 0) != "web" {
+		t.Errorf("Expected the container table back, got %q", cell(1, 0))
+	}
+}
+
+func TestDockerClientErrors(t *testing.T) {
+	_, host := startFakeEngine(t)
+	client, err := newDockerClient(host)
+	if err != nil {
+		t.Fatalf("newDockerClient failed: %v", err)
+	}
+
+	err = client.Action(context.Background(), "missing", "stop")
+	if err == nil || err.Error() != "docker POST /containers/missing/stop: No such container: missing" {
+		t.Errorf("Expected the daemon's message, got %v", err)
+	}
+	if err := client.Action(context.Background(), "web0123456789abcdef", "remove"); err == nil {
+		t.Error("Expected an unknown action to be rejected")
+	}
+}
+
+func TestSplitDockerLogs(t *testing.T) {
+	if lines := splitDockerLogs([]byte("tty output\r\nsecond\n")); strings.Join(lines, "|") != "tty output|second" {
+		t.Errorf("Expected raw TTY logs split into lines, got %q", lines)
+	}
+
+	frame := func(stream byte, text string) []byte {
+		header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(header[4:], uint32(len(text)))
+		return append(header, text...)
+	}
+	body := append(frame(1, "out "), frame(2, "and err\nnext\n")...)
+	if lines := splitDockerLogs(body); strings.Join(lines, "|") != "out and err|next" {
+		t.Errorf("Expected multiplexed frames joined, got %q", lines)
+	}
+}
+
+func TestNextContainerStats(t *testing.T) {
+	var first apiStats
+	raw, _ := json.Marshal(fakeStats(time.Unix(100, 0), 0))
+	json.Unmarshal(raw, &first)
+
+	stats := nextContainerStats(ContainerStats{}, first)
+	if stats.NetRxRate != 0 || stats.NetRx != 1<<20 {
+		t.Errorf("Expected no rates from the first sample, got %+v", stats)
+	}
+
+	// Counters going backwards, like after a restart, are not negative rates.
+	var second apiStats
+	raw, _ = json.Marshal(fakeStats(time.Unix(102, 0), 0))
+	json.Unmarshal(raw, &second)
+	second.Networks["eth1"] = second.Networks["eth0"]
+	next := nextContainerStats(ContainerStats{Updated: time.Unix(100, 0), NetRx: 1 << 20, BlockRead: 4 << 20}, second)
+	if next.NetRxRate != float64(1<<20)/2 || next.BlockReadRate != 0 {
+		t.Errorf("Unexpected rates %+v", next)
+	}
+}
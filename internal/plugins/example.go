@@ -2,6 +2,7 @@ package plugins
 
 import (
 	"fmt"
+	"sync"
 	"syspulse/internal/utils"
 	"time"
 
@@ -9,8 +10,21 @@ import (
 )
 
 type ExamplePlugin struct {
+	redrawer
+
 	config PluginConfig
-	data   map[string]interface{}
+
+	// mu guards data, which exports read while the widget updates.
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func init() {
+	Register("example", func() Plugin { return NewExamplePlugin() },
+		SettingSchema{Key: "show_time", Type: SettingBool, Default: true, Description: "Show the current time"},
+		SettingSchema{Key: "show_stats", Type: SettingBool, Default: true, Description: "Show the update counter"},
+		SettingSchema{Key: "custom_message", Type: SettingString, Default: "Hello from SysPulse!", Description: "Message shown in the widget"},
+	)
 }
 
 func NewExamplePlugin() *ExamplePlugin {
@@ -36,6 +50,9 @@ func (p *ExamplePlugin) Author() string {
 }
 
 func (p *ExamplePlugin) Initialize(config PluginConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.config = config
 	p.data["initialized"] = true
 	p.data["init_time"] = time.Now().Format("15:04:05")
@@ -43,6 +60,9 @@ func (p *ExamplePlugin) Initialize(config PluginConfig) error {
 }
 
 func (p *ExamplePlugin) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.data["shutdown_time"] = time.Now().Format("15:04:05")
 	return nil
 }
@@ -61,10 +81,7 @@ func (p *ExamplePlugin) CreateWidget() (tview.Primitive, error) {
 		textView.SetTitleColor(utils.GetColorFromName(p.config.Layout.ForegroundColor))
 	}
 
-	if err := p.UpdateWidget(textView); err != nil {
-		return nil, err
-	}
-
+	textView.SetText(p.content())
 	return textView, nil
 }
 
@@ -74,6 +91,17 @@ func (p *ExamplePlugin) UpdateWidget(widget tview.Primitive) error {
 		return fmt.Errorf("widget is not a TextView")
 	}
 
+	content := p.content()
+	p.queueDraw(func() {
+		textView.SetText(content)
+	})
+	return nil
+}
+
+func (p *ExamplePlugin) content() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	currentTime := time.Now().Format("15:04:05")
 	p.data["current_time"] = currentTime
 
@@ -89,9 +117,7 @@ func (p *ExamplePlugin) UpdateWidget(widget tview.Primitive) error {
 			content += fmt.Sprintf("  %s: %v\n", key, value)
 		}
 	}
-
-	textView.SetText(content)
-	return nil
+	return content
 }
 
 func (p *ExamplePlugin) GetWidgetConfig() WidgetConfig {
@@ -99,6 +125,9 @@ func (p *ExamplePlugin) GetWidgetConfig() WidgetConfig {
 }
 
 func (p *ExamplePlugin) CollectData() (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.incrementUpdateCount()
 
 	data := make(map[string]interface{})
@@ -0,0 +1,563 @@
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"syspulse/internal/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// External plugins are executables in the plugin directory that SysPulse
+// spawns and talks to over stdin/stdout. Every message is one line of
+// JSON-RPC 2.0. SysPulse sends requests and the plugin answers each with a
+// response carrying the same id:
+//
+//	-> {"jsonrpc":"2.0","id":1,"method":"Initialize","params":{"name":"uptime","enabled":true,"settings":{},"layout":{...}}}
+//	<- {"jsonrpc":"2.0","id":1,"result":{"version":"1.0.0","description":"Shows uptime","author":"me"}}
+//	-> {"jsonrpc":"2.0","id":2,"method":"Render","params":{"width":40,"height":10}}
+//	<- {"jsonrpc":"2.0","id":2,"result":{"text":"[green]up[white] 3 days"}}
+//
+// The methods mirror the Plugin interface: Initialize, CollectData,
+// ExportData and Shutdown, plus Render, which returns the widget content as
+// tview color-tagged text. A plugin that exits or stops answering is killed
+// and started again with exponential backoff, and receives Initialize again
+// with the same config.
+const ExternalProtocolVersion = 1
+
+const (
+	externalCallTimeout     = 5 * time.Second
+	externalShutdownTimeout = 2 * time.Second
+	externalMinBackoff      = time.Second
+	externalMaxBackoff      = time.Minute
+
+	// externalMaxMessage bounds one line from a plugin, so a plugin that
+	// never writes a newline cannot grow SysPulse's memory without limit.
+	externalMaxMessage = 4 << 20
+)
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// RPCError is an error a plugin returned for a call. The plugin keeps running.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// ExternalInfo is the result of Initialize.
+type ExternalInfo struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+}
+
+type RenderParams struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type RenderResult struct {
+	Text string `json:"text"`
+}
+
+// ExternalPlugin runs a plugin executable. Calls are serialized; a plugin
+// only ever has one request in flight.
+type ExternalPlugin struct {
+	redrawer
+
+	name string
+	path string
+
+	// width and height are the inner size of the widget, for Render.
+	width  atomic.Int32
+	height atomic.Int32
+
+	mu          sync.Mutex
+	proc        *pluginProcess
+	config      PluginConfig
+	initialized bool
+	info        ExternalInfo
+	nextID      int64
+
+	failures  int
+	nextStart time.Time
+	lastErr   error
+
+	timeout    time.Duration
+	minBackoff time.Duration
+}
+
+func NewExternalPlugin(path string) *ExternalPlugin {
+	base := filepath.Base(path)
+	return &ExternalPlugin{
+		name:       strings.TrimSuffix(base, filepath.Ext(base)),
+		path:       path,
+		timeout:    externalCallTimeout,
+		minBackoff: externalMinBackoff,
+	}
+}
+
+// DiscoverPlugins returns an external plugin for every executable in dir,
+// named after the file without its extension. A missing directory is not an
+// error.
+func DiscoverPlugins(dir string) ([]*ExternalPlugin, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var found []*ExternalPlugin
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || !isExecutable(info) {
+			continue
+		}
+		found = append(found, NewExternalPlugin(filepath.Join(dir, entry.Name())))
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].name < found[j].name })
+	return found, nil
+}
+
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+func (p *ExternalPlugin) Name() string {
+	return p.name
+}
+
+func (p *ExternalPlugin) Version() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.info.Version == "" {
+		return "unknown"
+	}
+	return p.info.Version
+}
+
+func (p *ExternalPlugin) Description() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.info.Description == "" {
+		return "External plugin " + p.path
+	}
+	return p.info.Description
+}
+
+func (p *ExternalPlugin) Author() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.info.Author
+}
+
+// Path returns the plugin executable.
+func (p *ExternalPlugin) Path() string {
+	return p.path
+}
+
+func (p *ExternalPlugin) Initialize(config PluginConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A failed start must not delay an explicit (re)initialization.
+	p.nextStart = time.Time{}
+	if err := p.ensureRunning(); err != nil {
+		return err
+	}
+
+	var info ExternalInfo
+	if err := p.callLocked("Initialize", config, &info); err != nil {
+		return err
+	}
+
+	p.config = config
+	p.info = info
+	p.initialized = true
+	return nil
+}
+
+func (p *ExternalPlugin) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.initialized = false
+	if p.proc == nil || p.proc.exited() {
+		p.proc = nil
+		return nil
+	}
+
+	err := p.callLocked("Shutdown", nil, nil)
+	if p.proc != nil {
+		p.proc.stop(externalShutdownTimeout)
+		p.proc = nil
+	}
+	return err
+}
+
+func (p *ExternalPlugin) CreateWidget() (tview.Primitive, error) {
+	textView := tview.NewTextView()
+	utils.SetBorderStyle(textView.Box)
+	textView.SetTitle(p.config.Layout.Title)
+	textView.SetDynamicColors(true)
+
+	if p.config.Layout.BorderColor != "" {
+		textView.SetBorderColor(utils.GetColorFromName(p.config.Layout.BorderColor))
+	}
+
+	if p.config.Layout.ForegroundColor != "" {
+		textView.SetTitleColor(utils.GetColorFromName(p.config.Layout.ForegroundColor))
+	}
+
+	// Render runs off the UI goroutine, so it uses the inner size the widget
+	// had when it was last drawn instead of asking the widget.
+	_, _, width, height := textView.GetInnerRect()
+	p.setSize(width, height)
+	textView.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		p.setSize(width-2, height-2)
+		return x + 1, y + 1, width - 2, height - 2
+	})
+
+	text, _ := p.render()
+	textView.SetText(text)
+	return textView, nil
+}
+
+func (p *ExternalPlugin) UpdateWidget(widget tview.Primitive) error {
+	textView, ok := widget.(*tview.TextView)
+	if !ok {
+		return fmt.Errorf("widget is not a TextView")
+	}
+
+	text, err := p.render()
+	p.queueDraw(func() {
+		textView.SetText(text)
+	})
+	return err
+}
+
+// render asks the plugin for the widget content, or describes why it could
+// not.
+func (p *ExternalPlugin) render() (string, error) {
+	var result RenderResult
+	params := RenderParams{Width: int(p.width.Load()), Height: int(p.height.Load())}
+	if err := p.call("Render", params, &result); err != nil {
+		return fmt.Sprintf("[red]%s is unavailable[white]\n%s", tview.Escape(p.name), tview.Escape(err.Error())), err
+	}
+	return result.Text, nil
+}
+
+func (p *ExternalPlugin) setSize(width, height int) {
+	p.width.Store(int32(width))
+	p.height.Store(int32(height))
+}
+
+func (p *ExternalPlugin) GetWidgetConfig() WidgetConfig {
+	return p.config.Layout
+}
+
+func (p *ExternalPlugin) CollectData() (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := p.call("CollectData", nil, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p *ExternalPlugin) ExportData() map[string]interface{} {
+	var data map[string]interface{}
+	if err := p.call("ExportData", nil, &data); err != nil {
+		return nil
+	}
+	return data
+}
+
+func (p *ExternalPlugin) call(method string, params, result any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.initialized {
+		return fmt.Errorf("plugin %s is not initialized", p.name)
+	}
+	if err := p.ensureRunning(); err != nil {
+		return err
+	}
+	return p.callLocked(method, params, result)
+}
+
+// ensureRunning starts the plugin if it is not running and its restart
+// backoff has passed. A restarted plugin is initialized with the last config
+// before it gets any other call.
+func (p *ExternalPlugin) ensureRunning() error {
+	if p.proc != nil && p.proc.exited() {
+		p.crashed(p.proc.exitError())
+	}
+	if p.proc != nil {
+		return nil
+	}
+
+	if wait := time.Until(p.nextStart); wait > 0 {
+		return fmt.Errorf("plugin %s failed (%v), restarting in %s", p.name, p.lastErr, wait.Round(time.Second))
+	}
+
+	proc, err := startPluginProcess(p.path)
+	if err != nil {
+		p.crashed(err)
+		return err
+	}
+	p.proc = proc
+
+	if p.initialized {
+		var info ExternalInfo
+		if err := p.callLocked("Initialize", p.config, &info); err != nil {
+			return err
+		}
+		p.info = info
+	}
+	return nil
+}
+
+// callLocked sends one request and waits for its response. A plugin that
+// exits or does not answer in time is treated as crashed.
+func (p *ExternalPlugin) callLocked(method string, params, result any) error {
+	p.nextID++
+	id := p.nextID
+
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	proc := p.proc
+	if _, err := proc.stdin.Write(append(data, '\n')); err != nil {
+		err = fmt.Errorf("plugin %s: failed to send %s: %w", p.name, method, err)
+		p.crashed(err)
+		return err
+	}
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case resp := <-proc.responses:
+			// Answers to calls that timed out earlier are dropped.
+			if resp.ID != id {
+				continue
+			}
+			// A plugin that starts but fails on its first real call still
+			// backs off further each time.
+			if method != "Initialize" {
+				p.failures = 0
+			}
+			if resp.Error != nil {
+				return fmt.Errorf("plugin %s: %s failed: %w", p.name, method, resp.Error)
+			}
+			if result == nil || len(resp.Result) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("plugin %s: invalid %s result: %w", p.name, method, err)
+			}
+			return nil
+		case <-proc.done:
+			err := fmt.Errorf("plugin %s exited during %s: %w", p.name, method, proc.exitError())
+			p.crashed(err)
+			return err
+		case <-timer.C:
+			err := fmt.Errorf("plugin %s did not answer %s within %s", p.name, method, p.timeout)
+			p.crashed(err)
+			return err
+		}
+	}
+}
+
+// crashed kills the plugin process and schedules the next start, doubling
+// the delay with every failure in a row.
+func (p *ExternalPlugin) crashed(err error) {
+	if p.proc != nil {
+		p.proc.kill()
+		p.proc = nil
+	}
+
+	backoff := p.minBackoff << min(p.failures, 16)
+	if backoff > externalMaxBackoff || backoff <= 0 {
+		backoff = externalMaxBackoff
+	}
+	p.failures++
+	p.lastErr = err
+	p.nextStart = time.Now().Add(backoff)
+}
+
+type pluginProcess struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan rpcResponse
+	done      chan struct{}
+	stderr    *tailBuffer
+	err       error
+}
+
+func startPluginProcess(path string) (*pluginProcess, error) {
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("SYSPULSE_PLUGIN_PROTOCOL=%d", ExternalProtocolVersion))
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	proc := &pluginProcess{
+		cmd:       cmd,
+		stdin:     stdin,
+		responses: make(chan rpcResponse, 16),
+		done:      make(chan struct{}),
+		stderr:    &tailBuffer{limit: 2048},
+	}
+	cmd.Stderr = proc.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", path, err)
+	}
+
+	go proc.read(stdout)
+	return proc, nil
+}
+
+// read delivers responses until the plugin closes stdout, then reaps it.
+// Lines that are not JSON-RPC responses are ignored.
+func (proc *pluginProcess) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), externalMaxMessage)
+	for scanner.Scan() {
+		var resp rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil || resp.JSONRPC != "2.0" {
+			continue
+		}
+		select {
+		case proc.responses <- resp:
+		default:
+		}
+	}
+
+	// Kill a plugin that is still running with a broken stdout, such as a
+	// line over the size limit, so Wait returns.
+	if scanner.Err() != nil {
+		proc.cmd.Process.Kill()
+	}
+	err := proc.cmd.Wait()
+	if err == nil {
+		err = errors.New("exit status 0")
+	}
+	if tail := proc.stderr.lastLine(); tail != "" {
+		err = fmt.Errorf("%w: %s", err, tail)
+	}
+	proc.err = err
+	close(proc.done)
+}
+
+func (proc *pluginProcess) exited() bool {
+	select {
+	case <-proc.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (proc *pluginProcess) exitError() error {
+	<-proc.done
+	return proc.err
+}
+
+func (proc *pluginProcess) kill() {
+	proc.stdin.Close()
+	proc.cmd.Process.Kill()
+}
+
+// stop closes stdin, which plugins should treat as a request to exit, and
+// kills the plugin if it is still running after timeout.
+func (proc *pluginProcess) stop(timeout time.Duration) {
+	proc.stdin.Close()
+	select {
+	case <-proc.done:
+	case <-time.After(timeout):
+		proc.cmd.Process.Kill()
+	}
+}
+
+// tailBuffer keeps the last bytes a plugin wrote to stderr, to explain why
+// it exited.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (b *tailBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, data...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(data), nil
+}
+
+func (b *tailBuffer) lastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := strings.Split(strings.TrimSpace(string(b.buf)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,302 @@
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// TestMain turns the test binary into a fake external plugin when started
+// by one of the wrapper scripts written in writeTestPlugin.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("SYSPULSE_TEST_PLUGIN"); mode != "" {
+		runTestPlugin(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin answers requests like a plugin would. In "crash" mode it
+// exits on the first CollectData after being started, unless the marker
+// file from a previous crash exists; in "hang" mode it never answers Render.
+func runTestPlugin(mode string) {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	starts := os.Getenv("SYSPULSE_TEST_PLUGIN_MARKER")
+
+	for scanner.Scan() {
+		var req struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+
+		var result any
+		var rpcErr *RPCError
+		switch req.Method {
+		case "Initialize":
+			var config PluginConfig
+			json.Unmarshal(req.Params, &config)
+			result = ExternalInfo{Version: "2.1.0", Description: "Test plugin", Author: "tests"}
+		case "Render":
+			if mode == "hang" {
+				continue
+			}
+			var params RenderParams
+			json.Unmarshal(req.Params, &params)
+			result = RenderResult{Text: fmt.Sprintf("[green]ok[white] %dx%d", params.Width, params.Height)}
+		case "CollectData", "ExportData":
+			if mode == "crash" {
+				if _, err := os.Stat(starts); err != nil {
+					os.WriteFile(starts, nil, 0644)
+					fmt.Fprintln(os.Stderr, "panic: simulated crash")
+					os.Exit(2)
+				}
+			}
+			result = map[string]any{"method": req.Method, "pid": os.Getpid()}
+		case "Fail":
+			rpcErr = &RPCError{Code: -32000, Message: "not today"}
+		case "Shutdown":
+			encoder.Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": nil})
+			return
+		default:
+			rpcErr = &RPCError{Code: -32601, Message: "method not found"}
+		}
+
+		// Noise on stdout must not confuse the host.
+		fmt.Println("not json")
+		if rpcErr != nil {
+			encoder.Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": rpcErr})
+		} else {
+			encoder.Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		}
+	}
+}
+
+func writeTestPlugin(t *testing.T, dir, name, mode string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin wrapper scripts need a POSIX shell")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to find test binary: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf("#!/bin/sh\nSYSPULSE_TEST_PLUGIN=%s SYSPULSE_TEST_PLUGIN_MARKER=%s exec %q\n",
+		mode, filepath.Join(dir, name+".crashed"), self)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func startTestPlugin(t *testing.T, mode string) *ExternalPlugin {
+	t.Helper()
+	plugin := NewExternalPlugin(writeTestPlugin(t, t.TempDir(), "tester", mode))
+	plugin.minBackoff = 50 * time.Millisecond
+	plugin.timeout = 2 * time.Second
+
+	if err := plugin.Initialize(PluginConfig{Name: "tester", Enabled: true}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	t.Cleanup(func() { plugin.Shutdown() })
+	return plugin
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "uptime.sh", "ok")
+	writeTestPlugin(t, dir, "battery", "ok")
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0644)
+	os.WriteFile(filepath.Join(dir, ".hidden"), []byte("#!/bin/sh\n"), 0755)
+	os.Mkdir(filepath.Join(dir, "subdir"), 0755)
+
+	found, err := DiscoverPlugins(dir)
+	if err != nil {
+		t.Fatalf("DiscoverPlugins failed: %v", err)
+	}
+
+	var names []string
+	for _, plugin := range found {
+		names = append(names, plugin.Name())
+	}
+	if strings.Join(names, ",") != "battery,uptime" {
+		t.Errorf("Expected battery and uptime, got %v", names)
+	}
+
+	if found, err := DiscoverPlugins(filepath.Join(dir, "missing")); err != nil || len(found) != 0 {
+		t.Errorf("Expected a missing directory to yield nothing, got %v, %v", found, err)
+	}
+}
+
+func TestExternalPluginCalls(t *testing.T) {
+	plugin := startTestPlugin(t, "ok")
+
+	if plugin.Version() != "2.1.0" || plugin.Description() != "Test plugin" || plugin.Author() != "tests" {
+		t.Errorf("Unexpected plugin info %s %q %q", plugin.Version(), plugin.Description(), plugin.Author())
+	}
+
+	data, err := plugin.CollectData()
+	if err != nil || data["method"] != "CollectData" {
+		t.Errorf("Unexpected CollectData result %v, %v", data, err)
+	}
+	if data := plugin.ExportData(); data["method"] != "ExportData" {
+		t.Errorf("Unexpected ExportData result %v", data)
+	}
+
+	widget, err := plugin.CreateWidget()
+	if err != nil {
+		t.Fatalf("CreateWidget failed: %v", err)
+	}
+	textView := widget.(*tview.TextView)
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init screen: %v", err)
+	}
+	defer screen.Fini()
+	textView.SetRect(0, 0, 22, 6)
+	textView.Draw(screen)
+
+	// The text is set on the UI goroutine, which the redraw queue stands in for.
+	draws := make(chan func(), 1)
+	plugin.SetRedraw(func(f func()) { draws <- f })
+	if err := plugin.UpdateWidget(textView); err != nil {
+		t.Fatalf("UpdateWidget failed: %v", err)
+	}
+	if text := textView.GetText(true); strings.Contains(text, "20x4") {
+		t.Errorf("Expected the text to wait for the UI goroutine, got %q", text)
+	}
+	(<-draws)()
+	if text := textView.GetText(true); text != "ok 20x4" {
+		t.Errorf("Expected rendered text, got %q", text)
+	}
+
+	err = plugin.call("Fail", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "not today") {
+		t.Errorf("Expected the plugin error, got %v", err)
+	}
+	if _, err := plugin.CollectData(); err != nil {
+		t.Errorf("Expected the plugin to keep running after an error, got %v", err)
+	}
+}
+
+func TestExternalPluginRestartsAfterCrash(t *testing.T) {
+	plugin := startTestPlugin(t, "crash")
+
+	_, err := plugin.CollectData()
+	if err == nil || !strings.Contains(err.Error(), "simulated crash") {
+		t.Fatalf("Expected the crash to be reported with stderr, got %v", err)
+	}
+
+	if _, err := plugin.CollectData(); err == nil || !strings.Contains(err.Error(), "restarting in") {
+		t.Errorf("Expected the restart to wait for the backoff, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	data, err := plugin.CollectData()
+	if err != nil {
+		t.Fatalf("Expected the plugin to be restarted, got %v", err)
+	}
+	if data["pid"] == nil {
+		t.Errorf("Unexpected data after restart %v", data)
+	}
+	if plugin.Version() != "2.1.0" {
+		t.Errorf("Expected the restarted plugin to be initialized again, got version %s", plugin.Version())
+	}
+}
+
+func TestExternalPluginTimeout(t *testing.T) {
+	plugin := startTestPlugin(t, "hang")
+	plugin.timeout = 100 * time.Millisecond
+
+	textView := tview.NewTextView()
+	if err := plugin.UpdateWidget(textView); err == nil || !strings.Contains(err.Error(), "did not answer") {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	if !strings.Contains(textView.GetText(true), "tester is unavailable") {
+		t.Errorf("Expected the widget to show the failure, got %q", textView.GetText(true))
+	}
+}
+
+func TestExternalPluginShutdown(t *testing.T) {
+	plugin := startTestPlugin(t, "ok")
+	proc := plugin.proc
+
+	if err := plugin.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-proc.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the plugin process to exit")
+	}
+
+	if _, err := plugin.CollectData(); err == nil {
+		t.Error("Expected calls after shutdown to fail")
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "auto", "ok")
+	writeTestPlugin(t, dir, "configured", "ok")
+	writeTestPlugin(t, dir, "disabled", "ok")
+	writeTestPlugin(t, dir, "docker", "ok")
+
+	config := &PluginSystemConfig{
+		Plugins: map[string]PluginConfig{
+			"configured": {Enabled: true, Layout: WidgetConfig{Title: "Configured"}},
+			"disabled":   {Enabled: false},
+			"example":    {Enabled: true, Settings: map[string]interface{}{"custom_message": "hi"}},
+			"typo":       {Enabled: false},
+		},
+		PluginSettings: PluginSettings{AutoLoad: true, PluginDirectory: dir},
+	}
+
+	manager := NewPluginManager()
+	err := loadPlugins(manager, config)
+	defer manager.ShutdownAll()
+
+	if err == nil || !strings.Contains(err.Error(), `unknown plugin "typo"`) {
+		t.Errorf("Expected an error for the unknown plugin, got %v", err)
+	}
+
+	var names []string
+	for _, plugin := range manager.GetAllPlugins() {
+		names = append(names, plugin.Name())
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "Example Plugin,auto,configured" {
+		t.Errorf("Expected example, auto and configured to load, got %v", names)
+	}
+
+	exampleConfig, _ := manager.GetPluginConfig("Example Plugin")
+	if exampleConfig.Settings["custom_message"] != "hi" || exampleConfig.Settings["show_time"] != true {
+		t.Errorf("Expected configured settings over schema defaults, got %v", exampleConfig.Settings)
+	}
+
+	config.PluginSettings.AutoLoad = false
+	delete(config.Plugins, "typo")
+	other := NewPluginManager()
+	if err := loadPlugins(other, config); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	defer other.ShutdownAll()
+	if plugins := other.GetAllPlugins(); len(plugins) != 2 {
+		t.Errorf("Expected only configured plugins without auto_load, got %d", len(plugins))
+	}
+}
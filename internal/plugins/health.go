@@ -0,0 +1,171 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type HealthState string
+
+const (
+	HealthOK       HealthState = "ok"
+	HealthDegraded HealthState = "degraded"
+	HealthFailed   HealthState = "failed"
+)
+
+const (
+	DefaultCallTimeout = 10 * time.Second
+	DefaultMaxFailures = 5
+)
+
+// Health is how the calls to a plugin have gone so far. A plugin is degraded
+// after a failed call and failed once MaxFailures calls in a row failed, at
+// which point the manager disables it.
+type Health struct {
+	State               HealthState   `json:"state"`
+	LastError           string        `json:"last_error,omitempty"`
+	LastErrorAt         time.Time     `json:"last_error_at,omitempty"`
+	LastDuration        time.Duration `json:"last_duration"`
+	LastRun             time.Time     `json:"last_run,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	Calls               int           `json:"calls"`
+
+	// busy is set while a call runs, including one that timed out but has
+	// not returned yet, so a hung plugin is not called again.
+	busy bool
+}
+
+// SetLimits sets how long a call may take and after how many consecutive
+// failed calls a plugin is disabled. A maxFailures below 1 never disables.
+func (pm *PluginManager) SetLimits(callTimeout time.Duration, maxFailures int) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if callTimeout <= 0 {
+		callTimeout = DefaultCallTimeout
+	}
+	pm.callTimeout = callTimeout
+	pm.maxFailures = max(maxFailures, 0)
+}
+
+// Call runs fn with the plugin under the call timeout and recovers from
+// panics, recording the outcome in the plugin's health. The plugin API has
+// no context, so a call that times out keeps running in the background and
+// the plugin is reported as busy until it returns. Calls cut short because
+// ctx was cancelled are not held against the plugin.
+func (pm *PluginManager) Call(ctx context.Context, name string, fn func(Plugin) error) error {
+	pm.mutex.Lock()
+	plugin, exists := pm.plugins[name]
+	if !exists {
+		pm.mutex.Unlock()
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	health := pm.healthLocked(name)
+	if health.busy {
+		pm.mutex.Unlock()
+		err := fmt.Errorf("plugin %s is still busy with a call that timed out", name)
+		pm.record(name, 0, err)
+		return err
+	}
+	health.busy = true
+	timeout := pm.callTimeout
+	pm.mutex.Unlock()
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("plugin %s panicked: %v", name, r)
+			}
+		}()
+		done <- fn(plugin)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		pm.setIdle(name)
+	case <-callCtx.Done():
+		go func() {
+			<-done
+			pm.setIdle(name)
+		}()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = fmt.Errorf("plugin %s did not finish within %s", name, timeout)
+	}
+
+	pm.record(name, time.Since(start), err)
+	return err
+}
+
+// GetHealth returns the health of a loaded plugin.
+func (pm *PluginManager) GetHealth(name string) (Health, bool) {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	if _, exists := pm.plugins[name]; !exists {
+		return Health{}, false
+	}
+	if health, exists := pm.health[name]; exists {
+		return *health, true
+	}
+	return Health{State: HealthOK}, true
+}
+
+func (pm *PluginManager) healthLocked(name string) *Health {
+	health, exists := pm.health[name]
+	if !exists {
+		health = &Health{State: HealthOK}
+		pm.health[name] = health
+	}
+	return health
+}
+
+func (pm *PluginManager) setIdle(name string) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if health, exists := pm.health[name]; exists {
+		health.busy = false
+	}
+}
+
+func (pm *PluginManager) record(name string, duration time.Duration, err error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if _, exists := pm.plugins[name]; !exists {
+		return
+	}
+
+	now := time.Now()
+	health := pm.healthLocked(name)
+	health.Calls++
+	health.LastRun = now
+	health.LastDuration = duration
+
+	if err == nil {
+		health.State = HealthOK
+		health.ConsecutiveFailures = 0
+		return
+	}
+
+	health.LastError = err.Error()
+	health.LastErrorAt = now
+	health.ConsecutiveFailures++
+	health.State = HealthDegraded
+
+	if pm.maxFailures > 0 && health.ConsecutiveFailures >= pm.maxFailures {
+		health.State = HealthFailed
+		config := pm.configs[name]
+		config.Enabled = false
+		pm.configs[name] = config
+	}
+}
@@ -0,0 +1,225 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"syspulse/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+type stubPlugin struct {
+	name string
+}
+
+func (p *stubPlugin) Name() string                                 { return p.name }
+func (p *stubPlugin) Version() string                              { return "1.0.0" }
+func (p *stubPlugin) Description() string                          { return "Stub plugin" }
+func (p *stubPlugin) Author() string                               { return "tests" }
+func (p *stubPlugin) Initialize(config PluginConfig) error         { return nil }
+func (p *stubPlugin) Shutdown() error                              { return nil }
+func (p *stubPlugin) CreateWidget() (tview.Primitive, error)       { return tview.NewTextView(), nil }
+func (p *stubPlugin) UpdateWidget(widget tview.Primitive) error    { return nil }
+func (p *stubPlugin) GetWidgetConfig() WidgetConfig                { return WidgetConfig{} }
+func (p *stubPlugin) CollectData() (map[string]interface{}, error) { return nil, nil }
+func (p *stubPlugin) ExportData() map[string]interface{}           { return nil }
+
+func newStubManager(t *testing.T, callTimeout time.Duration, maxFailures int) *PluginManager {
+	t.Helper()
+	manager := NewPluginManager()
+	manager.SetLimits(callTimeout, maxFailures)
+	if err := manager.LoadPlugin(&stubPlugin{name: "stub"}); err != nil {
+		t.Fatalf("LoadPlugin failed: %v", err)
+	}
+	return manager
+}
+
+func TestCallHealth(t *testing.T) {
+	manager := newStubManager(t, time.Second, 3)
+	ctx := context.Background()
+	failing := func(Plugin) error { return errors.New("daemon not running") }
+
+	if err := manager.Call(ctx, "stub", func(Plugin) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if health, _ := manager.GetHealth("stub"); health.State != HealthOK || health.Calls != 1 {
+		t.Errorf("Expected a healthy plugin after one call, got %+v", health)
+	}
+
+	tests := []struct {
+		state   HealthState
+		enabled bool
+	}{
+		{HealthDegraded, true},
+		{HealthDegraded, true},
+		{HealthFailed, false},
+	}
+	for i, tt := range tests {
+		if err := manager.Call(ctx, "stub", failing); err == nil {
+			t.Fatalf("Call %d: expected the error", i+1)
+		}
+		health, _ := manager.GetHealth("stub")
+		if health.State != tt.state || health.ConsecutiveFailures != i+1 || health.LastError != "daemon not running" {
+			t.Errorf("Call %d: expected %s, got %+v", i+1, tt.state, health)
+		}
+		if manager.IsPluginEnabled("stub") != tt.enabled {
+			t.Errorf("Call %d: expected enabled %v", i+1, tt.enabled)
+		}
+	}
+
+	manager.EnablePlugin("stub")
+	health, _ := manager.GetHealth("stub")
+	if health.State != HealthOK || health.ConsecutiveFailures != 0 || health.LastError == "" {
+		t.Errorf("Expected enabling to reset the failures but keep the last error, got %+v", health)
+	}
+}
+
+func TestCallRecoversPanic(t *testing.T) {
+	manager := newStubManager(t, time.Second, 0)
+
+	err := manager.Call(context.Background(), "stub", func(Plugin) error { panic("nil map") })
+	if err == nil || !strings.Contains(err.Error(), "panicked: nil map") {
+		t.Fatalf("Expected the panic as an error, got %v", err)
+	}
+	if !manager.IsPluginEnabled("stub") {
+		t.Error("Expected a max_failures below 1 to keep the plugin enabled")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	manager := newStubManager(t, 50*time.Millisecond, 0)
+	ctx := context.Background()
+	release := make(chan struct{})
+
+	err := manager.Call(ctx, "stub", func(Plugin) error {
+		<-release
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "did not finish within 50ms") {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	if health, _ := manager.GetHealth("stub"); health.LastDuration < 50*time.Millisecond {
+		t.Errorf("Expected the duration up to the timeout, got %s", health.LastDuration)
+	}
+
+	called := false
+	err = manager.Call(ctx, "stub", func(Plugin) error {
+		called = true
+		return nil
+	})
+	if called || err == nil || !strings.Contains(err.Error(), "still busy") {
+		t.Errorf("Expected the hung plugin not to be called again, got %v", err)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		err = manager.Call(ctx, "stub", func(Plugin) error { return nil })
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Errorf("Expected the plugin to be called once the hung call returned, got %v", err)
+	}
+}
+
+func TestCallCancelled(t *testing.T) {
+	manager := newStubManager(t, time.Second, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	err := manager.Call(ctx, "stub", func(Plugin) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation, got %v", err)
+	}
+	if health, _ := manager.GetHealth("stub"); health.ConsecutiveFailures != 0 || !manager.IsPluginEnabled("stub") {
+		t.Errorf("Expected a cancelled call not to count as a failure, got %+v", health)
+	}
+
+	if err := manager.Call(context.Background(), "missing", func(Plugin) error { return nil }); err == nil {
+		t.Error("Expected an error for an unknown plugin")
+	}
+}
+
+func TestLoadConfiguredPlugin(t *testing.T) {
+	dashboard := &utils.Dashboard{PluginManager: NewPluginManager()}
+	config := &PluginSystemConfig{
+		Plugins: map[string]PluginConfig{
+			"example": {Enabled: false, Settings: map[string]interface{}{"custom_message": "hi"}},
+		},
+	}
+
+	if err := LoadConfiguredPlugin(dashboard, config, "example"); err != nil {
+		t.Fatalf("LoadConfiguredPlugin failed: %v", err)
+	}
+	manager := dashboard.PluginManager.(*PluginManager)
+	if !manager.IsPluginEnabled("Example Plugin") || dashboard.PluginWidgets["Example Plugin"] == nil {
+		t.Error("Expected the plugin to be enabled with a widget")
+	}
+	if loaded, _ := manager.GetPluginConfig("Example Plugin"); loaded.Settings["custom_message"] != "hi" || loaded.Settings["show_time"] != true {
+		t.Errorf("Expected configured settings over defaults, got %v", loaded.Settings)
+	}
+
+	if err := UnloadConfiguredPlugin(dashboard, "example"); err != nil {
+		t.Fatalf("UnloadConfiguredPlugin failed: %v", err)
+	}
+	if _, loaded := manager.GetPlugin("Example Plugin"); loaded || dashboard.PluginWidgets["Example Plugin"] != nil {
+		t.Error("Expected the plugin and its widget to be gone")
+	}
+	if err := UnloadConfiguredPlugin(dashboard, "example"); err != nil {
+		t.Errorf("Expected unloading twice to do nothing, got %v", err)
+	}
+
+	delete(config.Plugins, "example")
+	if err := LoadConfiguredPlugin(dashboard, config, "example"); err != nil {
+		t.Errorf("Expected a plugin without a config entry to load, got %v", err)
+	}
+
+	if err := LoadConfiguredPlugin(dashboard, config, "typo"); err == nil {
+		t.Error("Expected an error for an unknown plugin")
+	}
+}
+
+type panickingPlugin struct{ stubPlugin }
+
+func (p *panickingPlugin) ExportData() map[string]interface{} { panic("broken export") }
+
+func TestExportPluginData(t *testing.T) {
+	dashboard := &utils.Dashboard{PluginManager: NewPluginManager()}
+	if data := ExportPluginData(context.Background(), dashboard); data != nil {
+		t.Errorf("Expected no data without plugins, got %v", data)
+	}
+
+	if err := LoadConfiguredPlugin(dashboard, &PluginSystemConfig{}, "example"); err != nil {
+		t.Fatalf("LoadConfiguredPlugin failed: %v", err)
+	}
+	manager := dashboard.PluginManager.(*PluginManager)
+	manager.LoadPlugin(&panickingPlugin{stubPlugin{name: "broken"}})
+	manager.LoadPlugin(&stubPlugin{name: "disabled"})
+	manager.DisablePlugin("disabled")
+
+	data := ExportPluginData(context.Background(), dashboard)
+	example, ok := data["example"].(map[string]interface{})
+	if len(data) != 1 || !ok || example["initialized"] != true {
+		t.Errorf("Expected only the example plugin under its configured name, got %v", data)
+	}
+
+	if health, _ := manager.GetHealth("broken"); health.ConsecutiveFailures != 1 {
+		t.Errorf("Expected the panicking export to count as a failure, got %+v", health)
+	}
+
+	if PluginKey("Example Plugin") != "example" || PluginKey("uptime") != "uptime" {
+		t.Error("Expected PluginKey to reverse PluginName")
+	}
+}
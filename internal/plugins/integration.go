@@ -1,8 +1,11 @@
 package plugins
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"syspulse/internal/utils"
 	"time"
 
@@ -10,14 +13,28 @@ import (
 )
 
 func InitializePluginSystem(dashboard *utils.Dashboard) error {
-	dashboard.PluginManager = NewPluginManager()
-	dashboard.PluginWidgets = make(map[string]tview.Primitive)
+	err := InitializeHeadless(dashboard)
 
-	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
-	if !ok {
-		return fmt.Errorf("failed to create plugin manager")
+	pluginManager := dashboard.PluginManager.(*PluginManager)
+	for _, plugin := range pluginManager.GetAllPlugins() {
+		attachRedraw(dashboard, plugin)
 	}
 
+	widgets := pluginManager.CreateWidgets()
+	for name, widget := range widgets {
+		dashboard.PluginWidgets[name] = widget
+	}
+
+	return err
+}
+
+// InitializeHeadless loads the enabled plugins without creating widgets, for
+// commands that only collect their data.
+func InitializeHeadless(dashboard *utils.Dashboard) error {
+	pluginManager := NewPluginManager()
+	dashboard.PluginManager = pluginManager
+	dashboard.PluginWidgets = make(map[string]tview.Primitive)
+
 	pluginConfig, err := LoadPluginConfig("plugins_config.json")
 	if err != nil {
 		log.Printf("Failed to load plugin config: %v", err)
@@ -28,45 +45,193 @@ func InitializePluginSystem(dashboard *utils.Dashboard) error {
 				UpdateInterval:      2,
 				EnableNotifications: false,
 				PluginDirectory:     "./plugins",
+				MaxFailures:         DefaultMaxFailures,
 			},
 		}
 	}
 
-	if IsPluginEnabledInConfig(pluginConfig, "example") {
-		examplePlugin := NewExamplePlugin()
-		if config, exists := GetPluginConfigFromFile(pluginConfig, "example"); exists {
-			if err := pluginManager.LoadPluginWithConfig(examplePlugin, config); err != nil {
-				log.Printf("Failed to load example plugin: %v", err)
-			}
-		} else {
-			if err := pluginManager.LoadPlugin(examplePlugin); err != nil {
-				log.Printf("Failed to load example plugin: %v", err)
-			}
+	pluginManager.SetLimits(time.Duration(pluginConfig.PluginSettings.CallTimeout)*time.Second, pluginConfig.PluginSettings.MaxFailures)
+	return loadPlugins(pluginManager, pluginConfig)
+}
+
+// loadPlugins loads every enabled plugin in the config from the registry or
+// the plugin directory. With auto_load, executables in the plugin directory
+// without a config entry are loaded as well. Entries naming no known plugin
+// are reported together once the rest are loaded.
+func loadPlugins(pluginManager *PluginManager, pluginConfig *PluginSystemConfig) error {
+	external, err := discoverExternal(pluginConfig.PluginSettings.PluginDirectory)
+	if err != nil {
+		log.Printf("Failed to discover plugins: %v", err)
+	}
+
+	names := make([]string, 0, len(pluginConfig.Plugins))
+	for name := range pluginConfig.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		plugin, err := NewPlugin(name, external)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		config := pluginConfig.Plugins[name]
+		if !config.Enabled {
+			continue
+		}
+		if err := pluginManager.LoadPluginWithConfig(plugin, withDefaults(name, config)); err != nil {
+			log.Printf("Failed to load plugin %s: %v", name, err)
+			plugin.Shutdown()
 		}
 	}
 
-	if IsPluginEnabledInConfig(pluginConfig, "docker") {
-		dockerPlugin := NewDockerPlugin()
-		if config, exists := GetPluginConfigFromFile(pluginConfig, "docker"); exists {
-			if err := pluginManager.LoadPluginWithConfig(dockerPlugin, config); err != nil {
-				log.Printf("Failed to load Docker plugin: %v", err)
+	if pluginConfig.PluginSettings.AutoLoad {
+		for _, name := range sortedKeys(external) {
+			if _, exists := pluginConfig.Plugins[name]; exists {
+				continue
 			}
-		} else {
-			if err := pluginManager.LoadPlugin(dockerPlugin); err != nil {
-				log.Printf("Failed to load Docker plugin: %v", err)
+			if err := pluginManager.LoadPlugin(external[name]); err != nil {
+				log.Printf("Failed to load plugin %s: %v", name, err)
+				external[name].Shutdown()
 			}
 		}
 	}
 
-	widgets := pluginManager.CreateWidgets()
-	for name, widget := range widgets {
-		dashboard.PluginWidgets[name] = widget
+	return errors.Join(errs...)
+}
+
+// discoverExternal returns the executables in the plugin directory by name.
+// Registered plugins take precedence over executables of the same name.
+func discoverExternal(dir string) (map[string]*ExternalPlugin, error) {
+	found, err := DiscoverPlugins(dir)
+	external := make(map[string]*ExternalPlugin, len(found))
+	for _, plugin := range found {
+		if _, registered := Lookup(plugin.Name()); registered {
+			log.Printf("Skipping external plugin %s: the name is taken by a built-in plugin", plugin.Path())
+			continue
+		}
+		external[plugin.Name()] = plugin
+	}
+	return external, err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
+// ShutdownPluginSystem shuts every loaded plugin down, which stops the
+// processes of external plugins.
+func ShutdownPluginSystem(dashboard *utils.Dashboard) error {
+	if dashboard.PluginManager == nil {
+		return nil
+	}
+
+	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
+	if !ok {
+		return fmt.Errorf("invalid plugin manager type")
+	}
+
+	return pluginManager.ShutdownAll()
+}
+
+// PluginName returns the name a plugin configured as key is loaded under:
+// built-in plugins have a display name of their own, external plugins are
+// named after their file.
+func PluginName(key string) string {
+	if registration, exists := Lookup(key); exists {
+		return registration.Factory().Name()
+	}
+	return key
+}
+
+// PluginKey returns the name a loaded plugin is configured as, the reverse of
+// PluginName.
+func PluginKey(name string) string {
+	for _, registration := range Registered() {
+		if registration.Factory().Name() == name {
+			return registration.Name
+		}
+	}
+	return name
+}
+
+// LoadConfiguredPlugin loads the plugin configured as key in config and
+// creates its widget, for plugins enabled while the dashboard runs. The
+// plugin is loaded even if its entry is disabled or missing.
+func LoadConfiguredPlugin(dashboard *utils.Dashboard, config *PluginSystemConfig, key string) error {
+	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
+	if !ok {
+		return fmt.Errorf("plugin manager not initialized")
+	}
+
+	external, err := discoverExternal(config.PluginSettings.PluginDirectory)
+	if err != nil {
+		log.Printf("Failed to discover plugins: %v", err)
+	}
+	plugin, err := NewPlugin(key, external)
+	if err != nil {
+		return err
+	}
+
+	pluginConfig, exists := config.Plugins[key]
+	if !exists {
+		pluginConfig = DefaultPluginConfig(key)
+	}
+	pluginConfig.Enabled = true
+
+	if err := pluginManager.LoadPluginWithConfig(plugin, withDefaults(key, pluginConfig)); err != nil {
+		plugin.Shutdown()
+		return err
+	}
+
+	attachRedraw(dashboard, plugin)
+	widget, err := pluginManager.CreateWidget(plugin.Name())
+	if err != nil {
+		pluginManager.UnloadPlugin(plugin.Name())
+		return err
+	}
+	if dashboard.PluginWidgets == nil {
+		dashboard.PluginWidgets = make(map[string]tview.Primitive)
+	}
+	dashboard.PluginWidgets[plugin.Name()] = widget
 	return nil
 }
 
-func StartPluginUpdateWorker(dashboard *utils.Dashboard) {
+func attachRedraw(dashboard *utils.Dashboard, plugin Plugin) {
+	if redrawer, ok := plugin.(Redrawer); ok && dashboard.App != nil {
+		redrawer.SetRedraw(func(f func()) {
+			dashboard.App.QueueUpdateDraw(f)
+		})
+	}
+}
+
+// UnloadConfiguredPlugin shuts the plugin configured as key down and drops
+// its widget. Unloading a plugin that is not loaded does nothing.
+func UnloadConfiguredPlugin(dashboard *utils.Dashboard, key string) error {
+	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
+	if !ok {
+		return fmt.Errorf("plugin manager not initialized")
+	}
+
+	name := PluginName(key)
+	if _, loaded := pluginManager.GetPlugin(name); !loaded {
+		return nil
+	}
+	delete(dashboard.PluginWidgets, name)
+	return pluginManager.UnloadPlugin(name)
+}
+
+// StartPluginWorkers updates the widget of every enabled plugin on its own
+// interval until ctx is cancelled.
+func StartPluginWorkers(ctx context.Context, dashboard *utils.Dashboard) {
 	if dashboard.PluginManager == nil {
 		return
 	}
@@ -76,37 +241,85 @@ func StartPluginUpdateWorker(dashboard *utils.Dashboard) {
 		return
 	}
 
-	pluginInfo := pluginManager.GetPluginInfo()
-	for _, info := range pluginInfo {
-		if info.Config.Enabled {
-			plugin, exists := pluginManager.GetPlugin(info.Name)
-			if exists {
-				startPluginWorker(dashboard, plugin, info.Config)
-			}
-		}
+	for _, plugin := range pluginManager.GetAllPlugins() {
+		StartPluginWorker(ctx, dashboard, plugin.Name())
+	}
+}
+
+// StartPluginWorker updates the widget of a loaded plugin until ctx is
+// cancelled or the plugin is disabled, unloaded or reloaded. Updates go
+// through PluginManager.Call, so a slow or panicking plugin cannot stall the
+// dashboard.
+func StartPluginWorker(ctx context.Context, dashboard *utils.Dashboard, name string) {
+	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
+	if !ok {
+		return
 	}
+
+	config, exists := pluginManager.GetPluginConfig(name)
+	widget, hasWidget := pluginManager.GetWidget(name)
+	if !exists || !config.Enabled || !hasWidget {
+		return
+	}
+
+	go runPluginWorker(ctx, dashboard, pluginManager, name, widget, config.Layout.UpdateInterval)
 }
 
-func startPluginWorker(dashboard *utils.Dashboard, plugin Plugin, config PluginConfig) {
-	interval := config.Layout.UpdateInterval
+func runPluginWorker(ctx context.Context, dashboard *utils.Dashboard, pluginManager *PluginManager, name string, widget tview.Primitive, interval int) {
 	if interval <= 0 {
 		interval = 5
 	}
 
-	go func() {
-		ticker := time.NewTicker(time.Duration(interval) * time.Second)
-		defer ticker.Stop()
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
 
-		for range ticker.C {
-			if dashboard.PluginManager != nil {
-				if pluginManager, ok := dashboard.PluginManager.(*PluginManager); ok {
-					if widget, exists := pluginManager.GetWidget(plugin.Name()); exists {
-						plugin.UpdateWidget(widget)
-					}
-				}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// A reloaded plugin has a new widget and a worker of its own.
+		if current, exists := pluginManager.GetWidget(name); !exists || current != widget {
+			return
+		}
+		if !pluginManager.IsPluginEnabled(name) {
+			return
+		}
+
+		err := pluginManager.Call(ctx, name, func(plugin Plugin) error {
+			return plugin.UpdateWidget(widget)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		enabled := pluginManager.IsPluginEnabled(name)
+		dashboard.App.QueueUpdateDraw(func() {
+			if err != nil {
+				showPluginError(widget, name, err, enabled)
 			}
+		})
+		if !enabled {
+			return
 		}
-	}()
+	}
+}
+
+// showPluginError replaces the content of a text widget with the error of
+// its plugin.
+func showPluginError(widget tview.Primitive, name string, err error, enabled bool) {
+	textView, ok := widget.(*tview.TextView)
+	if !ok {
+		return
+	}
+
+	if !enabled {
+		textView.SetText(fmt.Sprintf("[red]%s was disabled after repeated failures[white]\n\n%s", name, tview.Escape(err.Error())))
+		return
+	}
+	textView.SetText(fmt.Sprintf("[yellow]%s failed to update[white]\n\n%s", name, tview.Escape(err.Error())))
 }
 
 func AddPluginWidgetsToLayout(dashboard *utils.Dashboard, mainFlex *tview.Flex) {
@@ -156,42 +369,6 @@ func AddPluginWidgetsToGrid(dashboard *utils.Dashboard, grid *tview.Grid) {
 	}
 }
 
-func ShowPluginManagerModal(dashboard *utils.Dashboard) {
-	if dashboard.PluginManager == nil {
-		return
-	}
-
-	pluginManager, ok := dashboard.PluginManager.(*PluginManager)
-	if !ok {
-		return
-	}
-
-	modal := tview.NewModal()
-	modal.SetTitle("Plugin Manager")
-	modal.SetBorder(true)
-
-	pluginInfo := pluginManager.GetPluginInfo()
-
-	text := "Loaded Plugins:\n\n"
-	for _, info := range pluginInfo {
-		status := "Disabled"
-		if info.Config.Enabled {
-			status = "Enabled"
-		}
-		text += fmt.Sprintf("• %s v%s - %s\n", info.Name, info.Version, status)
-		text += fmt.Sprintf("  %s\n", info.Description)
-		text += fmt.Sprintf("  Author: %s\n\n", info.Author)
-	}
-
-	modal.SetText(text)
-	modal.AddButtons([]string{"Close"})
-	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		dashboard.App.SetRoot(dashboard.MainWidget, true)
-	})
-
-	dashboard.App.SetRoot(modal, true)
-}
-
 func EnablePlugin(dashboard *utils.Dashboard, pluginName string) error {
 	if dashboard.PluginManager == nil {
 		return fmt.Errorf("plugin manager not initialized")
@@ -249,7 +426,11 @@ func GetPluginData(dashboard *utils.Dashboard, pluginName string) (map[string]in
 	return plugin.CollectData()
 }
 
-func ExportPluginData(dashboard *utils.Dashboard) map[string]interface{} {
+// ExportPluginData returns the ExportData of every enabled plugin, keyed by
+// the name it is configured as. Exports go through PluginManager.Call, so a
+// plugin that fails, panics or hangs is left out and counted against its
+// health.
+func ExportPluginData(ctx context.Context, dashboard *utils.Dashboard) map[string]interface{} {
 	if dashboard.PluginManager == nil {
 		return nil
 	}
@@ -260,16 +441,27 @@ func ExportPluginData(dashboard *utils.Dashboard) map[string]interface{} {
 	}
 
 	data := make(map[string]interface{})
-	pluginInfo := pluginManager.GetPluginInfo()
+	for _, info := range pluginManager.GetPluginInfo() {
+		if !info.Config.Enabled {
+			continue
+		}
 
-	for _, info := range pluginInfo {
-		if info.Config.Enabled {
-			plugin, exists := pluginManager.GetPlugin(info.Name)
-			if exists {
-				data[info.Name] = plugin.ExportData()
-			}
+		var exported map[string]interface{}
+		err := pluginManager.Call(ctx, info.Name, func(plugin Plugin) error {
+			exported = plugin.ExportData()
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to export plugin %s: %v", info.Name, err)
+			continue
+		}
+		if exported != nil {
+			data[PluginKey(info.Name)] = exported
 		}
 	}
 
+	if len(data) == 0 {
+		return nil
+	}
 	return data
 }
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"sync"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -52,9 +53,43 @@ type PluginInfo struct {
 	Config      PluginConfig           `json:"config"`
 	Widget      tview.Primitive        `json:"-"`
 	Data        map[string]interface{} `json:"-"`
+	Health      Health                 `json:"health"`
 	LastUpdate  time.Time              `json:"last_update"`
 }
 
+// Redrawer is implemented by plugins to change their widget from UpdateWidget,
+// which runs on a worker goroutine, or once an action finishes in the
+// background. queue runs f on the UI goroutine and redraws.
+type Redrawer interface {
+	SetRedraw(queue func(f func()))
+}
+
+// redrawer implements Redrawer for embedding in plugins. Until SetRedraw is
+// called, as in tests, queueDraw runs f right away.
+type redrawer struct {
+	mu    sync.Mutex
+	queue func(f func())
+}
+
+func (r *redrawer) SetRedraw(queue func(f func())) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queue = queue
+}
+
+func (r *redrawer) queueDraw(f func()) {
+	r.mu.Lock()
+	queue := r.queue
+	r.mu.Unlock()
+
+	if queue == nil {
+		f()
+		return
+	}
+	queue(f)
+}
+
 type InputHandler interface {
 	HandleInput(event *tcell.EventKey) *tcell.EventKey
 }
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -12,7 +13,11 @@ type PluginManager struct {
 	plugins map[string]Plugin
 	configs map[string]PluginConfig
 	widgets map[string]tview.Primitive
+	health  map[string]*Health
 	mutex   sync.RWMutex
+
+	callTimeout time.Duration
+	maxFailures int
 }
 
 func NewPluginManager() *PluginManager {
@@ -20,20 +25,17 @@ func NewPluginManager() *PluginManager {
 		plugins: make(map[string]Plugin),
 		configs: make(map[string]PluginConfig),
 		widgets: make(map[string]tview.Primitive),
-	}
-}
-
-func (pm *PluginManager) LoadPlugin(plugin Plugin) error {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
-	name := plugin.Name()
+		health:  make(map[string]*Health),
 
-	if _, exists := pm.plugins[name]; exists {
-		return fmt.Errorf("plugin %s already loaded", name)
+		callTimeout: DefaultCallTimeout,
+		maxFailures: DefaultMaxFailures,
 	}
+}
 
-	config := PluginConfig{
+// DefaultPluginConfig is the config of a plugin loaded without an entry in
+// plugins_config.json.
+func DefaultPluginConfig(name string) PluginConfig {
+	return PluginConfig{
 		Name:     name,
 		Enabled:  true,
 		Settings: make(map[string]interface{}),
@@ -48,6 +50,19 @@ func (pm *PluginManager) LoadPlugin(plugin Plugin) error {
 			UpdateInterval: 5,
 		},
 	}
+}
+
+func (pm *PluginManager) LoadPlugin(plugin Plugin) error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	name := plugin.Name()
+
+	if _, exists := pm.plugins[name]; exists {
+		return fmt.Errorf("plugin %s already loaded", name)
+	}
+
+	config := DefaultPluginConfig(name)
 
 	if err := plugin.Initialize(config); err != nil {
 		return fmt.Errorf("failed to initialize plugin %s: %w", name, err)
@@ -97,10 +112,29 @@ func (pm *PluginManager) UnloadPlugin(name string) error {
 	delete(pm.plugins, name)
 	delete(pm.configs, name)
 	delete(pm.widgets, name)
+	delete(pm.health, name)
 
 	return nil
 }
 
+func (pm *PluginManager) ShutdownAll() error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	var errs []error
+	for name, plugin := range pm.plugins {
+		if err := plugin.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown plugin %s: %w", name, err))
+		}
+	}
+
+	pm.plugins = make(map[string]Plugin)
+	pm.configs = make(map[string]PluginConfig)
+	pm.widgets = make(map[string]tview.Primitive)
+	pm.health = make(map[string]*Health)
+	return errors.Join(errs...)
+}
+
 func (pm *PluginManager) GetPlugin(name string) (Plugin, bool) {
 	pm.mutex.RLock()
 	defer pm.mutex.RUnlock()
@@ -139,6 +173,24 @@ func (pm *PluginManager) CreateWidgets() map[string]tview.Primitive {
 	return widgets
 }
 
+// CreateWidget creates the widget of a plugin loaded after CreateWidgets ran.
+func (pm *PluginManager) CreateWidget(name string) (tview.Primitive, error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	plugin, exists := pm.plugins[name]
+	if !exists {
+		return nil, fmt.Errorf("plugin %s not found", name)
+	}
+
+	widget, err := plugin.CreateWidget()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create widget for plugin %s: %w", name, err)
+	}
+	pm.widgets[name] = widget
+	return widget, nil
+}
+
 func (pm *PluginManager) EnablePlugin(name string) error {
 	pm.mutex.Lock()
 	defer pm.mutex.Unlock()
@@ -151,6 +203,13 @@ func (pm *PluginManager) EnablePlugin(name string) error {
 	config.Enabled = true
 	pm.configs[name] = config
 
+	// Enabling a plugin gives it a fresh start after it was disabled for
+	// failing; a call still hanging keeps it busy.
+	if health, exists := pm.health[name]; exists {
+		health.State = HealthOK
+		health.ConsecutiveFailures = 0
+	}
+
 	return nil
 }
 
@@ -208,6 +267,11 @@ func (pm *PluginManager) GetPluginInfo() []PluginInfo {
 			data = pluginData
 		}
 
+		health := Health{State: HealthOK}
+		if h, exists := pm.health[name]; exists {
+			health = *h
+		}
+
 		pluginInfo := PluginInfo{
 			Name:        plugin.Name(),
 			Version:     plugin.Version(),
@@ -216,6 +280,7 @@ func (pm *PluginManager) GetPluginInfo() []PluginInfo {
 			Config:      config,
 			Widget:      widget,
 			Data:        data,
+			Health:      health,
 			LastUpdate:  time.Now(),
 		}
 
@@ -0,0 +1,232 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Factory creates an uninitialized plugin.
+type Factory func() Plugin
+
+type SettingType string
+
+const (
+	SettingBool   SettingType = "bool"
+	SettingInt    SettingType = "int"
+	SettingFloat  SettingType = "float"
+	SettingString SettingType = "string"
+)
+
+// SettingSchema describes one key of a plugin's settings.
+type SettingSchema struct {
+	Key         string      `json:"key"`
+	Type        SettingType `json:"type"`
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+}
+
+// Registration is a plugin known to SysPulse, under the name it is
+// configured with in plugins_config.json.
+type Registration struct {
+	Name     string
+	Factory  Factory
+	Settings []SettingSchema
+}
+
+// Defaults returns the default value of every setting in the schema.
+func (r Registration) Defaults() map[string]interface{} {
+	defaults := make(map[string]interface{}, len(r.Settings))
+	for _, setting := range r.Settings {
+		defaults[setting.Key] = setting.Default
+	}
+	return defaults
+}
+
+var registry = struct {
+	sync.RWMutex
+	entries map[string]Registration
+}{entries: make(map[string]Registration)}
+
+// Register makes a plugin available under name. It is meant to be called
+// from init() and panics when the name is taken, like database/sql drivers.
+func Register(name string, factory Factory, settings ...SettingSchema) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if factory == nil {
+		panic("plugins: Register factory is nil for " + name)
+	}
+	if _, exists := registry.entries[name]; exists {
+		panic("plugins: Register called twice for " + name)
+	}
+	registry.entries[name] = Registration{Name: name, Factory: factory, Settings: settings}
+}
+
+func Lookup(name string) (Registration, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	registration, exists := registry.entries[name]
+	return registration, exists
+}
+
+// Registered returns every registered plugin sorted by name.
+func Registered() []Registration {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	registrations := make([]Registration, 0, len(registry.entries))
+	for _, registration := range registry.entries {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Name < registrations[j].Name
+	})
+	return registrations
+}
+
+// NewPlugin creates the plugin configured as name: a registered plugin, or
+// else an external plugin from the plugin directory.
+func NewPlugin(name string, external map[string]*ExternalPlugin) (Plugin, error) {
+	if registration, exists := Lookup(name); exists {
+		return registration.Factory(), nil
+	}
+	if plugin, exists := external[name]; exists {
+		return plugin, nil
+	}
+
+	var registered []string
+	for _, registration := range Registered() {
+		registered = append(registered, registration.Name)
+	}
+	return nil, fmt.Errorf("unknown plugin %q: not a registered plugin (%s) or an executable in the plugin directory", name, strings.Join(registered, ", "))
+}
+
+// SettingsSchema returns the settings schema of the plugin configured as
+// name. Plugins without a registered schema, like external ones, get one
+// inferred from the settings they are configured with.
+func SettingsSchema(name string, settings map[string]interface{}) []SettingSchema {
+	if registration, exists := Lookup(name); exists && len(registration.Settings) > 0 {
+		return registration.Settings
+	}
+
+	schema := make([]SettingSchema, 0, len(settings))
+	for _, key := range sortedKeys(settings) {
+		setting := SettingSchema{Key: key, Type: SettingString, Default: settings[key]}
+		switch settings[key].(type) {
+		case bool:
+			setting.Type = SettingBool
+		case int, int64:
+			setting.Type = SettingInt
+		case float64:
+			setting.Type = SettingFloat
+		}
+		schema = append(schema, setting)
+	}
+	return schema
+}
+
+// ParseSetting converts text entered for a setting to the setting's type.
+func ParseSetting(setting SettingSchema, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch setting.Type {
+	case SettingBool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", setting.Key)
+		}
+		return value, nil
+	case SettingInt:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number", setting.Key)
+		}
+		return value, nil
+	case SettingFloat:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", setting.Key)
+		}
+		return value, nil
+	}
+	return text, nil
+}
+
+// withDefaults fills settings missing from config with the defaults of the
+// registered schema.
+func withDefaults(name string, config PluginConfig) PluginConfig {
+	registration, exists := Lookup(name)
+	if !exists || len(registration.Settings) == 0 {
+		return config
+	}
+
+	settings := registration.Defaults()
+	for key, value := range config.Settings {
+		settings[key] = value
+	}
+	config.Settings = settings
+	return config
+}
+
+// Listing describes a plugin for `syspulse plugins list`.
+type Listing struct {
+	Name        string
+	Version     string
+	Kind        string
+	State       string
+	Description string
+}
+
+// List describes the registered plugins and the executables in the plugin
+// directory, and whether the config enables them. External plugins are not
+// started, so their version is unknown. Config entries naming no known plugin
+// are returned as an error next to the listing.
+func List(config *PluginSystemConfig) ([]Listing, error) {
+	external, err := discoverExternal(config.PluginSettings.PluginDirectory)
+
+	state := func(name string, autoLoad bool) string {
+		entry, exists := config.Plugins[name]
+		switch {
+		case exists && entry.Enabled:
+			return "enabled"
+		case exists:
+			return "disabled"
+		case autoLoad:
+			return "auto-load"
+		}
+		return "not configured"
+	}
+
+	var listings []Listing
+	for _, registration := range Registered() {
+		plugin := registration.Factory()
+		listings = append(listings, Listing{
+			Name:        registration.Name,
+			Version:     plugin.Version(),
+			Kind:        "built-in",
+			State:       state(registration.Name, false),
+			Description: plugin.Description(),
+		})
+	}
+	for _, name := range sortedKeys(external) {
+		listings = append(listings, Listing{
+			Name:        name,
+			Version:     "-",
+			Kind:        "external",
+			State:       state(name, config.PluginSettings.AutoLoad),
+			Description: external[name].Path(),
+		})
+	}
+
+	errs := []error{err}
+	for _, name := range sortedKeys(config.Plugins) {
+		if _, err := NewPlugin(name, external); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return listings, errors.Join(errs...)
+}
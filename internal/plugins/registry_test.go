@@ -0,0 +1,125 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	names := make([]string, 0)
+	for _, registration := range Registered() {
+		names = append(names, registration.Name)
+	}
+	if strings.Join(names, ",") != "docker,example,systemd" {
+		t.Errorf("Expected the built-in plugins to be registered, got %v", names)
+	}
+
+	plugin, err := NewPlugin("example", nil)
+	if err != nil || plugin.Name() != "Example Plugin" {
+		t.Errorf("Expected the example plugin, got %v, %v", plugin, err)
+	}
+
+	external := map[string]*ExternalPlugin{"uptime": NewExternalPlugin("/plugins/uptime.sh")}
+	if plugin, err := NewPlugin("uptime", external); err != nil || plugin.Name() != "uptime" {
+		t.Errorf("Expected the external plugin, got %v, %v", plugin, err)
+	}
+
+	_, err = NewPlugin("dokcer", external)
+	if err == nil || !strings.Contains(err.Error(), `unknown plugin "dokcer"`) || !strings.Contains(err.Error(), "docker, example, systemd") {
+		t.Errorf("Expected a clear error for an unknown plugin, got %v", err)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	Register("example", func() Plugin { return NewExamplePlugin() })
+}
+
+func TestWithDefaults(t *testing.T) {
+	config := withDefaults("docker", PluginConfig{Settings: map[string]interface{}{"container_limit": 10.0}})
+	if config.Settings["container_limit"] != 10.0 || config.Settings["image_limit"] != 5 || config.Settings["show_images"] != true {
+		t.Errorf("Unexpected settings %v", config.Settings)
+	}
+
+	config = withDefaults("uptime", PluginConfig{})
+	if config.Settings != nil {
+		t.Errorf("Expected unregistered plugins to keep their settings, got %v", config.Settings)
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPlugin(t, dir, "uptime", "ok")
+
+	config := &PluginSystemConfig{
+		Plugins: map[string]PluginConfig{
+			"example": {Enabled: true},
+			"docker":  {Enabled: false},
+			"typo":    {Enabled: true},
+		},
+		PluginSettings: PluginSettings{AutoLoad: true, PluginDirectory: dir},
+	}
+
+	listings, err := List(config)
+	if err == nil || !strings.Contains(err.Error(), `unknown plugin "typo"`) {
+		t.Errorf("Expected the unknown entry to be reported, got %v", err)
+	}
+
+	expected := []Listing{
+		{Name: "docker", Version: "2.0.0", Kind: "built-in", State: "disabled"},
+		{Name: "example", Version: "1.0.0", Kind: "built-in", State: "enabled"},
+		{Name: "systemd", Version: "1.0.0", Kind: "built-in", State: "not configured"},
+		{Name: "uptime", Version: "-", Kind: "external", State: "auto-load"},
+	}
+	if len(listings) != len(expected) {
+		t.Fatalf("Expected %d listings, got %+v", len(expected), listings)
+	}
+	for i, want := range expected {
+		got := listings[i]
+		if got.Name != want.Name || got.Version != want.Version || got.Kind != want.Kind || got.State != want.State {
+			t.Errorf("Listing %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestSettingsSchema(t *testing.T) {
+	if schema := SettingsSchema("docker", nil); len(schema) != 6 || schema[0].Key != "host" || schema[5].Key != "logs_tail" {
+		t.Errorf("Expected the registered schema, got %v", schema)
+	}
+
+	schema := SettingsSchema("uptime", map[string]interface{}{"verbose": true, "limit": 3.0, "label": "up"})
+	var got []string
+	for _, setting := range schema {
+		got = append(got, setting.Key+":"+string(setting.Type))
+	}
+	if strings.Join(got, ",") != "label:string,limit:float,verbose:bool" {
+		t.Errorf("Expected a schema inferred from the settings, got %v", got)
+	}
+}
+
+func TestParseSetting(t *testing.T) {
+	tests := []struct {
+		setting SettingSchema
+		text    string
+		want    interface{}
+		wantErr bool
+	}{
+		{SettingSchema{Key: "show", Type: SettingBool}, "true", true, false},
+		{SettingSchema{Key: "show", Type: SettingBool}, "yes", nil, true},
+		{SettingSchema{Key: "limit", Type: SettingInt}, " 12 ", 12, false},
+		{SettingSchema{Key: "limit", Type: SettingInt}, "1.5", nil, true},
+		{SettingSchema{Key: "ratio", Type: SettingFloat}, "0.25", 0.25, false},
+		{SettingSchema{Key: "message", Type: SettingString}, "hi there", "hi there", false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSetting(tt.setting, tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSetting(%s, %q) = %v, %v; want %v", tt.setting.Type, tt.text, got, err, tt.want)
+		}
+	}
+}
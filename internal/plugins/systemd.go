@@ -0,0 +1,482 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const systemdKeys = "[gray]o start | x stop | r restart (press twice)[white]"
+
+type SystemdPlugin struct {
+	redrawer
+
+	config  PluginConfig
+	connect func() (systemdBus, error)
+	bus     systemdBus
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	units   []SystemdUnit
+	stats   SystemdStats
+	cgroups map[string]string
+	cpu     map[string]systemdCPUSample
+	status  string
+	pending pendingAction
+}
+
+type SystemdUnit struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	LoadState    string  `json:"load_state"`
+	ActiveState  string  `json:"active_state"`
+	SubState     string  `json:"sub_state"`
+	ControlGroup string  `json:"control_group,omitempty"`
+	Memory       uint64  `json:"memory_bytes"`
+	HasMemory    bool    `json:"-"`
+	CPUPercent   float64 `json:"cpu_percent"`
+}
+
+type SystemdStats struct {
+	Units  int `json:"units"`
+	Active int `json:"active"`
+	Failed int `json:"failed"`
+}
+
+// systemdCPUSample is the CPU time of a unit's cgroup when it was last read.
+type systemdCPUSample struct {
+	usage time.Duration
+	at    time.Time
+}
+
+type systemdWidget struct {
+	*tview.Flex
+	summary *tview.TextView
+	table   *tview.Table
+	names   []string
+}
+
+func init() {
+	Register("systemd", func() Plugin { return NewSystemdPlugin() },
+		SettingSchema{Key: "unit_types", Type: SettingString, Default: "service", Description: "Comma-separated unit types to list, like service,timer; empty lists all"},
+		SettingSchema{Key: "show_inactive", Type: SettingBool, Default: false, Description: "List inactive units too; failed units are always listed"},
+		SettingSchema{Key: "filter", Type: SettingString, Default: "", Description: "Only list units whose name contains this text"},
+		SettingSchema{Key: "cgroup_root", Type: SettingString, Default: cgroups.DefaultRoot, Description: "Where the cgroup hierarchy is mounted"},
+	)
+}
+
+func NewSystemdPlugin() *SystemdPlugin {
+	return &SystemdPlugin{
+		connect: connectSystemd,
+		units:   make([]SystemdUnit, 0),
+		cgroups: make(map[string]string),
+		cpu:     make(map[string]systemdCPUSample),
+	}
+}
+
+func (p *SystemdPlugin) Name() string {
+	return "Systemd Units"
+}
+
+func (p *SystemdPlugin) Version() string {
+	return "1.0.0"
+}
+
+func (p *SystemdPlugin) Description() string {
+	return "List systemd units with their cgroup memory and CPU, and start, stop or restart them"
+}
+
+func (p *SystemdPlugin) Author() string {
+	return "drclcomputers @ SysPulse"
+}
+
+func (p *SystemdPlugin) Initialize(config PluginConfig) error {
+	bus, err := p.connect()
+	if err != nil {
+		return fmt.Errorf("systemd is not available: %w", err)
+	}
+	if _, err := bus.ListUnits(context.Background()); err != nil {
+		bus.Close()
+		return fmt.Errorf("systemd is not available: %w", err)
+	}
+
+	p.Shutdown()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = config
+	p.bus = bus
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+// Shutdown cancels running actions and closes the bus connection.
+func (p *SystemdPlugin) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+	var err error
+	if p.bus != nil {
+		err = p.bus.Close()
+		p.bus = nil
+	}
+	p.cgroups = make(map[string]string)
+	p.cpu = make(map[string]systemdCPUSample)
+	return err
+}
+
+func (p *SystemdPlugin) CreateWidget() (tview.Primitive, error) {
+	w := &systemdWidget{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		summary: tview.NewTextView().SetDynamicColors(true),
+		table:   tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+	}
+	w.AddItem(w.summary, 2, 0, false).
+		AddItem(w.table, 0, 1, true)
+
+	utils.SetBorderStyle(w.Box)
+	w.SetTitle(p.config.Layout.Title)
+	if p.config.Layout.BorderColor != "" {
+		w.SetBorderColor(utils.GetColorFromName(p.config.Layout.BorderColor))
+	}
+	if p.config.Layout.ForegroundColor != "" {
+		w.SetTitleColor(utils.GetColorFromName(p.config.Layout.ForegroundColor))
+	}
+
+	w.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return p.handleKey(w, event)
+	})
+
+	if err := p.refresh(); err != nil {
+		return nil, err
+	}
+	p.render(w)
+
+	return w, nil
+}
+
+func (p *SystemdPlugin) UpdateWidget(widget tview.Primitive) error {
+	w, ok := widget.(*systemdWidget)
+	if !ok {
+		return fmt.Errorf("widget is not a systemd widget")
+	}
+
+	err := p.refresh()
+	p.queueDraw(func() {
+		if err != nil {
+			w.summary.SetText(fmt.Sprintf("[red]Error updating units: %s[white]", tview.Escape(err.Error())))
+			return
+		}
+		p.render(w)
+	})
+	return err
+}
+
+func (p *SystemdPlugin) GetWidgetConfig() WidgetConfig {
+	return p.config.Layout
+}
+
+func (p *SystemdPlugin) CollectData() (map[string]interface{}, error) {
+	if err := p.refresh(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return map[string]interface{}{
+		"units":        p.units,
+		"stats":        p.stats,
+		"last_updated": time.Now(),
+	}, nil
+}
+
+func (p *SystemdPlugin) ExportData() map[string]interface{} {
+	data, _ := p.CollectData()
+	return data
+}
+
+func (p *SystemdPlugin) UpdateInterval() time.Duration {
+	return 5 * time.Second
+}
+
+// listsUnit reports whether a unit passes the type, state and name filters
+// of config. Failed units are listed even when inactive ones are not.
+func listsUnit(config PluginConfig, unit systemdUnitStatus) bool {
+	if types := stringSetting(config, "unit_types", "service"); strings.TrimSpace(types) != "" {
+		matched := false
+		for _, unitType := range strings.Split(types, ",") {
+			if strings.TrimPrefix(filepath.Ext(unit.Name), ".") == strings.TrimSpace(unitType) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if filter := stringSetting(config, "filter", ""); filter != "" && !strings.Contains(unit.Name, filter) {
+		return false
+	}
+	return unit.ActiveState != "inactive" || boolSetting(config, "show_inactive", false)
+}
+
+// refresh lists the units and reads the memory and CPU of the active ones
+// from their cgroups. CPU is relative to one core, between two refreshes.
+func (p *SystemdPlugin) refresh() error {
+	p.mu.Lock()
+	bus, ctx, config := p.bus, p.ctx, p.config
+	p.mu.Unlock()
+
+	if bus == nil {
+		return fmt.Errorf("systemd plugin is not initialized")
+	}
+
+	listed, err := bus.ListUnits(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	knownCgroups := make(map[string]string, len(p.cgroups))
+	for name, cgroup := range p.cgroups {
+		knownCgroups[name] = cgroup
+	}
+	p.mu.Unlock()
+
+	units := make([]SystemdUnit, 0, len(listed))
+	stats := SystemdStats{}
+	for _, status := range listed {
+		if !listsUnit(config, status) {
+			continue
+		}
+
+		unit := SystemdUnit{
+			Name:        status.Name,
+			Description: status.Description,
+			LoadState:   status.LoadState,
+			ActiveState: status.ActiveState,
+			SubState:    status.SubState,
+		}
+		stats.Units++
+		switch status.ActiveState {
+		case "active", "reloading":
+			stats.Active++
+		case "failed":
+			stats.Failed++
+		}
+
+		// The cgroup of a unit does not change while it runs, so it is only
+		// asked for once.
+		if status.ActiveState != "inactive" && status.ActiveState != "failed" {
+			cgroup, known := knownCgroups[status.Name]
+			if !known {
+				if cgroup, err = bus.ControlGroup(ctx, status); err != nil {
+					return err
+				}
+			}
+			unit.ControlGroup = cgroup
+		}
+		units = append(units, unit)
+	}
+
+	sort.Slice(units, func(i, j int) bool {
+		if failedI, failedJ := units[i].ActiveState == "failed", units[j].ActiveState == "failed"; failedI != failedJ {
+			return failedI
+		}
+		return units[i].Name < units[j].Name
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.cgroups = make(map[string]string)
+	cpu := make(map[string]systemdCPUSample)
+	for i := range units {
+		unit := &units[i]
+		if unit.ControlGroup == "" {
+			continue
+		}
+		p.cgroups[unit.Name] = unit.ControlGroup
+
+		usage, err := cgroups.ReadUsage(stringSetting(config, "cgroup_root", cgroups.DefaultRoot), unit.ControlGroup)
+		if err != nil {
+			continue
+		}
+		unit.Memory, unit.HasMemory = usage.Memory, usage.HasMemory
+
+		if last, exists := p.cpu[unit.Name]; exists && now.After(last.at) && usage.CPU >= last.usage {
+			unit.CPUPercent = float64(usage.CPU-last.usage) / float64(now.Sub(last.at)) * 100
+		}
+		cpu[unit.Name] = systemdCPUSample{usage: usage.CPU, at: now}
+	}
+	p.cpu = cpu
+	p.units = units
+	p.stats = stats
+	return nil
+}
+
+// render draws the units into the widget, keeping the selected unit
+// selected. It runs on the UI goroutine.
+func (p *SystemdPlugin) render(w *systemdWidget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	summary := fmt.Sprintf("Units: %d  Active: [green]%d[white]  Failed: [red]%d[white]\n", p.stats.Units, p.stats.Active, p.stats.Failed)
+	if p.status != "" {
+		summary += p.status
+	} else {
+		summary += systemdKeys
+	}
+	w.summary.SetText(summary)
+
+	selected := ""
+	if row, _ := w.table.GetSelection(); row > 0 && row <= len(w.names) {
+		selected = w.names[row-1]
+	}
+
+	w.table.Clear()
+	for column, header := range []string{"UNIT", "ACTIVE", "SUB", "MEM", "CPU%", "DESCRIPTION"} {
+		w.table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+
+	w.names = w.names[:0]
+	selectRow := 1
+	for i, unit := range p.units {
+		row := i + 1
+		w.names = append(w.names, unit.Name)
+		if unit.Name == selected {
+			selectRow = row
+		}
+
+		stateColor := tcell.ColorWhite
+		switch unit.ActiveState {
+		case "active":
+			stateColor = tcell.ColorGreen
+		case "failed":
+			stateColor = tcell.ColorRed
+		case "activating", "deactivating", "reloading":
+			stateColor = tcell.ColorYellow
+		case "inactive":
+			stateColor = tcell.ColorGray
+		}
+		textColor := tcell.ColorWhite
+		if unit.ActiveState == "failed" {
+			textColor = tcell.ColorRed
+		}
+
+		memory, cpu := "-", "-"
+		if unit.HasMemory {
+			memory = formatBytes(unit.Memory)
+		}
+		if unit.ControlGroup != "" {
+			cpu = fmt.Sprintf("%.1f", unit.CPUPercent)
+		}
+
+		w.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(unit.Name)).SetTextColor(textColor).SetExpansion(1))
+		w.table.SetCell(row, 1, tview.NewTableCell(unit.ActiveState).SetTextColor(stateColor))
+		w.table.SetCell(row, 2, tview.NewTableCell(unit.SubState).SetTextColor(textColor))
+		w.table.SetCell(row, 3, tview.NewTableCell(memory).SetTextColor(textColor).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 4, tview.NewTableCell(cpu).SetTextColor(textColor).SetAlign(tview.AlignRight))
+		w.table.SetCell(row, 5, tview.NewTableCell(tview.Escape(unit.Description)).SetTextColor(textColor).SetExpansion(2))
+	}
+	if len(p.units) > 0 {
+		w.table.Select(selectRow, 0)
+	}
+}
+
+func (p *SystemdPlugin) handleKey(w *systemdWidget, event *tcell.EventKey) *tcell.EventKey {
+	row, _ := w.table.GetSelection()
+	if row < 1 || row > len(w.names) {
+		return event
+	}
+	name := w.names[row-1]
+
+	if event.Key() == tcell.KeyEscape {
+		p.setStatus(w, "")
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'o', 'O':
+		p.confirmAction(w, name, "start")
+		return nil
+	case 'x', 'X':
+		p.confirmAction(w, name, "stop")
+		return nil
+	case 'r', 'R':
+		p.confirmAction(w, name, "restart")
+		return nil
+	}
+
+	return event
+}
+
+// confirmAction asks for a second press before queueing a job for a unit.
+func (p *SystemdPlugin) confirmAction(w *systemdWidget, name, action string) {
+	p.mu.Lock()
+	pending := p.pending
+	p.mu.Unlock()
+
+	if pending.confirms(action, name, time.Now()) {
+		p.runAction(w, name, action)
+		return
+	}
+
+	p.mu.Lock()
+	p.pending = newPendingAction(action, name, time.Now())
+	p.mu.Unlock()
+
+	key := map[string]string{"start": "o", "stop": "x", "restart": "r"}[action]
+	p.setStatus(w, fmt.Sprintf("[yellow]Press %s again to %s %s[white]", key, action, tview.Escape(name)))
+}
+
+// runAction queues the job in the background. systemd runs it on its own,
+// so the state shown changes with the next refreshes.
+func (p *SystemdPlugin) runAction(w *systemdWidget, name, action string) {
+	p.mu.Lock()
+	bus, ctx := p.bus, p.ctx
+	p.pending = pendingAction{}
+	p.mu.Unlock()
+
+	p.setStatus(w, fmt.Sprintf("Queueing %s of %s...", action, tview.Escape(name)))
+	go func() {
+		status := fmt.Sprintf("[green]%s: %s queued[white]", tview.Escape(name), action)
+		if bus == nil {
+			status = "[red]systemd plugin is not initialized[white]"
+		} else if err := bus.UnitAction(ctx, name, action); err != nil {
+			status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+		} else if err := p.refresh(); err != nil {
+			status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+		}
+
+		p.queueDraw(func() {
+			p.setStatus(w, status)
+		})
+	}()
+}
+
+func (p *SystemdPlugin) setStatus(w *systemdWidget, status string) {
+	p.mu.Lock()
+	p.status = status
+	p.mu.Unlock()
+
+	p.render(w)
+}
@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// systemdRequestTimeout bounds every call to systemd over D-Bus.
+const systemdRequestTimeout = 5 * time.Second
+
+// systemdUnitStatus is a unit as listed by systemd's ListUnits.
+type systemdUnitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Path        string
+}
+
+// systemdBus is the part of systemd's D-Bus API the plugin uses. Tests
+// replace it with a fake.
+type systemdBus interface {
+	ListUnits(ctx context.Context) ([]systemdUnitStatus, error)
+	// ControlGroup returns the cgroup path of a unit relative to the
+	// cgroup root, or "" for unit types without one.
+	ControlGroup(ctx context.Context, unit systemdUnitStatus) (string, error)
+	// UnitAction queues a start, stop or restart job for the unit.
+	UnitAction(ctx context.Context, name, action string) error
+	Close() error
+}
+
+const (
+	systemdDestination = "org.freedesktop.systemd1"
+	systemdObject      = "/org/freedesktop/systemd1"
+	systemdManager     = "org.freedesktop.systemd1.Manager"
+)
+
+// systemdCgroupInterfaces maps unit types that run processes to the D-Bus
+// interface holding their ControlGroup property.
+var systemdCgroupInterfaces = map[string]string{
+	".service": "org.freedesktop.systemd1.Service",
+	".scope":   "org.freedesktop.systemd1.Scope",
+	".slice":   "org.freedesktop.systemd1.Slice",
+	".socket":  "org.freedesktop.systemd1.Socket",
+	".mount":   "org.freedesktop.systemd1.Mount",
+	".swap":    "org.freedesktop.systemd1.Swap",
+}
+
+// dbusSystemd talks to systemd on the system bus.
+type dbusSystemd struct {
+	conn    *dbus.Conn
+	manager dbus.BusObject
+}
+
+func connectSystemd() (systemdBus, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return &dbusSystemd{conn: conn, manager: conn.Object(systemdDestination, systemdObject)}, nil
+}
+
+func (s *dbusSystemd) ListUnits(ctx context.Context) ([]systemdUnitStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, systemdRequestTimeout)
+	defer cancel()
+
+	var listed []struct {
+		Name        string
+		Description string
+		LoadState   string
+		ActiveState string
+		SubState    string
+		Following   string
+		Path        dbus.ObjectPath
+		JobID       uint32
+		JobType     string
+		JobPath     dbus.ObjectPath
+	}
+	if err := s.manager.CallWithContext(ctx, systemdManager+".ListUnits", 0).Store(&listed); err != nil {
+		return nil, fmt.Errorf("failed to list units: %w", err)
+	}
+
+	units := make([]systemdUnitStatus, 0, len(listed))
+	for _, unit := range listed {
+		units = append(units, systemdUnitStatus{
+			Name:        unit.Name,
+			Description: unit.Description,
+			LoadState:   unit.LoadState,
+			ActiveState: unit.ActiveState,
+			SubState:    unit.SubState,
+			Path:        string(unit.Path),
+		})
+	}
+	return units, nil
+}
+
+func (s *dbusSystemd) ControlGroup(ctx context.Context, unit systemdUnitStatus) (string, error) {
+	iface, exists := systemdCgroupInterfaces[filepath.Ext(unit.Name)]
+	if !exists {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, systemdRequestTimeout)
+	defer cancel()
+
+	var value dbus.Variant
+	object := s.conn.Object(systemdDestination, dbus.ObjectPath(unit.Path))
+	if err := object.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Get", 0, iface, "ControlGroup").Store(&value); err != nil {
+		return "", fmt.Errorf("failed to read the cgroup of %s: %w", unit.Name, err)
+	}
+	cgroup, _ := value.Value().(string)
+	return cgroup, nil
+}
+
+func (s *dbusSystemd) UnitAction(ctx context.Context, name, action string) error {
+	var method string
+	switch action {
+	case "start":
+		method = "StartUnit"
+	case "stop":
+		method = "StopUnit"
+	case "restart":
+		method = "RestartUnit"
+	default:
+		return fmt.Errorf("unknown unit action %q", action)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, systemdRequestTimeout)
+	defer cancel()
+
+	var job dbus.ObjectPath
+	if err := s.manager.CallWithContext(ctx, systemdManager+"."+method, 0, name, "replace").Store(&job); err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, name, err)
+	}
+	return nil
+}
+
+func (s *dbusSystemd) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,225 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syspulse/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// fakeSystemdBus answers like systemd for a few units and records the jobs
+// queued.
+type fakeSystemdBus struct {
+	mu     sync.Mutex
+	units  []systemdUnitStatus
+	jobs   []string
+	closed bool
+}
+
+func newFakeSystemdBus() *fakeSystemdBus {
+	return &fakeSystemdBus{units: []systemdUnitStatus{
+		{Name: "nginx.service", Description: "Web server", LoadState: "loaded", ActiveState: "active", SubState: "running", Path: "/org/freedesktop/systemd1/unit/nginx_2eservice"},
+		{Name: "backup.service", Description: "Nightly backup", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+		{Name: "cron.service", Description: "Cron", LoadState: "loaded", ActiveState: "inactive", SubState: "dead"},
+		{Name: "backup.timer", Description: "Backup timer", LoadState: "loaded", ActiveState: "active", SubState: "waiting"},
+	}}
+}
+
+func (b *fakeSystemdBus) ListUnits(ctx context.Context) ([]systemdUnitStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, fmt.Errorf("connection closed")
+	}
+	return append([]systemdUnitStatus(nil), b.units...), nil
+}
+
+func (b *fakeSystemdBus) ControlGroup(ctx context.Context, unit systemdUnitStatus) (string, error) {
+	if filepath.Ext(unit.Name) != ".service" {
+		return "", nil
+	}
+	return "/system.slice/" + unit.Name, nil
+}
+
+func (b *fakeSystemdBus) UnitAction(ctx context.Context, name, action string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if name == "locked.service" {
+		return fmt.Errorf("failed to %s %s: Interactive authentication required.", action, name)
+	}
+	b.jobs = append(b.jobs, action+" "+name)
+	for i, unit := range b.units {
+		if unit.Name == name {
+			b.units[i].ActiveState, b.units[i].SubState = "active", "running"
+			if action == "stop" {
+				b.units[i].ActiveState, b.units[i].SubState = "inactive", "dead"
+			}
+		}
+	}
+	return nil
+}
+
+func (b *fakeSystemdBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	return nil
+}
+
+func startSystemdPlugin(t *testing.T, bus *fakeSystemdBus, settings map[string]interface{}) *SystemdPlugin {
+	t.Helper()
+	plugin := NewSystemdPlugin()
+	plugin.connect = func() (systemdBus, error) { return bus, nil }
+	if err := plugin.Initialize(withDefaults("systemd", PluginConfig{Settings: settings})); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	t.Cleanup(func() { plugin.Shutdown() })
+	return plugin
+}
+
+func TestSystemdPluginUnits(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, filepath.Join(root, "/system.slice/nginx.service"), map[string]string{
+		"cpu.stat":       "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000\n",
+		"memory.current": "52428800\n",
+	})
+
+	bus := newFakeSystemdBus()
+	plugin := startSystemdPlugin(t, bus, map[string]interface{}{"cgroup_root": root})
+
+	data, err := plugin.CollectData()
+	if err != nil {
+		t.Fatalf("CollectData failed: %v", err)
+	}
+	units := data["units"].([]SystemdUnit)
+	var names []string
+	for _, unit := range units {
+		names = append(names, unit.Name)
+	}
+	if strings.Join(names, ",") != "backup.service,nginx.service" {
+		t.Fatalf("Expected failed units first and inactive ones and timers left out, got %v", names)
+	}
+	if nginx := units[1]; nginx.ControlGroup != "/system.slice/nginx.service" || nginx.Memory != 50<<20 || !nginx.HasMemory {
+		t.Errorf("Expected the memory of the unit's cgroup, got %+v", nginx)
+	}
+	if stats := data["stats"].(SystemdStats); stats != (SystemdStats{Units: 2, Active: 1, Failed: 1}) {
+		t.Errorf("Unexpected counts %+v", stats)
+	}
+
+	// Half a second of CPU since the last refresh.
+	plugin.mu.Lock()
+	plugin.cpu["nginx.service"] = systemdCPUSample{usage: 500 * time.Millisecond, at: time.Now().Add(-time.Second)}
+	plugin.mu.Unlock()
+	data, _ = plugin.CollectData()
+	if cpu := data["units"].([]SystemdUnit)[1].CPUPercent; cpu < 40 || cpu > 50 {
+		t.Errorf("Expected about 50%% CPU, got %.1f", cpu)
+	}
+
+	plugin.config.Settings["unit_types"] = "service,timer"
+	plugin.config.Settings["show_inactive"] = true
+	plugin.config.Settings["filter"] = "backup"
+	data, _ = plugin.CollectData()
+	if units := data["units"].([]SystemdUnit); len(units) != 2 || units[1].Name != "backup.timer" || units[1].ControlGroup != "" {
+		t.Errorf("Expected the filters to apply, got %+v", units)
+	}
+
+	plugin.Shutdown()
+	if !bus.closed {
+		t.Error("Expected Shutdown to close the bus")
+	}
+	if _, err := plugin.CollectData(); err == nil {
+		t.Error("Expected an error after Shutdown")
+	}
+}
+
+func TestSystemdPluginUnavailable(t *testing.T) {
+	plugin := NewSystemdPlugin()
+	plugin.connect = func() (systemdBus, error) { return nil, fmt.Errorf("no system bus") }
+	if err := plugin.Initialize(PluginConfig{}); err == nil || !strings.Contains(err.Error(), "systemd is not available") {
+		t.Errorf("Expected Initialize to fail without a bus, got %v", err)
+	}
+}
+
+func TestSystemdPluginActions(t *testing.T) {
+	bus := newFakeSystemdBus()
+	bus.units = append(bus.units, systemdUnitStatus{Name: "locked.service", ActiveState: "active", SubState: "running"})
+	plugin := startSystemdPlugin(t, bus, map[string]interface{}{"cgroup_root": t.TempDir()})
+
+	draws := make(chan func(), 16)
+	plugin.SetRedraw(func(f func()) { draws <- f })
+
+	widget, err := plugin.CreateWidget()
+	if err != nil {
+		t.Fatalf("CreateWidget failed: %v", err)
+	}
+	w := widget.(*systemdWidget)
+
+	cell := func(row, column int) *tview.TableCell { return w.table.GetCell(row, column) }
+	if foreground, _, _ := cell(1, 0).Style.Decompose(); cell(1, 0).Text != "backup.service" || foreground != tcell.ColorRed || cell(2, 0).Text != "locked.service" {
+		t.Fatalf("Expected the failed unit first and highlighted, got %q", cell(1, 0).Text)
+	}
+	if cell(1, 3).Text != "-" || cell(1, 4).Text != "-" {
+		t.Errorf("Expected no usage for a failed unit, got %q %q", cell(1, 3).Text, cell(1, 4).Text)
+	}
+
+	key := func(r rune) {
+		w.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+	}
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for !done() {
+			select {
+			case f := <-draws:
+				f()
+			case <-time.After(10 * time.Millisecond):
+			case <-deadline:
+				t.Fatalf("Timed out waiting for %s", what)
+			}
+		}
+	}
+	jobs := func() string {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return strings.Join(bus.jobs, ",")
+	}
+	summary := func() string { return w.summary.GetText(true) }
+
+	// Every action needs a second press of the same key on the same unit.
+	key('r')
+	if jobs() != "" || !strings.Contains(summary(), "Press r again to restart backup.service") {
+		t.Fatalf("Expected a confirmation prompt, got %q", summary())
+	}
+	key('x')
+	if jobs() != "" || !strings.Contains(summary(), "Press x again to stop backup.service") {
+		t.Fatalf("Expected another key to ask again, got %q", summary())
+	}
+	key('x')
+	waitFor("stop", func() bool { return strings.Contains(summary(), "backup.service: stop queued") })
+	if jobs() != "stop backup.service" {
+		t.Errorf("Unexpected jobs %q", jobs())
+	}
+
+	// The stopped unit is no longer listed, so the first row is now locked.
+	if cell(1, 0).Text != "locked.service" {
+		t.Fatalf("Expected the stopped unit to be gone, got %q", cell(1, 0).Text)
+	}
+	key('o')
+	key('o')
+	waitFor("error", func() bool { return strings.Contains(summary(), "Interactive authentication required") })
+
+	w.InputHandler()(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), func(tview.Primitive) {})
+	if !strings.Contains(summary(), "o start") {
+		t.Errorf("Expected ESC to clear the status, got %q", summary())
+	}
+}
@@ -0,0 +1,24 @@
+package plugins
+
+import "fmt"
+
+func formatBytes(bytes uint64) string {
+	const (
+		KB = 1024
+		MB = KB * 1024
+		GB = MB * 1024
+	)
+
+	if bytes >= GB {
+		return fmt.Sprintf("%.1fG", float64(bytes)/float64(GB))
+	} else if bytes >= MB {
+		return fmt.Sprintf("%.1fM", float64(bytes)/float64(MB))
+	} else if bytes >= KB {
+		return fmt.Sprintf("%.1fK", float64(bytes)/float64(KB))
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
+
+func formatRate(rate float64) string {
+	return formatBytes(uint64(rate)) + "/s"
+}
@@ -0,0 +1,280 @@
+// Package recording stores collector samples so a session can be replayed in
+// the dashboard later.
+//
+// A recording is a gzip-compressed stream of JSON lines. The first line is a
+// Header; every following line is one frame holding the samples collected at
+// that time. A sample that did not change since the previous frame is left out
+// and carried forward when the recording is read, which keeps slow-moving
+// collectors such as battery or temperature almost free.
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/collector/builtin"
+)
+
+const (
+	Format  = "syspulse-recording"
+	Version = 1
+)
+
+type Header struct {
+	Format   string        `json:"format"`
+	Version  int           `json:"version"`
+	Host     string        `json:"host"`
+	Started  time.Time     `json:"started"`
+	Interval time.Duration `json:"interval"`
+}
+
+type frameLine struct {
+	Time    time.Time                  `json:"time"`
+	Samples map[string]json.RawMessage `json:"samples"`
+}
+
+type Writer struct {
+	mu     sync.Mutex
+	closer io.Closer
+	gz     *gzip.Writer
+	last   map[string][]byte
+	frames int
+}
+
+// Create starts a recording file at path.
+func Create(path string, header Header) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %v", err)
+	}
+
+	w, err := NewWriter(file, header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w.closer = file
+	return w, nil
+}
+
+func NewWriter(out io.Writer, header Header) (*Writer, error) {
+	header.Format = Format
+	header.Version = Version
+
+	w := &Writer{gz: gzip.NewWriter(out), last: make(map[string][]byte)}
+	if err := w.writeLine(header); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteSnapshot appends a frame with every sample of the snapshot that
+// changed since the previous frame. Each frame is flushed, so a recording cut
+// short by a crash or power loss is readable up to its last frame.
+func (w *Writer) WriteSnapshot(t time.Time, snapshot *collector.Snapshot) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	line := frameLine{Time: t, Samples: make(map[string]json.RawMessage)}
+	for _, record := range snapshot.Records() {
+		if record.Sample == nil {
+			continue
+		}
+
+		data, err := json.Marshal(record.Sample)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s sample: %v", record.Collector, err)
+		}
+		if bytes.Equal(data, w.last[record.Collector]) {
+			continue
+		}
+		w.last[record.Collector] = data
+		line.Samples[record.Collector] = data
+	}
+
+	if err := w.writeLine(line); err != nil {
+		return err
+	}
+	w.frames++
+	return nil
+}
+
+// Frames returns the number of frames written so far.
+func (w *Writer) Frames() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.frames
+}
+
+func (w *Writer) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := w.gz.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.gz.Close()
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+type frame struct {
+	time    time.Time
+	samples map[string]json.RawMessage
+}
+
+// Recording is a decoded recording. Frames are kept encoded and only decoded
+// when they are shown.
+type Recording struct {
+	Header Header
+
+	// Truncated is set when the recording ended mid-frame, for example
+	// because the recorder was killed. The frames before that are intact.
+	Truncated bool
+
+	frames []frame
+}
+
+// Open reads a recording file.
+func Open(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %v", err)
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+func Read(in io.Reader) (*Recording, error) {
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("not a SysPulse recording: %v", err)
+	}
+	defer gz.Close()
+
+	reader := bufio.NewReader(gz)
+	rec := &Recording{}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording header: %v", err)
+	}
+	if err := json.Unmarshal(line, &rec.Header); err != nil || rec.Header.Format != Format {
+		return nil, fmt.Errorf("not a SysPulse recording")
+	}
+	if rec.Header.Version > Version {
+		return nil, fmt.Errorf("recording version %d is newer than supported version %d", rec.Header.Version, Version)
+	}
+
+	current := make(map[string]json.RawMessage)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			break
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				rec.Truncated = true
+				break
+			}
+			return nil, fmt.Errorf("failed to read recording: %v", err)
+		}
+
+		var fl frameLine
+		if err := json.Unmarshal(line, &fl); err != nil {
+			return nil, fmt.Errorf("corrupt recording frame %d: %v", len(rec.frames)+1, err)
+		}
+
+		// Every frame gets the full set of samples; unchanged ones share the
+		// bytes of the frame they were last recorded in.
+		next := make(map[string]json.RawMessage, len(current)+len(fl.Samples))
+		for name, data := range current {
+			next[name] = data
+		}
+		for name, data := range fl.Samples {
+			next[name] = data
+		}
+		current = next
+		rec.frames = append(rec.frames, frame{time: fl.Time, samples: current})
+	}
+
+	if len(rec.frames) == 0 {
+		return nil, fmt.Errorf("recording has no frames")
+	}
+	return rec, nil
+}
+
+func (r *Recording) Len() int {
+	return len(r.frames)
+}
+
+// Time returns when frame i was collected.
+func (r *Recording) Time(i int) time.Time {
+	return r.frames[i].time
+}
+
+func (r *Recording) Start() time.Time {
+	return r.frames[0].time
+}
+
+func (r *Recording) End() time.Time {
+	return r.frames[len(r.frames)-1].time
+}
+
+func (r *Recording) Duration() time.Duration {
+	return r.End().Sub(r.Start())
+}
+
+// Index returns the last frame collected at or before t, or the first frame
+// when t is earlier than the recording.
+func (r *Recording) Index(t time.Time) int {
+	i := sort.Search(len(r.frames), func(i int) bool {
+		return r.frames[i].time.After(t)
+	})
+	if i == 0 {
+		return 0
+	}
+	return i - 1
+}
+
+// Snapshot decodes frame i. Samples from collectors this build does not know
+// are skipped so newer recordings still replay.
+func (r *Recording) Snapshot(i int) (*collector.Snapshot, error) {
+	f := r.frames[i]
+	snapshot := collector.NewSnapshot()
+
+	for name, data := range f.samples {
+		sample, ok := builtin.NewSample(name)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(data, sample); err != nil {
+			return nil, fmt.Errorf("failed to decode %s sample: %v", name, err)
+		}
+		snapshot.Put(collector.Record{Collector: name, Time: f.time, Sample: sample})
+	}
+	return snapshot, nil
+}
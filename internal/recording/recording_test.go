@@ -0,0 +1,197 @@
+package recording
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/services/battery"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/services/sysinfo"
+)
+
+func testSnapshot(cpu float64) *collector.Snapshot {
+	snapshot := collector.NewSnapshot()
+	snapshot.Set(collector.CPU, &sysinfo.CPUSample{PerCore: []float64{cpu, cpu * 2}, Total: cpu * 1.5})
+	snapshot.Set(collector.Battery, &battery.BatteryInfo{IsPresent: true, Level: 80})
+	snapshot.Set(collector.NetworkConnections, &network.ConnectionStats{
+		Connections: []network.ConnectionStat{{LocalAddr: "127.0.0.1:22", Status: "LISTEN", PID: 42}},
+		Summary:     network.ConnectionSummary{Total: 1, Listen: 1},
+	})
+	snapshot.Set(collector.ProcessTree, &processes.ProcessTree{
+		TotalCount: 2,
+		Roots: []*processes.ProcessNode{{PID: 1, Name: "init", Children: []*processes.ProcessNode{
+			{PID: 42, PPID: 1, Name: "sshd", CPUPct: cpu},
+		}}},
+	})
+	return snapshot
+}
+
+func writeTestRecording(t *testing.T, buf *bytes.Buffer, frames int) time.Time {
+	t.Helper()
+	start := time.Date(2025, 7, 15, 3, 0, 0, 0, time.UTC)
+
+	w, err := NewWriter(buf, Header{Host: "web1", Started: start, Interval: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	for i := 0; i < frames; i++ {
+		if err := w.WriteSnapshot(start.Add(time.Duration(i)*2*time.Second), testSnapshot(float64(10*(i+1)))); err != nil {
+			t.Fatalf("Failed to write frame %d: %v", i, err)
+		}
+	}
+	if w.Frames() != frames {
+		t.Errorf("Expected %d frames written, got %d", frames, w.Frames())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return start
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	start := writeTestRecording(t, &buf, 3)
+
+	rec, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if rec.Header.Format != Format || rec.Header.Host != "web1" || rec.Header.Interval != 2*time.Second {
+		t.Errorf("Unexpected header %+v", rec.Header)
+	}
+	if rec.Len() != 3 || rec.Truncated {
+		t.Fatalf("Expected 3 complete frames, got %d (truncated %v)", rec.Len(), rec.Truncated)
+	}
+	if rec.Duration() != 4*time.Second || !rec.Start().Equal(start) {
+		t.Errorf("Unexpected span %v from %v", rec.Duration(), rec.Start())
+	}
+
+	snapshot, err := rec.Snapshot(2)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	sample, ok := snapshot.Get(collector.CPU)
+	if cpu, _ := sample.(*sysinfo.CPUSample); !ok || cpu.Total != 45 {
+		t.Errorf("Expected CPU total 45 in the last frame, got %+v", sample)
+	}
+
+	// The battery sample never changed, so it was only stored in the first
+	// frame and must be carried forward.
+	sample, ok = snapshot.Get(collector.Battery)
+	if info, _ := sample.(*battery.BatteryInfo); !ok || info.Level != 80 {
+		t.Errorf("Expected battery to be carried forward, got %+v", sample)
+	}
+
+	sample, _ = snapshot.Get(collector.NetworkConnections)
+	if conns, _ := sample.(*network.ConnectionStats); conns == nil || len(conns.Connections) != 1 || conns.Connections[0].PID != 42 {
+		t.Errorf("Expected recorded connections, got %+v", sample)
+	}
+
+	sample, _ = snapshot.Get(collector.ProcessTree)
+	tree, _ := sample.(*processes.ProcessTree)
+	if tree == nil || len(tree.Flatten()) != 2 || tree.Roots[0].Children[0].CPUPct != 30 {
+		t.Errorf("Expected recorded process tree, got %+v", sample)
+	}
+
+	if record, _ := snapshot.Record(collector.CPU); !record.Time.Equal(rec.Time(2)) {
+		t.Errorf("Expected records to carry the frame time, got %v", record.Time)
+	}
+}
+
+func TestUnchangedSamplesAreNotRepeated(t *testing.T) {
+	var once, twice bytes.Buffer
+	writeTestRecording(t, &once, 1)
+
+	w, _ := NewWriter(&twice, Header{})
+	now := time.Now()
+	w.WriteSnapshot(now, testSnapshot(10))
+	w.WriteSnapshot(now.Add(time.Second), testSnapshot(10))
+	w.Close()
+
+	rec, err := Read(bytes.NewReader(twice.Bytes()))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(rec.frames[1].samples) != 4 {
+		t.Errorf("Expected the second frame to carry all samples forward, got %d", len(rec.frames[1].samples))
+	}
+	if twice.Len() > once.Len()+64 {
+		t.Errorf("Expected an identical frame to cost almost nothing, grew from %d to %d bytes", once.Len(), twice.Len())
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var buf bytes.Buffer
+	start := writeTestRecording(t, &buf, 3)
+	rec, _ := Read(&buf)
+
+	tests := []struct {
+		offset time.Duration
+		index  int
+	}{
+		{-time.Minute, 0},
+		{0, 0},
+		{time.Second, 0},
+		{2 * time.Second, 1},
+		{3 * time.Second, 1},
+		{time.Hour, 2},
+	}
+	for _, tt := range tests {
+		if got := rec.Index(start.Add(tt.offset)); got != tt.index {
+			t.Errorf("Index(start%+v) = %d, expected %d", tt.offset, got, tt.index)
+		}
+	}
+}
+
+func TestTruncatedRecording(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, Header{})
+	now := time.Now()
+	w.WriteSnapshot(now, testSnapshot(10))
+	w.WriteSnapshot(now.Add(time.Second), testSnapshot(20))
+	// The recorder dies before Close writes the gzip footer.
+
+	rec, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if rec.Len() != 2 || !rec.Truncated {
+		t.Errorf("Expected 2 frames from a truncated recording, got %d (truncated %v)", rec.Len(), rec.Truncated)
+	}
+}
+
+func TestReadRejectsOtherFiles(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("Timestamp,CPU_Total\n"))); err == nil {
+		t.Error("Expected a CSV file to be rejected")
+	}
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, Header{})
+	w.Close()
+	if _, err := Read(&buf); err == nil {
+		t.Error("Expected a recording without frames to be rejected")
+	}
+}
+
+func TestCreateAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec.gz")
+	w, err := Create(path, Header{Host: "web1"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w.WriteSnapshot(time.Now(), testSnapshot(10))
+	w.Close()
+
+	rec, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if rec.Len() != 1 || rec.Header.Version != Version {
+		t.Errorf("Unexpected recording %+v", rec.Header)
+	}
+}
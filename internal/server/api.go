@@ -0,0 +1,399 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/export"
+	"syspulse/internal/history"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/processes"
+)
+
+const defaultHistoryRange = 5 * time.Minute
+
+type processEntry struct {
+	PID        int32     `json:"pid"`
+	PPID       int32     `json:"ppid"`
+	Name       string    `json:"name"`
+	CPUPercent float64   `json:"cpu_percent"`
+	Memory     uint64    `json:"memory"`
+	Status     string    `json:"status"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+type signalRequest struct {
+	Signal string `json:"signal"`
+}
+
+type signalResponse struct {
+	PID    int32  `json:"pid"`
+	Signal string `json:"signal"`
+	Status string `json:"status"`
+}
+
+type alertsResponse struct {
+	Firing   []alerts.Alert `json:"firing"`
+	Pending  []alerts.Alert `json:"pending"`
+	Resolved []alerts.Alert `json:"resolved"`
+}
+
+type historyIndex struct {
+	Tiers   []history.Tier `json:"tiers"`
+	Metrics []string       `json:"metrics"`
+}
+
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/snapshot", s.handleSnapshot)
+	mux.HandleFunc("GET /api/v1/history", s.handleHistoryIndex)
+	mux.HandleFunc("GET /api/v1/history/{metric}", s.handleHistory)
+	mux.HandleFunc("GET /api/v1/processes", s.handleProcesses)
+	mux.HandleFunc("GET /api/v1/processes/tree", s.handleProcessTree)
+	mux.HandleFunc("GET /api/v1/connections", s.handleConnections)
+	mux.HandleFunc("GET /api/v1/alerts", s.handleAlerts)
+	mux.HandleFunc("POST /api/v1/processes/{pid}/signal", s.handleSignal)
+}
+
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, export.NewDataPoint(s.samples))
+}
+
+func (s *Server) handleHistoryIndex(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		writeError(w, http.StatusNotFound, "history is disabled")
+		return
+	}
+	writeJSON(w, http.StatusOK, historyIndex{Tiers: s.history.Tiers(), Metrics: s.history.Names()})
+}
+
+// handleHistory returns the series of one metric. The window is given either
+// as from/to (RFC 3339 or unix seconds) or as a range ending now, and
+// repeated label=key=value parameters select a single series.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.history == nil {
+		writeError(w, http.StatusNotFound, "history is disabled")
+		return
+	}
+
+	query := r.URL.Query()
+	from, to, err := parseTimeWindow(query.Get("from"), query.Get("to"), query.Get("range"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	labels, err := parseLabels(query["label"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	metric := r.PathValue("metric")
+	tier := query.Get("tier")
+
+	var series []history.Series
+	if tier != "" {
+		series, err = s.history.RangeAllTier(tier, metric, from, to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		series = s.history.RangeAll(metric, from, to)
+	}
+
+	if len(labels) > 0 {
+		series = filterSeries(series, labels)
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	tree, err := s.processTree()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	name := strings.ToLower(query.Get("name"))
+
+	entries := make([]processEntry, 0, tree.TotalCount)
+	for _, node := range tree.Flatten() {
+		if name != "" && !strings.Contains(strings.ToLower(node.Name), name) {
+			continue
+		}
+		entries = append(entries, processEntry{
+			PID:        node.PID,
+			PPID:       node.PPID,
+			Name:       node.Name,
+			CPUPercent: node.CPUPct,
+			Memory:     node.Memory,
+			Status:     node.Status,
+			CreateTime: node.CreateTime,
+		})
+	}
+
+	if err := sortProcesses(entries, query.Get("sort")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %s", limit))
+			return
+		}
+		if n < len(entries) {
+			entries = entries[:n]
+		}
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) handleProcessTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := s.processTree()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	var stats *network.ConnectionStats
+	if sample, ok := s.samples.Get(collector.NetworkConnections); ok {
+		stats, _ = sample.(*network.ConnectionStats)
+	}
+	if stats == nil {
+		var err error
+		if stats, err = network.GetNetworkConnections(); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	query := r.URL.Query()
+	state := strings.ToUpper(query.Get("state"))
+	pid := int32(-1)
+	if value := query.Get("pid"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid pid: %s", value))
+			return
+		}
+		pid = int32(n)
+	}
+
+	if state == "" && pid < 0 {
+		writeJSON(w, http.StatusOK, stats)
+		return
+	}
+
+	filtered := &network.ConnectionStats{Connections: make([]network.ConnectionStat, 0)}
+	for _, conn := range stats.Connections {
+		if state != "" && conn.Status != state {
+			continue
+		}
+		if pid >= 0 && conn.PID != pid {
+			continue
+		}
+		filtered.Connections = append(filtered.Connections, conn)
+	}
+	filtered.Summary = network.SummarizeConnections(filtered.Connections)
+	writeJSON(w, http.StatusOK, filtered)
+}
+
+func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if s.alerts == nil {
+		writeError(w, http.StatusNotFound, "alerts are disabled")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, alertsResponse{
+		Firing:   nonNil(s.alerts.Active()),
+		Pending:  nonNil(s.alerts.Pending()),
+		Resolved: nonNil(s.alerts.Recent()),
+	})
+}
+
+func nonNil(list []alerts.Alert) []alerts.Alert {
+	if list == nil {
+		return []alerts.Alert{}
+	}
+	return list
+}
+
+func (s *Server) handleSignal(w http.ResponseWriter, r *http.Request) {
+	if s.token == "" {
+		writeError(w, http.StatusForbidden, "process actions are disabled (no token_file configured)")
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="syspulse"`)
+		writeError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+
+	value := r.PathValue("pid")
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || n <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid pid: %s", value))
+		return
+	}
+	pid := int32(n)
+
+	req := signalRequest{Signal: "TERM"}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	}
+
+	if !processes.IsSupportedSignal(req.Signal) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported signal: %s (supported: %s)",
+			req.Signal, strings.Join(processes.GetSupportedSignals(), ", ")))
+		return
+	}
+
+	if canKill, reason := processes.CanKillProcess(pid); !canKill {
+		writeError(w, http.StatusForbidden, reason)
+		return
+	}
+
+	if result := processes.SendSignal(pid, req.Signal); result != "" {
+		writeError(w, http.StatusInternalServerError, result)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, signalResponse{PID: pid, Signal: strings.ToUpper(req.Signal), Status: "sent"})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	if !found {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.token)) == 1
+}
+
+func (s *Server) processTree() (*processes.ProcessTree, error) {
+	if sample, ok := s.samples.Get(collector.ProcessTree); ok {
+		if tree, ok := sample.(*processes.ProcessTree); ok {
+			return tree, nil
+		}
+	}
+	return processes.GetProcessTree()
+}
+
+func sortProcesses(entries []processEntry, by string) error {
+	var less func(a, b processEntry) bool
+	switch by {
+	case "", "cpu":
+		less = func(a, b processEntry) bool { return a.CPUPercent > b.CPUPercent }
+	case "memory":
+		less = func(a, b processEntry) bool { return a.Memory > b.Memory }
+	case "pid":
+		less = func(a, b processEntry) bool { return a.PID < b.PID }
+	case "name":
+		less = func(a, b processEntry) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	default:
+		return fmt.Errorf("invalid sort: %s (must be cpu, memory, pid or name)", by)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+	return nil
+}
+
+func parseTimeWindow(fromValue, toValue, rangeValue string) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toValue != "" {
+		t, err := parseTime(toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %v", err)
+		}
+		to = t
+	}
+
+	if fromValue != "" {
+		from, err := parseTime(fromValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %v", err)
+		}
+		return from, to, nil
+	}
+
+	window := defaultHistoryRange
+	if rangeValue != "" {
+		d, err := time.ParseDuration(rangeValue)
+		if err != nil || d <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid range: %s", rangeValue)
+		}
+		window = d
+	}
+	return to.Add(-window), to, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseLabels(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, found := strings.Cut(value, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid label: %s (expected key=value)", value)
+		}
+		labels[key] = val
+	}
+	return labels, nil
+}
+
+func filterSeries(series []history.Series, labels map[string]string) []history.Series {
+	filtered := make([]history.Series, 0, len(series))
+	for _, ser := range series {
+		matches := true
+		for key, value := range labels {
+			if ser.Labels[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, ser)
+		}
+	}
+	return filtered
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
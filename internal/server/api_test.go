@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/utils"
+)
+
+type staticCollector struct {
+	name   string
+	sample collector.Sample
+}
+
+func (c *staticCollector) Name() string {
+	return c.name
+}
+
+func (c *staticCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return c.sample, nil
+}
+
+func newAPITestServer(t *testing.T, token string) *Server {
+	t.Helper()
+
+	now := time.Now()
+	tree := &processes.ProcessTree{
+		Roots: []*processes.ProcessNode{
+			{PID: 1, Name: "init", CPUPct: 0.5, Memory: 100, Children: []*processes.ProcessNode{
+				{PID: 20, PPID: 1, Name: "worker", CPUPct: 40, Memory: 50},
+				{PID: 30, PPID: 1, Name: "database", CPUPct: 10, Memory: 900},
+			}},
+		},
+		TotalCount: 3,
+		LastUpdate: now,
+	}
+	conns := &network.ConnectionStats{
+		Connections: []network.ConnectionStat{
+			{LocalAddr: "127.0.0.1:80", Status: "LISTEN", PID: 20},
+			{LocalAddr: "127.0.0.1:5432", RemoteAddr: "127.0.0.1:40000", Status: "ESTABLISHED", PID: 30},
+		},
+	}
+	conns.Summary = network.SummarizeConnections(conns.Connections)
+
+	registry := collector.NewRegistry(
+		&fakeCollector{name: collector.CPU},
+		&staticCollector{name: collector.ProcessTree, sample: tree},
+		&staticCollector{name: collector.NetworkConnections, sample: conns},
+	)
+
+	config := utils.ServerConfig{}
+	if token != "" {
+		config.TokenFile = filepath.Join(t.TempDir(), "token")
+		if err := os.WriteFile(config.TokenFile, []byte(token+"\n"), 0600); err != nil {
+			t.Fatalf("Failed to write token file: %v", err)
+		}
+	}
+
+	s, err := New(config, registry, history.NewStore(history.DefaultConfig))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	s.Collect(context.Background())
+	return s
+}
+
+func doRequest(s *Server, method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPISnapshot(t *testing.T) {
+	s := newAPITestServer(t, "")
+	rec := doRequest(s, http.MethodGet, "/api/v1/snapshot", "", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var snapshot struct {
+		ProcessTree struct {
+			ProcessCount int
+			TopProcesses []string
+		}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+	if snapshot.ProcessTree.ProcessCount != 3 {
+		t.Errorf("Expected 3 processes, got %d", snapshot.ProcessTree.ProcessCount)
+	}
+	if tops := snapshot.ProcessTree.TopProcesses; len(tops) == 0 || tops[0] != "worker" {
+		t.Errorf("Expected worker as top process, got %v", tops)
+	}
+}
+
+func TestAPIHistory(t *testing.T) {
+	s := newAPITestServer(t, "")
+
+	t.Run("Index", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/history", "", nil)
+		var index historyIndex
+		if err := json.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+			t.Fatalf("Failed to decode index: %v", err)
+		}
+		if len(index.Tiers) != len(history.DefaultTiers) {
+			t.Errorf("Expected %d tiers, got %d", len(history.DefaultTiers), len(index.Tiers))
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/history/cpu_usage_percent?range=1m", "", nil)
+		var series []history.Series
+		if err := json.Unmarshal(rec.Body.Bytes(), &series); err != nil {
+			t.Fatalf("Failed to decode series: %v", err)
+		}
+		if len(series) != 1 || len(series[0].Points) != 1 || series[0].Points[0].Value != 42 {
+			t.Errorf("Unexpected series: %+v", series)
+		}
+	})
+
+	t.Run("Label Filter", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/history/disk_read_bytes_total?label=device=sdb", "", nil)
+		var series []history.Series
+		json.Unmarshal(rec.Body.Bytes(), &series)
+		if len(series) != 0 {
+			t.Errorf("Expected no series for unknown device, got %+v", series)
+		}
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		for _, target := range []string{
+			"/api/v1/history/cpu_usage_percent?range=abc",
+			"/api/v1/history/cpu_usage_percent?from=yesterday",
+			"/api/v1/history/cpu_usage_percent?tier=5m",
+			"/api/v1/history/cpu_usage_percent?label=device",
+		} {
+			if rec := doRequest(s, http.MethodGet, target, "", nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", target, rec.Code)
+			}
+		}
+	})
+}
+
+func TestAPIProcesses(t *testing.T) {
+	s := newAPITestServer(t, "")
+
+	t.Run("Sorted And Limited", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/processes?sort=memory&limit=2", "", nil)
+		var entries []processEntry
+		if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("Failed to decode processes: %v", err)
+		}
+		if len(entries) != 2 || entries[0].Name != "database" || entries[1].Name != "init" {
+			t.Errorf("Unexpected processes: %+v", entries)
+		}
+	})
+
+	t.Run("Name Filter", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/processes?name=WORK", "", nil)
+		var entries []processEntry
+		json.Unmarshal(rec.Body.Bytes(), &entries)
+		if len(entries) != 1 || entries[0].PID != 20 {
+			t.Errorf("Unexpected processes: %+v", entries)
+		}
+	})
+
+	t.Run("Invalid Sort", func(t *testing.T) {
+		if rec := doRequest(s, http.MethodGet, "/api/v1/processes?sort=age", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Tree", func(t *testing.T) {
+		rec := doRequest(s, http.MethodGet, "/api/v1/processes/tree", "", nil)
+		var tree processes.ProcessTree
+		if err := json.Unmarshal(rec.Body.Bytes(), &tree); err != nil {
+			t.Fatalf("Failed to decode tree: %v", err)
+		}
+		if tree.TotalCount != 3 || len(tree.Roots) != 1 || len(tree.Roots[0].Children) != 2 {
+			t.Errorf("Unexpected tree: %+v", tree)
+		}
+	})
+}
+
+func TestAPIConnections(t *testing.T) {
+	s := newAPITestServer(t, "")
+	rec := doRequest(s, http.MethodGet, "/api/v1/connections?state=listen", "", nil)
+
+	var stats network.ConnectionStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to decode connections: %v", err)
+	}
+	if len(stats.Connections) != 1 || stats.Summary.Listen != 1 || stats.Summary.Total != 1 {
+		t.Errorf("Unexpected connections: %+v", stats)
+	}
+}
+
+func TestAPIAlerts(t *testing.T) {
+	s := newAPITestServer(t, "")
+	if rec := doRequest(s, http.MethodGet, "/api/v1/alerts", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with alerts disabled, got %d", rec.Code)
+	}
+
+	engine, err := alerts.NewEngine([]alerts.Rule{
+		{Name: "busy", Expr: "cpu.total > 40"},
+		{Name: "database_memory", Expr: "process[database].memory > 500", Severity: alerts.SeverityCritical},
+		{Name: "idle", Expr: "cpu.total < 10"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	s.SetAlerts(engine, nil)
+	s.Collect(context.Background())
+
+	rec := doRequest(s, http.MethodGet, "/api/v1/alerts", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response alertsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode alerts: %v", err)
+	}
+	if len(response.Firing) != 2 {
+		t.Fatalf("Expected 2 firing alerts, got %+v", response.Firing)
+	}
+	if response.Firing[0].Rule != "database_memory" || response.Firing[0].Labels["pid"] != "30" {
+		t.Errorf("Expected critical process alert first, got %+v", response.Firing[0])
+	}
+}
+
+func TestAPISignal(t *testing.T) {
+	t.Run("Disabled Without Token", func(t *testing.T) {
+		s := newAPITestServer(t, "")
+		if rec := doRequest(s, http.MethodPost, "/api/v1/processes/20/signal", `{"signal":"TERM"}`, nil); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", rec.Code)
+		}
+	})
+
+	s := newAPITestServer(t, "secret")
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		header map[string]string
+		status int
+	}{
+		{"missing token", "/api/v1/processes/20/signal", `{"signal":"TERM"}`, nil, http.StatusUnauthorized},
+		{"wrong token", "/api/v1/processes/20/signal", `{"signal":"TERM"}`, map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"invalid pid", "/api/v1/processes/abc/signal", `{"signal":"TERM"}`, auth, http.StatusBadRequest},
+		{"unsupported signal", "/api/v1/processes/20/signal", `{"signal":"BOGUS"}`, auth, http.StatusBadRequest},
+		{"invalid body", "/api/v1/processes/20/signal", `{`, auth, http.StatusBadRequest},
+		{"init is protected", "/api/v1/processes/1/signal", `{"signal":"KILL"}`, auth, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doRequest(s, http.MethodPost, tt.target, tt.body, tt.header); rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("GET not allowed", func(t *testing.T) {
+		if rec := doRequest(s, http.MethodGet, "/api/v1/processes/20/signal", "", auth); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/export"
+	"syspulse/internal/history"
+	"syspulse/internal/metrics"
+	"syspulse/internal/utils"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Server runs the collectors headlessly on a fixed interval and serves the
+// latest snapshot over HTTP.
+type Server struct {
+	config   utils.ServerConfig
+	registry *collector.Registry
+	samples  *collector.Snapshot
+	metrics  *metrics.Metrics
+	history  *history.Store
+	alerts   *alerts.Engine
+	notifier *alerts.Dispatcher
+	token    string
+}
+
+// New creates a server for the given collectors. store may be nil, in which
+// case the history endpoints report that history is disabled.
+func New(config utils.ServerConfig, registry *collector.Registry, store *history.Store) (*Server, error) {
+	if config.Listen == "" {
+		config.Listen = utils.DefaultServerConfig.Listen
+	}
+	if config.Interval <= 0 {
+		config.Interval = utils.DefaultServerConfig.Interval
+	}
+
+	s := &Server{
+		config:   config,
+		registry: registry,
+		samples:  collector.NewSnapshot(),
+		metrics:  metrics.New(time.Duration(config.Interval) * time.Second * 10),
+		history:  store,
+	}
+
+	if config.TokenFile != "" {
+		token, err := readToken(config.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		s.token = token
+	}
+
+	return s, nil
+}
+
+// SetAlerts makes Collect evaluate the engine's rules after every collection
+// and hand state changes to the dispatcher, which may be nil.
+func (s *Server) SetAlerts(engine *alerts.Engine, dispatcher *alerts.Dispatcher) {
+	s.alerts = engine
+	s.notifier = dispatcher
+}
+
+func (s *Server) Config() utils.ServerConfig {
+	return s.config
+}
+
+func (s *Server) Snapshot() *collector.Snapshot {
+	return s.samples
+}
+
+func (s *Server) Metrics() *metrics.Metrics {
+	return s.metrics
+}
+
+// Collect runs every registered collector once and records its timing in the
+// self-metrics. A failed collector keeps its previous sample.
+func (s *Server) Collect(ctx context.Context) {
+	for _, c := range s.registry.Collectors() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		record := collector.Run(ctx, c)
+		metricType := metrics.ForCollector(record.Collector)
+		if record.Err != nil {
+			s.metrics.RecordError(metricType)
+		} else {
+			s.metrics.RecordUpdateDuration(metricType, record.Duration)
+		}
+		s.samples.Put(record)
+		s.history.AddRecord(record)
+	}
+
+	if events := s.alerts.Evaluate(s.samples, time.Now()); len(events) > 0 {
+		go s.notify(events)
+	}
+}
+
+func (s *Server) notify(events []alerts.Event) {
+	if err := s.notifier.Dispatch(context.Background(), events); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send alert notifications: %v\n", err)
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.registerAPI(mux)
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	return mux
+}
+
+// Run listens on the configured address until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	if !s.config.AllowRemote && !isLoopback(s.config.Listen) {
+		return fmt.Errorf("refusing to listen on non-loopback address %s (set allow_remote to override)", s.config.Listen)
+	}
+
+	listener, err := net.Listen("tcp", s.config.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Listen, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	s.Collect(ctx)
+	go s.collectLoop(ctx)
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	}
+}
+
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func readToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+func (s *Server) collectLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.config.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Collect(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	write := export.WritePrometheusText
+	contentType := export.PrometheusTextContentType
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		write = export.WriteOpenMetrics
+		contentType = export.OpenMetricsContentType
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, s.samples, s.metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<html><head><title>SysPulse</title></head><body><h1>SysPulse</h1><p><a href="/metrics">Metrics</a></p><p><a href="/api/v1/snapshot">Snapshot</a></p></body></html>`)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/utils"
+)
+
+type fakeSample struct {
+	value float64
+}
+
+func (s *fakeSample) Points() []collector.Point {
+	return []collector.Point{
+		collector.GaugePoint("cpu_usage_percent", s.value, nil),
+		collector.CounterPoint("disk_read_bytes_total", 4096, map[string]string{"device": "sda"}),
+	}
+}
+
+type fakeCollector struct {
+	name string
+	err  error
+}
+
+func (c *fakeCollector) Name() string {
+	return c.name
+}
+
+func (c *fakeCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &fakeSample{value: 42}, nil
+}
+
+func newTestServer() *Server {
+	registry := collector.NewRegistry(
+		&fakeCollector{name: collector.CPU},
+		&fakeCollector{name: collector.GPU, err: fmt.Errorf("no GPU")},
+	)
+	s, _ := New(utils.ServerConfig{}, registry, nil)
+	return s
+}
+
+func TestNew(t *testing.T) {
+	s := newTestServer()
+	if s.Config() != utils.DefaultServerConfig {
+		t.Errorf("Expected default config %+v, got %+v", utils.DefaultServerConfig, s.Config())
+	}
+
+	if _, err := New(utils.ServerConfig{TokenFile: filepath.Join(t.TempDir(), "missing")}, collector.NewRegistry(), nil); err == nil {
+		t.Error("Expected error for missing token file")
+	}
+}
+
+func TestRunRefusesRemote(t *testing.T) {
+	s, _ := New(utils.ServerConfig{Listen: "0.0.0.0:0"}, collector.NewRegistry(), nil)
+	if err := s.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "non-loopback") {
+		t.Errorf("Expected non-loopback error, got %v", err)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	s := newTestServer()
+	s.Collect(context.Background())
+
+	t.Run("OpenMetrics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+			t.Errorf("Unexpected content type %q", ct)
+		}
+
+		body := rec.Body.String()
+		for _, want := range []string{
+			"syspulse_cpu_usage_percent 42\n",
+			`syspulse_disk_read_bytes_total{device="sda"} 4096`,
+			`syspulse_collector_duration_seconds{collector="cpu"}`,
+			`syspulse_collector_errors_total{collector="gpu"} 1`,
+			"# EOF\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Expected body to contain %q\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("Prometheus text", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("Unexpected content type %q", ct)
+		}
+		if strings.Contains(rec.Body.String(), "# EOF") {
+			t.Error("Prometheus text format must not contain # EOF")
+		}
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rec.Code)
+		}
+	})
+}
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- newTestServer().Serve(ctx, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), "syspulse_cpu_usage_percent 42") {
+		t.Errorf("Expected scraped metrics, got:\n%s", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
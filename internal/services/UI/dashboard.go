@@ -1,10 +1,11 @@
 package ui
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"syspulse/internal/errors"
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+	"syspulse/internal/services/processes"
 	"syspulse/internal/utils"
 
 	"github.com/rivo/tview"
@@ -12,39 +13,38 @@ import (
 
 func newDashboard() *utils.Dashboard {
 	d := &utils.Dashboard{
-		App: tview.NewApplication(),
+		App:     tview.NewApplication(),
+		Samples: collector.NewSnapshot(),
 	}
 	if err := (*Dashboard)(d).loadTheme(); err != nil {
 		log.Fatal(fmt.Sprintf("Failed to load theme: %v", err))
 	}
+	processes.Configure(d.Theme.Performance)
+	if d.Theme.History.Enabled {
+		d.History = history.NewStore(d.Theme.History)
+	}
+	if d.Theme.Alerts.Enabled {
+		engine, err := alerts.NewEngine(d.Theme.Alerts.Rules)
+		if err != nil {
+			log.Fatal(fmt.Sprintf("Failed to load alert rules: %v", err))
+		}
+		d.Alerts = engine
+
+		dispatcher, err := alerts.NewDispatcher(d.Theme.Alerts, log)
+		if err != nil {
+			log.Fatal(fmt.Sprintf("Failed to load alert notifiers: %v", err))
+		}
+		d.AlertNotifier = dispatcher
+	}
 	(*Dashboard)(d).applyThemeColors()
 	(*Dashboard)(d).initWidgets()
 	return d
 }
 
 func (d *Dashboard) loadTheme() error {
-	var themeData utils.Theme
-
-	dataFile, err := os.ReadFile("config.json")
+	themeData, err := utils.LoadTheme()
 	if err != nil {
-		dataFileBackup, err := os.ReadFile("internal/services/UI/default.json")
-		if err != nil {
-			return errors.NewAppError(errors.ConfigError,
-				"Cannot load neither 'config.json' nor 'default.json'", err)
-		}
-		if err = json.Unmarshal(dataFileBackup, &themeData); err != nil {
-			return errors.NewAppError(errors.ConfigError,
-				"Failed to parse default.json", err)
-		}
-	} else {
-		if err = json.Unmarshal(dataFile, &themeData); err != nil {
-			return errors.NewAppError(errors.ConfigError,
-				"Failed to parse config.json", err)
-		}
-	}
-
-	if err = utils.Validate(themeData); err != nil {
-		return errors.Wrap(err, "Theme validation failed")
+		return err
 	}
 
 	d.Theme = themeData
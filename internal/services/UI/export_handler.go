@@ -1,23 +1,108 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"syspulse/internal/collector"
+	"syspulse/internal/collector/builtin"
 	"syspulse/internal/export"
-	"syspulse/internal/services/disk"
-	"syspulse/internal/services/gpu"
-	"syspulse/internal/services/memory"
-	"syspulse/internal/services/network"
-	"syspulse/internal/services/sysinfo"
+	"syspulse/internal/plugins"
 	"syspulse/internal/utils"
 	"time"
 )
 
-func startExportWorker(d *utils.Dashboard, quit chan struct{}) {
-	go func() {
-		if !d.Theme.Export.Enabled {
-			return
+var (
+	exportRegistry *collector.Registry
+	exportStreams  []*export.Stream
+	exportPusher   *export.Pusher
+	exportPushes   chan export.DataPoint
+	exportPushDone chan struct{}
+)
+
+const (
+	// exportPushQueue is how many snapshots may wait for a slow endpoint
+	// before new ones are skipped.
+	exportPushQueue = 4
+	pushTimeout     = time.Minute
+	// finalPushTimeout bounds how long quitting waits for the endpoint, and
+	// for the plugins to export their data.
+	finalPushTimeout = 5 * time.Second
+)
+
+func newExportRegistry(d *utils.Dashboard) *collector.Registry {
+	registry := builtin.NewRegistry()
+	if d.Theme.Layout.GPU.Enabled {
+		return registry
+	}
+
+	filtered := collector.NewRegistry()
+	for _, c := range registry.Collectors() {
+		if c.Name() != collector.GPU {
+			filtered.Register(c)
 		}
+	}
+	return filtered
+}
 
+// collectExportSnapshot runs the collectors and asks the plugins for their
+// data, giving up on the plugins when ctx is done.
+func collectExportSnapshot(ctx context.Context, d *utils.Dashboard) export.DataPoint {
+	if exportRegistry == nil {
+		exportRegistry = newExportRegistry(d)
+	}
+	snapshot := export.NewDataPoint(exportRegistry.CollectAll(context.Background()))
+	snapshot.Plugins = plugins.ExportPluginData(ctx, d)
+	return snapshot
+}
+
+// openExportStreams opens one stream per configured format. Each export
+// appends a single record to the active segment, so memory and disk use stay
+// proportional to the retention settings rather than the session length.
+func openExportStreams(d *utils.Dashboard) {
+	for _, format := range d.Theme.Export.Formats {
+		exportFormat, err := export.ParseFormat(format)
+		if err != nil {
+			log.Error(err.Error())
+			continue
+		}
+
+		stream, err := export.NewStream(export.NewStreamConfig(d.Theme.Export, exportFormat))
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to open %s export: %v", format, err))
+			continue
+		}
+		exportStreams = append(exportStreams, stream)
+	}
+}
+
+func openExportPusher(d *utils.Dashboard) {
+	config, err := export.NewPusherConfig(d.Theme.Export.Push)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to configure export push: %v", err))
+		return
+	}
+
+	exportPusher, err = export.NewPusher(config)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to configure export push: %v", err))
+	}
+}
+
+func startExportWorker(d *utils.Dashboard, quit chan struct{}) {
+	if !d.Theme.Export.Enabled && !d.Theme.Export.Push.Enabled {
+		return
+	}
+	if d.Theme.Export.Enabled {
+		openExportStreams(d)
+	}
+	if d.Theme.Export.Push.Enabled {
+		openExportPusher(d)
+	}
+	if exportPusher != nil {
+		startExportPushWorker(quit)
+	}
+
+	go func() {
 		ticker := time.NewTicker(time.Duration(d.Theme.Export.Interval) * time.Second)
 		defer ticker.Stop()
 
@@ -32,85 +117,103 @@ func startExportWorker(d *utils.Dashboard, quit chan struct{}) {
 	}()
 }
 
-func performPeriodicExport(d *utils.Dashboard) {
-	sysinfo.UpdateCPU(d)
-	memory.UpdateVMem(d)
-	disk.UpdateDisk(d)
-	network.UpdateNetwork(d)
-	if d.Theme.Layout.GPU.Enabled {
-		gpu.UpdateGPU(d)
-	}
+// startExportPushWorker pushes snapshots in the background, so that an
+// endpoint that is down and retried with backoff never delays the file
+// exports. Quitting interrupts a push in progress.
+func startExportPushWorker(quit chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exportPushes = make(chan export.DataPoint, exportPushQueue)
+	exportPushDone = make(chan struct{})
 
-	snapshot := export.CreateSnapshot(d)
-	dataPoints = append(dataPoints, snapshot)
-
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	go func() {
+		defer close(exportPushDone)
+		defer cancel()
 
-	for _, format := range d.Theme.Export.Formats {
-		filename := fmt.Sprintf("%s/%s_%s.%s",
-			d.Theme.Export.Directory,
-			d.Theme.Export.FilenamePrefix,
-			timestamp,
-			format)
-
-		var exportFormat export.ExportFormat
-		switch format {
-		case "csv":
-			exportFormat = export.CSV
-		case "json":
-			exportFormat = export.JSON
-		default:
-			log.Error(fmt.Sprintf("Unsupported export format: %s", format))
-			continue
+		for {
+			select {
+			case snapshot := <-exportPushes:
+				pushExport(ctx, pushTimeout, snapshot)
+			case <-quit:
+				return
+			}
 		}
+	}()
 
-		if err := export.ExportData(dataPoints, filename, exportFormat); err != nil {
-			log.Error(fmt.Sprintf("Failed to export %s: %v", format, err))
-		} else {
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
 		}
-	}
+	}()
 }
 
-func performFinalExport(d *utils.Dashboard) {
-	if !d.Theme.Export.Enabled {
+func performPeriodicExport(d *utils.Dashboard) {
+	snapshot := collectExportSnapshot(context.Background(), d)
+	writeExportStreams(snapshot)
+	queueExportPush(snapshot)
+}
+
+func queueExportPush(snapshot export.DataPoint) {
+	if exportPushes == nil {
 		return
 	}
 
-	sysinfo.UpdateCPU(d)
-	memory.UpdateVMem(d)
-	disk.UpdateDisk(d)
-	network.UpdateNetwork(d)
-	if d.Theme.Layout.GPU.Enabled {
-		gpu.UpdateGPU(d)
+	select {
+	case exportPushes <- snapshot:
+	default:
+		log.Warn("Export push is falling behind, skipping a snapshot")
 	}
+}
 
-	finalSnapshot := export.CreateSnapshot(d)
-	dataPoints = append(dataPoints, finalSnapshot)
+// pushExport sends snapshots to the configured metrics backend. Batches that
+// fail stay queued in the pusher and go out with the next export.
+func pushExport(ctx context.Context, timeout time.Duration, snapshots ...export.DataPoint) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := exportPusher.Push(ctx, snapshots...); err != nil {
+		log.Warn(fmt.Sprintf("Failed to push export: %v", err))
+	}
+}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+// finishExportPush waits for the push worker to stop and then pushes the
+// snapshots it had not got to along with the last one, for a short time.
+func finishExportPush(snapshot export.DataPoint) {
+	if exportPushes == nil {
+		return
+	}
+	<-exportPushDone
 
-	for _, format := range d.Theme.Export.Formats {
-		filename := fmt.Sprintf("%s/%s_final_%s.%s",
-			d.Theme.Export.Directory,
-			d.Theme.Export.FilenamePrefix,
-			timestamp,
-			format)
-
-		var exportFormat export.ExportFormat
-		switch format {
-		case "csv":
-			exportFormat = export.CSV
-		case "json":
-			exportFormat = export.JSON
-		default:
-			log.Error(fmt.Sprintf("Unsupported export format: %s", format))
-			continue
+	snapshots := []export.DataPoint{}
+	for len(exportPushes) > 0 {
+		snapshots = append(snapshots, <-exportPushes)
+	}
+	pushExport(context.Background(), finalPushTimeout, append(snapshots, snapshot)...)
+}
+
+func writeExportStreams(snapshot export.DataPoint) {
+	for _, stream := range exportStreams {
+		if err := stream.Write(snapshot); err != nil {
+			log.Error(fmt.Sprintf("Failed to export %s: %v", stream.Extension(), err))
 		}
+	}
+}
 
-		if err := export.ExportData(dataPoints, filename, exportFormat); err != nil {
-			log.Error(fmt.Sprintf("Failed to export final %s: %v", format, err))
-		} else {
+func performFinalExport(d *utils.Dashboard) {
+	if !d.Theme.Export.Enabled && !d.Theme.Export.Push.Enabled {
+		return
+	}
 
+	// Quitting does not wait for slow plugins.
+	ctx, cancel := context.WithTimeout(context.Background(), finalPushTimeout)
+	snapshot := collectExportSnapshot(ctx, d)
+	cancel()
+	writeExportStreams(snapshot)
+	for _, stream := range exportStreams {
+		if err := stream.Close(); err != nil {
+			log.Error(fmt.Sprintf("Failed to close %s export: %v", stream.Extension(), err))
 		}
 	}
+
+	finishExportPush(snapshot)
 }
@@ -182,6 +182,27 @@ func (d *Dashboard) buildFocusableWidgets() []tview.Primitive {
 			column: d.Theme.Layout.Battery.Column,
 		})
 	}
+	if d.CgroupsWidget != nil && d.Theme.Layout.Cgroups.Enabled {
+		widgetPositions = append(widgetPositions, widgetPosition{
+			widget: d.CgroupsWidget,
+			row:    d.Theme.Layout.Cgroups.Row,
+			column: d.Theme.Layout.Cgroups.Column,
+		})
+	}
+	if d.PressureWidget != nil && d.Theme.Layout.Pressure.Enabled {
+		widgetPositions = append(widgetPositions, widgetPosition{
+			widget: d.PressureWidget,
+			row:    d.Theme.Layout.Pressure.Row,
+			column: d.Theme.Layout.Pressure.Column,
+		})
+	}
+	if d.WatchWidget != nil && d.Theme.Layout.Watch.Enabled {
+		widgetPositions = append(widgetPositions, widgetPosition{
+			widget: d.WatchWidget,
+			row:    d.Theme.Layout.Watch.Row,
+			column: d.Theme.Layout.Watch.Column,
+		})
+	}
 
 	if d.PluginManager != nil {
 		if pluginManager, ok := d.PluginManager.(*plugins.PluginManager); ok {
@@ -299,6 +320,27 @@ func (d *Dashboard) addWidgetsToGrid(grid *tview.Grid) {
 			d.Theme.Layout.Battery.MinWidth, 0, false)
 	}
 
+	if d.Theme.Layout.Cgroups.Enabled && d.CgroupsWidget != nil {
+		grid.AddItem(d.CgroupsWidget,
+			d.Theme.Layout.Cgroups.Row, d.Theme.Layout.Cgroups.Column,
+			d.Theme.Layout.Cgroups.RowSpan, d.Theme.Layout.Cgroups.ColSpan,
+			d.Theme.Layout.Cgroups.MinWidth, 0, false)
+	}
+
+	if d.Theme.Layout.Pressure.Enabled && d.PressureWidget != nil {
+		grid.AddItem(d.PressureWidget,
+			d.Theme.Layout.Pressure.Row, d.Theme.Layout.Pressure.Column,
+			d.Theme.Layout.Pressure.RowSpan, d.Theme.Layout.Pressure.ColSpan,
+			d.Theme.Layout.Pressure.MinWidth, 0, false)
+	}
+
+	if d.Theme.Layout.Watch.Enabled && d.WatchWidget != nil {
+		grid.AddItem(d.WatchWidget,
+			d.Theme.Layout.Watch.Row, d.Theme.Layout.Watch.Column,
+			d.Theme.Layout.Watch.RowSpan, d.Theme.Layout.Watch.ColSpan,
+			d.Theme.Layout.Watch.MinWidth, 0, false)
+	}
+
 	if d.PluginManager != nil {
 		plugins.AddPluginWidgetsToGrid((*utils.Dashboard)(d), grid)
 	}
@@ -309,6 +351,12 @@ func (d *Dashboard) setupInputHandlers(focusableWidgets []tview.Primitive) {
 
 	d.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if !d.InModalState {
+			if replay != nil {
+				if _, ok := d.App.GetFocus().(*tview.InputField); !ok && handleReplayKey(event) {
+					return nil
+				}
+			}
+
 			isMainWidgetActive := !d.InModalState
 
 			currentFocused := d.App.GetFocus()
@@ -365,6 +413,16 @@ func (d *Dashboard) setupInputHandlers(focusableWidgets []tview.Primitive) {
 				if shouldProcessGlobalKeys && d.GPUWidget != nil {
 					d.App.SetFocus(d.GPUWidget)
 				}
+			case 'a', 'A', '7':
+				if shouldProcessGlobalKeys {
+					d.showAlertsModal()
+				}
+				return nil
+			case 'u', 'U', '8':
+				if shouldProcessGlobalKeys {
+					d.showPluginManager()
+				}
+				return nil
 			case 'h', 'H', '0', rune(tcell.KeyF1):
 				if shouldProcessGlobalKeys {
 					d.showHelpModal()
@@ -379,7 +437,8 @@ func (d *Dashboard) setupInputHandlers(focusableWidgets []tview.Primitive) {
 
 func (d *Dashboard) createMainWidget(grid *tview.Grid) {
 	d.FooterWidget = tview.NewTextView().
-		SetText("Press 'h' for help | TAB to cycle widgets | 'q' to quit").
+		SetDynamicColors(true).
+		SetText(footerText).
 		SetTextColor(tview.Styles.PrimaryTextColor)
 	d.FooterWidget.SetBackgroundColor(utils.GetColorFromName(d.Theme.Background))
 
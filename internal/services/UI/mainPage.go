@@ -4,15 +4,12 @@ import (
 	"fmt"
 	"os"
 
-	"syspulse/internal/export"
 	loggerv2 "syspulse/internal/logger/v2"
+	"syspulse/internal/plugins"
 	"syspulse/internal/utils"
 )
 
-var (
-	log        *loggerv2.Logger
-	dataPoints []export.DataPoint
-)
+var log *loggerv2.Logger
 
 func init() {
 	var err error
@@ -21,8 +18,6 @@ func init() {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
-
-	dataPoints = make([]export.DataPoint, 0)
 }
 
 func NewDashboard() *utils.Dashboard {
@@ -47,6 +42,10 @@ func Run(d *utils.Dashboard) error {
 
 	performFinalExport(d)
 
+	if err := plugins.ShutdownPluginSystem(d); err != nil {
+		log.Warn(fmt.Sprintf("Failed to shut down plugins: %v", err))
+	}
+
 	log.Info("Shutting down SysPulse application")
 	return err
 }
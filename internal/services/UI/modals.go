@@ -3,12 +3,14 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"syspulse/internal/alerts"
+	"syspulse/internal/services/cgroups"
 	"syspulse/internal/services/processes"
 	"syspulse/internal/utils"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"github.com/shirou/gopsutil/process"
 )
 
 func (d *Dashboard) showHelpModal() {
@@ -19,6 +21,7 @@ func (d *Dashboard) showHelpModal() {
 • Q - Quit application
 • H - Show this help screen
 • I or ENTER - Show detailed information modal for focused widget
+• V - Toggle bar/history view (CPU, Memory, Network, Disk I/O, Pressure)
 
 Quick Navigation:
 • C - Focus CPU widget
@@ -27,13 +30,36 @@ Quick Navigation:
 • N - Focus Network widget
 • P - Focus Process widget
 • G - Focus GPU widget
+• A - Show alerts
+• U - Plugin manager (enable, reload, configure)
 
 Process Management:
 • K - Kill selected process
+• E - Renice, set the CPU affinity or I/O priority of the selected process
 • F - Search/filter processes
 • Up/Down or W/S - Navigate process list
-• I - View selected process details
-• Y - Change process sorting (CPU/Memory)`
+• I - Inspect the selected process (Tab or 1-9 switch tabs)
+• T - Watch the selected process by PID or name
+• Y - Change process sorting (CPU/Memory)
+• < and > - Sort by the previous/next column
+• R - Reverse the sort order
+
+Cgroups:
+• Up/Down or J/K - Navigate the cgroup tree
+• ENTER/SPACE - Expand or collapse a cgroup
+• I - View the selected cgroup and its processes
+
+Watch:
+• Up/Down or W/S - Select a watched process
+• I or ENTER - View its processes and exit/restart timeline
+• X or DEL - Stop watching it
+
+Replay (syspulse replay):
+• SPACE - Pause/resume
+• , and . - Seek 10 seconds back/forward
+• [ and ] - Seek 1 minute back/forward
+• HOME/END - Jump to start/end
+• X - Cycle speed (1x/4x/16x)`
 
 	modal := tview.NewModal().
 		SetText(helpText).
@@ -70,18 +96,12 @@ func (d *Dashboard) showProcessSearch() {
 	searchInput := tview.NewInputField().
 		SetLabel("Search term: ").
 		SetFieldWidth(30).
-		SetPlaceholder("Enter process name, PID, or CPU/MEM %")
+		SetPlaceholder("Enter process name, PID, user or command")
 
 	if d.ProcessFilterActive {
 		searchInput.SetText(d.ProcessFilterTerm)
 	}
 
-	originalProcesses := make([]string, 0)
-	for i := 0; i < d.ProcessWidget.GetItemCount(); i++ {
-		text, _ := d.ProcessWidget.GetItemText(i)
-		originalProcesses = append(originalProcesses, text)
-	}
-
 	filterState := &struct {
 		filterType string
 	}{
@@ -122,13 +142,13 @@ func (d *Dashboard) showProcessSearch() {
 			case 4:
 				filterState.filterType = "user"
 			}
-			applyProcessFilter(d, searchInput.GetText(), filterState.filterType, originalProcesses)
+			applyProcessFilter(d, searchInput.GetText(), filterState.filterType)
 		})
 
 	form.AddFormItem(searchInput)
 
 	searchInput.SetChangedFunc(func(text string) {
-		applyProcessFilter(d, text, filterState.filterType, originalProcesses)
+		applyProcessFilter(d, text, filterState.filterType)
 	})
 
 	form.AddButton("Apply", func() {
@@ -139,11 +159,8 @@ func (d *Dashboard) showProcessSearch() {
 			d.ProcessFilterActive = false
 			d.ProcessFilterTerm = ""
 			d.ProcessFilterType = "all"
+			processes.RefreshProcessTable((*utils.Dashboard)(d))
 
-			d.ProcessWidget.Clear()
-			for _, proc := range originalProcesses {
-				d.ProcessWidget.AddItem(proc, "", 0, nil)
-			}
 			d.InModalState = false
 			d.App.SetRoot(d.MainWidget, true).SetFocus(d.ProcessWidget)
 		}).
@@ -171,59 +188,11 @@ func (d *Dashboard) showProcessSearch() {
 	d.App.SetRoot(flex, true).SetFocus(form)
 }
 
-func applyProcessFilter(d *Dashboard, searchTerm, filterType string, originalProcesses []string) {
+func applyProcessFilter(d *Dashboard, searchTerm, filterType string) {
 	d.ProcessFilterActive = true
 	d.ProcessFilterTerm = searchTerm
 	d.ProcessFilterType = filterType
-
-	d.ProcessWidget.Clear()
-	for _, proc := range originalProcesses {
-		var cpu, mem float64
-		var pid int32
-
-		pidStart := strings.Index(proc, "(PID: ")
-		if pidStart != -1 {
-			pidEnd := strings.Index(proc[pidStart:], ")")
-			if pidEnd != -1 && pidStart+6 < len(proc) && pidStart+pidEnd < len(proc) {
-				pidStr := proc[pidStart+6 : pidStart+pidEnd]
-				fmt.Sscanf(pidStr, "%d", &pid)
-			}
-
-			cpuStart := strings.Index(proc, "CPU: ")
-			if cpuStart != -1 {
-				cpuEnd := strings.Index(proc[cpuStart:], "%")
-				if cpuEnd != -1 {
-					cpuStr := proc[cpuStart+5 : cpuStart+cpuEnd]
-					fmt.Sscanf(cpuStr, "%f", &cpu)
-				}
-			}
-
-			memStart := strings.Index(proc, "MEM: ")
-			if memStart != -1 {
-				memEnd := strings.Index(proc[memStart:], "%")
-				if memEnd != -1 {
-					memStr := proc[memStart+5 : memStart+memEnd]
-					fmt.Sscanf(memStr, "%f", &mem)
-				}
-			}
-		}
-
-		show := true
-		switch filterType {
-		case "highcpu":
-			show = cpu > 50
-		case "highmem":
-			show = mem > 50
-		case "system":
-			show = pid < 1000
-		case "user":
-			show = pid >= 1000
-		}
-
-		if show && (searchTerm == "" || utils.CaseInsensitiveContains(proc, searchTerm)) {
-			d.ProcessWidget.AddItem(proc, "", 0, nil)
-		}
-	}
+	processes.RefreshProcessTable((*utils.Dashboard)(d))
 }
 
 func (d *Dashboard) quitModal() {
@@ -259,10 +228,9 @@ func (d *Dashboard) showProcessKillModal(selectedPID int32) {
 		return
 	}
 
-	proc, err := process.NewProcess(selectedPID)
 	var procName string
-	if err == nil {
-		procName, _ = proc.Name()
+	if proc, ok := processes.GetProcess(selectedPID); ok {
+		procName = proc.Name
 	}
 
 	displayText := fmt.Sprintf("Kill process PID: %d", selectedPID)
@@ -307,6 +275,87 @@ func (d *Dashboard) showKillResultModal(pid int32, errorMsg string) {
 	d.App.SetRoot(modal, false).SetFocus(modal)
 }
 
+// showWatchModal offers to watch the selected process by its PID, or by its
+// name so that the watch follows it across restarts.
+func (d *Dashboard) showWatchModal(pid int32) {
+	proc, ok := processes.GetProcess(pid)
+	if !ok {
+		return
+	}
+	d.InModalState = true
+
+	byPID := fmt.Sprintf("PID %d", pid)
+	byName := fmt.Sprintf("Name %s", proc.Name)
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Watch %s (PID %d)?\n\nWatching by name also follows restarts.", proc.Name, pid)).
+		AddButtons([]string{byPID, byName, "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			var err error
+			switch buttonLabel {
+			case byPID:
+				err = processes.Watch((*utils.Dashboard)(d), utils.WatchEntry{PID: pid})
+			case byName:
+				err = processes.Watch((*utils.Dashboard)(d), utils.WatchEntry{Pattern: proc.Name})
+			}
+			if err != nil {
+				d.showWatchErrorModal(err, d.ProcessWidget)
+				return
+			}
+			if buttonLabel != "Cancel" {
+				processes.UpdateWatch((*utils.Dashboard)(d))
+			}
+			d.InModalState = false
+			d.App.SetRoot(d.MainWidget, true).SetFocus(d.ProcessWidget)
+		})
+	d.App.SetRoot(modal, false).SetFocus(modal)
+}
+
+func (d *Dashboard) showWatchErrorModal(err error, focus tview.Primitive) {
+	d.InModalState = true
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("The watch list could not be saved and only applies until SysPulse exits:\n%v", err)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			d.InModalState = false
+			d.App.SetRoot(d.MainWidget, true).SetFocus(focus)
+		})
+	d.App.SetRoot(modal, false).SetFocus(modal)
+}
+
+func (d *Dashboard) showWatchInfoModal() {
+	d.InModalState = true
+
+	textView := tview.NewTextView().
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(processes.GetWatchFormattedInfo((*utils.Dashboard)(d)))
+
+	utils.SetBorderStyle(textView.Box)
+	textView.SetTitle("Watch (Arrow keys to scroll, ESC to close)").
+		SetTitleAlign(tview.AlignCenter)
+
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' || event.Rune() == 'Q' {
+			d.InModalState = false
+			d.App.SetRoot(d.MainWidget, true).SetFocus(d.WatchWidget)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(textView, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	d.App.SetRoot(flex, true).SetFocus(textView)
+}
+
 func (d *Dashboard) showProcessTreeModal() {
 	d.InModalState = true
 
@@ -391,6 +440,123 @@ func (d *Dashboard) showProcessTreeModal() {
 	d.App.SetRoot(flex, true).SetFocus(textView)
 }
 
+func (d *Dashboard) showCgroupModal(path string) {
+	sample, ok := d.CgroupsData.(*cgroups.CgroupSample)
+	if !ok {
+		return
+	}
+	d.InModalState = true
+
+	textView := tview.NewTextView().
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(cgroups.GetCgroupFormattedInfo(sample, path))
+
+	utils.SetBorderStyle(textView.Box)
+	textView.SetTitle("Cgroup (Arrow keys to scroll, ESC to close)").
+		SetTitleAlign(tview.AlignCenter)
+
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' || event.Rune() == 'Q' {
+			d.InModalState = false
+			d.App.SetRoot(d.MainWidget, true).SetFocus(d.CgroupsWidget)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(textView, 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	d.App.SetRoot(flex, true).SetFocus(textView)
+}
+
+func (d *Dashboard) showAlertsModal() {
+	d.InModalState = true
+	focused := d.App.GetFocus()
+
+	textView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true).
+		SetText(buildAlertsText((*utils.Dashboard)(d)))
+
+	utils.SetBorderStyle(textView.Box)
+	textView.SetTitle("Alerts (Arrow keys to scroll, ESC to close)").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeModal := func() {
+		d.InModalState = false
+		d.App.SetRoot(d.MainWidget, true).SetFocus(focused)
+	}
+
+	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeModal()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q', 'Q', 'a', 'A':
+			closeModal()
+			return nil
+		}
+
+		return event
+	})
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(textView, 0, 10, true).
+			AddItem(nil, 0, 1, false), 0, 10, true).
+		AddItem(nil, 0, 1, false)
+
+	d.App.SetRoot(flex, true).SetFocus(textView)
+}
+
+func buildAlertsText(d *utils.Dashboard) string {
+	if d.Alerts == nil {
+		return "Alerts are disabled. Set \"alerts.enabled\" in config.json to enable them."
+	}
+
+	var content strings.Builder
+	writeAlerts := func(title string, list []alerts.Alert, since func(alerts.Alert) time.Time) {
+		content.WriteString(fmt.Sprintf("[blue]%s (%d):[white]\n", title, len(list)))
+		if len(list) == 0 {
+			content.WriteString("  none\n")
+		}
+		for _, alert := range list {
+			content.WriteString(fmt.Sprintf("  [%s]%-8s[white] %s  (%s)\n",
+				severityColor(alert.Severity), alert.Severity, tview.Escape(alert.Summary()), since(alert).Format("15:04:05")))
+			if alert.Description != "" {
+				content.WriteString(fmt.Sprintf("           %s\n", tview.Escape(alert.Description)))
+			}
+		}
+		content.WriteString("\n")
+	}
+
+	writeAlerts("Firing", d.Alerts.Active(), func(a alerts.Alert) time.Time { return a.FiredAt })
+	writeAlerts("Pending", d.Alerts.Pending(), func(a alerts.Alert) time.Time { return a.ActiveSince })
+	writeAlerts("Recently Resolved", d.Alerts.Recent(), func(a alerts.Alert) time.Time { return a.ResolvedAt })
+
+	content.WriteString("[blue]Rules:[white]\n")
+	for _, rule := range d.Alerts.Rules() {
+		content.WriteString(fmt.Sprintf("  %-20s %s\n", rule.Name, tview.Escape(rule.Expr)))
+	}
+
+	return content.String()
+}
+
 func countProcessesByStatus(nodes []*processes.ProcessNode, statusCounts map[string]int) {
 	for _, node := range nodes {
 		statusCounts[node.Status]++
@@ -0,0 +1,449 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"syspulse/internal/plugins"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const pluginManagerKeys = "SPACE enable/disable | R reload | S or ENTER settings | ESC close"
+
+// pluginManagerScreen lists every known plugin and changes plugins_config.json
+// as plugins are toggled or configured.
+type pluginManagerScreen struct {
+	d       *Dashboard
+	manager *plugins.PluginManager
+	config  *plugins.PluginSystemConfig
+
+	listings []plugins.Listing
+	table    *tview.Table
+	details  *tview.TextView
+	layout   tview.Primitive
+	focused  tview.Primitive
+	status   string
+	busy     bool
+}
+
+func (d *Dashboard) showPluginManager() {
+	manager, ok := d.PluginManager.(*plugins.PluginManager)
+	if !ok {
+		return
+	}
+
+	s := &pluginManagerScreen{d: d, manager: manager, focused: d.App.GetFocus()}
+	config, err := plugins.LoadPluginConfig("")
+	if err != nil {
+		s.status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+		config = &plugins.PluginSystemConfig{Plugins: make(map[string]plugins.PluginConfig)}
+	}
+	if config.Plugins == nil {
+		config.Plugins = make(map[string]plugins.PluginConfig)
+	}
+	s.config = config
+
+	s.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	utils.SetBorderStyle(s.table.Box)
+	s.table.SetTitle("Plugin Manager").SetTitleAlign(tview.AlignCenter)
+	s.table.SetSelectionChangedFunc(func(row, column int) {
+		s.showDetails()
+	})
+
+	s.details = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true)
+	utils.SetBorderStyle(s.details.Box)
+	s.details.SetTitle(pluginManagerKeys).SetTitleAlign(tview.AlignCenter)
+
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			s.close()
+			return nil
+		case tcell.KeyEnter:
+			s.showSettings()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q', 'Q', 'u', 'U':
+			s.close()
+			return nil
+		case ' ', 'e', 'E':
+			s.toggle()
+			return nil
+		case 'r', 'R':
+			s.reload()
+			return nil
+		case 's', 'S':
+			s.showSettings()
+			return nil
+		}
+
+		return event
+	})
+
+	s.layout = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(s.table, 0, 5, true).
+			AddItem(s.details, 0, 5, false).
+			AddItem(nil, 0, 1, false), 0, 10, true).
+		AddItem(nil, 0, 1, false)
+
+	d.InModalState = true
+	s.refresh()
+	s.table.Select(1, 0)
+	d.App.SetRoot(s.layout, true).SetFocus(s.table)
+}
+
+func (s *pluginManagerScreen) close() {
+	s.d.InModalState = false
+	s.d.App.SetRoot(s.d.MainWidget, true).SetFocus(s.focused)
+}
+
+// refresh lists the plugins again. Config entries naming no known plugin are
+// left out; `syspulse plugins list` reports them.
+func (s *pluginManagerScreen) refresh() {
+	s.listings, _ = plugins.List(s.config)
+
+	s.table.Clear()
+	for column, header := range []string{"Plugin", "Type", "Version", "State", "Health", "Last update"} {
+		s.table.SetCell(0, column, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	for i, listing := range s.listings {
+		version, state, health := listing.Version, listing.State, "-"
+		lastUpdate := "-"
+		if plugin, loaded := s.manager.GetPlugin(plugins.PluginName(listing.Name)); loaded {
+			version = plugin.Version()
+			state = "enabled"
+			h, _ := s.manager.GetHealth(plugin.Name())
+			if !s.manager.IsPluginEnabled(plugin.Name()) {
+				state = "disabled"
+			}
+			health = fmt.Sprintf("[%s]%s[white]", healthColor(h.State), h.State)
+			if h.Calls > 0 {
+				lastUpdate = fmt.Sprintf("%s (%s)", h.LastRun.Format("15:04:05"), h.LastDuration.Round(time.Microsecond))
+			}
+		}
+
+		row := i + 1
+		s.table.SetCell(row, 0, tview.NewTableCell(tview.Escape(listing.Name)).SetExpansion(1))
+		s.table.SetCell(row, 1, tview.NewTableCell(listing.Kind).SetExpansion(1))
+		s.table.SetCell(row, 2, tview.NewTableCell(tview.Escape(version)).SetExpansion(1))
+		s.table.SetCell(row, 3, tview.NewTableCell(state).SetExpansion(1))
+		s.table.SetCell(row, 4, tview.NewTableCell(health).SetExpansion(1))
+		s.table.SetCell(row, 5, tview.NewTableCell(lastUpdate).SetExpansion(1))
+	}
+
+	s.showDetails()
+}
+
+func (s *pluginManagerScreen) selected() (plugins.Listing, bool) {
+	row, _ := s.table.GetSelection()
+	if row < 1 || row > len(s.listings) {
+		return plugins.Listing{}, false
+	}
+	return s.listings[row-1], true
+}
+
+func (s *pluginManagerScreen) showDetails() {
+	var text strings.Builder
+	if s.status != "" {
+		text.WriteString(s.status + "\n\n")
+	}
+
+	listing, ok := s.selected()
+	if !ok {
+		text.WriteString("No plugins found. Built-in plugins register themselves; executables in the plugin directory are listed as external plugins.")
+		s.details.SetText(text.String())
+		return
+	}
+
+	name := plugins.PluginName(listing.Name)
+	fmt.Fprintf(&text, "[yellow]%s[white] (%s, configured as %q)\n", tview.Escape(name), listing.Kind, listing.Name)
+	fmt.Fprintf(&text, "%s\n\n", tview.Escape(listing.Description))
+
+	if _, loaded := s.manager.GetPlugin(name); loaded {
+		health, _ := s.manager.GetHealth(name)
+		fmt.Fprintf(&text, "Health: %s\n", formatPluginHealth(health))
+		if health.LastError != "" {
+			fmt.Fprintf(&text, "Last error (%s): [red]%s[white]\n", health.LastErrorAt.Format("15:04:05"), tview.Escape(health.LastError))
+		}
+	} else {
+		text.WriteString("Not loaded\n")
+	}
+
+	entry := s.config.Plugins[listing.Name]
+	schema := plugins.SettingsSchema(listing.Name, entry.Settings)
+	if len(schema) > 0 {
+		text.WriteString("\nSettings:\n")
+		for _, setting := range schema {
+			fmt.Fprintf(&text, "  %s = %v\n", setting.Key, settingValue(setting, entry.Settings))
+		}
+	}
+
+	s.details.SetText(text.String())
+	s.details.ScrollToBeginning()
+}
+
+// entry returns the config entry of a plugin, creating one for plugins that
+// have none yet.
+func (s *pluginManagerScreen) entry(key string) plugins.PluginConfig {
+	if entry, exists := s.config.Plugins[key]; exists {
+		return entry
+	}
+	entry := plugins.DefaultPluginConfig(plugins.PluginName(key))
+	entry.Enabled = false
+	return entry
+}
+
+func (s *pluginManagerScreen) save(key string, entry plugins.PluginConfig) error {
+	s.config.Plugins[key] = entry
+	return plugins.SavePluginConfig(s.config, "")
+}
+
+func (s *pluginManagerScreen) toggle() {
+	listing, ok := s.selected()
+	if !ok || s.busy {
+		return
+	}
+
+	name := plugins.PluginName(listing.Name)
+	if s.manager.IsPluginEnabled(name) {
+		entry := s.entry(listing.Name)
+		entry.Enabled = false
+		if err := s.save(listing.Name, entry); err != nil {
+			s.setStatus(fmt.Sprintf("[red]Failed to save: %s[white]", tview.Escape(err.Error())))
+			return
+		}
+		s.run(fmt.Sprintf("Disabling %s...", name), fmt.Sprintf("%s disabled", name), func() error {
+			return plugins.UnloadConfiguredPlugin((*utils.Dashboard)(s.d), listing.Name)
+		}, nil)
+		return
+	}
+
+	s.run(fmt.Sprintf("Enabling %s...", name), fmt.Sprintf("%s enabled", name), func() error {
+		return s.load(listing.Name)
+	}, func() error {
+		entry := s.entry(listing.Name)
+		entry.Enabled = true
+		return s.save(listing.Name, entry)
+	})
+}
+
+func (s *pluginManagerScreen) reload() {
+	listing, ok := s.selected()
+	if !ok || s.busy {
+		return
+	}
+
+	name := plugins.PluginName(listing.Name)
+	if _, loaded := s.manager.GetPlugin(name); !loaded {
+		s.setStatus(fmt.Sprintf("%s is not loaded; press SPACE to enable it", name))
+		return
+	}
+
+	s.run(fmt.Sprintf("Reloading %s...", name), fmt.Sprintf("%s reloaded", name), func() error {
+		return s.load(listing.Name)
+	}, nil)
+}
+
+// load (re)loads a plugin with its config entry and starts its worker. It
+// runs off the UI goroutine, as starting a plugin may take a while.
+func (s *pluginManagerScreen) load(key string) error {
+	d := (*utils.Dashboard)(s.d)
+	if err := plugins.UnloadConfiguredPlugin(d, key); err != nil {
+		return err
+	}
+	if err := plugins.LoadConfiguredPlugin(d, s.config, key); err != nil {
+		return err
+	}
+	if pluginCtx != nil {
+		plugins.StartPluginWorker(pluginCtx, d, plugins.PluginName(key))
+	}
+	return nil
+}
+
+// run does a slow plugin operation in the background, then lays the
+// dashboard out again so plugin widgets appear or disappear. succeeded, if
+// given, runs on the UI goroutine once the operation worked.
+func (s *pluginManagerScreen) run(progress, done string, operation, succeeded func() error) {
+	s.busy = true
+	s.setStatus(progress)
+
+	go func() {
+		err := operation()
+		s.d.App.QueueUpdateDraw(func() {
+			s.busy = false
+			if err == nil && succeeded != nil {
+				err = succeeded()
+			}
+			if err != nil {
+				s.status = fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+			} else {
+				s.status = "[green]" + done + "[white]"
+			}
+
+			s.d.rebuildLayoutGrid()
+			updateFooterText((*utils.Dashboard)(s.d))
+			if s.d.InModalState {
+				s.d.App.SetRoot(s.layout, true).SetFocus(s.table)
+			}
+			s.refresh()
+		})
+	}()
+}
+
+func (s *pluginManagerScreen) setStatus(status string) {
+	s.status = status
+	s.showDetails()
+}
+
+func (s *pluginManagerScreen) showSettings() {
+	listing, ok := s.selected()
+	if !ok || s.busy {
+		return
+	}
+
+	entry := s.entry(listing.Name)
+	schema := plugins.SettingsSchema(listing.Name, entry.Settings)
+	if len(schema) == 0 {
+		s.setStatus(fmt.Sprintf("%s has no settings", plugins.PluginName(listing.Name)))
+		return
+	}
+
+	form := tview.NewForm().
+		SetButtonsAlign(tview.AlignCenter).
+		SetFieldTextColor(tcell.ColorWhite).
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetButtonBackgroundColor(tcell.ColorBlue)
+	utils.SetBorderStyle(form.Box)
+	form.SetTitle(plugins.PluginName(listing.Name) + " Settings").SetTitleAlign(tview.AlignCenter)
+
+	var help strings.Builder
+	for _, setting := range schema {
+		value := settingValue(setting, entry.Settings)
+		switch setting.Type {
+		case plugins.SettingBool:
+			checked, _ := value.(bool)
+			form.AddCheckbox(setting.Key, checked, nil)
+		case plugins.SettingInt:
+			form.AddInputField(setting.Key, fmt.Sprint(value), 20, tview.InputFieldInteger, nil)
+		case plugins.SettingFloat:
+			form.AddInputField(setting.Key, fmt.Sprint(value), 20, tview.InputFieldFloat, nil)
+		default:
+			form.AddInputField(setting.Key, fmt.Sprint(value), 40, nil, nil)
+		}
+
+		description := setting.Description
+		if description == "" {
+			description = "(no description)"
+		}
+		fmt.Fprintf(&help, "[yellow]%s[white] (%s, default %v)\n%s\n\n", setting.Key, setting.Type, setting.Default, tview.Escape(description))
+	}
+
+	helpView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetText(help.String())
+	utils.SetBorderStyle(helpView.Box)
+	helpView.SetTitle("Description")
+
+	back := func() {
+		s.d.App.SetRoot(s.layout, true).SetFocus(s.table)
+	}
+
+	form.AddButton("Save", func() {
+		settings := make(map[string]interface{}, len(entry.Settings)+len(schema))
+		for key, value := range entry.Settings {
+			settings[key] = value
+		}
+		for i, setting := range schema {
+			switch item := form.GetFormItem(i).(type) {
+			case *tview.Checkbox:
+				settings[setting.Key] = item.IsChecked()
+			case *tview.InputField:
+				value, err := plugins.ParseSetting(setting, item.GetText())
+				if err != nil {
+					form.SetTitle(fmt.Sprintf("[red]%s[white]", err))
+					return
+				}
+				settings[setting.Key] = value
+			}
+		}
+
+		entry.Settings = settings
+		if err := s.save(listing.Name, entry); err != nil {
+			form.SetTitle(fmt.Sprintf("[red]Failed to save: %s[white]", err))
+			return
+		}
+
+		back()
+		name := plugins.PluginName(listing.Name)
+		if s.manager.IsPluginEnabled(name) {
+			s.run(fmt.Sprintf("Applying settings to %s...", name), fmt.Sprintf("Saved and applied the settings of %s", name), func() error {
+				return s.load(listing.Name)
+			}, nil)
+			return
+		}
+		s.setStatus(fmt.Sprintf("[green]Saved the settings of %s[white]", name))
+	}).
+		AddButton("Cancel", back)
+	form.SetCancelFunc(back)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(tview.NewFlex().
+				AddItem(form, 0, 1, true).
+				AddItem(helpView, 0, 1, false), 0, 3, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 1, false)
+
+	s.d.App.SetRoot(flex, true).SetFocus(form)
+}
+
+// settingValue is the configured value of a setting, or its default.
+func settingValue(setting plugins.SettingSchema, settings map[string]interface{}) interface{} {
+	if value, exists := settings[setting.Key]; exists {
+		return value
+	}
+	return setting.Default
+}
+
+func healthColor(state plugins.HealthState) string {
+	switch state {
+	case plugins.HealthDegraded:
+		return "yellow"
+	case plugins.HealthFailed:
+		return "red"
+	}
+	return "green"
+}
+
+func formatPluginHealth(health plugins.Health) string {
+	text := fmt.Sprintf("[%s]%s[white]", healthColor(health.State), health.State)
+	if health.ConsecutiveFailures > 0 {
+		text += fmt.Sprintf(", %d failed in a row", health.ConsecutiveFailures)
+	}
+	if health.Calls == 0 {
+		return text + ", not updated yet"
+	}
+	return text + fmt.Sprintf(", last update took %s at %s", health.LastDuration.Round(time.Microsecond), health.LastRun.Format("15:04:05"))
+}
@@ -0,0 +1,367 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+	"syspulse/internal/plugins"
+	"syspulse/internal/recording"
+	"syspulse/internal/services/battery"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/services/disk"
+	"syspulse/internal/services/gpu"
+	"syspulse/internal/services/load"
+	"syspulse/internal/services/memory"
+	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/services/sysinfo"
+	"syspulse/internal/services/temperature"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ReplaySpeeds are the playback speeds the 'x' key cycles through.
+var ReplaySpeeds = []int{1, 4, 16}
+
+const (
+	replayTick = 100 * time.Millisecond
+
+	// replayBackfill is how much of the recording before the playback
+	// position is loaded into history and alerts after a seek, so charts and
+	// trends look the same as they did live.
+	replayBackfill = 15 * time.Minute
+
+	replayFooterText = "SPACE pause | ,/. seek 10s | [/] seek 1m | HOME/END jump | X speed | 'q' to quit"
+)
+
+// replay is set while the dashboard plays a recording instead of running the
+// live collectors.
+var replay *replayer
+
+type replayer struct {
+	mu     sync.Mutex
+	rec    *recording.Recording
+	clock  time.Time
+	index  int
+	speed  int
+	paused bool
+	seeked bool
+
+	// history and alerts are only touched by the replay worker and handed
+	// to the dashboard with each frame.
+	history *history.Store
+	alerts  *alerts.Engine
+}
+
+func newReplayer(rec *recording.Recording, speed int) *replayer {
+	return &replayer{rec: rec, clock: rec.Start(), index: -1, speed: speed, seeked: true}
+}
+
+func (r *replayer) now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clock
+}
+
+// advance moves the playback position by the wall time elapsed since the last
+// tick, scaled by the speed, and pauses at the end of the recording.
+func (r *replayer) advance(elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.paused {
+		return
+	}
+	r.clock = r.clock.Add(elapsed * time.Duration(r.speed))
+	if end := r.rec.End(); !r.clock.Before(end) {
+		r.clock = end
+		r.paused = true
+	}
+}
+
+func (r *replayer) seek(offset time.Duration) {
+	r.seekTo(r.now().Add(offset))
+}
+
+func (r *replayer) seekTo(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t.Before(r.rec.Start()) {
+		t = r.rec.Start()
+	}
+	if t.After(r.rec.End()) {
+		t = r.rec.End()
+	}
+	r.clock = t
+	r.seeked = true
+}
+
+func (r *replayer) togglePause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Resuming at the end starts over rather than pausing again right away.
+	if r.paused && !r.clock.Before(r.rec.End()) {
+		r.clock = r.rec.Start()
+		r.seeked = true
+	}
+	r.paused = !r.paused
+}
+
+func (r *replayer) cycleSpeed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, speed := range ReplaySpeeds {
+		if speed == r.speed {
+			r.speed = ReplaySpeeds[(i+1)%len(ReplaySpeeds)]
+			return
+		}
+	}
+	r.speed = ReplaySpeeds[0]
+}
+
+// pending returns the range of frames to feed into history and alerts to
+// reach the playback position, empty when it did not move past a frame, and
+// whether they start over after a seek.
+func (r *replayer) pending() (from, to int, reset bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	to = r.rec.Index(r.clock)
+	if r.seeked {
+		r.seeked = false
+		r.index = to
+		return r.rec.Index(r.clock.Add(-replayBackfill)), to, true
+	}
+	from = r.index + 1
+	r.index = to
+	return from, to, false
+}
+
+func (r *replayer) status() (clock time.Time, speed int, paused bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clock, r.speed, r.paused
+}
+
+func RunReplay(d *utils.Dashboard, rec *recording.Recording, speed int) error {
+	defer log.Close()
+
+	replay = newReplayer(rec, speed)
+	d.Clock = replay.now
+
+	quit := make(chan struct{})
+
+	startReplayWorker(d, quit)
+
+	d.App.EnableMouse(true)
+	d.App.EnablePaste(true)
+
+	log.Info(fmt.Sprintf("Replaying recording of %s from %s (%d frames)", rec.Header.Host, rec.Start().Format(time.RFC3339), rec.Len()))
+	err := d.App.Run()
+	close(quit)
+
+	if err := plugins.ShutdownPluginSystem(d); err != nil {
+		log.Warn(fmt.Sprintf("Failed to shut down plugins: %v", err))
+	}
+
+	log.Info("Shutting down SysPulse replay")
+	return err
+}
+
+// startReplayWorker takes the place of the widget, alerts and export workers:
+// nothing is collected or sent anywhere, every widget shows the recorded
+// frame at the playback position.
+func startReplayWorker(d *utils.Dashboard, quit chan struct{}) {
+	d.HeaderWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		drawReplayTimeline(screen, x+1, y+h-1, w-2)
+		return x, y, w, h
+	})
+	updateFooterText(d)
+
+	go func() {
+		showReplayFrame(d)
+
+		ticker := time.NewTicker(replayTick)
+		defer ticker.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case now := <-ticker.C:
+				replay.advance(now.Sub(last))
+				last = now
+				showReplayFrame(d)
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// showReplayFrame brings the dashboard to the playback position. Frames
+// skipped over at higher speeds still go into history and alerts; after a
+// seek both are rebuilt from the frames leading up to the new position.
+func showReplayFrame(d *utils.Dashboard) {
+	from, to, reset := replay.pending()
+
+	if reset {
+		if d.Theme.History.Enabled {
+			replay.history = history.NewStore(d.Theme.History)
+		}
+		if d.Theme.Alerts.Enabled {
+			engine, err := alerts.NewEngine(d.Theme.Alerts.Rules)
+			if err != nil {
+				log.Error(fmt.Sprintf("Failed to load alert rules: %v", err))
+			}
+			replay.alerts = engine
+		}
+	}
+	store, engine := replay.history, replay.alerts
+
+	var frame *collector.Snapshot
+	for i := from; i <= to; i++ {
+		snapshot, err := replay.rec.Snapshot(i)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to decode recording frame %d: %v", i+1, err))
+			continue
+		}
+		store.AddSnapshot(snapshot)
+		engine.Evaluate(snapshot, replay.rec.Time(i))
+		frame = snapshot
+	}
+
+	d.App.QueueUpdateDraw(func() {
+		d.History = store
+		d.Alerts = engine
+		if frame != nil {
+			applyReplayFrame(d, frame)
+		}
+		updateHeaderTitle(d)
+		updateFooterText(d)
+	})
+}
+
+func applyReplayFrame(d *utils.Dashboard, frame *collector.Snapshot) {
+	for _, record := range frame.Records() {
+		switch sample := record.Sample.(type) {
+		case *sysinfo.CPUSample:
+			sysinfo.ApplyCPUSample(d, sample)
+		case *memory.MemorySample:
+			memory.ApplyMemorySample(d, sample)
+		case *disk.UsageSample:
+			disk.ApplyUsageSample(d, sample)
+		case *disk.DiskIOData:
+			disk.ApplyDiskIOData(d, sample)
+		case *network.IOSample:
+			network.ApplyIOSample(d, sample)
+		case *network.ConnectionStats:
+			network.ApplyConnectionStats(d, sample)
+		case *load.LoadAverage:
+			load.ApplyLoadAverage(d, sample)
+		case *temperature.TemperatureData:
+			temperature.ApplyTemperatureData(d, sample)
+		case *battery.BatteryInfo:
+			battery.ApplyBatteryInfo(d, sample)
+		case *gpu.GPUSample:
+			gpu.ApplyGPUSample(d, sample)
+		case *processes.ProcessTree:
+			processes.ApplyProcessTree(d, sample)
+		case *cgroups.CgroupSample:
+			cgroups.ApplyCgroupSample(d, sample)
+		case *pressure.PressureSample:
+			pressure.ApplyPressureSample(d, sample)
+		}
+		// Keep the recorded collection time rather than the time it was shown.
+		d.Samples.Put(record)
+	}
+
+	// The process list needs the CPU and memory samples of the same frame.
+	if sample, ok := frame.Get(collector.ProcessTree); ok {
+		tree := sample.(*processes.ProcessTree)
+		processes.ApplyProcessList(d, tree)
+		updateProcessTitle(d, tree.TotalCount)
+	}
+}
+
+func replayHeaderTitle() string {
+	clock, speed, paused := replay.status()
+
+	state := "▶"
+	if paused {
+		state = "⏸"
+	}
+	return fmt.Sprint("SysPulse v", utils.VER, " | REPLAY ", replay.rec.Header.Host, " | ", state, " ", speed, "x | ", clock.Local().Format("2006-01-02 15:04:05"))
+}
+
+// drawReplayTimeline draws the playback position over the bottom border of
+// the header: a progress bar followed by elapsed and total time.
+func drawReplayTimeline(screen tcell.Screen, x, y, width int) {
+	clock, _, _ := replay.status()
+	elapsed := clock.Sub(replay.rec.Start())
+	total := replay.rec.Duration()
+
+	label := fmt.Sprintf(" %s / %s ", formatReplayDuration(elapsed), formatReplayDuration(total))
+	barWidth := width - len(label) - 2
+	if barWidth < 10 {
+		tview.Print(screen, label, x, y, width, tview.AlignCenter, tview.Styles.PrimaryTextColor)
+		return
+	}
+
+	filled := barWidth
+	if total > 0 {
+		filled = int(float64(barWidth) * float64(elapsed) / float64(total))
+	}
+	filled = min(max(filled, 0), barWidth)
+
+	bar := " [green]" + strings.Repeat("━", filled) + "[white]●[gray]" + strings.Repeat("─", max(barWidth-filled-1, 0)) + "[-]"
+	tview.Print(screen, bar+label, x, y, width, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+}
+
+func formatReplayDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// handleReplayKey handles the playback keys and reports whether the event was
+// one of them. The replay worker picks up the change on its next tick.
+func handleReplayKey(event *tcell.EventKey) bool {
+	switch event.Key() {
+	case tcell.KeyHome:
+		replay.seekTo(replay.rec.Start())
+	case tcell.KeyEnd:
+		replay.seekTo(replay.rec.End())
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case ' ':
+			replay.togglePause()
+		case ',':
+			replay.seek(-10 * time.Second)
+		case '.':
+			replay.seek(10 * time.Second)
+		case '[':
+			replay.seek(-time.Minute)
+		case ']':
+			replay.seek(time.Minute)
+		case 'x', 'X':
+			replay.cycleSpeed()
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"syspulse/internal/services/processes"
+	"syspulse/internal/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var ioClassDescriptions = map[string]string{
+	"none":        "Follows the nice value",
+	"realtime":    "Served before everything else, needs root",
+	"best-effort": "The default, level 0 is served first",
+	"idle":        "Served only when nothing else uses the disk",
+}
+
+// showProcessTuneModal offers renice, CPU affinity and I/O priority for the
+// selected process, after the same kind of checks as killing it.
+func (d *Dashboard) showProcessTuneModal(selectedPID int32) {
+	d.InModalState = true
+
+	canTune, reason := processes.CanTuneProcess(selectedPID)
+	if !canTune {
+		modal := tview.NewModal().
+			SetText(fmt.Sprintf("Cannot tune process PID %d: %s", selectedPID, reason)).
+			AddButtons([]string{"OK"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				d.closeTuning()
+			})
+		d.App.SetRoot(modal, false).SetFocus(modal)
+		return
+	}
+
+	proc, ok := processes.GetProcess(selectedPID)
+	if !ok {
+		d.closeTuning()
+		return
+	}
+
+	affinity := "unknown"
+	cpus, err := processes.GetProcessAffinity(selectedPID)
+	if err == nil {
+		affinity = processes.FormatCPUList(cpus)
+	}
+	ioPriority := "unknown"
+	priority, err := processes.GetProcessIOPriority(selectedPID)
+	if err == nil {
+		ioPriority = priority.String()
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Tune process: %s (PID: %d)\n\nNice %d | CPUs %s | I/O %s",
+			proc.Name, selectedPID, proc.Nice, affinity, ioPriority)).
+		AddButtons([]string{"Renice", "CPU Affinity", "I/O Priority", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Renice":
+				d.showReniceDialog(proc)
+			case "CPU Affinity":
+				d.showAffinityDialog(proc, cpus)
+			case "I/O Priority":
+				d.showIOPriorityDialog(proc, priority)
+			default:
+				d.closeTuning()
+			}
+		})
+	d.App.SetRoot(modal, false).SetFocus(modal)
+}
+
+func (d *Dashboard) closeTuning() {
+	d.InModalState = false
+	d.App.SetRoot(d.MainWidget, true).SetFocus(d.ProcessWidget)
+}
+
+// showTuningDialog shows a fixed size dialog drawn on a Box, for the slider
+// and the checkbox grid tview has no widgets for. ENTER calls apply, which
+// returns an error message to show in the dialog or "" to close it.
+func (d *Dashboard) showTuningDialog(title string, width, lines int, hint string,
+	draw func(screen tcell.Screen, x, y, width int),
+	input func(event *tcell.EventKey) bool,
+	apply func() string) {
+	var status string
+
+	box := tview.NewBox()
+	utils.SetBorderStyle(box)
+	box.SetTitle(title).SetTitleAlign(tview.AlignCenter)
+	box.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		draw(screen, x+2, y+1, w-4)
+		tview.Print(screen, hint, x+2, y+h-4, w-4, tview.AlignLeft, tcell.ColorGray)
+		for i, line := range utils.WrapText(status, w-4) {
+			if i == 2 {
+				break
+			}
+			tview.Print(screen, line, x+2, y+h-3+i, w-4, tview.AlignLeft, tcell.ColorRed)
+		}
+		return x + 1, y + 1, w - 2, h - 2
+	})
+
+	box.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			d.closeTuning()
+		case tcell.KeyEnter:
+			if status = apply(); status == "" {
+				d.closeTuning()
+			}
+		default:
+			if input(event) {
+				status = ""
+			}
+		}
+		return nil
+	})
+
+	// The content, a blank line, the hint and two lines for errors.
+	height := lines + 6
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(box, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	d.App.SetRoot(flex, true).SetFocus(box)
+}
+
+// formatSlider draws value on a track with one cell per possible value.
+func formatSlider(value, lo, hi int) string {
+	return fmt.Sprintf("[gray]%d [green]%s[white]●[gray]%s %d[-]",
+		lo, strings.Repeat("━", value-lo), strings.Repeat("─", hi-value), hi)
+}
+
+func clamp(value, lo, hi int) int {
+	return min(max(value, lo), hi)
+}
+
+func (d *Dashboard) showReniceDialog(proc processes.ProcessRow) {
+	nice := int(proc.Nice)
+
+	d.showTuningDialog(fmt.Sprintf("Renice %s (PID %d)", proc.Name, proc.PID), 64, 3,
+		"←/→ adjust  PgUp/PgDn ±5  ENTER apply  ESC cancel",
+		func(screen tcell.Screen, x, y, width int) {
+			tview.Print(screen, fmt.Sprintf("Nice value: [yellow]%d[-] (currently %d, lower runs first)", nice, proc.Nice),
+				x, y, width, tview.AlignLeft, tcell.ColorWhite)
+			tview.Print(screen, formatSlider(nice, processes.MinNice, processes.MaxNice),
+				x, y+2, width, tview.AlignLeft, tcell.ColorWhite)
+		},
+		func(event *tcell.EventKey) bool {
+			switch event.Key() {
+			case tcell.KeyLeft:
+				nice--
+			case tcell.KeyRight:
+				nice++
+			case tcell.KeyPgUp:
+				nice -= 5
+			case tcell.KeyPgDn:
+				nice += 5
+			case tcell.KeyHome:
+				nice = processes.MinNice
+			case tcell.KeyEnd:
+				nice = processes.MaxNice
+			default:
+				return false
+			}
+			nice = clamp(nice, processes.MinNice, processes.MaxNice)
+			return true
+		},
+		func() string {
+			return processes.ReniceProcess(proc.PID, nice)
+		})
+}
+
+// showAffinityDialog shows a checkbox for each CPU this machine has.
+func (d *Dashboard) showAffinityDialog(proc processes.ProcessRow, current []int) {
+	const cellWidth = 8
+	n := runtime.NumCPU()
+	columns := min(n, 8)
+	rows := (n + columns - 1) / columns
+
+	selected := make([]bool, n)
+	for _, cpu := range current {
+		if cpu < n {
+			selected[cpu] = true
+		}
+	}
+	if current == nil {
+		for cpu := range selected {
+			selected[cpu] = true
+		}
+	}
+	cursor := 0
+
+	allowed := func() []int {
+		var cpus []int
+		for cpu, on := range selected {
+			if on {
+				cpus = append(cpus, cpu)
+			}
+		}
+		return cpus
+	}
+
+	width := max(columns*cellWidth+4, 64)
+	d.showTuningDialog(fmt.Sprintf("CPU Affinity %s (PID %d)", proc.Name, proc.PID), width, rows+2,
+		"Arrows move  SPACE toggle  A all/none  ENTER apply  ESC cancel",
+		func(screen tcell.Screen, x, y, width int) {
+			list := processes.FormatCPUList(allowed())
+			if list == "" {
+				list = "[red]none[-]"
+			}
+			tview.Print(screen, "Allowed CPUs: [yellow]"+list+"[-]", x, y, width, tview.AlignLeft, tcell.ColorWhite)
+
+			for cpu := 0; cpu < n; cpu++ {
+				mark := " "
+				if selected[cpu] {
+					mark = "x"
+				}
+				cell := fmt.Sprintf("[%s] %d", mark, cpu)
+				if cpu == cursor {
+					cell = "[black:yellow]" + tview.Escape(cell) + "[-:-]"
+				} else {
+					cell = tview.Escape(cell)
+				}
+				tview.Print(screen, cell, x+(cpu%columns)*cellWidth, y+2+cpu/columns, cellWidth, tview.AlignLeft, tcell.ColorWhite)
+			}
+		},
+		func(event *tcell.EventKey) bool {
+			switch event.Key() {
+			case tcell.KeyLeft:
+				cursor--
+			case tcell.KeyRight:
+				cursor++
+			case tcell.KeyUp:
+				cursor -= columns
+			case tcell.KeyDown:
+				cursor += columns
+			case tcell.KeyRune:
+				switch event.Rune() {
+				case ' ':
+					selected[cursor] = !selected[cursor]
+				case 'a', 'A':
+					all := !slices.Contains(selected, false)
+					for cpu := range selected {
+						selected[cpu] = !all
+					}
+				default:
+					return false
+				}
+			default:
+				return false
+			}
+			cursor = clamp(cursor, 0, n-1)
+			return true
+		},
+		func() string {
+			return processes.SetProcessAffinity(proc.PID, allowed())
+		})
+}
+
+func (d *Dashboard) showIOPriorityDialog(proc processes.ProcessRow, current processes.IOPriority) {
+	class := max(slices.Index(processes.IOClasses, current.Class), 0)
+	level := current.Level
+	if !current.HasLevel() {
+		// What the kernel uses for a process at nice 0.
+		level = 4
+	}
+	row := 0
+
+	priority := func() processes.IOPriority {
+		return processes.IOPriority{Class: processes.IOClasses[class], Level: level}
+	}
+
+	d.showTuningDialog(fmt.Sprintf("I/O Priority %s (PID %d)", proc.Name, proc.PID), 64, 4,
+		"↑/↓ select  ←/→ change  ENTER apply  ESC cancel",
+		func(screen tcell.Screen, x, y, width int) {
+			marker := func(r int) string {
+				if r == row {
+					return "[yellow]▶[-] "
+				}
+				return "  "
+			}
+
+			tview.Print(screen, fmt.Sprintf("%sClass: ◀ [yellow]%s[-] ▶", marker(0), priority().Class),
+				x, y, width, tview.AlignLeft, tcell.ColorWhite)
+			levelText := "[gray]not used by this class[-]"
+			if priority().HasLevel() {
+				levelText = formatSlider(level, 0, processes.MaxIOLevel)
+			}
+			tview.Print(screen, fmt.Sprintf("%sLevel: %s", marker(1), levelText),
+				x, y+1, width, tview.AlignLeft, tcell.ColorWhite)
+			tview.Print(screen, ioClassDescriptions[priority().Class], x, y+3, width, tview.AlignLeft, tcell.ColorGray)
+		},
+		func(event *tcell.EventKey) bool {
+			delta := 0
+			switch event.Key() {
+			case tcell.KeyUp, tcell.KeyDown:
+				row = 1 - row
+				return true
+			case tcell.KeyLeft:
+				delta = -1
+			case tcell.KeyRight:
+				delta = 1
+			default:
+				return false
+			}
+
+			if row == 0 {
+				class = (class + delta + len(processes.IOClasses)) % len(processes.IOClasses)
+			} else {
+				level = clamp(level+delta, 0, processes.MaxIOLevel)
+			}
+			return true
+		},
+		func() string {
+			return processes.SetProcessIOPriority(proc.PID, priority())
+		})
+}
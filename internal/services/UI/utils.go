@@ -2,18 +2,33 @@ package ui
 
 import (
 	"fmt"
+	"strings"
+	"syspulse/internal/alerts"
+	"syspulse/internal/history"
+	"syspulse/internal/services/disk"
+	"syspulse/internal/services/processes"
 	"syspulse/internal/services/sysinfo"
 	"syspulse/internal/utils"
+	"time"
+
+	"github.com/rivo/tview"
 )
 
 type Dashboard utils.Dashboard
 
-func formatSort(sorttype string) string {
-	if sorttype == "mem" {
-		return "RAM"
-	} else {
-		return "CPU"
+func formatSort(sorttype string, reverse bool) string {
+	label := "CPU"
+	switch sorttype {
+	case "", "cpu":
+	case "mem":
+		label = "RAM"
+	default:
+		label = processes.ColumnHeader(sorttype)
+	}
+	if reverse {
+		label += " (reversed)"
 	}
+	return label
 }
 
 func createHeaderTitle() string {
@@ -21,5 +36,125 @@ func createHeaderTitle() string {
 }
 
 func updateHeaderTitle(d *utils.Dashboard) {
-	d.HeaderWidget.SetTitle(createHeaderTitle())
+	title := createHeaderTitle()
+	if replay != nil {
+		title = replayHeaderTitle()
+	}
+	d.HeaderWidget.SetTitle(title + alertsHeaderText(d))
+}
+
+const footerText = "Press 'h' for help | TAB to cycle widgets | 'a' for alerts | 'q' to quit"
+
+func updateProcessTitle(d *utils.Dashboard, count int) {
+	if d.ProcessWidget == nil {
+		return
+	}
+
+	d.ProcessWidget.SetTitle(fmt.Sprint("Processes - ", count, " Sorted by: ", formatSort(d.Theme.Sorting, d.ProcessSortReverse)))
+}
+
+func alertsHeaderText(d *utils.Dashboard) string {
+	active := d.Alerts.Active()
+	if len(active) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | [%s]%d alert(s) firing[-]", severityColor(active[0].Severity), len(active))
+}
+
+func updateFooterText(d *utils.Dashboard) {
+	if d.FooterWidget == nil {
+		return
+	}
+
+	footer := footerText
+	if replay != nil {
+		footer = replayFooterText
+	}
+
+	active := d.Alerts.Active()
+	if len(active) == 0 {
+		d.FooterWidget.SetText(footer)
+		return
+	}
+
+	text := fmt.Sprintf("[%s]%s: %s[-]", severityColor(active[0].Severity), strings.ToUpper(active[0].Severity), tview.Escape(active[0].Summary()))
+	if len(active) > 1 {
+		text += fmt.Sprintf(" (+%d more)", len(active)-1)
+	}
+	d.FooterWidget.SetText(text + " | " + footer)
+}
+
+func severityColor(severity string) string {
+	switch severity {
+	case alerts.SeverityCritical:
+		return "red"
+	case alerts.SeverityWarning:
+		return "yellow"
+	}
+	return "blue"
+}
+
+const historyTrendWindow = 5 * time.Minute
+
+type historyTrend struct {
+	label  string
+	metric string
+	labels map[string]string
+	format func(float64) string
+}
+
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+func formatRate(value float64) string {
+	if value < 0 {
+		value = 0
+	}
+	return formatMemoryBytes(uint64(value)) + "/s"
+}
+
+func historyTrendText(d *utils.Dashboard, trends ...historyTrend) string {
+	if d.History == nil {
+		return ""
+	}
+
+	now := d.Now()
+	var text string
+	for _, trend := range trends {
+		summary := history.Summarize(d.History.Range(trend.metric, trend.labels, now.Add(-historyTrendWindow), now))
+		if summary.Count == 0 {
+			continue
+		}
+		text += fmt.Sprintf("%s: min %s / avg %s / max %s\n",
+			trend.label, trend.format(summary.Min), trend.format(summary.Avg), trend.format(summary.Max))
+	}
+
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n--- Last %d minutes ---\n%s", int(historyTrendWindow.Minutes()), text)
+}
+
+func diskIOTrendText(d *utils.Dashboard) string {
+	ioData, ok := d.DiskIOData.(*disk.DiskIOData)
+	if !ok {
+		return ""
+	}
+
+	var trends []historyTrend
+	for _, device := range ioData.Disks {
+		labels := map[string]string{"device": device.Name}
+		trends = append(trends,
+			historyTrend{device.Name + " read", "disk_read_bytes_per_second", labels, formatRate},
+			historyTrend{device.Name + " write", "disk_write_bytes_per_second", labels, formatRate},
+		)
+	}
+	return historyTrendText(d, trends...)
+}
+
+func recordHistory(d *utils.Dashboard, name string) {
+	if record, ok := d.Samples.Record(name); ok {
+		d.History.AddRecord(record)
+	}
 }
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"syspulse/internal/plugins"
 	"syspulse/internal/services/battery"
+	"syspulse/internal/services/cgroups"
 	"syspulse/internal/services/disk"
 	"syspulse/internal/services/gpu"
 	"syspulse/internal/services/load"
 	"syspulse/internal/services/memory"
 	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
 	"syspulse/internal/services/processes"
 	"syspulse/internal/services/sysinfo"
 	"syspulse/internal/services/temperature"
@@ -32,6 +34,9 @@ func (d *Dashboard) initWidgets() {
 	d.initDiskIOWidget()
 	d.initProcessTreeWidget()
 	d.initBatteryWidget()
+	d.initCgroupsWidget()
+	d.initPressureWidget()
+	d.initWatchWidget()
 	d.initPluginSystem()
 	d.initMainLayout()
 }
@@ -74,13 +79,17 @@ func (d *Dashboard) initCPUWidget() {
 				case 'q', 'Q':
 					d.quitModal()
 					return nil
+				case 'v', 'V':
+					d.Theme.Layout.CPU.View = utils.ToggleView(d.Theme.Layout.CPU.View)
+					return nil
 				case 'i', 'I', rune(tcell.KeyEnter):
 					textView := tview.NewTextView().
 						SetDynamicColors(true).
 						SetRegions(true).
 						SetWordWrap(true).
 						SetScrollable(true).
-						SetText(sysinfo.GetCpuFormattedInfo())
+						SetText(sysinfo.GetCpuFormattedInfo() + historyTrendText((*utils.Dashboard)(d),
+							historyTrend{"CPU", "cpu_usage_percent", nil, formatPercent}))
 
 					utils.SetBorderStyle(textView.Box)
 					textView.SetTitle("CPU Information (Arrow keys to scroll, ESC to close)").
@@ -136,9 +145,14 @@ func (d *Dashboard) initMemoryWidget() {
 				case 'q', 'Q':
 					d.quitModal()
 					return nil
+				case 'v', 'V':
+					d.Theme.Layout.Memory.View = utils.ToggleView(d.Theme.Layout.Memory.View)
+					return nil
 				case 'i', 'I', rune(tcell.KeyEnter):
 					modal := tview.NewModal().
-						SetText(memory.GetMemoryFormattedInfo()).
+						SetText(memory.GetMemoryFormattedInfo() + historyTrendText((*utils.Dashboard)(d),
+							historyTrend{"RAM", "memory_used_percent", nil, formatPercent},
+							historyTrend{"Swap", "swap_used_percent", nil, formatPercent})).
 						AddButtons([]string{"Close"}).
 						SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 							d.App.SetRoot(d.MainWidget, true).SetFocus(d.MemWidget)
@@ -231,13 +245,18 @@ func (d *Dashboard) initNetworkWidget() {
 				case 'q', 'Q':
 					d.quitModal()
 					return nil
+				case 'v', 'V':
+					d.Theme.Layout.Network.View = utils.ToggleView(d.Theme.Layout.Network.View)
+					return nil
 				case 'i', 'I', rune(tcell.KeyEnter):
 					textView := tview.NewTextView().
 						SetDynamicColors(true).
 						SetRegions(true).
 						SetWordWrap(true).
 						SetScrollable(true).
-						SetText(network.GetNetworkFormattedInfo())
+						SetText(network.GetNetworkFormattedInfo() + historyTrendText((*utils.Dashboard)(d),
+							historyTrend{"Upload", "network_aggregate_transmit_bytes_per_second", nil, formatRate},
+							historyTrend{"Download", "network_aggregate_receive_bytes_per_second", nil, formatRate}))
 
 					utils.SetBorderStyle(textView.Box)
 					textView.SetTitle("Network Activity & Interfaces (Arrow keys to scroll, ESC to close)").
@@ -334,14 +353,14 @@ func (d *Dashboard) initGPUWidget() {
 
 func (d *Dashboard) initProcessWidget() {
 	if d.Theme.Layout.Process.Enabled {
-		d.ProcessWidget = tview.NewList()
+		d.ProcessWidget = tview.NewTable().
+			SetSelectable(true, false).
+			SetFixed(1, 0).
+			SetSelectedStyle(tcell.StyleDefault.
+				Background(tcell.ColorDarkBlue).
+				Foreground(utils.GetColorFromName(d.Theme.Altforeground)))
 		utils.SetBorderStyle(d.ProcessWidget.Box)
 		d.ProcessWidget.SetTitle(fmt.Sprint("Processes - ", processes.GetNrProcesses()))
-		d.ProcessWidget.ShowSecondaryText(false)
-		d.ProcessWidget.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
-		d.ProcessWidget.SetSelectedTextColor(utils.GetColorFromName(d.Theme.Altforeground))
-		d.ProcessWidget.SetWrapAround(true)
-		d.ProcessWidget.SetMainTextColor(utils.GetColorFromName(d.Theme.Layout.Process.ForegroundColor))
 		d.ProcessWidget.SetInputCapture(d.getProcessInputHandler())
 
 		if d.Theme.Layout.Process.BorderColor != "" {
@@ -356,18 +375,6 @@ func (d *Dashboard) initProcessWidget() {
 func (d *Dashboard) getProcessInputHandler() func(event *tcell.EventKey) *tcell.EventKey {
 	return func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
-		case tcell.KeyUp:
-			current := d.ProcessWidget.GetCurrentItem()
-			if current > 0 {
-				d.ProcessWidget.SetCurrentItem(current - 1)
-			}
-			return nil
-		case tcell.KeyDown:
-			current := d.ProcessWidget.GetCurrentItem()
-			if current < d.ProcessWidget.GetItemCount()-1 {
-				d.ProcessWidget.SetCurrentItem(current + 1)
-			}
-			return nil
 		case tcell.KeyEnter:
 			processes.ShowProcessDetails((*utils.Dashboard)(d))
 			return nil
@@ -375,11 +382,21 @@ func (d *Dashboard) getProcessInputHandler() func(event *tcell.EventKey) *tcell.
 
 		switch event.Rune() {
 		case 'y', 'Y':
-			if d.Theme.Sorting == "cpu" {
-				d.Theme.Sorting = "mem"
-			} else {
+			if d.Theme.Sorting == "mem" {
 				d.Theme.Sorting = "cpu"
+			} else {
+				d.Theme.Sorting = "mem"
 			}
+			d.ProcessSortReverse = false
+			d.refreshProcessTable()
+			return nil
+		case '<', '>':
+			d.cycleProcessSort(event.Rune() == '>')
+			return nil
+		case 'r', 'R':
+			d.ProcessSortReverse = !d.ProcessSortReverse
+			d.refreshProcessTable()
+			return nil
 		case 'i', 'I':
 			processes.ShowProcessDetails((*utils.Dashboard)(d))
 			return nil
@@ -390,30 +407,70 @@ func (d *Dashboard) getProcessInputHandler() func(event *tcell.EventKey) *tcell.
 			d.quitModal()
 			return nil
 		case 'w', 'W', 'o', 'O':
-			current := d.ProcessWidget.GetCurrentItem()
-			if current > 0 {
-				d.ProcessWidget.SetCurrentItem(current - 1)
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case 's', 'S', 'l', 'L':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k', 'K':
+			// A recorded PID may belong to an unrelated process by now.
+			if replay != nil {
+				return nil
+			}
+			if pid, ok := processes.SelectedPID((*utils.Dashboard)(d)); ok {
+				d.showProcessKillModal(pid)
 			}
 			return nil
-		case 's', 'S', 'l', 'L':
-			current := d.ProcessWidget.GetCurrentItem()
-			if current < d.ProcessWidget.GetItemCount()-1 {
-				d.ProcessWidget.SetCurrentItem(current + 1)
+		case 'e', 'E':
+			if replay != nil {
+				return nil
+			}
+			if pid, ok := processes.SelectedPID((*utils.Dashboard)(d)); ok {
+				d.showProcessTuneModal(pid)
 			}
 			return nil
-		case 'k', 'K':
-			var selectedPID int32
-			currentItem := d.ProcessWidget.GetCurrentItem()
-			if currentItem >= 0 && currentItem < d.ProcessWidget.GetItemCount() {
-				text, _ := d.ProcessWidget.GetItemText(currentItem)
-				fmt.Sscanf(text, "%s (PID: %d)", new(string), &selectedPID)
+		case 't', 'T':
+			if replay != nil {
+				return nil
 			}
-			d.showProcessKillModal(selectedPID)
+			if pid, ok := processes.SelectedPID((*utils.Dashboard)(d)); ok {
+				d.showWatchModal(pid)
+			}
+			return nil
 		}
 		return event
 	}
 }
 
+// cycleProcessSort sorts the process table by the next or previous visible
+// column.
+func (d *Dashboard) cycleProcessSort(forward bool) {
+	columns := processes.VisibleColumns((*utils.Dashboard)(d))
+	if len(columns) == 0 {
+		return
+	}
+
+	current := 0
+	for i, column := range columns {
+		if column.Key == d.Theme.Sorting {
+			current = i
+			break
+		}
+	}
+	if forward {
+		current = (current + 1) % len(columns)
+	} else {
+		current = (current - 1 + len(columns)) % len(columns)
+	}
+
+	d.Theme.Sorting = columns[current].Key
+	d.ProcessSortReverse = false
+	d.refreshProcessTable()
+}
+
+func (d *Dashboard) refreshProcessTable() {
+	processes.RefreshProcessTable((*utils.Dashboard)(d))
+	updateProcessTitle((*utils.Dashboard)(d), processes.GetNrProcesses())
+}
+
 func (d *Dashboard) initLoadWidget() {
 	d.LoadWidget = tview.NewBox()
 	utils.SetBorderStyle(d.LoadWidget)
@@ -520,13 +577,16 @@ func (d *Dashboard) initDiskIOWidget() {
 	d.DiskIOWidget.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		key := event.Rune()
 		switch key {
+		case 'v', 'V':
+			d.Theme.Layout.DiskIO.View = utils.ToggleView(d.Theme.Layout.DiskIO.View)
+			return nil
 		case 'i', 'I', rune(tcell.KeyEnter):
 			textView := tview.NewTextView().
 				SetDynamicColors(true).
 				SetRegions(true).
 				SetWordWrap(true).
 				SetScrollable(true).
-				SetText(disk.GetDiskIOFormattedInfo())
+				SetText(disk.GetDiskIOFormattedInfo() + diskIOTrendText((*utils.Dashboard)(d)))
 
 			utils.SetBorderStyle(textView.Box)
 			textView.SetTitle("Disk I/O Information (Arrow keys to scroll, ESC to close)").
@@ -621,6 +681,123 @@ func (d *Dashboard) initBatteryWidget() {
 	}
 }
 
+func (d *Dashboard) initCgroupsWidget() {
+	d.CgroupsWidget = tview.NewTreeView()
+	utils.SetBorderStyle(d.CgroupsWidget.Box)
+	d.CgroupsWidget.SetTitle("Cgroups").
+		SetTitleAlign(tview.AlignCenter)
+	d.CgroupsWidget.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+	d.CgroupsWidget.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'i', 'I':
+			if path, ok := cgroups.SelectedCgroup((*utils.Dashboard)(d)); ok {
+				d.showCgroupModal(path)
+			}
+			return nil
+		}
+		return event
+	})
+
+	if d.Theme.Layout.Cgroups.BorderColor != "" {
+		d.CgroupsWidget.SetBorderColor(utils.GetColorFromName(d.Theme.Layout.Cgroups.BorderColor))
+	}
+	if d.Theme.Layout.Cgroups.ForegroundColor != "" {
+		d.CgroupsWidget.SetTitleColor(utils.GetColorFromName(d.Theme.Layout.Cgroups.ForegroundColor))
+	}
+}
+
+func (d *Dashboard) initPressureWidget() {
+	d.PressureWidget = tview.NewBox()
+	utils.SetBorderStyle(d.PressureWidget)
+	d.PressureWidget.SetTitle("Pressure").
+		SetTitleAlign(tview.AlignCenter)
+	d.PressureWidget.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		key := event.Rune()
+		switch key {
+		case 'v', 'V':
+			d.Theme.Layout.Pressure.View = utils.ToggleView(d.Theme.Layout.Pressure.View)
+			return nil
+		case 'i', 'I', rune(tcell.KeyEnter):
+			modal := tview.NewModal().
+				SetText(pressure.GetPressureFormattedInfo((*utils.Dashboard)(d))).
+				AddButtons([]string{"Close"}).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					d.App.SetRoot(d.MainWidget, true).SetFocus(d.PressureWidget)
+				})
+			modal.SetTitle("Pressure Stall Information")
+			d.App.SetRoot(modal, true).SetFocus(modal)
+		}
+		return nil
+	})
+
+	if d.Theme.Layout.Pressure.BorderColor != "" {
+		d.PressureWidget.SetBorderColor(utils.GetColorFromName(d.Theme.Layout.Pressure.BorderColor))
+	}
+	if d.Theme.Layout.Pressure.ForegroundColor != "" {
+		d.PressureWidget.SetTitleColor(utils.GetColorFromName(d.Theme.Layout.Pressure.ForegroundColor))
+	}
+}
+
+func (d *Dashboard) initWatchWidget() {
+	d.WatchWidget = tview.NewBox()
+	utils.SetBorderStyle(d.WatchWidget)
+	d.WatchWidget.SetTitle("Watch").
+		SetTitleAlign(tview.AlignCenter)
+	d.WatchWidget.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			d.moveWatchSelection(-1)
+			return nil
+		case tcell.KeyDown:
+			d.moveWatchSelection(1)
+			return nil
+		case tcell.KeyEnter:
+			d.showWatchInfoModal()
+			return nil
+		case tcell.KeyDelete:
+			d.unwatchSelected()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'w', 'W', 'o', 'O':
+			d.moveWatchSelection(-1)
+		case 's', 'S', 'l', 'L':
+			d.moveWatchSelection(1)
+		case 'i', 'I':
+			d.showWatchInfoModal()
+		case 'x', 'X':
+			d.unwatchSelected()
+		}
+		return nil
+	})
+
+	if d.Theme.Layout.Watch.BorderColor != "" {
+		d.WatchWidget.SetBorderColor(utils.GetColorFromName(d.Theme.Layout.Watch.BorderColor))
+	}
+	if d.Theme.Layout.Watch.ForegroundColor != "" {
+		d.WatchWidget.SetTitleColor(utils.GetColorFromName(d.Theme.Layout.Watch.ForegroundColor))
+	}
+}
+
+func (d *Dashboard) moveWatchSelection(delta int) {
+	last := len(processes.WatchList((*utils.Dashboard)(d))) - 1
+	d.WatchSelected = max(0, min(d.WatchSelected+delta, last))
+}
+
+func (d *Dashboard) unwatchSelected() {
+	entry, ok := processes.SelectedWatchEntry((*utils.Dashboard)(d))
+	if !ok {
+		return
+	}
+	if err := processes.Unwatch((*utils.Dashboard)(d), entry); err != nil {
+		d.showWatchErrorModal(err, d.WatchWidget)
+	}
+	processes.UpdateWatch((*utils.Dashboard)(d))
+}
+
 func (d *Dashboard) initPluginSystem() {
 	if err := plugins.InitializePluginSystem((*utils.Dashboard)(d)); err != nil {
 		fmt.Printf("Failed to initialize plugin system: %v\n", err)
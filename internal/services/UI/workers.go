@@ -1,14 +1,20 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/collector/builtin"
 	"syspulse/internal/plugins"
 	"syspulse/internal/services/battery"
+	"syspulse/internal/services/cgroups"
 	"syspulse/internal/services/disk"
 	"syspulse/internal/services/gpu"
 	"syspulse/internal/services/load"
 	"syspulse/internal/services/memory"
 	"syspulse/internal/services/network"
+	"syspulse/internal/services/pressure"
 	"syspulse/internal/services/processes"
 	"syspulse/internal/services/sysinfo"
 	"syspulse/internal/services/temperature"
@@ -19,6 +25,7 @@ import (
 func startWorkers(d *utils.Dashboard, quit chan struct{}) {
 	startIndividualWidgetWorkers(d, quit)
 	startPluginUpdateWorker(d, quit)
+	startAlertsWorker(d, quit)
 }
 
 func startIndividualWidgetWorkers(d *utils.Dashboard, quit chan struct{}) {
@@ -28,13 +35,7 @@ func startIndividualWidgetWorkers(d *utils.Dashboard, quit chan struct{}) {
 	startWidgetWorker(d, quit, "network", func() { network.UpdateNetwork(d) }, d.Theme.Layout.Network)
 	startWidgetWorker(d, quit, "process", func() {
 		processes.UpdateProcesses(d)
-		if d.ProcessWidget != nil {
-			sortLabel := "CPU"
-			if d.Theme.Sorting != "" {
-				sortLabel = formatSort(d.Theme.Sorting)
-			}
-			d.ProcessWidget.SetTitle(fmt.Sprint("Processes - ", processes.GetNrProcesses(), " Sorted by: ", sortLabel))
-		}
+		updateProcessTitle(d, processes.GetNrProcesses())
 	}, d.Theme.Layout.Process)
 	startWidgetWorker(d, quit, "gpu", func() { gpu.UpdateGPU(d) }, d.Theme.Layout.GPU)
 	startWidgetWorker(d, quit, "load", func() { load.UpdateLoadAverage(d) }, d.Theme.Layout.Load)
@@ -43,6 +44,9 @@ func startIndividualWidgetWorkers(d *utils.Dashboard, quit chan struct{}) {
 	startWidgetWorker(d, quit, "disk_io", func() { disk.UpdateDiskIO(d) }, d.Theme.Layout.DiskIO)
 	startWidgetWorker(d, quit, "process_tree", func() { processes.UpdateProcessTree(d) }, d.Theme.Layout.ProcessTree)
 	startWidgetWorker(d, quit, "battery", func() { battery.UpdateBatteryStatus(d) }, d.Theme.Layout.Battery)
+	startWidgetWorker(d, quit, "cgroups", func() { cgroups.UpdateCgroups(d) }, d.Theme.Layout.Cgroups)
+	startWidgetWorker(d, quit, "pressure", func() { pressure.UpdatePressure(d) }, d.Theme.Layout.Pressure)
+	startWidgetWorker(d, quit, "watch", func() { processes.UpdateWatch(d) }, d.Theme.Layout.Watch)
 
 	startWidgetWorker(d, quit, "header", func() { updateHeaderTitle(d) }, utils.WidgetConfig{Enabled: true, UpdateInterval: 1})
 
@@ -67,6 +71,7 @@ func startWidgetWorker(d *utils.Dashboard, quit chan struct{}, widgetName string
 			select {
 			case <-ticker.C:
 				updateFunc()
+				recordHistory(d, widgetName)
 				d.App.QueueUpdateDraw(func() {})
 			case <-quit:
 				return
@@ -90,13 +95,7 @@ func performInitialUpdates(d *utils.Dashboard) {
 	}
 	if d.Theme.Layout.Process.Enabled {
 		processes.UpdateProcesses(d)
-		if d.ProcessWidget != nil {
-			sortLabel := "CPU"
-			if d.Theme.Sorting != "" {
-				sortLabel = formatSort(d.Theme.Sorting)
-			}
-			d.ProcessWidget.SetTitle(fmt.Sprint("Processes - ", processes.GetNrProcesses(), " Sorted by: ", sortLabel))
-		}
+		updateProcessTitle(d, processes.GetNrProcesses())
 	}
 	if d.Theme.Layout.GPU.Enabled {
 		gpu.UpdateGPU(d)
@@ -119,35 +118,35 @@ func performInitialUpdates(d *utils.Dashboard) {
 	if d.Theme.Layout.Battery.Enabled {
 		battery.UpdateBatteryStatus(d)
 	}
+	if d.Theme.Layout.Cgroups.Enabled {
+		cgroups.UpdateCgroups(d)
+	}
+	if d.Theme.Layout.Pressure.Enabled {
+		pressure.UpdatePressure(d)
+	}
+	if d.Theme.Layout.Watch.Enabled {
+		processes.UpdateWatch(d)
+	}
 
+	d.History.AddSnapshot(d.Samples)
 	updateHeaderTitle(d)
 }
 
-func startPluginUpdateWorker(d *utils.Dashboard, quit chan struct{}) {
-	if d.PluginManager == nil {
+func startAlertsWorker(d *utils.Dashboard, quit chan struct{}) {
+	if d.Alerts == nil {
 		return
 	}
 
-	pluginManager, ok := d.PluginManager.(*plugins.PluginManager)
-	if !ok {
-		return
+	interval := d.Theme.Alerts.Interval
+	if interval <= 0 {
+		interval = alerts.DefaultInterval
 	}
 
-	pluginInfo := pluginManager.GetPluginInfo()
-	for _, info := range pluginInfo {
-		if info.Config.Enabled {
-			plugin, exists := pluginManager.GetPlugin(info.Name)
-			if exists {
-				startPluginWorker(d, plugin, info.Config, quit)
-			}
-		}
-	}
-}
-
-func startPluginWorker(d *utils.Dashboard, plugin plugins.Plugin, config plugins.PluginConfig, quit chan struct{}) {
-	interval := config.Layout.UpdateInterval
-	if interval <= 0 {
-		interval = 5
+	// Rules read their own collectors rather than the widget samples, so
+	// they also fire for metrics whose widget is disabled.
+	registry := collector.NewRegistry()
+	if names := d.Alerts.Collectors(); len(names) > 0 {
+		registry = builtin.NewRegistry(names...)
 	}
 
 	go func() {
@@ -157,16 +156,48 @@ func startPluginWorker(d *utils.Dashboard, plugin plugins.Plugin, config plugins
 		for {
 			select {
 			case <-ticker.C:
-				if d.PluginManager != nil {
-					if pluginManager, ok := d.PluginManager.(*plugins.PluginManager); ok {
-						if widget, exists := pluginManager.GetWidget(plugin.Name()); exists {
-							plugin.UpdateWidget(widget)
+				events := d.Alerts.Evaluate(registry.CollectAll(context.Background()), time.Now())
+				for _, event := range events {
+					logAlertEvent(event)
+				}
+				if len(events) > 0 {
+					go func() {
+						if err := d.AlertNotifier.Dispatch(context.Background(), events); err != nil {
+							log.Error(fmt.Sprintf("Failed to send alert notifications: %v", err))
 						}
-					}
+					}()
 				}
+				d.App.QueueUpdateDraw(func() {
+					updateHeaderTitle(d)
+					updateFooterText(d)
+				})
 			case <-quit:
 				return
 			}
 		}
 	}()
 }
+
+func logAlertEvent(event alerts.Event) {
+	message := fmt.Sprintf("Alert %s (%s): %s", event.Alert.State, event.Alert.Severity, event.Alert.Summary())
+	if event.Alert.State == alerts.StateFiring {
+		log.Warn(message)
+	} else {
+		log.Info(message)
+	}
+}
+
+// pluginCtx is cancelled on quit; plugins enabled from the plugin manager run
+// their workers under it.
+var pluginCtx context.Context
+
+func startPluginUpdateWorker(d *utils.Dashboard, quit chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-quit
+		cancel()
+	}()
+
+	pluginCtx = ctx
+	plugins.StartPluginWorkers(ctx, d)
+}
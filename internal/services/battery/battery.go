@@ -3,6 +3,7 @@ package battery
 import (
 	"fmt"
 	"runtime"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 	"time"
 
@@ -59,6 +60,10 @@ func UpdateBatteryStatus(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyBatteryInfo(d, batteryInfo)
+}
+
+func ApplyBatteryInfo(d *utils.Dashboard, batteryInfo *BatteryInfo) {
 	d.BatteryData = map[string]interface{}{
 		"level":          batteryInfo.Level,
 		"status":         batteryInfo.Status,
@@ -72,6 +77,11 @@ func UpdateBatteryStatus(d *utils.Dashboard) {
 		"charging_time":  batteryInfo.ChargingTime,
 		"last_update":    batteryInfo.LastUpdate,
 	}
+	d.Samples.Set(collector.Battery, batteryInfo)
+	if d.BatteryWidget == nil {
+		return
+	}
+
 	d.BatteryWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		currentY := y + 1
 
@@ -0,0 +1,43 @@
+package battery
+
+import (
+	"context"
+	"syspulse/internal/collector"
+)
+
+func (b *BatteryInfo) Points() []collector.Point {
+	points := []collector.Point{
+		collector.GaugePoint("battery_present", boolValue(b.IsPresent), nil),
+	}
+	if !b.IsPresent {
+		return points
+	}
+
+	return append(points,
+		collector.GaugePoint("battery_level_percent", b.Level, nil),
+		collector.GaugePoint("battery_charging", boolValue(b.IsCharging), nil),
+		collector.GaugePoint("battery_voltage_volts", b.Voltage, nil),
+		collector.GaugePoint("battery_cycle_count", float64(b.CycleCount), nil),
+	)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type Collector struct{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collector.Battery
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetBatteryInfo()
+}
@@ -0,0 +1,277 @@
+package cgroups
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syspulse/internal/collector"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shirou/gopsutil/process"
+)
+
+// DefaultRoot is where the cgroup hierarchy is mounted.
+const DefaultRoot = "/sys/fs/cgroup"
+
+// Cgroup is the usage of one cgroup. Path is relative to the hierarchy root,
+// as in /proc/<pid>/cgroup.
+type Cgroup struct {
+	Path               string  `json:"path"`
+	CPUUsageUsec       uint64  `json:"cpu_usage_usec"`
+	CPUPercent         float64 `json:"cpu_percent"`
+	MemoryCurrent      uint64  `json:"memory_current"`
+	MemoryMax          uint64  `json:"memory_max"` // 0 when unlimited
+	IOReadBytes        uint64  `json:"io_read_bytes"`
+	IOWriteBytes       uint64  `json:"io_write_bytes"`
+	IOReadBytesPerSec  float64 `json:"io_read_bytes_per_sec"`
+	IOWriteBytesPerSec float64 `json:"io_write_bytes_per_sec"`
+	Pids               uint64  `json:"pids"`
+}
+
+// CgroupSample holds every cgroup below Root, parents before their children.
+type CgroupSample struct {
+	Root     string    `json:"root"`
+	Cgroups  []Cgroup  `json:"cgroups"`
+	LastTime time.Time `json:"last_time"`
+}
+
+// Find returns the cgroup at path.
+func (s *CgroupSample) Find(path string) (Cgroup, bool) {
+	for _, cg := range s.Cgroups {
+		if cg.Path == path {
+			return cg, true
+		}
+	}
+	return Cgroup{}, false
+}
+
+var defaultCollector = NewCollector()
+
+func GetCgroupSample() (*CgroupSample, error) {
+	return defaultCollector.Stats(context.Background())
+}
+
+// ProcessCgroup returns the cgroup v2 path of a process.
+func ProcessCgroup(pid int32) (string, error) {
+	return readProcessCgroup("/proc", pid)
+}
+
+// readProcessCgroup reads the "0::<path>" line of /proc/<pid>/cgroup, which
+// names the process's cgroup in the unified hierarchy.
+func readProcessCgroup(procRoot string, pid int32) (string, error) {
+	file, err := os.Open(filepath.Join(procRoot, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if cgroup, found := strings.CutPrefix(scanner.Text(), "0::"); found {
+			return cgroup, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("process %d is not in a cgroup v2 hierarchy", pid)
+}
+
+// Procs returns the PIDs of the processes directly in the cgroup at path.
+func Procs(root, path string) ([]int32, error) {
+	file, err := os.Open(filepath.Join(root, path, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var pids []int32
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pid, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 32)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, int32(pid))
+	}
+	return pids, scanner.Err()
+}
+
+func UpdateCgroups(d *utils.Dashboard) {
+	if d.CgroupsWidget == nil {
+		return
+	}
+
+	sample, err := GetCgroupSample()
+	if err != nil {
+		d.CgroupsWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+			utils.SafePrintText(screen, "cgroup v2 stats unavailable", x+3, y+1, w-6, y+h-1, tcell.ColorRed)
+			return x, y, w, h
+		})
+		return
+	}
+
+	ApplyCgroupSample(d, sample)
+}
+
+func ApplyCgroupSample(d *utils.Dashboard, sample *CgroupSample) {
+	d.CgroupsData = sample
+	d.Samples.Set(collector.Cgroups, sample)
+	if d.CgroupsWidget == nil {
+		return
+	}
+
+	d.CgroupsWidget.SetDrawFunc(nil)
+	syncTree(d.CgroupsWidget, sample, utils.GetColorFromName(d.Theme.Layout.Cgroups.ForegroundColor))
+}
+
+// syncTree updates the tree to the sample. Nodes are kept by cgroup path so
+// the expanded branches and the selection survive updates.
+func syncTree(view *tview.TreeView, sample *CgroupSample, foreground tcell.Color) {
+	existing := make(map[string]*tview.TreeNode)
+	if root := view.GetRoot(); root != nil {
+		root.Walk(func(node, parent *tview.TreeNode) bool {
+			if path, ok := node.GetReference().(string); ok {
+				existing[path] = node
+			}
+			return true
+		})
+	}
+
+	nodes := make(map[string]*tview.TreeNode, len(sample.Cgroups))
+	for _, cg := range sample.Cgroups {
+		node, exists := existing[cg.Path]
+		if !exists {
+			// Slices are expanded at first, the cgroups below them are not.
+			node = tview.NewTreeNode("").
+				SetReference(cg.Path).
+				SetExpanded(strings.Count(cg.Path, "/") <= 1)
+		}
+		node.ClearChildren()
+		node.SetText(formatCgroup(cg)).SetColor(cgroupColor(cg, foreground))
+		nodes[cg.Path] = node
+
+		if cg.Path == "/" {
+			continue
+		}
+		if parent, exists := nodes[path.Dir(cg.Path)]; exists {
+			parent.AddChild(node)
+		}
+	}
+
+	root := nodes["/"]
+	view.SetRoot(root)
+	if current := view.GetCurrentNode(); current == nil || nodes[nodePath(current)] != current {
+		view.SetCurrentNode(root)
+	}
+}
+
+func nodePath(node *tview.TreeNode) string {
+	path, _ := node.GetReference().(string)
+	return path
+}
+
+// SelectedCgroup returns the path of the cgroup selected in the widget.
+func SelectedCgroup(d *utils.Dashboard) (string, bool) {
+	if d.CgroupsWidget == nil || d.CgroupsWidget.GetCurrentNode() == nil {
+		return "", false
+	}
+	return nodePath(d.CgroupsWidget.GetCurrentNode()), true
+}
+
+func formatCgroup(cg Cgroup) string {
+	name := path.Base(cg.Path)
+
+	memory := formatBytes(float64(cg.MemoryCurrent))
+	if cg.MemoryMax > 0 {
+		memory += "/" + formatBytes(float64(cg.MemoryMax))
+	}
+
+	return fmt.Sprintf("%s  cpu %.1f%%  mem %s  io r %s/s w %s/s  pids %d",
+		name, cg.CPUPercent, memory,
+		formatBytes(cg.IOReadBytesPerSec), formatBytes(cg.IOWriteBytesPerSec), cg.Pids)
+}
+
+// cgroupColor highlights cgroups that are busy or close to their memory
+// limit.
+func cgroupColor(cg Cgroup, foreground tcell.Color) tcell.Color {
+	memoryPercent := 0.0
+	if cg.MemoryMax > 0 {
+		memoryPercent = float64(cg.MemoryCurrent) / float64(cg.MemoryMax) * 100
+	}
+
+	switch {
+	case cg.CPUPercent >= 80 || memoryPercent >= 90:
+		return tcell.ColorRed
+	case cg.CPUPercent >= 50 || memoryPercent >= 75:
+		return tcell.ColorYellow
+	default:
+		return foreground
+	}
+}
+
+func formatBytes(bytes float64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%.0fB", bytes)
+	}
+
+	div, exp := float64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%c", bytes/div, "KMGTPE"[exp])
+}
+
+// GetCgroupFormattedInfo describes the cgroup at path and lists the
+// processes directly in it.
+func GetCgroupFormattedInfo(sample *CgroupSample, path string) string {
+	cg, exists := sample.Find(path)
+	if !exists {
+		return fmt.Sprintf("Cgroup %s no longer exists", path)
+	}
+
+	limit := "unlimited"
+	if cg.MemoryMax > 0 {
+		limit = formatBytes(float64(cg.MemoryMax))
+	}
+
+	var info strings.Builder
+	fmt.Fprintf(&info, "Cgroup: %s\n\n", cg.Path)
+	fmt.Fprintf(&info, "CPU: %.1f%% (%s total)\n", cg.CPUPercent, (time.Duration(cg.CPUUsageUsec) * time.Microsecond).Round(time.Second))
+	fmt.Fprintf(&info, "Memory: %s of %s\n", formatBytes(float64(cg.MemoryCurrent)), limit)
+	fmt.Fprintf(&info, "I/O: %s/s read, %s/s written\n", formatBytes(cg.IOReadBytesPerSec), formatBytes(cg.IOWriteBytesPerSec))
+	fmt.Fprintf(&info, "PIDs: %d\n\n", cg.Pids)
+
+	pids, err := Procs(sample.Root, cg.Path)
+	if err != nil {
+		fmt.Fprintf(&info, "Processes unavailable: %v\n", err)
+		return info.String()
+	}
+	if len(pids) == 0 {
+		info.WriteString("No processes directly in this cgroup\n")
+		return info.String()
+	}
+
+	info.WriteString("Processes:\n")
+	for _, pid := range pids {
+		name := "?"
+		if proc, err := process.NewProcess(pid); err == nil {
+			if n, err := proc.Name(); err == nil {
+				name = n
+			}
+		}
+		fmt.Fprintf(&info, "• %d %s\n", pid, name)
+	}
+	return info.String()
+}
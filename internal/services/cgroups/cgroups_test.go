@@ -0,0 +1,215 @@
+package cgroups
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"syspulse/internal/testutil"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/utils"
+
+	"github.com/rivo/tview"
+)
+
+func newHierarchy(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu io memory pids\n",
+		"cpu.stat":           "usage_usec 9000000\n",
+	})
+	testutil.WriteFiles(t, filepath.Join(root, "system.slice"), map[string]string{
+		"memory.current": "104857600\n",
+		"memory.max":     "max\n",
+		"pids.current":   "12\n",
+	})
+	testutil.WriteFiles(t, filepath.Join(root, "system.slice", "nginx.service"), map[string]string{
+		"cpu.stat":       "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000\n",
+		"memory.current": "52428800\n",
+		"memory.max":     "536870912\n",
+		"io.stat":        "8:0 rbytes=4096 wbytes=1024 rios=1 wios=1 dbytes=0 dios=0\n259:0 rbytes=4096 wbytes=0 rios=1 wios=0\n",
+		"pids.current":   "4\n",
+		"cgroup.procs":   "101\n102\n",
+	})
+	return root
+}
+
+func TestCollectorStats(t *testing.T) {
+	root := newHierarchy(t)
+	c := NewCollectorAt(root)
+
+	sample, err := c.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+
+	var paths []string
+	for _, cg := range sample.Cgroups {
+		paths = append(paths, cg.Path)
+	}
+	if strings.Join(paths, ",") != "/,/system.slice,/system.slice/nginx.service" {
+		t.Fatalf("Expected parents before children, got %v", paths)
+	}
+
+	nginx, _ := sample.Find("/system.slice/nginx.service")
+	want := Cgroup{
+		Path:          "/system.slice/nginx.service",
+		CPUUsageUsec:  1000000,
+		MemoryCurrent: 50 << 20,
+		MemoryMax:     512 << 20,
+		IOReadBytes:   8192,
+		IOWriteBytes:  1024,
+		Pids:          4,
+	}
+	if nginx != want {
+		t.Errorf("Expected %+v, got %+v", want, nginx)
+	}
+	if slice, _ := sample.Find("/system.slice"); slice.MemoryMax != 0 || slice.MemoryCurrent != 100<<20 {
+		t.Errorf("Expected an unlimited slice, got %+v", slice)
+	}
+
+	// Half a second of CPU and 2 KiB read over the last second.
+	c.mu.Lock()
+	c.lastTime = time.Now().Add(-time.Second)
+	last := c.last[want.Path]
+	last.CPUUsageUsec -= 500000
+	last.IOReadBytes -= 2048
+	c.last[want.Path] = last
+	c.mu.Unlock()
+
+	sample, _ = c.Stats(context.Background())
+	nginx, _ = sample.Find(want.Path)
+	if nginx.CPUPercent < 40 || nginx.CPUPercent > 50 {
+		t.Errorf("Expected about 50%% CPU, got %.1f", nginx.CPUPercent)
+	}
+	if nginx.IOReadBytesPerSec < 1600 || nginx.IOReadBytesPerSec > 2048 || nginx.IOWriteBytesPerSec != 0 {
+		t.Errorf("Expected about 2 KiB/s read, got %.0f/%.0f", nginx.IOReadBytesPerSec, nginx.IOWriteBytesPerSec)
+	}
+
+	points := sample.Points()
+	found := false
+	for _, p := range points {
+		if p.Name == "cgroup_memory_max_bytes" {
+			if p.Labels["cgroup"] != want.Path {
+				t.Errorf("Expected a memory limit only for nginx, got %v", p.Labels)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected a cgroup_memory_max_bytes point")
+	}
+}
+
+func TestCollectorRoot(t *testing.T) {
+	hybrid := t.TempDir()
+	testutil.WriteFiles(t, filepath.Join(hybrid, "unified"), map[string]string{"cgroup.controllers": "\n"})
+
+	sample, err := NewCollectorAt(hybrid).Stats(context.Background())
+	if err != nil || sample.Root != filepath.Join(hybrid, "unified") {
+		t.Errorf("Expected the unified hierarchy of a hybrid layout, got %v (%v)", sample, err)
+	}
+
+	if _, err := NewCollectorAt(t.TempDir()).Stats(context.Background()); err == nil {
+		t.Error("Expected an error without a cgroup v2 hierarchy")
+	}
+}
+
+func TestProcessCgroup(t *testing.T) {
+	proc := t.TempDir()
+	testutil.WriteFiles(t, filepath.Join(proc, "42"), map[string]string{
+		"cgroup": "4:memory:/docker/abc\n0::/system.slice/docker-abc.scope\n",
+	})
+	testutil.WriteFiles(t, filepath.Join(proc, "43"), map[string]string{"cgroup": "4:memory:/\n"})
+
+	tests := []struct {
+		pid  int32
+		want string
+		err  bool
+	}{
+		{42, "/system.slice/docker-abc.scope", false},
+		{43, "", true},
+		{44, "", true},
+	}
+	for _, tt := range tests {
+		got, err := readProcessCgroup(proc, tt.pid)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("PID %d: expected %q (error %v), got %q (%v)", tt.pid, tt.want, tt.err, got, err)
+		}
+	}
+
+	pids, err := Procs(newHierarchy(t), "/system.slice/nginx.service")
+	if err != nil || len(pids) != 2 || pids[0] != 101 || pids[1] != 102 {
+		t.Errorf("Expected PIDs 101 and 102, got %v (%v)", pids, err)
+	}
+}
+
+func TestApplyCgroupSample(t *testing.T) {
+	d := &utils.Dashboard{
+		Samples:       collector.NewSnapshot(),
+		CgroupsWidget: tview.NewTreeView(),
+	}
+	sample := &CgroupSample{Cgroups: []Cgroup{
+		{Path: "/"},
+		{Path: "/system.slice", MemoryCurrent: 2048},
+		{Path: "/system.slice/nginx.service", CPUPercent: 90},
+		{Path: "/user.slice"},
+	}}
+
+	ApplyCgroupSample(d, sample)
+
+	if _, ok := d.Samples.Get(collector.Cgroups); !ok {
+		t.Error("Expected the sample in the snapshot")
+	}
+	root := d.CgroupsWidget.GetRoot()
+	if len(root.GetChildren()) != 2 || len(root.GetChildren()[0].GetChildren()) != 1 {
+		t.Fatalf("Expected the cgroups nested by path")
+	}
+	slice := root.GetChildren()[0]
+	if !slice.IsExpanded() || slice.GetChildren()[0].IsExpanded() {
+		t.Error("Expected slices expanded and the cgroups below them collapsed")
+	}
+	if text := slice.GetText(); !strings.HasPrefix(text, "system.slice  cpu 0.0%  mem 2.0K  ") {
+		t.Errorf("Unexpected node text %q", text)
+	}
+
+	// The selection and collapsed branches survive an update.
+	d.CgroupsWidget.SetCurrentNode(slice.GetChildren()[0])
+	slice.Collapse()
+	ApplyCgroupSample(d, sample)
+	if path, _ := SelectedCgroup(d); path != "/system.slice/nginx.service" || d.CgroupsWidget.GetRoot().GetChildren()[0].IsExpanded() {
+		t.Errorf("Expected the tree state kept, selected %q", path)
+	}
+
+	// A cgroup that went away moves the selection back to the root.
+	ApplyCgroupSample(d, &CgroupSample{Cgroups: []Cgroup{{Path: "/"}, {Path: "/user.slice"}}})
+	if path, _ := SelectedCgroup(d); path != "/" {
+		t.Errorf("Expected the root selected, got %q", path)
+	}
+}
+
+func TestReadUsage(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, filepath.Join(root, "/v2.service"), map[string]string{"cpu.stat": "usage_usec 2500\n"})
+	testutil.WriteFiles(t, filepath.Join(root, "/cpuacct/v1.service"), map[string]string{"cpuacct.usage": "7000000\n"})
+	testutil.WriteFiles(t, filepath.Join(root, "/memory/v1.service"), map[string]string{"memory.usage_in_bytes": "4096\n"})
+
+	tests := []struct {
+		cgroup string
+		want   Usage
+		err    bool
+	}{
+		{"/v2.service", Usage{CPU: 2500 * time.Microsecond}, false},
+		{"/v1.service", Usage{Memory: 4096, HasMemory: true, CPU: 7 * time.Millisecond}, false},
+		{"/missing.service", Usage{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ReadUsage(root, tt.cgroup)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%s: expected %+v (error %v), got %+v (%v)", tt.cgroup, tt.want, tt.err, got, err)
+		}
+	}
+}
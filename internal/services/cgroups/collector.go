@@ -0,0 +1,263 @@
+package cgroups
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"time"
+)
+
+func (s *CgroupSample) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(s.Cgroups)*9)
+	for _, cg := range s.Cgroups {
+		labels := map[string]string{"cgroup": cg.Path}
+		points = append(points,
+			collector.CounterPoint("cgroup_cpu_usage_seconds_total", float64(cg.CPUUsageUsec)/1e6, labels),
+			collector.GaugePoint("cgroup_cpu_percent", cg.CPUPercent, labels),
+			collector.GaugePoint("cgroup_memory_bytes", float64(cg.MemoryCurrent), labels),
+			collector.CounterPoint("cgroup_io_read_bytes_total", float64(cg.IOReadBytes), labels),
+			collector.CounterPoint("cgroup_io_written_bytes_total", float64(cg.IOWriteBytes), labels),
+			collector.GaugePoint("cgroup_io_read_bytes_per_second", cg.IOReadBytesPerSec, labels),
+			collector.GaugePoint("cgroup_io_write_bytes_per_second", cg.IOWriteBytesPerSec, labels),
+			collector.GaugePoint("cgroup_pids", float64(cg.Pids), labels),
+		)
+		if cg.MemoryMax > 0 {
+			points = append(points, collector.GaugePoint("cgroup_memory_max_bytes", float64(cg.MemoryMax), labels))
+		}
+	}
+	return points
+}
+
+// Collector walks a cgroup v2 hierarchy. It keeps the previous counters of
+// every cgroup so CPU and I/O rates are computed per instance.
+type Collector struct {
+	root string
+
+	mu       sync.Mutex
+	last     map[string]Cgroup
+	lastTime time.Time
+}
+
+func NewCollector() *Collector {
+	return NewCollectorAt(DefaultRoot)
+}
+
+// NewCollectorAt returns a collector for the hierarchy mounted at root.
+func NewCollectorAt(root string) *Collector {
+	return &Collector{
+		root: root,
+		last: make(map[string]Cgroup),
+	}
+}
+
+func (c *Collector) Name() string {
+	return collector.Cgroups
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return c.Stats(ctx)
+}
+
+func (c *Collector) Stats(ctx context.Context) (*CgroupSample, error) {
+	root, err := unifiedRoot(c.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var cgroups []Cgroup
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Cgroups come and go while walking.
+			if os.IsNotExist(err) && path != root {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		cgroups = append(cgroups, readCgroup(path, cgroupPath(rel)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	currentTime := time.Now()
+	duration := currentTime.Sub(c.lastTime).Seconds()
+	current := make(map[string]Cgroup, len(cgroups))
+	for i := range cgroups {
+		cg := &cgroups[i]
+		if last, exists := c.last[cg.Path]; exists && !c.lastTime.IsZero() && duration > 0 {
+			cg.CPUPercent = counterRate(cg.CPUUsageUsec, last.CPUUsageUsec, duration) / 1e4
+			cg.IOReadBytesPerSec = counterRate(cg.IOReadBytes, last.IOReadBytes, duration)
+			cg.IOWriteBytesPerSec = counterRate(cg.IOWriteBytes, last.IOWriteBytes, duration)
+		}
+		current[cg.Path] = *cg
+	}
+	c.last = current
+	c.lastTime = currentTime
+
+	return &CgroupSample{Root: root, Cgroups: cgroups, LastTime: currentTime}, nil
+}
+
+// unifiedRoot returns the cgroup v2 hierarchy at root, or the one mounted at
+// root/unified on hosts running the hybrid layout.
+func unifiedRoot(root string) (string, error) {
+	for _, dir := range []string{root, filepath.Join(root, "unified")} {
+		if _, err := os.Stat(filepath.Join(dir, "cgroup.controllers")); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup v2 hierarchy at %s", root)
+}
+
+// cgroupPath turns a directory relative to the hierarchy root into the path
+// the kernel uses for the cgroup, as in /proc/<pid>/cgroup.
+func cgroupPath(rel string) string {
+	if rel == "." {
+		return "/"
+	}
+	return "/" + filepath.ToSlash(rel)
+}
+
+// readCgroup reads the accounting files of the cgroup at dir. Files missing
+// because a controller is not enabled, or on the root cgroup, leave their
+// values at zero.
+func readCgroup(dir, path string) Cgroup {
+	cg := Cgroup{Path: path}
+
+	if usec, err := readStat(filepath.Join(dir, "cpu.stat"), "usage_usec"); err == nil {
+		cg.CPUUsageUsec = usec
+	}
+	if current, err := readValue(filepath.Join(dir, "memory.current")); err == nil {
+		cg.MemoryCurrent = current
+	}
+	// memory.max holds "max" when unlimited, which fails to parse and stays 0.
+	if max, err := readValue(filepath.Join(dir, "memory.max")); err == nil {
+		cg.MemoryMax = max
+	}
+	if pids, err := readValue(filepath.Join(dir, "pids.current")); err == nil {
+		cg.Pids = pids
+	}
+	cg.IOReadBytes, cg.IOWriteBytes = readIOStat(filepath.Join(dir, "io.stat"))
+	return cg
+}
+
+// Usage is the memory and CPU time charged to a cgroup.
+type Usage struct {
+	Memory    uint64
+	HasMemory bool
+	CPU       time.Duration
+}
+
+// ReadUsage reads the usage of the cgroup at path below root from the cgroup
+// v2 files, or else from the v1 memory and cpuacct hierarchies.
+func ReadUsage(root, path string) (Usage, error) {
+	var usage Usage
+
+	dir := filepath.Join(root, path)
+	if usec, err := readStat(filepath.Join(dir, "cpu.stat"), "usage_usec"); err == nil {
+		usage.CPU = time.Duration(usec) * time.Microsecond
+		if memory, err := readValue(filepath.Join(dir, "memory.current")); err == nil {
+			usage.Memory, usage.HasMemory = memory, true
+		}
+		return usage, nil
+	}
+
+	nsec, err := readValue(filepath.Join(root, "cpuacct", path, "cpuacct.usage"))
+	if err != nil {
+		return usage, fmt.Errorf("no CPU accounting for cgroup %s", path)
+	}
+	usage.CPU = time.Duration(nsec)
+	if memory, err := readValue(filepath.Join(root, "memory", path, "memory.usage_in_bytes")); err == nil {
+		usage.Memory, usage.HasMemory = memory, true
+	}
+	return usage, nil
+}
+
+func readValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readStat returns a field of a flat keyed file like cpu.stat.
+func readStat(path, key string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("%s has no %s", path, key)
+}
+
+// readIOStat sums the bytes read and written over every device in io.stat,
+// whose lines look like "8:0 rbytes=1 wbytes=2 rios=3 wios=4 ...".
+func readIOStat(path string) (read, written uint64) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				read += n
+			case "wbytes":
+				written += n
+			}
+		}
+	}
+	return read, written
+}
+
+func counterRate(current, previous uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
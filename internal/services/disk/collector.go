@@ -0,0 +1,184 @@
+package disk
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"syspulse/internal/collector"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+)
+
+type PartitionUsage struct {
+	Device string `json:"device"`
+	disk.UsageStat
+}
+
+type UsageSample struct {
+	Partitions []PartitionUsage `json:"partitions"`
+}
+
+func (s *UsageSample) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(s.Partitions)*4)
+	for _, p := range s.Partitions {
+		labels := map[string]string{"mountpoint": p.Path, "device": p.Device, "fstype": p.Fstype}
+		points = append(points,
+			collector.GaugePoint("disk_total_bytes", float64(p.Total), labels),
+			collector.GaugePoint("disk_used_bytes", float64(p.Used), labels),
+			collector.GaugePoint("disk_free_bytes", float64(p.Free), labels),
+			collector.GaugePoint("disk_used_percent", p.UsedPercent, labels),
+		)
+	}
+	return points
+}
+
+func (s *UsageSample) Stats() []*disk.UsageStat {
+	stats := make([]*disk.UsageStat, 0, len(s.Partitions))
+	for i := range s.Partitions {
+		stats = append(stats, &s.Partitions[i].UsageStat)
+	}
+	return stats
+}
+
+func (d *DiskIOData) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(d.Disks)*9)
+	for _, device := range d.Disks {
+		labels := map[string]string{"device": device.Name}
+		stats := device.Stats
+		points = append(points,
+			collector.CounterPoint("disk_read_bytes_total", float64(stats.ReadBytes), labels),
+			collector.CounterPoint("disk_written_bytes_total", float64(stats.WriteBytes), labels),
+			collector.CounterPoint("disk_reads_completed_total", float64(stats.ReadCount), labels),
+			collector.CounterPoint("disk_writes_completed_total", float64(stats.WriteCount), labels),
+			collector.GaugePoint("disk_read_bytes_per_second", stats.ReadBytesPerSec, labels),
+			collector.GaugePoint("disk_write_bytes_per_second", stats.WriteBytesPerSec, labels),
+			collector.GaugePoint("disk_read_ops_per_second", stats.ReadOpsPerSec, labels),
+			collector.GaugePoint("disk_write_ops_per_second", stats.WriteOpsPerSec, labels),
+			collector.GaugePoint("disk_utilization_percent", stats.UtilizationPct, labels),
+		)
+	}
+	return points
+}
+
+type UsageCollector struct{}
+
+func NewUsageCollector() *UsageCollector {
+	return &UsageCollector{}
+}
+
+func (c *UsageCollector) Name() string {
+	return collector.Disk
+}
+
+func (c *UsageCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetUsageSample(ctx)
+}
+
+func GetUsageSample(ctx context.Context) (*UsageSample, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &UsageSample{Partitions: make([]PartitionUsage, 0, len(partitions))}
+	for _, p := range partitions {
+		usage, err := disk.UsageWithContext(ctx, p.Mountpoint)
+		if err != nil {
+			continue
+		}
+		if usage.Fstype == "" {
+			usage.Fstype = p.Fstype
+		}
+		sample.Partitions = append(sample.Partitions, PartitionUsage{Device: p.Device, UsageStat: *usage})
+	}
+	return sample, nil
+}
+
+// IOCollector keeps the previous counters so every instance computes its own
+// rates independently of other consumers.
+type IOCollector struct {
+	mu       sync.Mutex
+	last     map[string]disk.IOCountersStat
+	lastTime time.Time
+}
+
+func NewIOCollector() *IOCollector {
+	return &IOCollector{
+		last: make(map[string]disk.IOCountersStat),
+	}
+}
+
+func (c *IOCollector) Name() string {
+	return collector.DiskIO
+}
+
+func (c *IOCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return c.Stats(ctx)
+}
+
+func (c *IOCollector) Stats(ctx context.Context) (*DiskIOData, error) {
+	ioStats, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	currentTime := time.Now()
+	ioData := &DiskIOData{
+		Disks:    make([]*DiskIODevice, 0, len(ioStats)),
+		LastTime: currentTime,
+	}
+
+	deviceNames := make([]string, 0, len(ioStats))
+	for device := range ioStats {
+		deviceNames = append(deviceNames, device)
+	}
+	sort.Strings(deviceNames)
+
+	for _, device := range deviceNames {
+		stat := ioStats[device]
+		diskStat := &DiskIOStats{
+			ReadCount:  stat.ReadCount,
+			WriteCount: stat.WriteCount,
+			ReadBytes:  stat.ReadBytes,
+			WriteBytes: stat.WriteBytes,
+			ReadTime:   stat.ReadTime,
+			WriteTime:  stat.WriteTime,
+		}
+
+		if lastStat, exists := c.last[device]; exists && !c.lastTime.IsZero() {
+			duration := currentTime.Sub(c.lastTime).Seconds()
+			if duration > 0 {
+				diskStat.ReadBytesPerSec = counterRate(stat.ReadBytes, lastStat.ReadBytes, duration)
+				diskStat.WriteBytesPerSec = counterRate(stat.WriteBytes, lastStat.WriteBytes, duration)
+				diskStat.ReadOpsPerSec = counterRate(stat.ReadCount, lastStat.ReadCount, duration)
+				diskStat.WriteOpsPerSec = counterRate(stat.WriteCount, lastStat.WriteCount, duration)
+
+				totalTime := counterRate(stat.ReadTime+stat.WriteTime, lastStat.ReadTime+lastStat.WriteTime, 1)
+				diskStat.UtilizationPct = (totalTime / (duration * 1000)) * 100
+				if diskStat.UtilizationPct > 100 {
+					diskStat.UtilizationPct = 100
+				}
+			}
+		}
+
+		ioData.Disks = append(ioData.Disks, &DiskIODevice{
+			Name:  device,
+			Stats: diskStat,
+		})
+		c.last[device] = stat
+	}
+
+	c.lastTime = currentTime
+	return ioData, nil
+}
+
+func counterRate(current, previous uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
@@ -1,13 +1,15 @@
 package disk
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"github.com/shirou/gopsutil/disk"
 )
 
 type DiskIOStats struct {
@@ -34,68 +36,10 @@ type DiskIODevice struct {
 	Stats *DiskIOStats `json:"stats"`
 }
 
-var (
-	lastIOStats map[string]disk.IOCountersStat
-	lastIOTime  time.Time
-)
-
-func init() {
-	lastIOStats = make(map[string]disk.IOCountersStat)
-}
+var defaultIOCollector = NewIOCollector()
 
 func GetDiskIOStats() (*DiskIOData, error) {
-	ioStats, err := disk.IOCounters()
-	if err != nil {
-		return nil, err
-	}
-
-	currentTime := time.Now()
-	ioData := &DiskIOData{
-		Disks:    make([]*DiskIODevice, 0),
-		LastTime: currentTime,
-	}
-
-	deviceNames := make([]string, 0, len(ioStats))
-	for device := range ioStats {
-		deviceNames = append(deviceNames, device)
-	}
-
-	for _, device := range deviceNames {
-		stat := ioStats[device]
-		diskStat := &DiskIOStats{
-			ReadCount:  stat.ReadCount,
-			WriteCount: stat.WriteCount,
-			ReadBytes:  stat.ReadBytes,
-			WriteBytes: stat.WriteBytes,
-			ReadTime:   stat.ReadTime,
-			WriteTime:  stat.WriteTime,
-		}
-
-		if lastStat, exists := lastIOStats[device]; exists && !lastIOTime.IsZero() {
-			duration := currentTime.Sub(lastIOTime).Seconds()
-			if duration > 0 {
-				diskStat.ReadBytesPerSec = float64(stat.ReadBytes-lastStat.ReadBytes) / duration
-				diskStat.WriteBytesPerSec = float64(stat.WriteBytes-lastStat.WriteBytes) / duration
-				diskStat.ReadOpsPerSec = float64(stat.ReadCount-lastStat.ReadCount) / duration
-				diskStat.WriteOpsPerSec = float64(stat.WriteCount-lastStat.WriteCount) / duration
-
-				totalTime := float64(stat.ReadTime + stat.WriteTime - lastStat.ReadTime - lastStat.WriteTime)
-				diskStat.UtilizationPct = (totalTime / (duration * 1000)) * 100
-				if diskStat.UtilizationPct > 100 {
-					diskStat.UtilizationPct = 100
-				}
-			}
-		}
-
-		ioData.Disks = append(ioData.Disks, &DiskIODevice{
-			Name:  device,
-			Stats: diskStat,
-		})
-		lastIOStats[device] = stat
-	}
-
-	lastIOTime = currentTime
-	return ioData, nil
+	return defaultIOCollector.Stats(context.Background())
 }
 
 func UpdateDiskIO(d *utils.Dashboard) {
@@ -112,8 +56,22 @@ func UpdateDiskIO(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyDiskIOData(d, ioData)
+}
+
+func ApplyDiskIOData(d *utils.Dashboard, ioData *DiskIOData) {
 	d.DiskIOData = ioData
+	d.Samples.Set(collector.DiskIO, ioData)
+	if d.DiskIOWidget == nil {
+		return
+	}
+
 	d.DiskIOWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		if utils.IsHistoryView(d.Theme.Layout.DiskIO.View) && d.History != nil {
+			drawDiskIOHistory(screen, d, ioData, x, y, w, h)
+			return x, y, w, h
+		}
+
 		currentY := y + 1
 
 		for _, device := range ioData.Disks {
@@ -165,6 +123,48 @@ func UpdateDiskIO(d *utils.Dashboard) {
 	})
 }
 
+func drawDiskIOHistory(screen tcell.Screen, d *utils.Dashboard, ioData *DiskIOData, x, y, w, h int) {
+	foreground := utils.GetColorFromName(d.Theme.Layout.DiskIO.ForegroundColor)
+	interval := d.Theme.Layout.DiskIO.UpdateInterval
+	innerW := w - 4
+	if innerW <= 8 {
+		return
+	}
+
+	currentY := y + 1
+	for _, device := range ioData.Disks {
+		if currentY+2 >= y+h-1 {
+			break
+		}
+
+		currentY = utils.SafePrintText(screen, truncateDeviceName(device.Name, innerW), x+2, currentY, innerW, y+h-1, foreground)
+
+		labels := map[string]string{"device": device.Name}
+		rows := []struct {
+			label  string
+			metric string
+			rate   float64
+		}{
+			{"  R ", "disk_read_bytes_per_second", device.Stats.ReadBytesPerSec},
+			{"  W ", "disk_write_bytes_per_second", device.Stats.WriteBytesPerSec},
+		}
+
+		for _, row := range rows {
+			value := fmt.Sprintf(" %7s/s", formatBytes(row.rate))
+			sparkW := innerW - len(row.label) - len(value)
+			if sparkW <= 0 {
+				break
+			}
+
+			values := utils.HistoryValues(d, row.metric, labels, sparkW, interval)
+			tview.Print(screen, row.label, x+2, currentY, len(row.label), tview.AlignLeft, foreground)
+			utils.DrawSparkline(screen, values, x+2+len(row.label), currentY, sparkW, 0, math.NaN(), utils.GetColorFromName(getIOColor(row.rate)))
+			tview.Print(screen, value, x+2+len(row.label)+sparkW, currentY, len(value), tview.AlignLeft, foreground)
+			currentY++
+		}
+	}
+}
+
 func formatBytes(bytes float64) string {
 	const (
 		KB = 1024
@@ -1,8 +1,10 @@
 package disk
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -36,55 +38,57 @@ func UpdateDisk(d *utils.Dashboard) {
 		return
 	}
 
-	if partitions, err := disk.Partitions(false); err == nil {
-		d.DiskData = nil
-		for _, p := range partitions {
-			if usage, err := disk.Usage(p.Mountpoint); err == nil {
-				d.DiskData = append(d.DiskData, usage)
+	if sample, err := GetUsageSample(context.Background()); err == nil {
+		ApplyUsageSample(d, sample)
+	}
+}
+
+func ApplyUsageSample(d *utils.Dashboard, sample *UsageSample) {
+	d.DiskData = sample.Stats()
+	d.Samples.Set(collector.Disk, sample)
+	if d.DiskWidget == nil {
+		return
+	}
+
+	d.DiskWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		currentY := y + 1
+		for _, u := range d.DiskData {
+			used := float64(u.Used) / 1024 / 1024 / 1024
+			total := float64(u.Total) / 1024 / 1024 / 1024
+			//free := float64(u.Free) / 1024 / 1024 / 1024
+			bar := getDiskBar(used, total, d.Theme.Disk, w)
+
+			if currentY >= y+h-1 {
+				break
 			}
-		}
-		d.DiskWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
-			currentY := y + 1
-			for i, u := range d.DiskData {
-				used := float64(u.Used) / 1024 / 1024 / 1024
-				total := float64(u.Total) / 1024 / 1024 / 1024
-				//free := float64(u.Free) / 1024 / 1024 / 1024
-				bar := getDiskBar(used, total, d.Theme.Disk, w)
-
-				if currentY >= y+h-1 {
-					break
-				}
-
-				fs := ""
-				if i < len(partitions) && partitions[i].Fstype != "" {
-					fs = partitions[i].Fstype
-				} else {
-					fs = "Unknown"
-				}
-
-				line1 := fmt.Sprintf("%s (%s) %s",
-					u.Path,
-					fs,
-					bar,
-				)
-				tview.Print(screen, line1, x+2, currentY, w-2, y+h-1, utils.GetColorFromName(d.Theme.Layout.Disk.ForegroundColor))
-
-				currentY++
-				if currentY >= y+h-1 {
-					break
-				}
-
-				line2 := fmt.Sprintf("%.1f/%.1fGB",
-					used,
-					total,
-				)
-
-				tview.Print(screen, line2, x+3, currentY, w-2, y+h-1, utils.GetColorFromName(d.Theme.Layout.Disk.ForegroundColor))
-				currentY++
+
+			fs := u.Fstype
+			if fs == "" {
+				fs = "Unknown"
 			}
-			return x, y, w, h
-		})
-	}
+
+			line1 := fmt.Sprintf("%s (%s) %s",
+				u.Path,
+				fs,
+				bar,
+			)
+			tview.Print(screen, line1, x+2, currentY, w-2, y+h-1, utils.GetColorFromName(d.Theme.Layout.Disk.ForegroundColor))
+
+			currentY++
+			if currentY >= y+h-1 {
+				break
+			}
+
+			line2 := fmt.Sprintf("%.1f/%.1fGB",
+				used,
+				total,
+			)
+
+			tview.Print(screen, line2, x+3, currentY, w-2, y+h-1, utils.GetColorFromName(d.Theme.Layout.Disk.ForegroundColor))
+			currentY++
+		}
+		return x, y, w, h
+	})
 }
 
 func GetNumberofPartitions() string {
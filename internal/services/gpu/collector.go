@@ -0,0 +1,45 @@
+package gpu
+
+import (
+	"context"
+	"strconv"
+	"syspulse/internal/collector"
+)
+
+type GPUSample struct {
+	GPUs []GPUInfo `json:"gpus"`
+}
+
+func (s *GPUSample) Points() []collector.Point {
+	var points []collector.Point
+	for i, gpu := range s.GPUs {
+		labels := map[string]string{"index": strconv.Itoa(i), "name": gpu.Name, "vendor": gpu.Vendor}
+		points = append(points,
+			collector.GaugePoint("gpu_usage_percent", gpu.Usage, labels),
+			collector.GaugePoint("gpu_memory_total_bytes", float64(gpu.MemoryTotal), labels),
+			collector.GaugePoint("gpu_memory_used_bytes", float64(gpu.MemoryUsed), labels),
+			collector.GaugePoint("gpu_temperature_celsius", gpu.Temperature, labels),
+			collector.GaugePoint("gpu_power_draw_watts", float64(gpu.PowerDraw), labels),
+			collector.GaugePoint("gpu_clock_mhz", float64(gpu.ClockSpeed), labels),
+		)
+	}
+	return points
+}
+
+type Collector struct{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collector.GPU
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	gpus, err := GetGPUInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &GPUSample{GPUs: gpus}, nil
+}
@@ -3,6 +3,7 @@ package gpu
 import (
 	"fmt"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -24,13 +25,24 @@ func UpdateGPU(d *utils.Dashboard) error {
 		return err
 	}
 
+	ApplyGPUSample(d, &GPUSample{GPUs: gpus})
+	return nil
+}
+
+func ApplyGPUSample(d *utils.Dashboard, sample *GPUSample) {
+	gpus := sample.GPUs
+	d.Samples.Set(collector.GPU, sample)
+
 	if len(gpus) == 0 {
 		d.GPUData = nil
+		if d.GPUWidget == nil {
+			return
+		}
 		d.GPUWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 			utils.SafePrintText(screen, "No GPUs detected", x+1, y+1, w-2, h-(y+1-y), tcell.ColorYellow)
 			return x, y, w, h
 		})
-		return nil
+		return
 	}
 
 	var gpuDataSlice []interface{}
@@ -48,6 +60,9 @@ func UpdateGPU(d *utils.Dashboard) error {
 		gpuDataSlice = append(gpuDataSlice, gpuMap)
 	}
 	d.GPUData = gpuDataSlice
+	if d.GPUWidget == nil {
+		return
+	}
 
 	d.GPUWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		currentY := y + 1
@@ -147,8 +162,6 @@ func UpdateGPU(d *utils.Dashboard) error {
 
 		return x, y, w, h
 	})
-
-	return nil
 }
 
 func createUsageBar(percentage float64, width int) string {
@@ -0,0 +1,28 @@
+package load
+
+import (
+	"context"
+	"syspulse/internal/collector"
+)
+
+func (l *LoadAverage) Points() []collector.Point {
+	return []collector.Point{
+		collector.GaugePoint("load1", l.Load1, nil),
+		collector.GaugePoint("load5", l.Load5, nil),
+		collector.GaugePoint("load15", l.Load15, nil),
+	}
+}
+
+type Collector struct{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collector.Load
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetLoadAverage()
+}
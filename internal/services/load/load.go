@@ -3,6 +3,7 @@ package load
 import (
 	"fmt"
 	"runtime"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -41,7 +42,16 @@ func UpdateLoadAverage(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyLoadAverage(d, loadAvg)
+}
+
+func ApplyLoadAverage(d *utils.Dashboard, loadAvg *LoadAverage) {
 	d.LoadData = loadAvg
+	d.Samples.Set(collector.Load, loadAvg)
+	if d.LoadWidget == nil {
+		return
+	}
+
 	d.LoadWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		cpuCount := runtime.NumCPU()
 
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"syspulse/internal/collector"
+
+	"github.com/shirou/gopsutil/mem"
+)
+
+type MemorySample struct {
+	Virtual *mem.VirtualMemoryStat `json:"virtual"`
+	Swap    *mem.SwapMemoryStat    `json:"swap"`
+}
+
+func (s *MemorySample) Points() []collector.Point {
+	var points []collector.Point
+	if s.Virtual != nil {
+		points = append(points,
+			collector.GaugePoint("memory_total_bytes", float64(s.Virtual.Total), nil),
+			collector.GaugePoint("memory_used_bytes", float64(s.Virtual.Used), nil),
+			collector.GaugePoint("memory_available_bytes", float64(s.Virtual.Available), nil),
+			collector.GaugePoint("memory_used_percent", s.Virtual.UsedPercent, nil),
+		)
+	}
+	if s.Swap != nil {
+		points = append(points,
+			collector.GaugePoint("swap_total_bytes", float64(s.Swap.Total), nil),
+			collector.GaugePoint("swap_used_bytes", float64(s.Swap.Used), nil),
+			collector.GaugePoint("swap_used_percent", s.Swap.UsedPercent, nil),
+		)
+	}
+	return points
+}
+
+type Collector struct{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collector.Memory
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetMemorySample(ctx)
+}
+
+func GetMemorySample(ctx context.Context) (*MemorySample, error) {
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	swap, err := mem.SwapMemoryWithContext(ctx)
+	if err != nil || swap == nil {
+		swap = &mem.SwapMemoryStat{}
+	}
+
+	return &MemorySample{Virtual: vm, Swap: swap}, nil
+}
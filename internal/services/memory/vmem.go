@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -34,36 +36,68 @@ func UpdateVMem(d *utils.Dashboard) {
 		return
 	}
 
-	if VmemStat, err := mem.VirtualMemory(); err == nil {
-		d.VMemData = VmemStat
-		d.SMemData = GetSwapMem()
-		if d.SMemData == nil {
-			d.SMemData = &mem.SwapMemoryStat{
-				Total: 0,
-				Free:  0,
-				Used:  0,
-			}
+	if sample, err := GetMemorySample(context.Background()); err == nil {
+		ApplyMemorySample(d, sample)
+	}
+}
+
+func ApplyMemorySample(d *utils.Dashboard, sample *MemorySample) {
+	d.VMemData = sample.Virtual
+	d.SMemData = sample.Swap
+	if d.SMemData == nil {
+		d.SMemData = &mem.SwapMemoryStat{
+			Total: 0,
+			Free:  0,
+			Used:  0,
+		}
+	}
+	d.Samples.Set(collector.Memory, sample)
+	if d.MemWidget == nil {
+		return
+	}
+
+	d.MemWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		if utils.IsHistoryView(d.Theme.Layout.Memory.View) && d.History != nil {
+			drawMemoryHistory(screen, d, x, y, w, h)
+			return x, y, w, h
 		}
 
-		d.MemWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
-			VMemusedGB := float64(d.VMemData.Used) / 1024 / 1024 / 1024
-			VMemtotalGB := float64(d.VMemData.Total) / 1024 / 1024 / 1024
-			VMembar := getMemoryBar(VMemusedGB, VMemtotalGB, d.Theme.Memory.VMemGauge, d, w)
+		VMemusedGB := float64(d.VMemData.Used) / 1024 / 1024 / 1024
+		VMemtotalGB := float64(d.VMemData.Total) / 1024 / 1024 / 1024
+		VMembar := getMemoryBar(VMemusedGB, VMemtotalGB, d.Theme.Memory.VMemGauge, d, w)
 
-			SMemusedGB := float64(d.SMemData.Used) / 1024 / 1024 / 1024
-			SMemtotalGB := float64(d.SMemData.Total) / 1024 / 1024 / 1024
-			SMembar := getMemoryBar(SMemusedGB, SMemtotalGB, d.Theme.Memory.SMemGauge, d, w)
+		SMemusedGB := float64(d.SMemData.Used) / 1024 / 1024 / 1024
+		SMemtotalGB := float64(d.SMemData.Total) / 1024 / 1024 / 1024
+		SMembar := getMemoryBar(SMemusedGB, SMemtotalGB, d.Theme.Memory.SMemGauge, d, w)
 
-			currentY := 3
-			vMemText := fmt.Sprintf("RAM : %s %.1f/%.1fGB", VMembar, VMemusedGB, VMemtotalGB)
-			tview.Print(screen, vMemText, x+2, currentY, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Memory.ForegroundColor))
-			currentY += 2
+		currentY := 3
+		vMemText := fmt.Sprintf("RAM : %s %.1f/%.1fGB", VMembar, VMemusedGB, VMemtotalGB)
+		tview.Print(screen, vMemText, x+2, currentY, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Memory.ForegroundColor))
+		currentY += 2
 
-			sMemText := fmt.Sprintf("Swap: %s %.1f/%.1fGB", SMembar, SMemusedGB, SMemtotalGB)
-			tview.Print(screen, sMemText, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Memory.ForegroundColor))
-			return x, y, w, h
-		})
+		sMemText := fmt.Sprintf("Swap: %s %.1f/%.1fGB", SMembar, SMemusedGB, SMemtotalGB)
+		tview.Print(screen, sMemText, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Memory.ForegroundColor))
+		return x, y, w, h
+	})
+}
+
+func drawMemoryHistory(screen tcell.Screen, d *utils.Dashboard, x, y, w, h int) {
+	innerW := w - 4
+	chartH := h - 3
+	if innerW <= 0 || chartH <= 0 {
+		return
 	}
+
+	legend := fmt.Sprintf("[%s]RAM[-] %.1f%%  [%s]Swap[-] %.1f%%",
+		d.Theme.Memory.VMemGauge, d.VMemData.UsedPercent,
+		d.Theme.Memory.SMemGauge, d.SMemData.UsedPercent)
+	tview.Print(screen, legend, x+2, y+1, innerW, tview.AlignLeft, utils.GetColorFromName(d.Theme.Layout.Memory.ForegroundColor))
+
+	interval := d.Theme.Layout.Memory.UpdateInterval
+	utils.DrawLineChart(screen, []utils.ChartSeries{
+		{Label: "Swap", Values: utils.HistoryValues(d, "swap_used_percent", nil, innerW*2, interval), Color: utils.GetColorFromName(d.Theme.Memory.SMemGauge)},
+		{Label: "RAM", Values: utils.HistoryValues(d, "memory_used_percent", nil, innerW*2, interval), Color: utils.GetColorFromName(d.Theme.Memory.VMemGauge)},
+	}, x+2, y+2, innerW, chartH, 0, 100)
 }
 
 func GetRAM() string {
@@ -0,0 +1,208 @@
+package network
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"time"
+
+	"github.com/shirou/gopsutil/net"
+)
+
+type InterfaceIO struct {
+	Name        string  `json:"name"`
+	BytesSent   uint64  `json:"bytes_sent"`
+	BytesRecv   uint64  `json:"bytes_recv"`
+	PacketsSent uint64  `json:"packets_sent"`
+	PacketsRecv uint64  `json:"packets_recv"`
+	Errin       uint64  `json:"errin"`
+	Errout      uint64  `json:"errout"`
+	Dropin      uint64  `json:"dropin"`
+	Dropout     uint64  `json:"dropout"`
+	SentPerSec  float64 `json:"sent_per_sec"`
+	RecvPerSec  float64 `json:"recv_per_sec"`
+}
+
+type IOSample struct {
+	Total      InterfaceIO   `json:"total"`
+	Interfaces []InterfaceIO `json:"interfaces"`
+}
+
+func (s *IOSample) Points() []collector.Point {
+	points := []collector.Point{
+		collector.GaugePoint("network_aggregate_transmit_bytes_per_second", s.Total.SentPerSec, nil),
+		collector.GaugePoint("network_aggregate_receive_bytes_per_second", s.Total.RecvPerSec, nil),
+	}
+	for _, iface := range s.Interfaces {
+		labels := map[string]string{"interface": iface.Name}
+		points = append(points,
+			collector.CounterPoint("network_transmit_bytes_total", float64(iface.BytesSent), labels),
+			collector.CounterPoint("network_receive_bytes_total", float64(iface.BytesRecv), labels),
+			collector.CounterPoint("network_transmit_packets_total", float64(iface.PacketsSent), labels),
+			collector.CounterPoint("network_receive_packets_total", float64(iface.PacketsRecv), labels),
+			collector.CounterPoint("network_receive_errors_total", float64(iface.Errin), labels),
+			collector.CounterPoint("network_transmit_errors_total", float64(iface.Errout), labels),
+			collector.CounterPoint("network_receive_drops_total", float64(iface.Dropin), labels),
+			collector.CounterPoint("network_transmit_drops_total", float64(iface.Dropout), labels),
+			collector.GaugePoint("network_transmit_bytes_per_second", iface.SentPerSec, labels),
+			collector.GaugePoint("network_receive_bytes_per_second", iface.RecvPerSec, labels),
+		)
+	}
+	return points
+}
+
+func (s *IOSample) Counters() *net.IOCountersStat {
+	return &net.IOCountersStat{
+		Name:        "all",
+		BytesSent:   s.Total.BytesSent,
+		BytesRecv:   s.Total.BytesRecv,
+		PacketsSent: s.Total.PacketsSent,
+		PacketsRecv: s.Total.PacketsRecv,
+		Errin:       s.Total.Errin,
+		Errout:      s.Total.Errout,
+		Dropin:      s.Total.Dropin,
+		Dropout:     s.Total.Dropout,
+	}
+}
+
+// IOCollector keeps the previous per-interface counters so that rates are
+// computed per instance instead of through package state.
+type IOCollector struct {
+	mu       sync.Mutex
+	last     map[string]InterfaceIO
+	lastTime time.Time
+	latest   *IOSample
+}
+
+func NewIOCollector() *IOCollector {
+	return &IOCollector{
+		last: make(map[string]InterfaceIO),
+	}
+}
+
+func (c *IOCollector) Name() string {
+	return collector.Network
+}
+
+func (c *IOCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return c.Stats(ctx)
+}
+
+func (c *IOCollector) Latest() *IOSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest
+}
+
+func (c *IOCollector) Stats(ctx context.Context) (*IOSample, error) {
+	stats, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	duration := 0.0
+	if !c.lastTime.IsZero() {
+		duration = now.Sub(c.lastTime).Seconds()
+	}
+
+	sample := &IOSample{
+		Total:      InterfaceIO{Name: "all"},
+		Interfaces: make([]InterfaceIO, 0, len(stats)),
+	}
+
+	for _, stat := range stats {
+		iface := InterfaceIO{
+			Name:        stat.Name,
+			BytesSent:   stat.BytesSent,
+			BytesRecv:   stat.BytesRecv,
+			PacketsSent: stat.PacketsSent,
+			PacketsRecv: stat.PacketsRecv,
+			Errin:       stat.Errin,
+			Errout:      stat.Errout,
+			Dropin:      stat.Dropin,
+			Dropout:     stat.Dropout,
+		}
+
+		if previous, exists := c.last[stat.Name]; exists && duration > 0 {
+			iface.SentPerSec = counterRate(iface.BytesSent, previous.BytesSent, duration)
+			iface.RecvPerSec = counterRate(iface.BytesRecv, previous.BytesRecv, duration)
+		}
+		c.last[stat.Name] = iface
+
+		sample.Interfaces = append(sample.Interfaces, iface)
+		sample.Total.BytesSent += iface.BytesSent
+		sample.Total.BytesRecv += iface.BytesRecv
+		sample.Total.PacketsSent += iface.PacketsSent
+		sample.Total.PacketsRecv += iface.PacketsRecv
+		sample.Total.Errin += iface.Errin
+		sample.Total.Errout += iface.Errout
+		sample.Total.Dropin += iface.Dropin
+		sample.Total.Dropout += iface.Dropout
+		sample.Total.SentPerSec += iface.SentPerSec
+		sample.Total.RecvPerSec += iface.RecvPerSec
+	}
+
+	sort.Slice(sample.Interfaces, func(i, j int) bool {
+		return strings.ToLower(sample.Interfaces[i].Name) < strings.ToLower(sample.Interfaces[j].Name)
+	})
+
+	c.lastTime = now
+	c.latest = sample
+	return sample, nil
+}
+
+func counterRate(current, previous uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
+
+type ConnectionsCollector struct{}
+
+func NewConnectionsCollector() *ConnectionsCollector {
+	return &ConnectionsCollector{}
+}
+
+func (c *ConnectionsCollector) Name() string {
+	return collector.NetworkConnections
+}
+
+func (c *ConnectionsCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return getNetworkConnections(ctx)
+}
+
+func (s *ConnectionStats) Points() []collector.Point {
+	counts := map[string]int{
+		"established": s.Summary.Established,
+		"listen":      s.Summary.Listen,
+		"time_wait":   s.Summary.TimeWait,
+		"close_wait":  s.Summary.CloseWait,
+		"syn_sent":    s.Summary.SynSent,
+		"syn_recv":    s.Summary.SynRecv,
+		"fin_wait1":   s.Summary.FinWait1,
+		"fin_wait2":   s.Summary.FinWait2,
+		"closing":     s.Summary.Closing,
+		"last_ack":    s.Summary.LastAck,
+	}
+
+	states := make([]string, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	points := []collector.Point{
+		collector.GaugePoint("network_connections_count", float64(s.Summary.Total), nil),
+	}
+	for _, state := range states {
+		points = append(points, collector.GaugePoint("network_connections", float64(counts[state]), map[string]string{"state": state}))
+	}
+	return points
+}
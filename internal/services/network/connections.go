@@ -1,8 +1,10 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -39,7 +41,11 @@ type ConnectionSummary struct {
 }
 
 func GetNetworkConnections() (*ConnectionStats, error) {
-	connections, err := net.Connections("all")
+	return getNetworkConnections(context.Background())
+}
+
+func getNetworkConnections(ctx context.Context) (*ConnectionStats, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "all")
 	if err != nil {
 		return nil, err
 	}
@@ -50,50 +56,79 @@ func GetNetworkConnections() (*ConnectionStats, error) {
 	}
 
 	for _, conn := range connections {
-		connStat := ConnectionStat{
-			LocalAddr:  fmt.Sprintf("%s:%d", conn.Laddr.IP, conn.Laddr.Port),
-			RemoteAddr: fmt.Sprintf("%s:%d", conn.Raddr.IP, conn.Raddr.Port),
-			Status:     conn.Status,
-			PID:        conn.Pid,
-			Family:     conn.Family,
-			Type:       conn.Type,
+		stats.Connections = append(stats.Connections, newConnectionStat(conn))
+	}
+	stats.Summary = SummarizeConnections(stats.Connections)
+	sortConnections(stats.Connections)
+
+	return stats, nil
+}
+
+// GetProcessConnections returns the connections of one process only, which
+// is much cheaper than listing every connection and filtering.
+func GetProcessConnections(pid int32) ([]ConnectionStat, error) {
+	connections, err := net.ConnectionsPid("all", pid)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]ConnectionStat, 0, len(connections))
+	for _, conn := range connections {
+		stats = append(stats, newConnectionStat(conn))
+	}
+	sortConnections(stats)
+	return stats, nil
+}
+
+func newConnectionStat(conn net.ConnectionStat) ConnectionStat {
+	return ConnectionStat{
+		LocalAddr:  fmt.Sprintf("%s:%d", conn.Laddr.IP, conn.Laddr.Port),
+		RemoteAddr: fmt.Sprintf("%s:%d", conn.Raddr.IP, conn.Raddr.Port),
+		Status:     conn.Status,
+		PID:        conn.Pid,
+		Family:     conn.Family,
+		Type:       conn.Type,
+	}
+}
+
+func sortConnections(connections []ConnectionStat) {
+	sort.Slice(connections, func(i, j int) bool {
+		if connections[i].Status == connections[j].Status {
+			return connections[i].LocalAddr < connections[j].LocalAddr
 		}
+		return getStatusPriority(connections[i].Status) < getStatusPriority(connections[j].Status)
+	})
+}
 
-		stats.Connections = append(stats.Connections, connStat)
-		stats.Summary.Total++
+func SummarizeConnections(connections []ConnectionStat) ConnectionSummary {
+	var summary ConnectionSummary
+	for _, conn := range connections {
+		summary.Total++
 
 		switch conn.Status {
 		case "ESTABLISHED":
-			stats.Summary.Established++
+			summary.Established++
 		case "LISTEN":
-			stats.Summary.Listen++
+			summary.Listen++
 		case "TIME_WAIT":
-			stats.Summary.TimeWait++
+			summary.TimeWait++
 		case "CLOSE_WAIT":
-			stats.Summary.CloseWait++
+			summary.CloseWait++
 		case "SYN_SENT":
-			stats.Summary.SynSent++
+			summary.SynSent++
 		case "SYN_RECV":
-			stats.Summary.SynRecv++
+			summary.SynRecv++
 		case "FIN_WAIT1":
-			stats.Summary.FinWait1++
+			summary.FinWait1++
 		case "FIN_WAIT2":
-			stats.Summary.FinWait2++
+			summary.FinWait2++
 		case "CLOSING":
-			stats.Summary.Closing++
+			summary.Closing++
 		case "LAST_ACK":
-			stats.Summary.LastAck++
+			summary.LastAck++
 		}
 	}
-
-	sort.Slice(stats.Connections, func(i, j int) bool {
-		if stats.Connections[i].Status == stats.Connections[j].Status {
-			return stats.Connections[i].LocalAddr < stats.Connections[j].LocalAddr
-		}
-		return getStatusPriority(stats.Connections[i].Status) < getStatusPriority(stats.Connections[j].Status)
-	})
-
-	return stats, nil
+	return summary
 }
 
 func getStatusPriority(status string) int {
@@ -129,7 +164,16 @@ func UpdateNetworkConnections(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyConnectionStats(d, connStats)
+}
+
+func ApplyConnectionStats(d *utils.Dashboard, connStats *ConnectionStats) {
 	d.NetworkConnsData = connStats
+	d.Samples.Set(collector.NetworkConnections, connStats)
+	if d.NetworkConnsWidget == nil {
+		return
+	}
+
 	d.NetworkConnsWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		currentY := y + 1
 
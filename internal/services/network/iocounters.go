@@ -1,23 +1,19 @@
 package network
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
-	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/shirou/gopsutil/net"
 )
 
-var (
-	lastBytesSent   uint64
-	lastBytesRecv   uint64
-	lastMeasurement time.Time
-	bytesSentPerSec float64
-	bytesRecvPerSec float64
-)
+var defaultIOCollector = NewIOCollector()
 
 func getNetworkBar(bytesPerSec float64, barColor string, d *utils.Dashboard, w int) string {
 	maxSpeed := 100.0 * 1024 * 1024
@@ -62,58 +58,83 @@ func UpdateNetwork(d *utils.Dashboard) {
 		return
 	}
 
-	d.NetWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
-		stats, err := net.IOCounters(false)
-		if err == nil && len(stats) > 0 {
-			d.NetData = &stats[0]
-
-			now := time.Now()
-			if !lastMeasurement.IsZero() {
-				duration := now.Sub(lastMeasurement).Seconds()
-				if duration > 0 {
-					bytesSentPerSec = float64(d.NetData.BytesSent-lastBytesSent) / duration
-					bytesRecvPerSec = float64(d.NetData.BytesRecv-lastBytesRecv) / duration
-				}
-			}
+	sample, err := defaultIOCollector.Stats(context.Background())
+	if err != nil {
+		d.NetWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+			utils.SafePrintText(screen, "Network stats unavailable", x+2, y+2, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+			return x, y, w, h
+		})
+		return
+	}
 
-			lastBytesSent = d.NetData.BytesSent
-			lastBytesRecv = d.NetData.BytesRecv
-			lastMeasurement = now
+	ApplyIOSample(d, sample)
+}
 
-			uploadColor := d.Theme.Network.BarLow
-			if bytesSentPerSec > 10*1024*1024 {
-				uploadColor = d.Theme.Network.BarHigh
-			}
+func ApplyIOSample(d *utils.Dashboard, sample *IOSample) {
+	d.NetData = sample.Counters()
+	d.Samples.Set(collector.Network, sample)
+	if d.NetWidget == nil {
+		return
+	}
 
-			downloadColor := d.Theme.Network.BarLow
-			if bytesRecvPerSec > 10*1024*1024 {
-				downloadColor = d.Theme.Network.BarHigh
-			}
+	bytesSentPerSec := sample.Total.SentPerSec
+	bytesRecvPerSec := sample.Total.RecvPerSec
+	d.NetWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		if utils.IsHistoryView(d.Theme.Layout.Network.View) && d.History != nil {
+			drawNetworkHistory(screen, d, sample, x, y, w, h)
+			return x, y, w, h
+		}
 
-			uploadBar := getNetworkBar(bytesSentPerSec, uploadColor, d, w)
-			downloadBar := getNetworkBar(bytesRecvPerSec, downloadColor, d, w)
+		uploadColor := d.Theme.Network.BarLow
+		if bytesSentPerSec > 10*1024*1024 {
+			uploadColor = d.Theme.Network.BarHigh
+		}
 
-			uploadText := fmt.Sprintf("Upload  : %s %s", uploadBar, formatBytes(bytesSentPerSec))
-			tview.Print(screen, uploadText, x+2, y+1, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
-			currentY := y + 2
+		downloadColor := d.Theme.Network.BarLow
+		if bytesRecvPerSec > 10*1024*1024 {
+			downloadColor = d.Theme.Network.BarHigh
+		}
 
-			downloadText := fmt.Sprintf("Download: %s %s", downloadBar, formatBytes(bytesRecvPerSec))
-			tview.Print(screen, downloadText, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
-			currentY++
+		uploadBar := getNetworkBar(bytesSentPerSec, uploadColor, d, w)
+		downloadBar := getNetworkBar(bytesRecvPerSec, downloadColor, d, w)
 
-			for _, iface := range GetInterfaces() {
-				if currentY >= y+h-1 {
-					break
-				}
-				currentY = utils.SafePrintText(screen, iface, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+		uploadText := fmt.Sprintf("Upload  : %s %s", uploadBar, formatBytes(bytesSentPerSec))
+		tview.Print(screen, uploadText, x+2, y+1, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+		currentY := y + 2
+
+		downloadText := fmt.Sprintf("Download: %s %s", downloadBar, formatBytes(bytesRecvPerSec))
+		tview.Print(screen, downloadText, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+		currentY++
+
+		for _, iface := range GetInterfaces() {
+			if currentY >= y+h-1 {
+				break
 			}
-		} else {
-			utils.SafePrintText(screen, "Network stats unavailable", x+2, y+2, w-2, h-1, utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+			currentY = utils.SafePrintText(screen, iface, x+2, currentY, w-2, h-(currentY-y), utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
 		}
 		return x, y, w, h
 	})
 }
 
+func drawNetworkHistory(screen tcell.Screen, d *utils.Dashboard, sample *IOSample, x, y, w, h int) {
+	innerW := w - 4
+	chartH := h - 3
+	if innerW <= 0 || chartH <= 0 {
+		return
+	}
+
+	legend := fmt.Sprintf("[%s]Up[-] %s  [%s]Down[-] %s",
+		d.Theme.Network.BarHigh, formatBytes(sample.Total.SentPerSec),
+		d.Theme.Network.BarLow, formatBytes(sample.Total.RecvPerSec))
+	tview.Print(screen, legend, x+2, y+1, innerW, tview.AlignLeft, utils.GetColorFromName(d.Theme.Layout.Network.ForegroundColor))
+
+	interval := d.Theme.Layout.Network.UpdateInterval
+	utils.DrawLineChart(screen, []utils.ChartSeries{
+		{Label: "Down", Values: utils.HistoryValues(d, "network_aggregate_receive_bytes_per_second", nil, innerW*2, interval), Color: utils.GetColorFromName(d.Theme.Network.BarLow)},
+		{Label: "Up", Values: utils.HistoryValues(d, "network_aggregate_transmit_bytes_per_second", nil, innerW*2, interval), Color: utils.GetColorFromName(d.Theme.Network.BarHigh)},
+	}, x+2, y+2, innerW, chartH, 0, math.NaN())
+}
+
 func GetNetworkFormattedInfo() string {
 	stats, err := net.IOCounters(false)
 	if err != nil {
@@ -133,6 +154,11 @@ func GetNetworkFormattedInfo() string {
 		info += "\n"
 	}
 
+	var bytesSentPerSec, bytesRecvPerSec float64
+	if latest := defaultIOCollector.Latest(); latest != nil {
+		bytesSentPerSec = latest.Total.SentPerSec
+		bytesRecvPerSec = latest.Total.RecvPerSec
+	}
 	info += fmt.Sprintf("Up/Down Speed: %s / %s\n", formatBytes(bytesSentPerSec), formatBytes(bytesRecvPerSec))
 	info += "\n"
 
@@ -0,0 +1,183 @@
+package pressure
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"time"
+)
+
+func (s *PressureSample) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(s.Resources)*12)
+	for _, r := range s.Resources {
+		labels := map[string]string{"resource": r.Name}
+		points = append(points, stallPoints("some", r.Some, labels)...)
+		if r.HasFull {
+			points = append(points, stallPoints("full", r.Full, labels)...)
+		}
+	}
+	return points
+}
+
+func stallPoints(kind string, stall Stall, labels map[string]string) []collector.Point {
+	prefix := "pressure_" + kind + "_"
+	return []collector.Point{
+		collector.GaugePoint(prefix+"avg10", stall.Avg10, labels),
+		collector.GaugePoint(prefix+"avg60", stall.Avg60, labels),
+		collector.GaugePoint(prefix+"avg300", stall.Avg300, labels),
+		collector.CounterPoint(prefix+"stalled_seconds_total", float64(stall.Total)/1e6, labels),
+		collector.GaugePoint(prefix+"stalled_percent", stall.Rate, labels),
+	}
+}
+
+// Collector reads the pressure files of the system or of one cgroup. It keeps
+// the previous totals so the stall rates are computed per instance.
+type Collector struct {
+	dir    string
+	suffix string
+	cgroup string
+
+	mu       sync.Mutex
+	last     map[string]Resource
+	lastTime time.Time
+}
+
+func NewCollector() *Collector {
+	return NewCollectorAt(DefaultRoot)
+}
+
+// NewCollectorAt returns a collector for the system-wide pressure files in
+// dir, laid out like /proc/pressure.
+func NewCollectorAt(dir string) *Collector {
+	return &Collector{dir: dir, last: make(map[string]Resource)}
+}
+
+// NewCgroupCollector returns a collector for the cpu.pressure, memory.pressure
+// and io.pressure files of the cgroup at path below the cgroup v2 root.
+func NewCgroupCollector(root, path string) *Collector {
+	return &Collector{
+		dir:    filepath.Join(root, path),
+		suffix: ".pressure",
+		cgroup: path,
+		last:   make(map[string]Resource),
+	}
+}
+
+func (c *Collector) Name() string {
+	return collector.Pressure
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return c.Stats(ctx)
+}
+
+// Cgroup returns the cgroup path the collector reads, or "" for the system.
+func (c *Collector) Cgroup() string {
+	return c.cgroup
+}
+
+func (c *Collector) Stats(ctx context.Context) (*PressureSample, error) {
+	resources := make([]Resource, 0, len(Resources))
+	for _, name := range Resources {
+		resource, err := readResource(filepath.Join(c.dir, name+c.suffix), name)
+		if err != nil {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("pressure stall information is not available in %s", c.dir)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	currentTime := time.Now()
+	duration := currentTime.Sub(c.lastTime).Seconds()
+	for i := range resources {
+		r := &resources[i]
+		if last, exists := c.last[r.Name]; exists && !c.lastTime.IsZero() {
+			r.Some.Rate = stallRate(r.Some.Total, last.Some.Total, duration)
+			r.Full.Rate = stallRate(r.Full.Total, last.Full.Total, duration)
+		}
+		c.last[r.Name] = *r
+	}
+	c.lastTime = currentTime
+
+	return &PressureSample{Cgroup: c.cgroup, Resources: resources, LastTime: currentTime}, nil
+}
+
+// readResource parses a pressure file:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readResource(path, name string) (Resource, error) {
+	resource := Resource{Name: name}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return resource, err
+	}
+	defer file.Close()
+
+	found := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stall *Stall
+		switch fields[0] {
+		case "some":
+			stall = &resource.Some
+		case "full":
+			stall = &resource.Full
+			resource.HasFull = true
+		default:
+			continue
+		}
+		found = true
+
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "avg10":
+				stall.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stall.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stall.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				stall.Total, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return resource, err
+	}
+	if !found {
+		return resource, fmt.Errorf("%s has no pressure data", path)
+	}
+	return resource, nil
+}
+
+// stallRate turns the growth of a total in microseconds into the percentage
+// of the interval spent stalled.
+func stallRate(current, previous uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	rate := float64(current-previous) / seconds / 1e4
+	if rate > 100 {
+		rate = 100
+	}
+	return rate
+}
@@ -0,0 +1,234 @@
+package pressure
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// DefaultRoot is where the kernel reports system-wide pressure.
+const DefaultRoot = "/proc/pressure"
+
+// Resources lists the resources the kernel reports pressure for.
+var Resources = []string{"cpu", "memory", "io"}
+
+// Stall is one line of a pressure file. The averages are the percentage of
+// time some or all tasks were stalled; Rate is the same percentage over the
+// last collection interval, derived from Total.
+type Stall struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"` // Microseconds stalled
+	Rate   float64 `json:"rate"`
+}
+
+type Resource struct {
+	Name    string `json:"name"`
+	Some    Stall  `json:"some"`
+	Full    Stall  `json:"full"`
+	HasFull bool   `json:"has_full"`
+}
+
+// PressureSample holds the pressure of the system, or of Cgroup when set.
+type PressureSample struct {
+	Cgroup    string     `json:"cgroup,omitempty"`
+	Resources []Resource `json:"resources"`
+	LastTime  time.Time  `json:"last_time"`
+}
+
+var (
+	defaultCollector = NewCollector()
+
+	// cgroupCollector follows the cgroup selected in the cgroup view.
+	cgroupMu        sync.Mutex
+	cgroupCollector *Collector
+)
+
+func GetPressureSample() (*PressureSample, error) {
+	return defaultCollector.Stats(context.Background())
+}
+
+func UpdatePressure(d *utils.Dashboard) {
+	if d.PressureWidget == nil {
+		return
+	}
+
+	sample, err := GetPressureSample()
+	if err != nil {
+		d.PressureWidget.SetTitle("Pressure")
+		d.PressureWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+			utils.SafePrintText(screen, "Pressure stall information unavailable", x+3, y+1, w-6, y+h-1, tcell.ColorRed)
+			return x, y, w, h
+		})
+		return
+	}
+
+	ApplyPressureSample(d, sample)
+
+	// While the cgroup view is focused the widget shows the selected cgroup.
+	if root, path, ok := focusedCgroup(d); ok {
+		if cgroupSample, err := getCgroupSample(root, path); err == nil {
+			showPressure(d, cgroupSample)
+		}
+	}
+}
+
+func focusedCgroup(d *utils.Dashboard) (string, string, bool) {
+	if d.App == nil || d.CgroupsWidget == nil || d.App.GetFocus() != d.CgroupsWidget {
+		return "", "", false
+	}
+	sample, ok := d.CgroupsData.(*cgroups.CgroupSample)
+	if !ok {
+		return "", "", false
+	}
+	path, ok := cgroups.SelectedCgroup(d)
+	return sample.Root, path, ok
+}
+
+func getCgroupSample(root, path string) (*PressureSample, error) {
+	cgroupMu.Lock()
+	defer cgroupMu.Unlock()
+
+	if cgroupCollector == nil || cgroupCollector.Cgroup() != path {
+		cgroupCollector = NewCgroupCollector(root, path)
+	}
+	return cgroupCollector.Stats(context.Background())
+}
+
+func ApplyPressureSample(d *utils.Dashboard, sample *PressureSample) {
+	d.PressureData = sample
+	d.Samples.Set(collector.Pressure, sample)
+	if d.PressureWidget == nil {
+		return
+	}
+
+	showPressure(d, sample)
+}
+
+func showPressure(d *utils.Dashboard, sample *PressureSample) {
+	title := "Pressure"
+	if sample.Cgroup != "" {
+		title += ": " + sample.Cgroup
+	}
+	d.PressureWidget.SetTitle(title)
+
+	d.PressureWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		foreground := utils.GetColorFromName(d.Theme.Layout.Pressure.ForegroundColor)
+		if sample.Cgroup == "" && utils.IsHistoryView(d.Theme.Layout.Pressure.View) && d.History != nil {
+			drawPressureHistory(screen, d, sample, x, y, w, h)
+			return x, y, w, h
+		}
+
+		currentY := utils.SafePrintText(screen, "           avg10 avg60 avg300 stalled", x+2, y+1, w-4, h-1, foreground)
+		for _, r := range sample.Resources {
+			lines := []string{formatStall(resourceLabel(r.Name), "some", r.Some)}
+			if r.HasFull {
+				lines = append(lines, formatStall("", "full", r.Full))
+			}
+			for _, line := range lines {
+				if currentY >= y+h-1 {
+					return x, y, w, h
+				}
+				currentY = utils.SafePrintText(screen, line, x+2, currentY, w-4, h-(currentY-y), foreground)
+			}
+		}
+		return x, y, w, h
+	})
+}
+
+func resourceLabel(name string) string {
+	switch name {
+	case "cpu":
+		return "CPU"
+	case "memory":
+		return "MEM"
+	case "io":
+		return "IO"
+	}
+	return strings.ToUpper(name)
+}
+
+func formatStall(label, kind string, stall Stall) string {
+	return fmt.Sprintf("%-4s %-4s [%s]%6.2f[-] %5.2f %6.2f %6.1f%%",
+		label, kind, stallColor(stall.Avg10), stall.Avg10, stall.Avg60, stall.Avg300, stall.Rate)
+}
+
+func stallColor(avg float64) string {
+	switch {
+	case avg >= 20:
+		return "red"
+	case avg >= 5:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
+
+var resourceColors = map[string]tcell.Color{
+	"cpu":    tcell.ColorGreen,
+	"memory": tcell.ColorYellow,
+	"io":     tcell.ColorBlue,
+}
+
+func drawPressureHistory(screen tcell.Screen, d *utils.Dashboard, sample *PressureSample, x, y, w, h int) {
+	innerW := w - 4
+	chartH := h - 3
+	if innerW <= 0 || chartH <= 0 {
+		return
+	}
+
+	interval := d.Theme.Layout.Pressure.UpdateInterval
+	var legend []string
+	var series []utils.ChartSeries
+	for _, r := range sample.Resources {
+		color := resourceColors[r.Name]
+		legend = append(legend, fmt.Sprintf("[%s]%s[-] %.2f", color.String(), resourceLabel(r.Name), r.Some.Avg10))
+		series = append(series, utils.ChartSeries{
+			Label:  r.Name,
+			Values: utils.HistoryValues(d, "pressure_some_avg10", map[string]string{"resource": r.Name}, innerW*2, interval),
+			Color:  color,
+		})
+	}
+
+	tview.Print(screen, "some avg10  "+strings.Join(legend, "  "), x+2, y+1, innerW, tview.AlignLeft, utils.GetColorFromName(d.Theme.Layout.Pressure.ForegroundColor))
+	utils.DrawLineChart(screen, series, x+2, y+2, innerW, chartH, 0, math.NaN())
+}
+
+func GetPressureFormattedInfo(d *utils.Dashboard) string {
+	sample, ok := d.PressureData.(*PressureSample)
+	if !ok {
+		return "Pressure stall information unavailable\n\nPSI needs Linux 4.20 or later with CONFIG_PSI enabled."
+	}
+
+	var info strings.Builder
+	info.WriteString("=== Pressure Stall Information ===\n")
+	info.WriteString("Share of time tasks were stalled waiting for a resource.\n")
+	info.WriteString("some: at least one task stalled; full: all non-idle tasks stalled at once.\n\n")
+
+	for _, r := range sample.Resources {
+		fmt.Fprintf(&info, "%s\n", resourceLabel(r.Name))
+		writeStall(&info, "some", r.Some)
+		if r.HasFull {
+			writeStall(&info, "full", r.Full)
+		}
+		info.WriteString("\n")
+	}
+	info.WriteString("Focus the cgroup view to show the pressure of the selected cgroup.\n")
+	return info.String()
+}
+
+func writeStall(info *strings.Builder, kind string, stall Stall) {
+	fmt.Fprintf(info, "  %s: avg10 %.2f%%, avg60 %.2f%%, avg300 %.2f%%, %.1f%% stalled since the last update, %s in total\n",
+		kind, stall.Avg10, stall.Avg60, stall.Avg300, stall.Rate,
+		(time.Duration(stall.Total) * time.Microsecond).Round(time.Millisecond))
+}
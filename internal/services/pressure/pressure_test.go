@@ -0,0 +1,134 @@
+package pressure
+
+import (
+	"context"
+	"path/filepath"
+	"syspulse/internal/testutil"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/utils"
+
+	"github.com/rivo/tview"
+)
+
+func TestCollectorStats(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"cpu":    "some avg10=3.18 avg60=3.28 avg300=2.22 total=123200766\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"memory": "some avg10=1.50 avg60=0.50 avg300=0.10 total=5000000\nfull avg10=0.75 avg60=0.25 avg300=0.05 total=2000000\n",
+		// Only some is reported, as for cpu before Linux 5.13.
+		"io": "some avg10=0.00 avg60=0.00 avg300=0.00 total=10\n",
+	})
+	c := NewCollectorAt(dir)
+
+	sample, err := c.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if len(sample.Resources) != 3 || sample.Cgroup != "" {
+		t.Fatalf("Expected three resources, got %+v", sample)
+	}
+	cpu := sample.Resources[0]
+	if cpu.Name != "cpu" || cpu.Some != (Stall{Avg10: 3.18, Avg60: 3.28, Avg300: 2.22, Total: 123200766}) || !cpu.HasFull {
+		t.Errorf("Unexpected cpu pressure %+v", cpu)
+	}
+	if io := sample.Resources[2]; io.HasFull {
+		t.Errorf("Expected no full line for io, got %+v", io)
+	}
+
+	// A quarter second of memory stalls over the last second.
+	c.mu.Lock()
+	c.lastTime = time.Now().Add(-time.Second)
+	last := c.last["memory"]
+	last.Some.Total -= 250000
+	c.last["memory"] = last
+	c.mu.Unlock()
+
+	sample, _ = c.Stats(context.Background())
+	if rate := sample.Resources[1].Some.Rate; rate < 20 || rate > 25 {
+		t.Errorf("Expected about 25%% stalled, got %.1f", rate)
+	}
+
+	names := make(map[string]int)
+	for _, p := range sample.Points() {
+		names[p.Name]++
+	}
+	if names["pressure_some_avg10"] != 3 || names["pressure_full_avg10"] != 2 || names["pressure_some_stalled_percent"] != 3 {
+		t.Errorf("Unexpected points %v", names)
+	}
+
+	if _, err := NewCollectorAt(t.TempDir()).Stats(context.Background()); err == nil {
+		t.Error("Expected an error without pressure files")
+	}
+}
+
+func TestCgroupPressure(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{"cgroup.controllers": "cpu io memory\n"})
+	testutil.WriteFiles(t, filepath.Join(root, "system.slice", "nginx.service"), map[string]string{
+		"cpu.pressure":    "some avg10=12.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"memory.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"io.pressure":     "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+	})
+
+	sample, err := NewCgroupCollector(root, "/system.slice/nginx.service").Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if sample.Cgroup != "/system.slice/nginx.service" || len(sample.Resources) != 3 || sample.Resources[0].Some.Avg10 != 12 {
+		t.Errorf("Unexpected cgroup pressure %+v", sample)
+	}
+
+	cgroupSample, err := cgroups.NewCollectorAt(root).Stats(context.Background())
+	if err != nil {
+		t.Fatalf("cgroup Stats failed: %v", err)
+	}
+	d := &utils.Dashboard{
+		App:           tview.NewApplication(),
+		Samples:       collector.NewSnapshot(),
+		CgroupsWidget: tview.NewTreeView(),
+	}
+	cgroups.ApplyCgroupSample(d, cgroupSample)
+	d.CgroupsWidget.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if node.GetReference() == "/system.slice/nginx.service" {
+			d.CgroupsWidget.SetCurrentNode(node)
+		}
+		return true
+	})
+
+	if _, _, ok := focusedCgroup(d); ok {
+		t.Error("Expected no cgroup while the cgroup view is not focused")
+	}
+	d.App.SetFocus(d.CgroupsWidget)
+	if gotRoot, path, ok := focusedCgroup(d); !ok || gotRoot != root || path != "/system.slice/nginx.service" {
+		t.Errorf("Expected the selected cgroup, got %q %q %v", gotRoot, path, ok)
+	}
+}
+
+func TestApplyPressureSample(t *testing.T) {
+	d := &utils.Dashboard{
+		Samples:        collector.NewSnapshot(),
+		PressureWidget: tview.NewBox(),
+	}
+	sample := &PressureSample{Resources: []Resource{{Name: "cpu", Some: Stall{Avg10: 1}}}}
+
+	ApplyPressureSample(d, sample)
+	if _, ok := d.Samples.Get(collector.Pressure); !ok || d.PressureData != sample {
+		t.Error("Expected the sample in the snapshot")
+	}
+	if title := d.PressureWidget.GetTitle(); title != "Pressure" {
+		t.Errorf("Unexpected title %q", title)
+	}
+
+	showPressure(d, &PressureSample{Cgroup: "/user.slice"})
+	if title := d.PressureWidget.GetTitle(); title != "Pressure: /user.slice" {
+		t.Errorf("Expected the cgroup in the title, got %q", title)
+	}
+
+	if line := formatStall("MEM", "some", Stall{Avg10: 25, Avg60: 1, Avg300: 0.5, Rate: 30}); line != "MEM  some [red] 25.00[-]  1.00   0.50   30.0%" {
+		t.Errorf("Unexpected line %q", line)
+	}
+}
@@ -0,0 +1,80 @@
+package processes
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+)
+
+func (t *ProcessTree) Points() []collector.Point {
+	statusCounts := make(map[string]int)
+	countProcessesByStatus(t.Roots, statusCounts)
+
+	statuses := make([]string, 0, len(statusCounts))
+	for status := range statusCounts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	points := []collector.Point{
+		collector.GaugePoint("processes_count", float64(t.TotalCount), nil),
+	}
+	for _, status := range statuses {
+		state := strings.ToLower(status)
+		if state == "" {
+			state = "unknown"
+		}
+		points = append(points, collector.GaugePoint("processes_state", float64(statusCounts[status]), map[string]string{"state": state}))
+	}
+	return points
+}
+
+func (t *ProcessTree) Flatten() []*ProcessNode {
+	var nodes []*ProcessNode
+	var walk func([]*ProcessNode)
+	walk = func(children []*ProcessNode) {
+		for _, node := range children {
+			nodes = append(nodes, node)
+			walk(node.Children)
+		}
+	}
+	walk(t.Roots)
+	return nodes
+}
+
+// AlertProcesses lets process[name] alert rules match individual processes.
+func (t *ProcessTree) AlertProcesses() []alerts.Process {
+	nodes := t.Flatten()
+	procs := make([]alerts.Process, 0, len(nodes))
+	for _, node := range nodes {
+		procs = append(procs, alerts.Process{PID: node.PID, Name: node.Name, CPUPercent: node.CPUPct, Memory: node.Memory})
+	}
+	return procs
+}
+
+func (t *ProcessTree) TopByCPU(n int) []*ProcessNode {
+	nodes := t.Flatten()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].CPUPct > nodes[j].CPUPct
+	})
+	if len(nodes) > n {
+		nodes = nodes[:n]
+	}
+	return nodes
+}
+
+type TreeCollector struct{}
+
+func NewTreeCollector() *TreeCollector {
+	return &TreeCollector{}
+}
+
+func (c *TreeCollector) Name() string {
+	return collector.ProcessTree
+}
+
+func (c *TreeCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetProcessTree()
+}
@@ -0,0 +1,456 @@
+package processes
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"syspulse/internal/collector"
+	"syspulse/internal/services/cgroups"
+	"syspulse/internal/services/network"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// FileDescriptor is an open file of a process.
+type FileDescriptor struct {
+	FD     int
+	Target string
+}
+
+// MemoryField is one line of /proc/<pid>/smaps_rollup.
+type MemoryField struct {
+	Name  string
+	Bytes uint64
+}
+
+// Limit is a resource limit of a process. Soft and Hard are as the kernel
+// shows them, including "unlimited".
+type Limit struct {
+	Name  string
+	Soft  string
+	Hard  string
+	Units string
+}
+
+type inspectorTab struct {
+	title  string
+	render func(i *inspector, proc ProcessRow) string
+}
+
+var inspectorTabs = []inspectorTab{
+	{"Files", (*inspector).renderFiles},
+	{"Network", (*inspector).renderNetwork},
+	{"Environment", (*inspector).renderEnvironment},
+	{"Memory", (*inspector).renderMemory},
+	{"Threads", (*inspector).renderThreads},
+	{"Limits", (*inspector).renderLimits},
+	{"Cgroup", (*inspector).renderCgroup},
+	{"Paths", (*inspector).renderPaths},
+	{"Parents", (*inspector).renderParents},
+}
+
+const inspectorKeys = "Tab/←→ or 1-9 switch tabs, ↑↓ scroll, ESC close"
+
+// inspector shows one process in tabs. The selected tab is read again every
+// process update while the inspector is open.
+type inspector struct {
+	d        *utils.Dashboard
+	procRoot string
+	pid      int32
+	start    time.Time
+
+	mu          sync.Mutex
+	tab         int
+	threads     map[int32]rawProcess
+	threadsTime time.Time
+}
+
+func newInspector(d *utils.Dashboard, procRoot string, proc ProcessRow) *inspector {
+	return &inspector{d: d, procRoot: procRoot, pid: proc.PID, start: proc.StartTime}
+}
+
+// process returns the inspected process from the current snapshot. It is gone
+// once the PID exits or is reused.
+func (i *inspector) process() (ProcessRow, bool) {
+	proc, ok := GetProcess(i.pid)
+	if !ok || !proc.StartTime.Equal(i.start) {
+		return ProcessRow{}, false
+	}
+	return proc, true
+}
+
+// render returns the header and the content of the selected tab. Once the
+// process has exited there is no content.
+func (i *inspector) render() (header, content string, tab int, alive bool) {
+	i.mu.Lock()
+	tab = i.tab
+	i.mu.Unlock()
+
+	proc, alive := i.process()
+	if !alive {
+		return fmt.Sprintf("[red]Process %d has exited[-]", i.pid), "", tab, false
+	}
+	return formatInspectorHeader(proc), inspectorTabs[tab].render(i, proc), tab, true
+}
+
+func (i *inspector) setTab(tab int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.tab = (tab + len(inspectorTabs)) % len(inspectorTabs)
+}
+
+func formatInspectorHeader(proc ProcessRow) string {
+	return fmt.Sprintf("[::b]%s[::-] PID %d  PPID %d  User %s  State %s  Started %s\n"+
+		"CPU %.1f%%  Memory %.1f%%  RSS %s  VMS %s  Threads %d  Nice %d\n"+
+		"%s",
+		tview.Escape(proc.Name), proc.PID, proc.PPID, tview.Escape(proc.User), proc.State,
+		proc.StartTime.Format("2006-01-02 15:04:05"),
+		proc.CPUPercent, proc.MemoryPercent, formatMemory(proc.RSS), formatMemory(proc.VMS),
+		proc.Threads, proc.Nice,
+		tview.Escape(proc.Command))
+}
+
+func unavailable(what string, err error) string {
+	return fmt.Sprintf("[red]%s unavailable: %s[-]", what, tview.Escape(err.Error()))
+}
+
+func (i *inspector) renderFiles(proc ProcessRow) string {
+	fds, err := readFileDescriptors(i.procRoot, proc.PID)
+	if err != nil {
+		return unavailable("Open files", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d open file descriptors\n\n", len(fds))
+	for _, fd := range fds {
+		fmt.Fprintf(&b, "%5d  %s\n", fd.FD, tview.Escape(fd.Target))
+	}
+	return b.String()
+}
+
+func (i *inspector) renderNetwork(proc ProcessRow) string {
+	connections, err := network.GetProcessConnections(proc.PID)
+	if err != nil {
+		return unavailable("Connections", err)
+	}
+	if len(connections) == 0 {
+		return "No network connections"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-6s %-28s %-28s %s\n", "PROTO", "LOCAL", "REMOTE", "STATE")
+	for _, conn := range connections {
+		fmt.Fprintf(&b, "%-6s %-28s %-28s %s\n", connectionProtocol(conn),
+			tview.Escape(conn.LocalAddr), tview.Escape(conn.RemoteAddr), conn.Status)
+	}
+	return b.String()
+}
+
+func connectionProtocol(conn network.ConnectionStat) string {
+	var protocol string
+	switch {
+	case conn.Family == syscall.AF_UNIX:
+		return "unix"
+	case conn.Type == syscall.SOCK_STREAM:
+		protocol = "tcp"
+	case conn.Type == syscall.SOCK_DGRAM:
+		protocol = "udp"
+	default:
+		protocol = strconv.Itoa(int(conn.Type))
+	}
+	if conn.Family == syscall.AF_INET6 {
+		protocol += "6"
+	}
+	return protocol
+}
+
+func (i *inspector) renderEnvironment(proc ProcessRow) string {
+	env, err := readEnvironment(i.procRoot, proc.PID)
+	if err != nil {
+		return unavailable("Environment", err)
+	}
+
+	var b strings.Builder
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		fmt.Fprintf(&b, "[yellow]%s[-]=%s\n", tview.Escape(name), tview.Escape(value))
+	}
+	return b.String()
+}
+
+func (i *inspector) renderMemory(proc ProcessRow) string {
+	fields, err := readMemoryBreakdown(i.procRoot, proc.PID)
+	if err != nil {
+		return unavailable("Memory breakdown", err)
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, "%-18s %10s\n", field.Name, formatMemory(field.Bytes))
+	}
+	return b.String()
+}
+
+// renderThreads shows the CPU use of each thread since the previous refresh,
+// busiest first.
+func (i *inspector) renderThreads(proc ProcessRow) string {
+	threads, err := readThreads(i.procRoot, proc.PID)
+	if err != nil {
+		return unavailable("Threads", err)
+	}
+
+	now := time.Now()
+	i.mu.Lock()
+	elapsed := now.Sub(i.threadsTime).Seconds()
+	previous := i.threads
+	i.threads = make(map[int32]rawProcess, len(threads))
+	for _, thread := range threads {
+		i.threads[thread.row.PID] = thread
+	}
+	i.threadsTime = now
+	i.mu.Unlock()
+
+	rows := make([]ProcessRow, 0, len(threads))
+	for _, thread := range threads {
+		last, seen := previous[thread.row.PID]
+		row := thread.row
+		row.CPUPercent = cpuPercent(thread, last, seen, elapsed, now)
+		rows = append(rows, row)
+	}
+	SortRows(rows, "cpu", false)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-8s %-20s %s %7s\n", "TID", "NAME", "S", "CPU%")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%-8d %-20s %s %7.1f\n", row.PID, tview.Escape(row.Name), row.State, row.CPUPercent)
+	}
+	return b.String()
+}
+
+func (i *inspector) renderLimits(proc ProcessRow) string {
+	limits, err := readLimits(i.procRoot, proc.PID)
+	if err != nil {
+		return unavailable("Resource limits", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-26s %-20s %-20s %s\n", "LIMIT", "SOFT", "HARD", "UNITS")
+	for _, limit := range limits {
+		fmt.Fprintf(&b, "%-26s %-20s %-20s %s\n", limit.Name, limit.Soft, limit.Hard, limit.Units)
+	}
+	return b.String()
+}
+
+// renderCgroup describes the process's cgroup from the sample of the cgroups
+// widget, or from a sample of its own when the widget is not shown.
+func (i *inspector) renderCgroup(proc ProcessRow) string {
+	path, err := cgroups.ProcessCgroup(proc.PID)
+	if err != nil {
+		return unavailable("Cgroup", err)
+	}
+
+	var sample *cgroups.CgroupSample
+	if i.d.Samples != nil {
+		if s, ok := i.d.Samples.Get(collector.Cgroups); ok {
+			sample, _ = s.(*cgroups.CgroupSample)
+		}
+	}
+	if sample == nil {
+		if sample, err = cgroups.GetCgroupSample(); err != nil {
+			return fmt.Sprintf("Cgroup: %s\n\n%s", tview.Escape(path), unavailable("Cgroup usage", err))
+		}
+	}
+	return tview.Escape(cgroups.GetCgroupFormattedInfo(sample, path))
+}
+
+func (i *inspector) renderPaths(proc ProcessRow) string {
+	var b strings.Builder
+	for _, link := range []struct{ name, title string }{{"cwd", "Working directory"}, {"exe", "Executable"}} {
+		target, err := readLink(i.procRoot, proc.PID, link.name)
+		if err != nil {
+			b.WriteString(unavailable(link.title, err) + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", link.title, tview.Escape(target))
+	}
+	return b.String()
+}
+
+func (i *inspector) renderParents(proc ProcessRow) string {
+	snapshot, err := GetProcessSnapshot()
+	if err != nil {
+		return unavailable("Parents", err)
+	}
+
+	var b strings.Builder
+	for depth, ancestor := range parentChain(snapshot, proc.PID) {
+		fmt.Fprintf(&b, "%s%d %s (%s)\n", strings.Repeat("  ", depth), ancestor.PID,
+			tview.Escape(ancestor.Name), tview.Escape(ancestor.User))
+	}
+	return b.String()
+}
+
+// parentChain returns the process and its ancestors in the snapshot, the
+// topmost first.
+func parentChain(snapshot *ProcessSnapshot, pid int32) []ProcessRow {
+	var chain []ProcessRow
+	seen := make(map[int32]bool)
+	for !seen[pid] {
+		row, ok := snapshot.Find(pid)
+		if !ok {
+			break
+		}
+		seen[pid] = true
+		chain = append(chain, row)
+		pid = row.PPID
+	}
+
+	slices.Reverse(chain)
+	return chain
+}
+
+// ShowProcessDetails opens the inspector for the selected process. Its tabs
+// refresh with every process update until it is closed.
+func ShowProcessDetails(d *utils.Dashboard) {
+	selectedPID, ok := SelectedPID(d)
+	if !ok {
+		return
+	}
+
+	proc, ok := GetProcess(selectedPID)
+	if !ok {
+		return
+	}
+
+	i := newInspector(d, defaultProcRoot, proc)
+	done := make(chan struct{})
+	refresh := make(chan struct{}, 1)
+	requestRefresh := func() {
+		select {
+		case refresh <- struct{}{}:
+		default:
+		}
+	}
+
+	tabs := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWrap(false)
+	var titles []string
+	for n, tab := range inspectorTabs {
+		titles = append(titles, fmt.Sprintf(`["%d"] %d %s [""]`, n, n+1, tab.title))
+	}
+	tabs.SetText(strings.Join(titles, " "))
+
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+
+	content := tview.NewTextView().
+		SetScrollable(true).
+		SetWrap(false).
+		SetDynamicColors(true)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(header, 3, 0, false).
+		AddItem(tabs, 2, 0, false).
+		AddItem(content, 0, 1, true)
+	utils.SetBorderStyle(layout.Box)
+	layout.SetTitle(fmt.Sprintf("Process %d (%s)", proc.PID, inspectorKeys)).
+		SetTitleAlign(tview.AlignCenter)
+
+	shown := -1
+	show := func(headerText, text string, tab int, alive bool) {
+		header.SetText(headerText)
+		tabs.Highlight(strconv.Itoa(tab))
+		if !alive {
+			// Keep what was read last.
+			return
+		}
+		if tab == shown {
+			row, column := content.GetScrollOffset()
+			content.SetText(text).ScrollTo(row, column)
+		} else {
+			content.SetText(text).ScrollToBeginning()
+			shown = tab
+		}
+	}
+
+	switchTab := func(tab int) {
+		i.setTab(tab)
+		i.mu.Lock()
+		tabs.Highlight(strconv.Itoa(i.tab))
+		i.mu.Unlock()
+		requestRefresh()
+	}
+
+	content.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		i.mu.Lock()
+		current := i.tab
+		i.mu.Unlock()
+
+		switch event.Key() {
+		case tcell.KeyEscape:
+			close(done)
+			d.InModalState = false
+			d.App.SetRoot(d.MainWidget, true).SetFocus(d.ProcessWidget)
+			return nil
+		case tcell.KeyTab, tcell.KeyRight:
+			switchTab(current + 1)
+			return nil
+		case tcell.KeyBacktab, tcell.KeyLeft:
+			switchTab(current - 1)
+			return nil
+		}
+
+		if r := event.Rune(); r >= '1' && r < '1'+rune(len(inspectorTabs)) {
+			switchTab(int(r - '1'))
+			return nil
+		}
+		return event
+	})
+
+	go func() {
+		ticker := time.NewTicker(defaultSampler.UpdateInterval())
+		defer ticker.Stop()
+
+		for {
+			headerText, text, tab, alive := i.render()
+			d.App.QueueUpdateDraw(func() {
+				select {
+				case <-done:
+				default:
+					show(headerText, text, tab, alive)
+				}
+			})
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			case <-refresh:
+			}
+		}
+	}()
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(layout, 0, 6, true).
+			AddItem(nil, 0, 1, false), 0, 6, true).
+		AddItem(nil, 0, 1, false)
+
+	tabs.Highlight("0")
+	d.InModalState = true
+	d.App.SetRoot(flex, true).SetFocus(content)
+}
@@ -0,0 +1,158 @@
+package processes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func procDir(procRoot string, pid int32) string {
+	return filepath.Join(procRoot, strconv.Itoa(int(pid)))
+}
+
+// readFileDescriptors resolves the links in /proc/<pid>/fd. Sockets and pipes
+// show as "socket:[inode]" and "pipe:[inode]".
+func readFileDescriptors(procRoot string, pid int32) ([]FileDescriptor, error) {
+	dir := filepath.Join(procDir(procRoot, pid), "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]FileDescriptor, 0, len(entries))
+	for _, entry := range entries {
+		fd, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			// Closed since the directory was listed.
+			continue
+		}
+		fds = append(fds, FileDescriptor{FD: fd, Target: target})
+	}
+	sort.Slice(fds, func(i, j int) bool {
+		return fds[i].FD < fds[j].FD
+	})
+	return fds, nil
+}
+
+func readEnvironment(procRoot string, pid int32) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(procDir(procRoot, pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	for _, variable := range strings.Split(string(data), "\x00") {
+		if variable != "" {
+			env = append(env, variable)
+		}
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// readMemoryBreakdown reads /proc/<pid>/smaps_rollup, the sums of all
+// mappings in /proc/<pid>/smaps.
+func readMemoryBreakdown(procRoot string, pid int32) ([]MemoryField, error) {
+	file, err := os.Open(filepath.Join(procDir(procRoot, pid), "smaps_rollup"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var fields []MemoryField
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// The first line is the address range of the rollup.
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		fields = append(fields, MemoryField{Name: name, Bytes: kb * 1024})
+	}
+	return fields, scanner.Err()
+}
+
+// readThreads reads /proc/<pid>/task/<tid>/stat of every thread.
+func readThreads(procRoot string, pid int32) ([]rawProcess, error) {
+	dir := filepath.Join(procDir(procRoot, pid), "task")
+	tids, err := listPIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	boot := bootTime(procRoot)
+	threads := make([]rawProcess, 0, len(tids))
+	for _, tid := range tids {
+		raw, err := readStat(filepath.Join(dir, strconv.Itoa(int(tid)), "stat"), boot)
+		if err != nil {
+			continue
+		}
+		raw.row.PID = tid
+		threads = append(threads, raw)
+	}
+	return threads, nil
+}
+
+// readLimits parses the table in /proc/<pid>/limits. Its columns are aligned
+// to the header, and the limit names contain spaces.
+func readLimits(procRoot string, pid int32) ([]Limit, error) {
+	path := filepath.Join(procDir(procRoot, pid), "limits")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	header := lines[0]
+	soft, hard, units := strings.Index(header, "Soft Limit"), strings.Index(header, "Hard Limit"), strings.Index(header, "Units")
+	if soft < 0 || hard < soft || units < hard {
+		return nil, fmt.Errorf("malformed %s", path)
+	}
+
+	column := func(line string, from, to int) string {
+		if from >= len(line) {
+			return ""
+		}
+		return strings.TrimSpace(line[from:min(to, len(line))])
+	}
+	limits := make([]Limit, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		limits = append(limits, Limit{
+			Name:  column(line, 0, soft),
+			Soft:  column(line, soft, hard),
+			Hard:  column(line, hard, units),
+			Units: column(line, units, len(line)),
+		})
+	}
+	return limits, nil
+}
+
+// readLink resolves a link of a process like cwd or exe.
+func readLink(procRoot string, pid int32, name string) (string, error) {
+	return os.Readlink(filepath.Join(procDir(procRoot, pid), name))
+}
+
+// countFileDescriptors counts the entries of /proc/<pid>/fd without
+// resolving them.
+func countFileDescriptors(procRoot string, pid int32) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(procDir(procRoot, pid), "fd"))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
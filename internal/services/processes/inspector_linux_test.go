@@ -0,0 +1,123 @@
+package processes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syspulse/internal/testutil"
+	"testing"
+	"time"
+)
+
+const limitsFile = `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            1024                 524288               files
+Max realtime timeout      unlimited            unlimited            us
+`
+
+const smapsRollup = `55d5c2a4e000-7ffd5b3f1000 ---p 00000000 00:00 0                          [rollup]
+Rss:                9428 kB
+Pss:                2130 kB
+Swap:                  0 kB
+`
+
+func newInspectedProc(t *testing.T) string {
+	t.Helper()
+	root := newProcRoot(t)
+	dir := filepath.Join(root, "1")
+	testutil.WriteFiles(t, dir, map[string]string{
+		"environ":      "PATH=/usr/bin\x00HOME=/root\x00LANG=C.UTF-8\x00",
+		"smaps_rollup": smapsRollup,
+		"limits":       limitsFile,
+	})
+	testutil.WriteFiles(t, filepath.Join(dir, "task", "1"), map[string]string{
+		"stat": statLine(1, "systemd", 0, 150, 50, 100, 2500),
+	})
+	testutil.WriteFiles(t, filepath.Join(dir, "task", "7"), map[string]string{
+		"stat": statLine(7, "worker", 0, 10, 0, 100, 2500),
+	})
+
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"fd/0":  "/dev/null",
+		"fd/3":  "socket:[12345]",
+		"fd/12": "/var/log/syslog",
+		"cwd":   "/",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestInspectorReaders(t *testing.T) {
+	root := newInspectedProc(t)
+
+	fds, err := readFileDescriptors(root, 1)
+	if err != nil || len(fds) != 3 || fds[1].FD != 3 || fds[1].Target != "socket:[12345]" || fds[2].FD != 12 {
+		t.Errorf("Unexpected file descriptors %+v (%v)", fds, err)
+	}
+
+	env, err := readEnvironment(root, 1)
+	if err != nil || strings.Join(env, " ") != "HOME=/root LANG=C.UTF-8 PATH=/usr/bin" {
+		t.Errorf("Unexpected environment %q (%v)", env, err)
+	}
+
+	fields, err := readMemoryBreakdown(root, 1)
+	if err != nil || len(fields) != 3 || fields[0].Name != "Rss" || fields[0].Bytes != 9428*1024 {
+		t.Errorf("Unexpected memory breakdown %+v (%v)", fields, err)
+	}
+
+	limits, err := readLimits(root, 1)
+	if err != nil || len(limits) != 3 {
+		t.Fatalf("Unexpected limits %+v (%v)", limits, err)
+	}
+	if want := (Limit{Name: "Max open files", Soft: "1024", Hard: "524288", Units: "files"}); limits[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, limits[1])
+	}
+
+	if cwd, err := readLink(root, 1, "cwd"); err != nil || cwd != "/" {
+		t.Errorf("Expected / as the working directory, got %q (%v)", cwd, err)
+	}
+	if _, err := readLink(root, 1, "exe"); err == nil {
+		t.Error("Expected an error for a missing executable link")
+	}
+	if _, err := readEnvironment(root, 42); err == nil {
+		t.Error("Expected an error for an unreadable environment")
+	}
+}
+
+func TestInspectorThreads(t *testing.T) {
+	root := newInspectedProc(t)
+	s := NewSamplerAt(root)
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc, _ := snapshot.Find(1)
+	i := newInspector(nil, root, proc)
+
+	if text := i.renderThreads(proc); !strings.Contains(text, "systemd") || !strings.Contains(text, "worker") {
+		t.Errorf("Expected both threads, got:\n%s", text)
+	}
+
+	// The worker used a quarter of a CPU since the previous refresh.
+	testutil.WriteFiles(t, filepath.Join(root, "1", "task", "7"), map[string]string{
+		"stat": statLine(7, "worker", 0, 35, 0, 100, 2500),
+	})
+	i.mu.Lock()
+	i.threadsTime = time.Now().Add(-time.Second)
+	i.mu.Unlock()
+
+	lines := strings.Split(i.renderThreads(proc), "\n")
+	if !strings.HasPrefix(lines[1], "7 ") || !strings.Contains(lines[1], "25.0") {
+		t.Errorf("Expected the worker first at 25%%, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "0.0") {
+		t.Errorf("Expected the idle main thread at 0%%, got %q", lines[2])
+	}
+}
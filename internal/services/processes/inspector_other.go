@@ -0,0 +1,105 @@
+//go:build !linux
+// +build !linux
+
+package processes
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// Without a Linux /proc the inspector asks gopsutil, which implements only
+// some of the tabs on each platform.
+
+func readFileDescriptors(procRoot string, pid int32) ([]FileDescriptor, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	files, err := p.OpenFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]FileDescriptor, 0, len(files))
+	for _, file := range files {
+		fds = append(fds, FileDescriptor{FD: int(file.Fd), Target: file.Path})
+	}
+	sort.Slice(fds, func(i, j int) bool {
+		return fds[i].FD < fds[j].FD
+	})
+	return fds, nil
+}
+
+func readEnvironment(procRoot string, pid int32) ([]string, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	env, err := p.Environ()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+func readMemoryBreakdown(procRoot string, pid int32) ([]MemoryField, error) {
+	return nil, fmt.Errorf("smaps_rollup not supported on %s", runtime.GOOS)
+}
+
+func readThreads(procRoot string, pid int32) ([]rawProcess, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	times, err := p.Threads()
+	if err != nil {
+		return nil, err
+	}
+
+	// Thread start times are unknown, so they count from the process start.
+	var start time.Time
+	if createTime, err := p.CreateTime(); err == nil {
+		start = time.UnixMilli(createTime)
+	}
+	threads := make([]rawProcess, 0, len(times))
+	for tid, t := range times {
+		threads = append(threads, rawProcess{
+			row:        ProcessRow{PID: tid, StartTime: start},
+			cpuSeconds: t.User + t.System,
+		})
+	}
+	return threads, nil
+}
+
+func readLimits(procRoot string, pid int32) ([]Limit, error) {
+	return nil, fmt.Errorf("resource limits not supported on %s", runtime.GOOS)
+}
+
+func readLink(procRoot string, pid int32, name string) (string, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case "cwd":
+		return p.Cwd()
+	case "exe":
+		return p.Exe()
+	}
+	return "", fmt.Errorf("%s not supported on %s", name, runtime.GOOS)
+}
+
+func countFileDescriptors(procRoot string, pid int32) (int, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return 0, err
+	}
+	n, err := p.NumFDs()
+	return int(n), err
+}
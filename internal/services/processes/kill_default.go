@@ -50,3 +50,14 @@ func CanKillProcess(pid int32) (bool, string) {
 
 	return true, ""
 }
+
+func GetSupportedSignals() []string {
+	return []string{"KILL"}
+}
+
+func SendSignal(pid int32, name string) string {
+	if normalizeSignalName(name) == "KILL" {
+		return KillProcByID(pid)
+	}
+	return fmt.Sprintf("unsupported signal: %s", name)
+}
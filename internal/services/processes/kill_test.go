@@ -58,3 +58,15 @@ func TestForceKillProcByID(t *testing.T) {
 		t.Errorf("Expected error message for non-existent PID, got empty string")
 	}
 }
+
+func TestSendSignal(t *testing.T) {
+	if result := SendSignal(999999, "BOGUS"); result == "" {
+		t.Errorf("Expected error message for unsupported signal, got empty string")
+	}
+
+	for _, name := range GetSupportedSignals() {
+		if result := SendSignal(-1, "sig"+name); result == "" {
+			t.Errorf("Expected error message when signalling invalid PID with %s", name)
+		}
+	}
+}
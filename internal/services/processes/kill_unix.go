@@ -150,3 +150,28 @@ func CanKillProcess(pid int32) (bool, string) {
 
 	return true, ""
 }
+
+var signalsByName = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+}
+
+func GetSupportedSignals() []string {
+	return []string{"TERM", "KILL", "INT", "HUP", "QUIT", "USR1", "USR2", "STOP", "CONT"}
+}
+
+// SendSignal delivers a signal given by name, e.g. "TERM" or "SIGKILL".
+func SendSignal(pid int32, name string) string {
+	signal, exists := signalsByName[normalizeSignalName(name)]
+	if !exists {
+		return fmt.Sprintf("unsupported signal: %s", name)
+	}
+	return KillProcessWithSignal(pid, signal)
+}
@@ -6,6 +6,7 @@ package processes
 import (
 	"fmt"
 	"os/exec"
+	"strings"
 	"syscall"
 
 	"github.com/shirou/gopsutil/process"
@@ -56,6 +57,20 @@ func terminateProcessWithTaskkill(pid int32) error {
 	return nil
 }
 
+// requestTermination asks one process to close, like taskkill without /F and
+// /T: it is neither forced nor applied to the process's children.
+func requestTermination(pid int32) error {
+	cmd := exec.Command("taskkill", "/PID", fmt.Sprintf("%d", pid))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("taskkill failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
 func terminateProcessWithAPI(pid int32) error {
 	proc, err := process.NewProcess(pid)
 	if err != nil {
@@ -123,3 +138,21 @@ func CanKillProcess(pid int32) (bool, string) {
 
 	return true, ""
 }
+
+func GetSupportedSignals() []string {
+	return []string{"TERM", "KILL"}
+}
+
+func SendSignal(pid int32, name string) string {
+	switch normalizeSignalName(name) {
+	case "TERM":
+		// Escalating to a forced kill of the tree is left to an explicit KILL.
+		if err := requestTermination(pid); err != nil {
+			return fmt.Sprintf("failed to send signal TERM: %v", err)
+		}
+		return ""
+	case "KILL":
+		return ForceKillProcByID(pid)
+	}
+	return fmt.Sprintf("unsupported signal: %s", name)
+}
@@ -0,0 +1,88 @@
+package processes
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Nice values run from MinNice, scheduled first, to MaxNice.
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// IOClasses are the I/O scheduling classes of ioprio_set(2), indexed by
+// their kernel number. "none" lets the kernel derive the priority from the
+// nice value.
+var IOClasses = []string{"none", "realtime", "best-effort", "idle"}
+
+// MaxIOLevel is the lowest of the levels of the realtime and best-effort
+// classes; level 0 is served first.
+const MaxIOLevel = 7
+
+type IOPriority struct {
+	Class string `json:"class"`
+	Level int    `json:"level"`
+}
+
+// HasLevel reports whether the class orders its processes by level.
+func (p IOPriority) HasLevel() bool {
+	return p.Class == "realtime" || p.Class == "best-effort"
+}
+
+func (p IOPriority) String() string {
+	if p.HasLevel() {
+		return fmt.Sprintf("%s %d", p.Class, p.Level)
+	}
+	return p.Class
+}
+
+func validateNice(nice int) error {
+	if nice < MinNice || nice > MaxNice {
+		return fmt.Errorf("nice value %d is outside %d to %d", nice, MinNice, MaxNice)
+	}
+	return nil
+}
+
+func validateAffinity(cpus []int, maxCPUs int) error {
+	if len(cpus) == 0 {
+		return fmt.Errorf("select at least one CPU")
+	}
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= maxCPUs {
+			return fmt.Errorf("CPU %d is outside 0 to %d", cpu, maxCPUs-1)
+		}
+	}
+	return nil
+}
+
+func validateIOPriority(p IOPriority) error {
+	if !slices.Contains(IOClasses, p.Class) {
+		return fmt.Errorf("unknown I/O class %q (supported: %s)", p.Class, strings.Join(IOClasses, ", "))
+	}
+	if p.HasLevel() && (p.Level < 0 || p.Level > MaxIOLevel) {
+		return fmt.Errorf("I/O level %d is outside 0 to %d", p.Level, MaxIOLevel)
+	}
+	return nil
+}
+
+// FormatCPUList writes sorted CPU numbers the way taskset and cpusets do,
+// e.g. "0-3,6".
+func FormatCPUList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		part := strconv.Itoa(cpus[i])
+		if j > i {
+			part += "-" + strconv.Itoa(cpus[j])
+		}
+		parts = append(parts, part)
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,188 @@
+package processes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"unsafe"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// ioprio_get(2) and ioprio_set(2) have no wrappers in the syscall package.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioLevelMask  = 1<<ioprioClassShift - 1
+)
+
+// cpuMask is a cpu_set_t, large enough for the kernel's default NR_CPUS.
+type cpuMask [1024 / 64]uint64
+
+const maxAffinityCPUs = len(cpuMask{}) * 64
+
+// CanTuneProcess reports whether the nice value, CPU affinity and I/O
+// priority of a process may be changed. The kernel still decides, e.g.
+// raising a priority needs root.
+func CanTuneProcess(pid int32) (bool, string) {
+	if pid <= 0 {
+		return false, "Invalid PID"
+	}
+
+	if pid == 2 {
+		return false, "Cannot tune kernel threads"
+	}
+
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return false, "Process not found"
+	}
+
+	if ppid, err := proc.Ppid(); err == nil && ppid == 2 {
+		return false, "Cannot tune kernel threads"
+	}
+
+	uids, err := proc.Uids()
+	if err != nil || len(uids) < 2 {
+		return false, "Cannot get process owner"
+	}
+
+	euid := os.Geteuid()
+	if euid != 0 && int(uids[0]) != euid && int(uids[1]) != euid {
+		return false, "Cannot tune processes of other users without root privileges"
+	}
+
+	return true, ""
+}
+
+// forEachThread applies a per-thread setting to every thread of a process,
+// like renice and taskset -a do. Threads that exit meanwhile are skipped.
+func forEachThread(pid int32, apply func(tid int) error) error {
+	tids, err := listPIDs(filepath.Join(procDir(defaultProcRoot, pid), "task"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return syscall.ESRCH
+		}
+		return err
+	}
+
+	for _, tid := range tids {
+		if err := apply(int(tid)); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+	return nil
+}
+
+// tuningError explains the errors the scheduler calls have in common.
+func tuningError(action string, err error, privileged string) string {
+	switch {
+	case errors.Is(err, syscall.ESRCH):
+		return fmt.Sprintf("failed to %s: process not found", action)
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		return fmt.Sprintf("failed to %s: permission denied, %s", action, privileged)
+	}
+	return fmt.Sprintf("failed to %s: %v", action, err)
+}
+
+func ReniceProcess(pid int32, nice int) string {
+	if err := validateNice(nice); err != nil {
+		return err.Error()
+	}
+
+	err := forEachThread(pid, func(tid int) error {
+		return syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice)
+	})
+	if err != nil {
+		return tuningError("renice process", err, "lowering the nice value needs root or CAP_SYS_NICE")
+	}
+	return ""
+}
+
+// GetProcessAffinity returns the CPUs the main thread of a process may run
+// on.
+func GetProcessAffinity(pid int32) ([]int, error) {
+	var mask cpuMask
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY,
+		uintptr(pid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return nil, errno
+	}
+
+	var cpus []int
+	for cpu := 0; cpu < maxAffinityCPUs; cpu++ {
+		if mask[cpu/64]&(1<<(cpu%64)) != 0 {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+func SetProcessAffinity(pid int32, cpus []int) string {
+	if err := validateAffinity(cpus, maxAffinityCPUs); err != nil {
+		return err.Error()
+	}
+
+	var mask cpuMask
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+
+	err := forEachThread(pid, func(tid int) error {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY,
+			uintptr(tid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	})
+	if errors.Is(err, syscall.EINVAL) {
+		return fmt.Sprintf("failed to set CPU affinity: none of CPUs %s is online and allowed", FormatCPUList(cpus))
+	}
+	if err != nil {
+		return tuningError("set CPU affinity", err, "other users' processes need root or CAP_SYS_NICE")
+	}
+	return ""
+}
+
+// GetProcessIOPriority returns the I/O class and level of the main thread of
+// a process.
+func GetProcessIOPriority(pid int32) (IOPriority, error) {
+	value, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return IOPriority{}, errno
+	}
+
+	class := int(value >> ioprioClassShift)
+	if class >= len(IOClasses) {
+		return IOPriority{}, fmt.Errorf("unknown I/O class %d", class)
+	}
+	return IOPriority{Class: IOClasses[class], Level: int(value & ioprioLevelMask)}, nil
+}
+
+func SetProcessIOPriority(pid int32, priority IOPriority) string {
+	if err := validateIOPriority(priority); err != nil {
+		return err.Error()
+	}
+
+	var level int
+	if priority.HasLevel() {
+		level = priority.Level
+	}
+	value := uintptr(slices.Index(IOClasses, priority.Class)<<ioprioClassShift | level)
+
+	err := forEachThread(pid, func(tid int) error {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), value)
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	})
+	if err != nil {
+		return tuningError("set I/O priority", err, "the realtime class and other users' processes need root or CAP_SYS_ADMIN")
+	}
+	return ""
+}
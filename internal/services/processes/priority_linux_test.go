@@ -0,0 +1,55 @@
+package processes
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// The test process may always set its own values again unprivileged.
+func TestTuneOwnProcess(t *testing.T) {
+	pid := int32(os.Getpid())
+	if canTune, reason := CanTuneProcess(pid); !canTune {
+		t.Fatalf("Expected to be allowed to tune the test process: %s", reason)
+	}
+
+	raw, err := readProcess(defaultProcRoot, pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := ReniceProcess(pid, int(raw.row.Nice)); result != "" {
+		t.Errorf("Failed to keep the nice value: %s", result)
+	}
+
+	cpus, err := GetProcessAffinity(pid)
+	if err != nil || len(cpus) == 0 {
+		t.Fatalf("Expected the CPUs of the test process, got %v (%v)", cpus, err)
+	}
+	if result := SetProcessAffinity(pid, cpus); result != "" {
+		t.Errorf("Failed to keep the CPU affinity %s: %s", FormatCPUList(cpus), result)
+	}
+
+	priority, err := GetProcessIOPriority(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := SetProcessIOPriority(pid, priority); result != "" {
+		t.Errorf("Failed to keep the I/O priority %s: %s", priority, result)
+	}
+}
+
+func TestTuneErrors(t *testing.T) {
+	const gone = 1 << 30
+	if result := ReniceProcess(gone, 0); !strings.Contains(result, "process not found") {
+		t.Errorf("Expected a missing process, got %q", result)
+	}
+	if result := ReniceProcess(int32(os.Getpid()), MaxNice+1); result == "" {
+		t.Error("Expected an out of range nice value to be refused")
+	}
+	if result := SetProcessAffinity(gone, nil); !strings.Contains(result, "at least one CPU") {
+		t.Errorf("Expected an empty CPU set to be refused, got %q", result)
+	}
+	if result := SetProcessIOPriority(gone, IOPriority{Class: "idle"}); !strings.Contains(result, "process not found") {
+		t.Errorf("Expected a missing process, got %q", result)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !linux
+// +build !linux
+
+package processes
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Renice, affinity and I/O priority use Linux scheduler calls; elsewhere
+// they report that they are unsupported.
+
+func unsupportedTuning(action string) string {
+	return fmt.Sprintf("%s is not supported on %s", action, runtime.GOOS)
+}
+
+func CanTuneProcess(pid int32) (bool, string) {
+	if pid <= 0 {
+		return false, "Invalid PID"
+	}
+	return false, unsupportedTuning("Changing process priorities")
+}
+
+func ReniceProcess(pid int32, nice int) string {
+	return unsupportedTuning("renice")
+}
+
+func GetProcessAffinity(pid int32) ([]int, error) {
+	return nil, errors.New(unsupportedTuning("CPU affinity"))
+}
+
+func SetProcessAffinity(pid int32, cpus []int) string {
+	return unsupportedTuning("CPU affinity")
+}
+
+func GetProcessIOPriority(pid int32) (IOPriority, error) {
+	return IOPriority{}, errors.New(unsupportedTuning("I/O priority"))
+}
+
+func SetProcessIOPriority(pid int32, priority IOPriority) string {
+	return unsupportedTuning("I/O priority")
+}
@@ -0,0 +1,58 @@
+package processes
+
+import "testing"
+
+func TestValidateTuning(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		valid bool
+	}{
+		{"lowest nice", validateNice(MinNice), true},
+		{"highest nice", validateNice(MaxNice), true},
+		{"nice too high", validateNice(20), false},
+		{"nice too low", validateNice(-21), false},
+		{"some CPUs", validateAffinity([]int{0, 3}, 4), true},
+		{"no CPUs", validateAffinity(nil, 4), false},
+		{"CPU past the last", validateAffinity([]int{4}, 4), false},
+		{"negative CPU", validateAffinity([]int{-1}, 4), false},
+		{"best-effort", validateIOPriority(IOPriority{Class: "best-effort", Level: MaxIOLevel}), true},
+		{"idle ignores the level", validateIOPriority(IOPriority{Class: "idle", Level: 42}), true},
+		{"level past the lowest", validateIOPriority(IOPriority{Class: "realtime", Level: 8}), false},
+		{"unknown class", validateIOPriority(IOPriority{Class: "batch"}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, tt.err)
+			}
+		})
+	}
+}
+
+func TestFormatCPUList(t *testing.T) {
+	tests := []struct {
+		cpus []int
+		want string
+	}{
+		{nil, ""},
+		{[]int{2}, "2"},
+		{[]int{0, 1, 2, 3}, "0-3"},
+		{[]int{0, 1, 2, 3, 6, 8, 9}, "0-3,6,8-9"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatCPUList(tt.cpus); got != tt.want {
+			t.Errorf("FormatCPUList(%v) = %q, want %q", tt.cpus, got, tt.want)
+		}
+	}
+}
+
+func TestCanTuneProcess(t *testing.T) {
+	for _, pid := range []int32{-1, 0} {
+		if canTune, reason := CanTuneProcess(pid); canTune || reason == "" {
+			t.Errorf("Expected PID %d to be refused with a reason", pid)
+		}
+	}
+}
@@ -1,88 +1,41 @@
 package processes
 
 import (
-	"fmt"
-	"sort"
-	"strings"
 	"sync"
 	"syspulse/internal/utils"
-	"time"
-
-	"github.com/gdamore/tcell/v2"
-	"github.com/rivo/tview"
-	"github.com/shirou/gopsutil/cpu"
-	"github.com/shirou/gopsutil/process"
 )
 
 var (
-	processCache     = GetProcessCache()
-	processesMu      sync.RWMutex
-	lastFullScan     time.Time
-	fullScanInterval = 10 * time.Second
+	defaultSampler = NewSampler()
+	processesMu    sync.RWMutex
 )
 
-func applyProcessFilterToItems(items []string, searchTerm, filterType string) []string {
-	if searchTerm == "" && filterType == "all" {
-		return items
-	}
-
-	var filteredItems []string
-	for _, item := range items {
-		var cpu, mem float64
-		var pid int32
-
-		pidStart := strings.Index(item, "(PID: ")
-		if pidStart != -1 {
-			pidEnd := strings.Index(item[pidStart:], ")")
-			if pidEnd != -1 {
-				pidStr := item[pidStart+6 : pidStart+pidEnd]
-				fmt.Sscanf(pidStr, "%d", &pid)
-			}
-
-			cpuStart := strings.Index(item, "CPU: ")
-			if cpuStart != -1 {
-				cpuEnd := strings.Index(item[cpuStart:], "%")
-				if cpuEnd != -1 {
-					cpuStr := item[cpuStart+5 : cpuStart+cpuEnd]
-					fmt.Sscanf(cpuStr, "%f", &cpu)
-				}
-			}
-
-			memStart := strings.Index(item, "MEM: ")
-			if memStart != -1 {
-				memEnd := strings.Index(item[memStart:], "%")
-				if memEnd != -1 {
-					memStr := item[memStart+5 : memStart+memEnd]
-					fmt.Sscanf(memStr, "%f", &mem)
-				}
-			}
-		}
+// Configure applies the performance settings to the shared process sampler.
+func Configure(config utils.PerformanceConfig) {
+	defaultSampler.Configure(config)
+}
 
-		show := true
-		switch filterType {
-		case "highcpu":
-			show = cpu > 50
-		case "highmem":
-			show = mem > 50
-		case "system":
-			show = pid < 1000
-		case "user":
-			show = pid >= 1000
-		}
+// GetProcessSnapshot returns the snapshot of the current tick, scanning the
+// processes if nobody did within the update interval.
+func GetProcessSnapshot() (*ProcessSnapshot, error) {
+	return defaultSampler.Snapshot()
+}
 
-		if show && (searchTerm == "" || utils.CaseInsensitiveContains(item, searchTerm)) {
-			filteredItems = append(filteredItems, item)
-		}
+// GetProcess returns a process from the snapshot of the current tick.
+func GetProcess(pid int32) (ProcessRow, bool) {
+	snapshot, err := GetProcessSnapshot()
+	if err != nil {
+		return ProcessRow{}, false
 	}
-	return filteredItems
+	return snapshot.Find(pid)
 }
 
 func GetNrProcesses() int {
-	procs, err := process.Processes()
+	snapshot, err := GetProcessSnapshot()
 	if err != nil {
-		panic(err)
+		return 0
 	}
-	return len(procs)
+	return snapshot.Count()
 }
 
 func UpdateProcesses(d *utils.Dashboard) {
@@ -90,219 +43,53 @@ func UpdateProcesses(d *utils.Dashboard) {
 		return
 	}
 
-	processesMu.Lock()
-	defer processesMu.Unlock()
-
-	var selectedPID int32
-	currentItem := d.ProcessWidget.GetCurrentItem()
-	if currentItem >= 0 && currentItem < d.ProcessWidget.GetItemCount() {
-		text, _ := d.ProcessWidget.GetItemText(currentItem)
-		fmt.Sscanf(text, "%s (PID: %d)", new(string), &selectedPID)
-	}
-
-	doFullScan := time.Since(lastFullScan) >= fullScanInterval
-	if doFullScan {
-		lastFullScan = time.Now()
-		processCache.Clear()
-	}
-
-	procs, err := process.Processes()
+	snapshot, err := GetProcessSnapshot()
 	if err != nil {
 		return
 	}
 
-	if d.Theme.Sorting == "mem" {
-		sort.Slice(procs, func(i, j int) bool {
-			mem1, _ := procs[i].MemoryPercent()
-			mem2, _ := procs[j].MemoryPercent()
-			return mem1 > mem2
-		})
-	} else {
-		sort.Slice(procs, func(i, j int) bool {
-			cpu1, _ := procs[i].CPUPercent()
-			cpu2, _ := procs[j].CPUPercent()
-			return cpu1 > cpu2
-		})
-	}
-
-	var items []string
-	selectedIndex := 0
-
-	// Get total system CPU usage
-	systemPercents, err := cpu.Percent(0, false)
-	systemUsage := 0.0
-	if err == nil && len(systemPercents) > 0 {
-		systemUsage = systemPercents[0]
-	}
-
-	for i, p := range procs {
-		pid := p.Pid
-		name, _ := p.Name()
-		procCPU, _ := p.CPUPercent()
-		mem, _ := p.MemoryPercent()
-
-		actualCPU := (procCPU * systemUsage) / 100.0
-
-		mainText := fmt.Sprintf("%s-CPU:%.2f%%(of %.1f%% sys) MEM:%.1f%% (PID: %d)", name, actualCPU, systemUsage, mem, pid)
-		items = append(items, mainText)
-
-		if pid == selectedPID {
-			selectedIndex = i
-		}
-	}
-
-	if d.ProcessFilterActive {
-		items = applyProcessFilterToItems(items, d.ProcessFilterTerm, d.ProcessFilterType)
-		selectedIndex = 0
-		for i, item := range items {
-			var pid int32
-			fmt.Sscanf(item, "%s (PID: %d)", new(string), &pid)
-			if pid == selectedPID {
-				selectedIndex = i
-				break
-			}
-		}
-	}
-
-	if d.ProcessWidget.GetItemCount() != len(items) {
-		d.ProcessWidget.Clear()
-		for _, item := range items {
-			d.ProcessWidget.AddItem(item, "", 0, nil)
-		}
-	} else {
-		for i, item := range items {
-			currentText, _ := d.ProcessWidget.GetItemText(i)
-			if currentText != item {
-				d.ProcessWidget.SetItemText(i, item, "")
-			}
-		}
-	}
+	processesMu.Lock()
+	defer processesMu.Unlock()
 
-	if selectedPID != 0 {
-		if currentItem != selectedIndex {
-			d.ProcessWidget.SetCurrentItem(selectedIndex)
-		}
-	}
+	d.ProcessData = snapshot.Processes
+	showProcessRows(d, snapshot.Processes)
 }
 
-func ShowProcessDetails(d *utils.Dashboard) {
-	currentItem := d.ProcessWidget.GetCurrentItem()
-	if currentItem < 0 || currentItem >= d.ProcessWidget.GetItemCount() {
+// ApplyProcessList fills the process table from a process tree sample instead
+// of the live process table, for replaying a recording. Memory percentages are
+// taken against the memory sample applied last.
+func ApplyProcessList(d *utils.Dashboard, tree *ProcessTree) {
+	if d.ProcessWidget == nil {
 		return
 	}
 
-	text, _ := d.ProcessWidget.GetItemText(currentItem)
-	var selectedPID int32
-	fmt.Sscanf(text, "%s (PID: %d)", new(string), &selectedPID)
-
-	var pinfo *ProcessInfo
-	var exists bool
+	processesMu.Lock()
+	defer processesMu.Unlock()
 
-	if pinfo, exists = processCache.Get(selectedPID); !exists {
-		proc, err := process.NewProcess(selectedPID)
-		if err != nil {
-			return
+	var totalMemory uint64
+	if d.VMemData != nil {
+		totalMemory = d.VMemData.Total
+	}
+
+	nodes := tree.Flatten()
+	rows := make([]ProcessRow, 0, len(nodes))
+	for _, node := range nodes {
+		row := ProcessRow{
+			PID:        node.PID,
+			PPID:       node.PPID,
+			Name:       node.Name,
+			State:      node.Status,
+			CPUPercent: node.CPUPct,
+			RSS:        node.Memory,
+			StartTime:  node.CreateTime,
+			Command:    node.Name,
 		}
-
-		name, _ := proc.Name()
-		cmdline, _ := proc.Cmdline()
-		procCPU, _ := proc.CPUPercent()
-		mem, _ := proc.MemoryPercent()
-		status, _ := proc.Status()
-		createTime, _ := proc.CreateTime()
-		numThreads, _ := proc.NumThreads()
-		username, _ := proc.Username()
-		memInfo, _ := proc.MemoryInfo()
-
-		pinfo = &ProcessInfo{
-			Name:       name,
-			CPU:        procCPU,
-			Memory:     mem,
-			Status:     status,
-			Username:   username,
-			CmdLine:    cmdline,
-			CreateTime: createTime,
-			NumThreads: numThreads,
-			MemoryInfo: memInfo,
-			LastUpdate: time.Now(),
-			TTL:        2 * time.Second,
+		if totalMemory > 0 {
+			row.MemoryPercent = float64(node.Memory) / float64(totalMemory) * 100
 		}
-		processCache.Set(selectedPID, pinfo)
-	}
-
-	name := pinfo.Name
-	cmdline := pinfo.CmdLine
-	procCPU := pinfo.CPU
-	mem := pinfo.Memory
-	status := pinfo.Status
-	createTime := pinfo.CreateTime
-	numThreads := pinfo.NumThreads
-	username := pinfo.Username
-	memInfo := pinfo.MemoryInfo
-
-	systemPercents, err := cpu.Percent(0, false)
-	systemUsage := 0.0
-	if err == nil && len(systemPercents) > 0 {
-		systemUsage = systemPercents[0]
+		rows = append(rows, row)
 	}
 
-	actualCPU := procCPU
-	if systemUsage > 0 {
-		actualCPU = (procCPU * systemUsage) / 100.0
-	}
-
-	details := fmt.Sprintf(`Basic Information:
-• Name: %s
-• PID: %d
-• Status: %s
-• Username: %s
-• Created: %s
-
-Resource Usage:
-• CPU Usage: %.2f%%
-• Memory Usage: %.2f%%
-• Memory RSS: %d MB
-• Memory VMS: %d MB
-• Threads: %d
-
-Command:
-%s`,
-		name, selectedPID, status, username,
-		time.Unix(createTime/1000, 0).Format("2006-01-02 15:04:05"),
-		actualCPU, mem,
-		memInfo.RSS/1024/1024,
-		memInfo.VMS/1024/1024,
-		numThreads,
-		cmdline)
-
-	modal := tview.NewTextView().
-		SetText(details).
-		SetScrollable(true).
-		SetWrap(true).
-		SetDynamicColors(true).
-		SetWordWrap(true)
-
-	utils.SetBorderStyle(modal.Box)
-	modal.SetTitle("Process Details (Arrow keys to scroll, ESC to close)").
-		SetTitleAlign(tview.AlignCenter)
-
-	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEscape:
-			d.App.SetRoot(d.MainWidget, true).SetFocus(d.ProcessWidget)
-			return nil
-		}
-		return event
-	})
-
-	flex := tview.NewFlex().
-		AddItem(nil, 0, 1, false).
-		AddItem(tview.NewFlex().
-			SetDirection(tview.FlexRow).
-			AddItem(nil, 0, 1, false).
-			AddItem(modal, 0, 3, true).
-			AddItem(nil, 0, 1, false), 0, 3, true).
-		AddItem(nil, 0, 1, false)
-
-	d.App.SetRoot(flex, true).SetFocus(modal)
+	d.ProcessData = rows
+	showProcessRows(d, rows)
 }
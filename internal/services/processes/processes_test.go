@@ -0,0 +1,177 @@
+package processes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/collector"
+	"syspulse/internal/utils"
+
+	"github.com/rivo/tview"
+	"github.com/shirou/gopsutil/mem"
+)
+
+func tableRow(table *tview.Table, row int) []string {
+	var cells []string
+	for column := 0; column < table.GetColumnCount(); column++ {
+		cells = append(cells, table.GetCell(row, column).Text)
+	}
+	return cells
+}
+
+func TestApplyProcessList(t *testing.T) {
+	d := &utils.Dashboard{
+		Samples:       collector.NewSnapshot(),
+		ProcessWidget: tview.NewTable(),
+		VMemData:      &mem.VirtualMemoryStat{Total: 1000},
+	}
+	d.Theme.Layout.Process.Columns = []string{"pid", "name", "cpu", "mem"}
+	tree := &ProcessTree{
+		TotalCount: 3,
+		Roots: []*ProcessNode{{PID: 1, Name: "init", CPUPct: 1, Memory: 10, Children: []*ProcessNode{
+			{PID: 42, PPID: 1, Name: "sshd", CPUPct: 50, Memory: 100},
+			{PID: 7, PPID: 1, Name: "cron", CPUPct: 10, Memory: 500},
+		}}},
+	}
+
+	ApplyProcessList(d, tree)
+
+	expected := [][]string{
+		{"PID", "NAME", "CPU%▼", "MEM%"},
+		{"42", "sshd", "50.0", "10.0"},
+		{"7", "cron", "10.0", "50.0"},
+		{"1", "init", "1.0", "1.0"},
+	}
+	if d.ProcessWidget.GetRowCount() != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), d.ProcessWidget.GetRowCount())
+	}
+	for i := range expected {
+		if got := tableRow(d.ProcessWidget, i); strings.Join(got, ",") != strings.Join(expected[i], ",") {
+			t.Errorf("Row %d: expected %v, got %v", i, expected[i], got)
+		}
+	}
+
+	// The selection follows the PID when the order changes.
+	d.ProcessWidget.Select(2, 0)
+	d.Theme.Sorting = "mem"
+	ApplyProcessList(d, tree)
+	if pid, ok := SelectedPID(d); !ok || pid != 7 {
+		t.Errorf("Expected cron to stay selected, got %d", pid)
+	}
+	if row, _ := d.ProcessWidget.GetSelection(); row != 1 {
+		t.Errorf("Expected cron first when sorted by memory, selected row %d", row)
+	}
+
+	d.ProcessFilterActive = true
+	d.ProcessFilterTerm = "ss"
+	RefreshProcessTable(d)
+	if d.ProcessWidget.GetRowCount() != 2 || d.ProcessWidget.GetCell(1, 1).Text != "sshd" {
+		t.Errorf("Expected only sshd after filtering, got %d rows", d.ProcessWidget.GetRowCount())
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	start := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	rows := []ProcessRow{
+		{PID: 3, Name: "bash", User: "root", CPUPercent: 1, RSS: 300, StartTime: start},
+		{PID: 1, Name: "Xorg", User: "alice", CPUPercent: 5, RSS: 100, StartTime: start.Add(time.Hour)},
+		{PID: 2, Name: "cron", User: "root", CPUPercent: 5, RSS: 200, StartTime: start.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		key     string
+		reverse bool
+		want    []int32
+	}{
+		{"cpu", false, []int32{1, 2, 3}},
+		{"cpu", true, []int32{3, 1, 2}},
+		{"rss", false, []int32{3, 2, 1}},
+		{"pid", false, []int32{3, 2, 1}},
+		{"name", false, []int32{3, 2, 1}},
+		{"user", false, []int32{1, 2, 3}},
+		{"start", false, []int32{2, 3, 1}},
+		{"bogus", false, []int32{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		sorted := append([]ProcessRow(nil), rows...)
+		SortRows(sorted, tt.key, tt.reverse)
+		for i, row := range sorted {
+			if row.PID != tt.want[i] {
+				t.Errorf("%s (reverse %v): expected %v, got PID %d at %d", tt.key, tt.reverse, tt.want, row.PID, i)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterRows(t *testing.T) {
+	rows := []ProcessRow{
+		{PID: 1, Name: "systemd", User: "root", Command: "/sbin/init splash"},
+		{PID: 1200, Name: "firefox", User: "alice", CPUPercent: 70, Command: "/usr/lib/firefox/firefox"},
+		{PID: 1300, Name: "java", User: "alice", MemoryPercent: 60, Command: "java -jar app.jar"},
+	}
+
+	tests := []struct {
+		term, filterType string
+		want             int
+	}{
+		{"", "all", 3},
+		{"ALICE", "all", 2},
+		{"splash", "all", 1},
+		{"1200", "all", 1},
+		{"", "highcpu", 1},
+		{"", "highmem", 1},
+		{"", "system", 1},
+		{"java", "user", 1},
+		{"firefox", "highmem", 0},
+	}
+	for _, tt := range tests {
+		if got := FilterRows(rows, tt.term, tt.filterType); len(got) != tt.want {
+			t.Errorf("%q/%s: expected %d rows, got %d", tt.term, tt.filterType, tt.want, len(got))
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	for _, key := range utils.ProcessColumns {
+		if _, ok := columns[key]; !ok {
+			t.Errorf("Column %q has no definition", key)
+		}
+	}
+	if len(columns) != len(utils.ProcessColumns) {
+		t.Errorf("Expected %d columns, got %d", len(utils.ProcessColumns), len(columns))
+	}
+
+	now := time.Date(2025, 7, 15, 18, 0, 0, 0, time.Local)
+	if got := formatStartTime(time.Date(2025, 7, 15, 9, 5, 0, 0, time.Local), now); got != "09:05" {
+		t.Errorf("Expected the clock time for today, got %q", got)
+	}
+	if got := formatStartTime(time.Date(2025, 7, 1, 9, 5, 0, 0, time.Local), now); got != "Jul01" {
+		t.Errorf("Expected the date for older processes, got %q", got)
+	}
+}
+
+func TestParentChain(t *testing.T) {
+	snapshot := newProcessSnapshot([]ProcessRow{
+		{PID: 1, Name: "systemd"},
+		{PID: 900, PPID: 1, Name: "sshd"},
+		{PID: 1200, PPID: 900, Name: "bash"},
+		{PID: 1300, PPID: 1300, Name: "loop"},
+	}, time.Now())
+
+	var names []string
+	for _, row := range parentChain(snapshot, 1200) {
+		names = append(names, row.Name)
+	}
+	if strings.Join(names, ",") != "systemd,sshd,bash" {
+		t.Errorf("Expected the chain from systemd to bash, got %v", names)
+	}
+
+	if chain := parentChain(snapshot, 1300); len(chain) != 1 {
+		t.Errorf("Expected a process that is its own parent once, got %d", len(chain))
+	}
+	if chain := parentChain(snapshot, 5); len(chain) != 0 {
+		t.Errorf("Expected no chain for an unknown PID, got %d", len(chain))
+	}
+}
@@ -0,0 +1,301 @@
+package processes
+
+import (
+	"sort"
+	"sync"
+	"syspulse/internal/utils"
+	"time"
+)
+
+// ProcessSnapshot is one scan of the process table. It is shared by the
+// process list, tree, header, details and exports, so it must not be
+// modified; copy Processes before sorting it.
+type ProcessSnapshot struct {
+	Processes []ProcessRow
+	Time      time.Time
+
+	byPID map[int32]int
+}
+
+func newProcessSnapshot(rows []ProcessRow, t time.Time) *ProcessSnapshot {
+	byPID := make(map[int32]int, len(rows))
+	for i, row := range rows {
+		byPID[row.PID] = i
+	}
+	return &ProcessSnapshot{Processes: rows, Time: t, byPID: byPID}
+}
+
+func (s *ProcessSnapshot) Count() int {
+	return len(s.Processes)
+}
+
+func (s *ProcessSnapshot) Find(pid int32) (ProcessRow, bool) {
+	i, ok := s.byPID[pid]
+	if !ok {
+		return ProcessRow{}, false
+	}
+	return s.Processes[i], true
+}
+
+// Tree links the processes of the snapshot to their parents. Processes whose
+// parent is not in the snapshot become roots.
+func (s *ProcessSnapshot) Tree() *ProcessTree {
+	nodes := make(map[int32]*ProcessNode, len(s.Processes))
+	for _, row := range s.Processes {
+		nodes[row.PID] = &ProcessNode{
+			PID:        row.PID,
+			PPID:       row.PPID,
+			Name:       row.Name,
+			CPUPct:     row.CPUPercent,
+			Memory:     row.RSS,
+			Status:     row.State,
+			CreateTime: row.StartTime,
+			Children:   make([]*ProcessNode, 0),
+		}
+	}
+
+	roots := make([]*ProcessNode, 0)
+	for _, row := range s.Processes {
+		node := nodes[row.PID]
+		if parent, exists := nodes[node.PPID]; exists && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Name < roots[j].Name
+	})
+	sortChildren(roots)
+
+	return &ProcessTree{
+		Roots:      roots,
+		TotalCount: len(nodes),
+		LastUpdate: s.Time,
+	}
+}
+
+// rawProcess is one read of /proc/<pid>, before rates are derived.
+type rawProcess struct {
+	row        ProcessRow
+	cpuSeconds float64 // User and system time
+	ioRead     uint64
+	ioWrite    uint64
+	hasIO      bool
+}
+
+// staticInfo holds the fields that rarely change during a process's life.
+type staticInfo struct {
+	start   time.Time
+	command string
+	user    string
+	read    time.Time
+}
+
+// Sampler scans all processes at most once per ProcessUpdateInterval and
+// hands every caller in between the same snapshot. CPU and I/O rates are
+// derived from the previous scan.
+type Sampler struct {
+	procRoot string
+
+	mu       sync.Mutex
+	config   utils.PerformanceConfig
+	batcher  *utils.SyscallBatcher
+	current  *ProcessSnapshot
+	last     map[int32]rawProcess
+	lastTime time.Time
+	static   map[int32]staticInfo
+	users    *userCache
+	lastFull time.Time
+}
+
+func NewSampler() *Sampler {
+	return NewSamplerAt(defaultProcRoot)
+}
+
+// NewSamplerAt returns a sampler reading the proc filesystem mounted at
+// procRoot. Only Linux reads it directly; elsewhere it is ignored.
+func NewSamplerAt(procRoot string) *Sampler {
+	s := &Sampler{
+		procRoot: procRoot,
+		last:     make(map[int32]rawProcess),
+		static:   make(map[int32]staticInfo),
+		users:    newUserCache(),
+	}
+	s.Configure(utils.DefaultPerformanceConfig)
+	return s
+}
+
+// Configure applies the performance settings, using the defaults for the
+// ones left at zero.
+func (s *Sampler) Configure(config utils.PerformanceConfig) {
+	defaults := utils.DefaultPerformanceConfig
+	if config.ProcessCacheTTL <= 0 {
+		config.ProcessCacheTTL = defaults.ProcessCacheTTL
+	}
+	if config.FullScanInterval <= 0 {
+		config.FullScanInterval = defaults.FullScanInterval
+	}
+	if config.SyscallBatchSize <= 0 {
+		config.SyscallBatchSize = defaults.SyscallBatchSize
+	}
+	if config.ProcessUpdateInterval <= 0 {
+		config.ProcessUpdateInterval = defaults.ProcessUpdateInterval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = config
+	s.batcher = utils.NewSyscallBatcher(config.SyscallBatchSize, seconds(config.ProcessUpdateInterval))
+}
+
+// Snapshot returns the current snapshot, scanning the processes again once
+// it is older than the update interval.
+func (s *Sampler) Snapshot() (*ProcessSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && time.Since(s.current.Time) < seconds(s.config.ProcessUpdateInterval) {
+		return s.current, nil
+	}
+	return s.scan()
+}
+
+// UpdateInterval is how long one snapshot is shared.
+func (s *Sampler) UpdateInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return seconds(s.config.ProcessUpdateInterval)
+}
+
+// Latest returns the last snapshot without scanning, if there is one.
+func (s *Sampler) Latest() (*ProcessSnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.current, s.current != nil
+}
+
+func (s *Sampler) scan() (*ProcessSnapshot, error) {
+	pids, err := listPIDs(s.procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	if currentTime.Sub(s.lastFull) >= seconds(s.config.FullScanInterval) {
+		s.lastFull = currentTime
+		s.static = make(map[int32]staticInfo)
+		s.users = newUserCache()
+	}
+	cacheTTL := seconds(s.config.ProcessCacheTTL)
+
+	// Every process is read by one batched operation writing its own slot.
+	raws := make([]rawProcess, len(pids))
+	statics := make([]staticInfo, len(pids))
+	ok := make([]bool, len(pids))
+	for i, pid := range pids {
+		cached, hasCached := s.static[pid]
+		s.batcher.Add(func() error {
+			raw, err := readProcess(s.procRoot, pid)
+			if err != nil {
+				return err
+			}
+			info := cached
+			if !hasCached || !info.start.Equal(raw.row.StartTime) || currentTime.Sub(info.read) >= cacheTTL {
+				info = readStatic(s.procRoot, pid, s.users)
+				info.start = raw.row.StartTime
+				info.read = currentTime
+			}
+			raws[i], statics[i], ok[i] = raw, info, true
+			return nil
+		})
+	}
+	s.batcher.Flush()
+
+	totalMemory := readTotalMemory(s.procRoot)
+	elapsed := currentTime.Sub(s.lastTime).Seconds()
+	last := make(map[int32]rawProcess, len(pids))
+	static := make(map[int32]staticInfo, len(pids))
+	rows := make([]ProcessRow, 0, len(pids))
+	for i := range raws {
+		if !ok[i] {
+			continue
+		}
+		raw, info := raws[i], statics[i]
+		row := raw.row
+		row.Command = info.command
+		row.User = info.user
+		if row.Command == "" {
+			row.Command = "[" + row.Name + "]"
+		}
+		if totalMemory > 0 {
+			row.MemoryPercent = float64(row.RSS) / float64(totalMemory) * 100
+		}
+
+		previous, seen := s.last[row.PID]
+		row.CPUPercent = cpuPercent(raw, previous, seen, elapsed, currentTime)
+		if seen && previous.row.StartTime.Equal(row.StartTime) && elapsed > 0 && raw.hasIO && previous.hasIO {
+			row.IOReadRate = counterRate(raw.ioRead, previous.ioRead, elapsed)
+			row.IOWriteRate = counterRate(raw.ioWrite, previous.ioWrite, elapsed)
+		}
+
+		last[row.PID] = raw
+		static[row.PID] = info
+		rows = append(rows, row)
+	}
+
+	s.last = last
+	s.static = static
+	s.lastTime = currentTime
+	s.current = newProcessSnapshot(rows, currentTime)
+	return s.current, nil
+}
+
+// cpuPercent is the CPU use of a process or thread since its previous read.
+// Without one, or when the ID was reused since, it is the average so far.
+func cpuPercent(raw, previous rawProcess, seen bool, elapsed float64, now time.Time) float64 {
+	var percent float64
+	if seen && previous.row.StartTime.Equal(raw.row.StartTime) && elapsed > 0 {
+		percent = (raw.cpuSeconds - previous.cpuSeconds) / elapsed * 100
+	} else if lifetime := now.Sub(raw.row.StartTime).Seconds(); !raw.row.StartTime.IsZero() && lifetime > 0 {
+		percent = raw.cpuSeconds / lifetime * 100
+	}
+	return max(percent, 0)
+}
+
+func counterRate(current, previous uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// userCache resolves user IDs to names once per full scan.
+type userCache struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+func newUserCache() *userCache {
+	return &userCache{names: make(map[string]string)}
+}
+
+func (c *userCache) lookup(uid string, resolve func(string) string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok := c.names[uid]
+	if !ok {
+		name = resolve(uid)
+		c.names[uid] = name
+	}
+	return name
+}
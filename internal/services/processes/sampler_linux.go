@@ -0,0 +1,193 @@
+package processes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultProcRoot = "/proc"
+
+// clockTicks is USER_HZ, the unit of the times in /proc/<pid>/stat. It is 100
+// on every architecture Linux supports.
+const clockTicks = 100
+
+var pageSize = uint64(os.Getpagesize())
+
+var (
+	bootTimeMu sync.Mutex
+	bootTimes  = make(map[string]time.Time)
+)
+
+func listPIDs(procRoot string) ([]int32, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]int32, 0, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		pids = append(pids, int32(pid))
+	}
+	return pids, nil
+}
+
+// readProcess reads /proc/<pid>/stat and, when permitted, /proc/<pid>/io.
+func readProcess(procRoot string, pid int32) (rawProcess, error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+	raw, err := readStat(filepath.Join(dir, "stat"), bootTime(procRoot))
+	raw.row.PID = pid
+	if err != nil {
+		return raw, err
+	}
+
+	raw.ioRead, raw.ioWrite, raw.hasIO = readIO(filepath.Join(dir, "io"))
+	return raw, nil
+}
+
+// readStat parses a stat file of a process or of one of its threads, whose
+// start times count from boot.
+func readStat(path string, boot time.Time) (rawProcess, error) {
+	var raw rawProcess
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return raw, err
+	}
+
+	// The name is in parentheses and may itself contain spaces and
+	// parentheses, so the fields start after the last ')'.
+	open, end := bytes.IndexByte(data, '('), bytes.LastIndexByte(data, ')')
+	if open < 0 || end < open {
+		return raw, fmt.Errorf("malformed %s", path)
+	}
+	raw.row.Name = string(data[open+1 : end])
+
+	// fields[0] is the third field of stat(5), the state.
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return raw, fmt.Errorf("short %s", path)
+	}
+	field := func(n int) uint64 {
+		value, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return value
+	}
+	nice, _ := strconv.ParseInt(fields[19-3], 10, 32)
+
+	raw.row.State = fields[0]
+	raw.row.PPID = int32(field(4))
+	raw.cpuSeconds = float64(field(14)+field(15)) / clockTicks
+	raw.row.Nice = int32(nice)
+	raw.row.Threads = int32(field(20))
+	if !boot.IsZero() {
+		raw.row.StartTime = boot.Add(time.Duration(field(22)) * (time.Second / clockTicks))
+	}
+	raw.row.VMS = field(23)
+	raw.row.RSS = field(24) * pageSize
+	return raw, nil
+}
+
+// readIO returns the bytes a process read from and wrote to storage. Reading
+// the io file of another user's process needs privileges.
+func readIO(path string) (uint64, uint64, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	var read, written uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), ":")
+		switch key {
+		case "read_bytes":
+			read, _ = strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		case "write_bytes":
+			written, _ = strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		}
+	}
+	return read, written, scanner.Err() == nil
+}
+
+// readStatic reads the command line and the effective user of a process.
+func readStatic(procRoot string, pid int32, users *userCache) staticInfo {
+	dir := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+	var info staticInfo
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		info.command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+
+	if status, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			if ids, ok := strings.CutPrefix(line, "Uid:"); ok {
+				// Real, effective, saved and filesystem UID.
+				if fields := strings.Fields(ids); len(fields) > 1 {
+					info.user = users.lookup(fields[1], lookupUser)
+				}
+				break
+			}
+		}
+	}
+	return info
+}
+
+func lookupUser(uid string) string {
+	if u, err := user.LookupId(uid); err == nil {
+		return u.Username
+	}
+	return uid
+}
+
+func readTotalMemory(procRoot string) uint64 {
+	file, err := os.Open(filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "MemTotal:"); ok {
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// bootTime reads the btime line of /proc/stat once per proc root.
+func bootTime(procRoot string) time.Time {
+	bootTimeMu.Lock()
+	defer bootTimeMu.Unlock()
+
+	if boot, ok := bootTimes[procRoot]; ok {
+		return boot
+	}
+
+	var boot time.Time
+	if data, err := os.ReadFile(filepath.Join(procRoot, "stat")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if value, ok := strings.CutPrefix(line, "btime "); ok {
+				if btime, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+					boot = time.Unix(btime, 0)
+				}
+				break
+			}
+		}
+	}
+	bootTimes[procRoot] = boot
+	return boot
+}
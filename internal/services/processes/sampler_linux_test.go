@@ -0,0 +1,132 @@
+package processes
+
+import (
+	"fmt"
+	"path/filepath"
+	"syspulse/internal/testutil"
+	"testing"
+	"time"
+
+	"syspulse/internal/utils"
+)
+
+// statLine builds /proc/<pid>/stat with the fields the sampler reads.
+func statLine(pid int, name string, ppid, utime, stime, starttime, rss int) string {
+	return fmt.Sprintf("%d (%s) S %d %d %d 0 -1 4194560 100 0 0 0 %d %d 0 0 20 -5 3 0 %d 170000000 %d 18446744073709551615\n",
+		pid, name, ppid, pid, pid, utime, stime, starttime, rss)
+}
+
+func newProcRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"stat":    "cpu  1 2 3 4\nbtime 1700000000\n",
+		"meminfo": "MemTotal:       1000000 kB\nMemFree:         500000 kB\n",
+	})
+	testutil.WriteFiles(t, filepath.Join(root, "1"), map[string]string{
+		"stat":    statLine(1, "systemd", 0, 150, 50, 100, 2500),
+		"cmdline": "/sbin/init\x00splash\x00",
+		"status":  "Name:\tsystemd\nUid:\t0\t0\t0\t0\n",
+		"io":      "rchar: 1\nread_bytes: 4096\nwrite_bytes: 0\n",
+	})
+	testutil.WriteFiles(t, filepath.Join(root, "42"), map[string]string{
+		"stat": statLine(42, "my (odd) worker", 1, 0, 0, 5000, 10),
+	})
+	// A process that exited while its directory was listed.
+	testutil.WriteFiles(t, filepath.Join(root, "99"), nil)
+	testutil.WriteFiles(t, filepath.Join(root, "self"), nil)
+	return root
+}
+
+func TestSamplerSnapshot(t *testing.T) {
+	root := newProcRoot(t)
+	s := NewSamplerAt(root)
+
+	snapshot, err := s.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if snapshot.Count() != 2 {
+		t.Fatalf("Expected two processes, got %d", snapshot.Count())
+	}
+
+	systemd, _ := snapshot.Find(1)
+	rss := 2500 * pageSize
+	if systemd.Name != "systemd" || systemd.Command != "/sbin/init splash" || systemd.User != "root" || systemd.State != "S" ||
+		systemd.RSS != rss || systemd.VMS != 170000000 || systemd.Threads != 3 || systemd.Nice != -5 {
+		t.Errorf("Unexpected process %+v", systemd)
+	}
+	if want := float64(rss) / 1024000000 * 100; systemd.MemoryPercent != want {
+		t.Errorf("Expected %.3f%% memory, got %.3f", want, systemd.MemoryPercent)
+	}
+	if !systemd.StartTime.Equal(time.Unix(1700000001, 0)) {
+		t.Errorf("Expected the start time from btime, got %v", systemd.StartTime)
+	}
+
+	worker, _ := snapshot.Find(42)
+	if worker.Name != "my (odd) worker" || worker.Command != "[my (odd) worker]" || worker.PPID != 1 {
+		t.Errorf("Unexpected kernel thread %+v", worker)
+	}
+
+	tree := snapshot.Tree()
+	if len(tree.Roots) != 1 || len(tree.Roots[0].Children) != 1 || tree.Roots[0].Children[0].PID != 42 {
+		t.Errorf("Expected the worker below systemd, got %+v", tree.Roots)
+	}
+
+	if again, _ := s.Snapshot(); again != snapshot {
+		t.Error("Expected callers within the update interval to share the snapshot")
+	}
+
+	// Half a second of CPU and 2 KiB read over the last second. The command
+	// line changes too, but stays cached.
+	testutil.WriteFiles(t, filepath.Join(root, "1"), map[string]string{
+		"stat":    statLine(1, "systemd", 0, 190, 60, 100, 2500),
+		"io":      "read_bytes: 6144\nwrite_bytes: 0\n",
+		"cmdline": "/sbin/init\x00",
+	})
+	s.mu.Lock()
+	s.current = nil
+	s.lastTime = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+
+	snapshot, _ = s.Snapshot()
+	systemd, _ = snapshot.Find(1)
+	if systemd.CPUPercent < 40 || systemd.CPUPercent > 50 {
+		t.Errorf("Expected about 50%% CPU, got %.1f", systemd.CPUPercent)
+	}
+	if systemd.IOReadRate < 1600 || systemd.IOReadRate > 2048 {
+		t.Errorf("Expected about 2 KiB/s read, got %.0f", systemd.IOReadRate)
+	}
+	if systemd.Command != "/sbin/init splash" {
+		t.Errorf("Expected the cached command line, got %q", systemd.Command)
+	}
+
+	// A reused PID is a new process: its CPU is not a delta of the old one.
+	testutil.WriteFiles(t, filepath.Join(root, "42"), map[string]string{
+		"stat": statLine(42, "bash", 1, 1000000, 0, 6000, 10),
+	})
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+
+	snapshot, _ = s.Snapshot()
+	if bash, _ := snapshot.Find(42); bash.Name != "bash" || bash.Command != "[bash]" || bash.CPUPercent > 1 {
+		t.Errorf("Expected a fresh process for the reused PID, got %+v", bash)
+	}
+}
+
+func TestSamplerConfigure(t *testing.T) {
+	s := NewSamplerAt(newProcRoot(t))
+	s.Configure(utils.PerformanceConfig{SyscallBatchSize: 1, ProcessUpdateInterval: 5})
+
+	if s.config.ProcessCacheTTL != utils.DefaultPerformanceConfig.ProcessCacheTTL || s.config.ProcessUpdateInterval != 5 {
+		t.Errorf("Expected defaults for unset settings, got %+v", s.config)
+	}
+	if snapshot, err := s.Snapshot(); err != nil || snapshot.Count() != 2 {
+		t.Errorf("Expected both processes with one read per batch, got %v", err)
+	}
+
+	if _, err := NewSamplerAt(filepath.Join(t.TempDir(), "missing")).Snapshot(); err == nil {
+		t.Error("Expected an error without a proc filesystem")
+	}
+}
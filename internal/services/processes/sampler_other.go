@@ -0,0 +1,67 @@
+//go:build !linux
+// +build !linux
+
+package processes
+
+import (
+	"time"
+
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/process"
+)
+
+// Without a Linux /proc the sampler reads each process through gopsutil and
+// ignores the proc root.
+const defaultProcRoot = ""
+
+func listPIDs(procRoot string) ([]int32, error) {
+	return process.Pids()
+}
+
+func readProcess(procRoot string, pid int32) (rawProcess, error) {
+	raw := rawProcess{row: ProcessRow{PID: pid}}
+
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return raw, err
+	}
+
+	raw.row.Name, _ = p.Name()
+	raw.row.State, _ = p.Status()
+	raw.row.PPID, _ = p.Ppid()
+	raw.row.Threads, _ = p.NumThreads()
+	raw.row.Nice, _ = p.Nice()
+	if createTime, err := p.CreateTime(); err == nil {
+		raw.row.StartTime = time.UnixMilli(createTime)
+	}
+	if memInfo, err := p.MemoryInfo(); err == nil {
+		raw.row.RSS = memInfo.RSS
+		raw.row.VMS = memInfo.VMS
+	}
+	if times, err := p.Times(); err == nil {
+		raw.cpuSeconds = times.User + times.System
+	}
+	if io, err := p.IOCounters(); err == nil {
+		raw.ioRead, raw.ioWrite, raw.hasIO = io.ReadBytes, io.WriteBytes, true
+	}
+	return raw, nil
+}
+
+func readStatic(procRoot string, pid int32, users *userCache) staticInfo {
+	var info staticInfo
+
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return info
+	}
+	info.command, _ = p.Cmdline()
+	info.user, _ = p.Username()
+	return info
+}
+
+func readTotalMemory(procRoot string) uint64 {
+	if vmem, err := mem.VirtualMemory(); err == nil {
+		return vmem.Total
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+package processes
+
+import "strings"
+
+// normalizeSignalName turns "sigterm", "SIGTERM" and "term" into "TERM".
+func normalizeSignalName(name string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+}
+
+func IsSupportedSignal(name string) bool {
+	name = normalizeSignalName(name)
+	for _, supported := range GetSupportedSignals() {
+		if supported == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package processes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ProcessRow is one line of the process table.
+type ProcessRow struct {
+	PID           int32     `json:"pid"`
+	PPID          int32     `json:"ppid"`
+	User          string    `json:"user"`
+	Name          string    `json:"name"`
+	State         string    `json:"state"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryPercent float64   `json:"memory_percent"`
+	RSS           uint64    `json:"rss"`
+	VMS           uint64    `json:"vms"`
+	Threads       int32     `json:"threads"`
+	Nice          int32     `json:"nice"`
+	StartTime     time.Time `json:"start_time"`
+	IOReadRate    float64   `json:"io_read_bytes_per_sec"`
+	IOWriteRate   float64   `json:"io_write_bytes_per_sec"`
+	Command       string    `json:"command"`
+}
+
+// Column describes how one column of the process table is shown and sorted.
+// Numeric columns sort largest first, text columns alphabetically.
+type Column struct {
+	Key      string
+	Header   string
+	Numeric  bool
+	MaxWidth int
+	less     func(a, b *ProcessRow) bool
+	format   func(r *ProcessRow) string
+}
+
+var columns = map[string]Column{
+	"pid": {Key: "pid", Header: "PID", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.PID < b.PID },
+		format: func(r *ProcessRow) string { return strconv.Itoa(int(r.PID)) }},
+	"user": {Key: "user", Header: "USER", MaxWidth: 12,
+		less:   func(a, b *ProcessRow) bool { return a.User < b.User },
+		format: func(r *ProcessRow) string { return r.User }},
+	"name": {Key: "name", Header: "NAME", MaxWidth: 24,
+		less:   func(a, b *ProcessRow) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) },
+		format: func(r *ProcessRow) string { return r.Name }},
+	"state": {Key: "state", Header: "S",
+		less:   func(a, b *ProcessRow) bool { return a.State < b.State },
+		format: func(r *ProcessRow) string { return r.State }},
+	"cpu": {Key: "cpu", Header: "CPU%", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.CPUPercent < b.CPUPercent },
+		format: func(r *ProcessRow) string { return fmt.Sprintf("%.1f", r.CPUPercent) }},
+	"mem": {Key: "mem", Header: "MEM%", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.MemoryPercent < b.MemoryPercent },
+		format: func(r *ProcessRow) string { return fmt.Sprintf("%.1f", r.MemoryPercent) }},
+	"rss": {Key: "rss", Header: "RSS", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.RSS < b.RSS },
+		format: func(r *ProcessRow) string { return formatMemory(r.RSS) }},
+	"threads": {Key: "threads", Header: "THR", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.Threads < b.Threads },
+		format: func(r *ProcessRow) string { return strconv.Itoa(int(r.Threads)) }},
+	"nice": {Key: "nice", Header: "NI", Numeric: true,
+		less:   func(a, b *ProcessRow) bool { return a.Nice < b.Nice },
+		format: func(r *ProcessRow) string { return strconv.Itoa(int(r.Nice)) }},
+	"start": {Key: "start", Header: "START",
+		less:   func(a, b *ProcessRow) bool { return a.StartTime.Before(b.StartTime) },
+		format: func(r *ProcessRow) string { return formatStartTime(r.StartTime, time.Now()) }},
+	"io": {Key: "io", Header: "IO R/W", Numeric: true,
+		less: func(a, b *ProcessRow) bool { return a.IOReadRate+a.IOWriteRate < b.IOReadRate+b.IOWriteRate },
+		format: func(r *ProcessRow) string {
+			return formatMemory(uint64(r.IOReadRate)) + "/" + formatMemory(uint64(r.IOWriteRate))
+		}},
+	"command": {Key: "command", Header: "COMMAND",
+		less:   func(a, b *ProcessRow) bool { return a.Command < b.Command },
+		format: func(r *ProcessRow) string { return r.Command }},
+}
+
+// DefaultColumns are shown when the process widget does not list its own.
+var DefaultColumns = []string{"pid", "user", "name", "state", "cpu", "mem", "rss", "command"}
+
+// VisibleColumns returns the configured columns of the process table,
+// skipping names it does not know.
+func VisibleColumns(d *utils.Dashboard) []Column {
+	keys := d.Theme.Layout.Process.Columns
+	if len(keys) == 0 {
+		keys = DefaultColumns
+	}
+
+	visible := make([]Column, 0, len(keys))
+	for _, key := range keys {
+		if column, ok := columns[key]; ok {
+			visible = append(visible, column)
+		}
+	}
+	return visible
+}
+
+// ColumnHeader returns the header of the column with the given key.
+func ColumnHeader(key string) string {
+	if column, ok := columns[key]; ok {
+		return column.Header
+	}
+	return strings.ToUpper(key)
+}
+
+// SortRows orders rows by the column with the given key, falling back to
+// CPU. Ties are broken by PID so the order is stable between updates.
+func SortRows(rows []ProcessRow, key string, reverse bool) {
+	column, ok := columns[key]
+	if !ok {
+		column = columns["cpu"]
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		switch {
+		case column.less(a, b):
+			return !column.Numeric != reverse
+		case column.less(b, a):
+			return column.Numeric != reverse
+		default:
+			return a.PID < b.PID
+		}
+	})
+}
+
+// FilterRows keeps the rows matching the search term in their PID, name,
+// user or command and the filter type of the process search.
+func FilterRows(rows []ProcessRow, searchTerm, filterType string) []ProcessRow {
+	if searchTerm == "" && (filterType == "" || filterType == "all") {
+		return rows
+	}
+
+	var filtered []ProcessRow
+	for _, row := range rows {
+		show := true
+		switch filterType {
+		case "highcpu":
+			show = row.CPUPercent > 50
+		case "highmem":
+			show = row.MemoryPercent > 50
+		case "system":
+			show = row.PID < 1000
+		case "user":
+			show = row.PID >= 1000
+		}
+		if !show {
+			continue
+		}
+
+		if searchTerm == "" ||
+			strconv.Itoa(int(row.PID)) == searchTerm ||
+			utils.CaseInsensitiveContains(row.Name, searchTerm) ||
+			utils.CaseInsensitiveContains(row.User, searchTerm) ||
+			utils.CaseInsensitiveContains(row.Command, searchTerm) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// SelectedPID returns the PID of the selected row of the process table.
+func SelectedPID(d *utils.Dashboard) (int32, bool) {
+	if d.ProcessWidget == nil {
+		return 0, false
+	}
+	row, _ := d.ProcessWidget.GetSelection()
+	cell := d.ProcessWidget.GetCell(row, 0)
+	if cell == nil {
+		return 0, false
+	}
+	pid, ok := cell.GetReference().(int32)
+	return pid, ok
+}
+
+// RefreshProcessTable redraws the process table from the rows collected last,
+// for example after the sort order or filter changed.
+func RefreshProcessTable(d *utils.Dashboard) {
+	processesMu.Lock()
+	defer processesMu.Unlock()
+
+	rows, _ := d.ProcessData.([]ProcessRow)
+	showProcessRows(d, rows)
+}
+
+func showProcessRows(d *utils.Dashboard, rows []ProcessRow) {
+	if d.ProcessWidget == nil {
+		return
+	}
+
+	selectedPID, hasSelection := SelectedPID(d)
+
+	sorted := make([]ProcessRow, len(rows))
+	copy(sorted, rows)
+	SortRows(sorted, d.Theme.Sorting, d.ProcessSortReverse)
+	if d.ProcessFilterActive {
+		sorted = FilterRows(sorted, d.ProcessFilterTerm, d.ProcessFilterType)
+	}
+
+	visible := VisibleColumns(d)
+	table := d.ProcessWidget
+	table.Clear()
+
+	headerColor := utils.GetColorFromName(d.Theme.Foreground)
+	for i, column := range visible {
+		header := column.Header
+		if column.Key == d.Theme.Sorting || (d.Theme.Sorting == "" && column.Key == "cpu") {
+			if column.Numeric != d.ProcessSortReverse {
+				header += "▼"
+			} else {
+				header += "▲"
+			}
+		}
+		cell := tview.NewTableCell(header).
+			SetTextColor(headerColor).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false)
+		if column.Numeric {
+			cell.SetAlign(tview.AlignRight)
+		}
+		table.SetCell(0, i, cell)
+	}
+
+	foreground := utils.GetColorFromName(d.Theme.Layout.Process.ForegroundColor)
+	selectRow := 1
+	for i := range sorted {
+		row := &sorted[i]
+		if hasSelection && row.PID == selectedPID {
+			selectRow = i + 1
+		}
+
+		for j, column := range visible {
+			cell := tview.NewTableCell(tview.Escape(column.format(row))).
+				SetTextColor(foreground).
+				SetMaxWidth(column.MaxWidth)
+			switch {
+			case column.Numeric:
+				cell.SetAlign(tview.AlignRight)
+			case column.Key == "state":
+				cell.SetTextColor(stateColor(row.State))
+			case column.Key == "command":
+				cell.SetExpansion(1)
+			}
+			if j == 0 {
+				cell.SetReference(row.PID)
+			}
+			table.SetCell(i+1, j, cell)
+		}
+	}
+
+	if len(sorted) > 0 {
+		table.Select(selectRow, 0)
+	}
+}
+
+func stateColor(state string) tcell.Color {
+	switch state {
+	case "R":
+		return tcell.ColorGreen
+	case "D", "T", "t":
+		return tcell.ColorYellow
+	case "Z":
+		return tcell.ColorRed
+	default:
+		return tcell.ColorGray
+	}
+}
+
+// formatStartTime shows the clock time for processes started today and the
+// date otherwise, like ps.
+func formatStartTime(start, now time.Time) string {
+	if start.IsZero() {
+		return "-"
+	}
+	start = start.Local()
+	if y, m, d := now.Date(); start.Year() == y && start.Month() == m && start.Day() == d {
+		return start.Format("15:04")
+	}
+	return start.Format("Jan02")
+}
@@ -4,11 +4,11 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
-	"github.com/shirou/gopsutil/process"
 )
 
 type ProcessNode struct {
@@ -29,64 +29,11 @@ type ProcessTree struct {
 }
 
 func GetProcessTree() (*ProcessTree, error) {
-	pids, err := process.Pids()
+	snapshot, err := GetProcessSnapshot()
 	if err != nil {
 		return nil, err
 	}
-
-	processMap := make(map[int32]*ProcessNode)
-	for _, pid := range pids {
-		proc, err := process.NewProcess(pid)
-		if err != nil {
-			continue
-		}
-
-		name, _ := proc.Name()
-		ppid, _ := proc.Ppid()
-		cpuPct, _ := proc.CPUPercent()
-		memInfo, _ := proc.MemoryInfo()
-		status, _ := proc.Status()
-		createTime, _ := proc.CreateTime()
-
-		var memory uint64
-		if memInfo != nil {
-			memory = memInfo.RSS
-		}
-
-		node := &ProcessNode{
-			PID:        pid,
-			PPID:       ppid,
-			Name:       name,
-			CPUPct:     cpuPct,
-			Memory:     memory,
-			Status:     status,
-			CreateTime: time.Unix(createTime/1000, 0),
-			Children:   make([]*ProcessNode, 0),
-		}
-
-		processMap[pid] = node
-	}
-
-	roots := make([]*ProcessNode, 0)
-	for _, node := range processMap {
-		if parent, exists := processMap[node.PPID]; exists {
-			parent.Children = append(parent.Children, node)
-		} else {
-			roots = append(roots, node)
-		}
-	}
-
-	sort.Slice(roots, func(i, j int) bool {
-		return roots[i].Name < roots[j].Name
-	})
-
-	sortChildren(roots)
-
-	return &ProcessTree{
-		Roots:      roots,
-		TotalCount: len(processMap),
-		LastUpdate: time.Now(),
-	}, nil
+	return snapshot.Tree(), nil
 }
 
 func sortChildren(nodes []*ProcessNode) {
@@ -112,7 +59,16 @@ func UpdateProcessTree(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyProcessTree(d, tree)
+}
+
+func ApplyProcessTree(d *utils.Dashboard, tree *ProcessTree) {
 	d.ProcessTreeData = tree
+	d.Samples.Set(collector.ProcessTree, tree)
+	if d.ProcessTreeWidget == nil {
+		return
+	}
+
 	d.ProcessTreeWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		currentY := y + 1
 		maxY := y + h - 1
@@ -0,0 +1,394 @@
+package processes
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"syspulse/internal/collector"
+	"syspulse/internal/utils"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// WatchEvent is a watched process starting or exiting.
+type WatchEvent struct {
+	Time time.Time `json:"time"`
+	PID  int32     `json:"pid"`
+	Kind string    `json:"kind"` // "start" or "exit"
+}
+
+// WatchedProcess is a running process matched by a watch entry.
+type WatchedProcess struct {
+	PID        int32     `json:"pid"`
+	Name       string    `json:"name"`
+	CPUPercent float64   `json:"cpu_percent"`
+	RSS        uint64    `json:"rss"`
+	IORate     float64   `json:"io_bytes_per_sec"` // Read and written
+	FDs        int       `json:"fds"`
+	StartTime  time.Time `json:"start_time"`
+}
+
+// WatchTarget sums up the processes one watch entry matches and lists when
+// they started and exited, oldest first.
+type WatchTarget struct {
+	Entry      utils.WatchEntry `json:"entry"`
+	Processes  []WatchedProcess `json:"processes"`
+	CPUPercent float64          `json:"cpu_percent"`
+	RSS        uint64           `json:"rss"`
+	IORate     float64          `json:"io_bytes_per_sec"`
+	FDs        int              `json:"fds"`
+	Events     []WatchEvent     `json:"events"`
+}
+
+type WatchSample struct {
+	Targets  []WatchTarget `json:"targets"`
+	LastTime time.Time     `json:"last_time"`
+}
+
+func (s *WatchSample) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(s.Targets)*5)
+	for _, target := range s.Targets {
+		labels := map[string]string{"watch": target.Entry.String()}
+		points = append(points,
+			collector.GaugePoint("watch_processes_count", float64(len(target.Processes)), labels),
+			collector.GaugePoint("watch_cpu_usage_percent", target.CPUPercent, labels),
+			collector.GaugePoint("watch_rss_bytes", float64(target.RSS), labels),
+			collector.GaugePoint("watch_io_bytes_per_second", target.IORate, labels),
+			collector.GaugePoint("watch_open_fds", float64(target.FDs), labels),
+		)
+	}
+	return points
+}
+
+// maxWatchEvents bounds the timeline kept per watch entry.
+const maxWatchEvents = 100
+
+// Watcher matches the watch list against each process snapshot and records
+// the processes that appeared and disappeared since the previous one.
+type Watcher struct {
+	procRoot string
+
+	mu      sync.Mutex
+	running map[utils.WatchEntry]map[int32]time.Time // PID to start time
+	events  map[utils.WatchEntry][]WatchEvent
+}
+
+func NewWatcher() *Watcher {
+	return NewWatcherAt(defaultProcRoot)
+}
+
+func NewWatcherAt(procRoot string) *Watcher {
+	return &Watcher{
+		procRoot: procRoot,
+		running:  make(map[utils.WatchEntry]map[int32]time.Time),
+		events:   make(map[utils.WatchEntry][]WatchEvent),
+	}
+}
+
+// Sample returns the watched processes of a snapshot. An entry's first
+// sample only notes what is running; later ones add start and exit events.
+// A PID reused by another process counts as an exit and a start.
+func (w *Watcher) Sample(snapshot *ProcessSnapshot, entries []utils.WatchEntry) *WatchSample {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for entry := range w.running {
+		if !slices.Contains(entries, entry) {
+			delete(w.running, entry)
+			delete(w.events, entry)
+		}
+	}
+
+	sample := &WatchSample{Targets: make([]WatchTarget, 0, len(entries)), LastTime: snapshot.Time}
+	for _, entry := range entries {
+		target := WatchTarget{Entry: entry}
+		running := make(map[int32]time.Time)
+		for i := range snapshot.Processes {
+			row := &snapshot.Processes[i]
+			if !matchesWatch(entry, row) {
+				continue
+			}
+			// Unreadable without privileges; counted as none.
+			fds, _ := countFileDescriptors(w.procRoot, row.PID)
+			target.Processes = append(target.Processes, WatchedProcess{
+				PID:        row.PID,
+				Name:       row.Name,
+				CPUPercent: row.CPUPercent,
+				RSS:        row.RSS,
+				IORate:     row.IOReadRate + row.IOWriteRate,
+				FDs:        fds,
+				StartTime:  row.StartTime,
+			})
+			target.CPUPercent += row.CPUPercent
+			target.RSS += row.RSS
+			target.IORate += row.IOReadRate + row.IOWriteRate
+			target.FDs += fds
+			running[row.PID] = row.StartTime
+		}
+
+		if previous, seen := w.running[entry]; seen {
+			events := w.events[entry]
+			events = append(events, changedProcesses(previous, running, snapshot.Time, "exit")...)
+			events = append(events, changedProcesses(running, previous, snapshot.Time, "start")...)
+			if len(events) > maxWatchEvents {
+				events = events[len(events)-maxWatchEvents:]
+			}
+			w.events[entry] = events
+		}
+		w.running[entry] = running
+
+		target.Events = slices.Clone(w.events[entry])
+		sample.Targets = append(sample.Targets, target)
+	}
+	return sample
+}
+
+// changedProcesses returns an event for each process in from that is not in
+// to, ordered by PID.
+func changedProcesses(from, to map[int32]time.Time, t time.Time, kind string) []WatchEvent {
+	var events []WatchEvent
+	for pid, start := range from {
+		if other, ok := to[pid]; !ok || !other.Equal(start) {
+			events = append(events, WatchEvent{Time: t, PID: pid, Kind: kind})
+		}
+	}
+	slices.SortFunc(events, func(a, b WatchEvent) int { return int(a.PID - b.PID) })
+	return events
+}
+
+// matchesWatch matches a pattern against the process name and the program
+// in the command line, since the kernel cuts names to 15 characters.
+func matchesWatch(entry utils.WatchEntry, row *ProcessRow) bool {
+	if entry.Pattern == "" {
+		return row.PID == entry.PID
+	}
+	if matched, _ := path.Match(entry.Pattern, row.Name); matched {
+		return true
+	}
+	if fields := strings.Fields(row.Command); len(fields) > 0 {
+		matched, _ := path.Match(entry.Pattern, path.Base(fields[0]))
+		return matched
+	}
+	return false
+}
+
+var (
+	defaultWatcher = NewWatcher()
+
+	// watchMu guards the watch list in the theme, which the UI changes while
+	// the watch worker reads it.
+	watchMu sync.Mutex
+)
+
+// WatchList returns a copy of the watch list.
+func WatchList(d *utils.Dashboard) []utils.WatchEntry {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	return slices.Clone(d.Theme.Watch)
+}
+
+// Watch adds an entry to the watch list and saves the list to config.json.
+// When saving fails the entry is still watched until the next start.
+func Watch(d *utils.Dashboard, entry utils.WatchEntry) error {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	if slices.Contains(d.Theme.Watch, entry) {
+		return nil
+	}
+	d.Theme.Watch = append(slices.Clone(d.Theme.Watch), entry)
+	return utils.SaveWatchList(d.Theme.Watch)
+}
+
+// Unwatch removes an entry from the watch list and saves the list to
+// config.json.
+func Unwatch(d *utils.Dashboard, entry utils.WatchEntry) error {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+
+	i := slices.Index(d.Theme.Watch, entry)
+	if i < 0 {
+		return nil
+	}
+	d.Theme.Watch = slices.Delete(slices.Clone(d.Theme.Watch), i, i+1)
+	return utils.SaveWatchList(d.Theme.Watch)
+}
+
+func UpdateWatch(d *utils.Dashboard) {
+	if d.WatchWidget == nil {
+		return
+	}
+
+	snapshot, err := GetProcessSnapshot()
+	if err != nil {
+		return
+	}
+	ApplyWatchSample(d, defaultWatcher.Sample(snapshot, WatchList(d)))
+}
+
+func ApplyWatchSample(d *utils.Dashboard, sample *WatchSample) {
+	d.WatchData = sample
+	d.Samples.Set(collector.Watch, sample)
+	if d.WatchWidget == nil {
+		return
+	}
+
+	d.WatchWidget.SetTitle(fmt.Sprintf("Watch - %d", len(sample.Targets)))
+	d.WatchWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		drawWatch(screen, d, sample, x, y, w, h)
+		return x, y, w, h
+	})
+}
+
+// SelectedWatchEntry returns the watch entry selected in the watch panel.
+func SelectedWatchEntry(d *utils.Dashboard) (utils.WatchEntry, bool) {
+	sample, ok := d.WatchData.(*WatchSample)
+	if !ok || len(sample.Targets) == 0 {
+		return utils.WatchEntry{}, false
+	}
+	return sample.Targets[selectedTarget(d, sample)].Entry, true
+}
+
+func selectedTarget(d *utils.Dashboard, sample *WatchSample) int {
+	return max(0, min(d.WatchSelected, len(sample.Targets)-1))
+}
+
+// watchLines is the height of one target in the watch panel: a summary, the
+// sparklines and the timeline.
+const watchLines = 3
+
+var watchMetrics = []struct {
+	label, name string
+	color       tcell.Color
+}{
+	{"CPU", "watch_cpu_usage_percent", tcell.ColorGreen},
+	{"RSS", "watch_rss_bytes", tcell.ColorYellow},
+	{"IO", "watch_io_bytes_per_second", tcell.ColorBlue},
+	{"FD", "watch_open_fds", tcell.ColorPurple},
+}
+
+func drawWatch(screen tcell.Screen, d *utils.Dashboard, sample *WatchSample, x, y, w, h int) {
+	foreground := utils.GetColorFromName(d.Theme.Layout.Watch.ForegroundColor)
+	innerW := w - 4
+	if innerW <= 0 || h <= 2 {
+		return
+	}
+	if len(sample.Targets) == 0 {
+		utils.SafePrintText(screen, "No watched processes. Press T on a process to watch it.", x+2, y+1, w-4, h-1, foreground)
+		return
+	}
+
+	visible := max(1, (h-2)/watchLines)
+	selected := selectedTarget(d, sample)
+	first := max(0, selected-visible+1)
+	interval := d.Theme.Layout.Watch.UpdateInterval
+
+	lineY := y + 1
+	for i := first; i < len(sample.Targets) && lineY+watchLines <= y+h; i++ {
+		target := &sample.Targets[i]
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		tview.Print(screen, marker+"[::b]"+tview.Escape(target.Entry.String())+"[::-] "+watchStatus(target),
+			x+2, lineY, innerW, tview.AlignLeft, foreground)
+
+		// One sparkline per metric, side by side.
+		labels := map[string]string{"watch": target.Entry.String()}
+		width := innerW/len(watchMetrics) - 5
+		for j, metric := range watchMetrics {
+			if width <= 0 {
+				break
+			}
+			column := x + 4 + j*(width+5)
+			tview.Print(screen, metric.label, column, lineY+1, 3, tview.AlignLeft, foreground)
+			values := utils.HistoryValues(d, metric.name, labels, width, interval)
+			utils.DrawSparkline(screen, values, column+4, lineY+1, width, 0, math.NaN(), metric.color)
+		}
+
+		tview.Print(screen, formatTimeline(target.Events, innerW-2), x+4, lineY+2, innerW-2, tview.AlignLeft, foreground)
+		lineY += watchLines
+	}
+}
+
+func watchStatus(target *WatchTarget) string {
+	if len(target.Processes) == 0 {
+		if n := len(target.Events); n > 0 && target.Events[n-1].Kind == "exit" {
+			return fmt.Sprintf("[red]exited %s[-]", target.Events[n-1].Time.Local().Format("15:04:05"))
+		}
+		return "[red]not running[-]"
+	}
+
+	running := fmt.Sprintf("pid %d", target.Processes[0].PID)
+	if len(target.Processes) > 1 {
+		running = fmt.Sprintf("%d running", len(target.Processes))
+	}
+	return fmt.Sprintf("%s  CPU %.1f%%  RSS %s  IO %s/s  FDs %d",
+		running, target.CPUPercent, formatMemory(target.RSS), formatMemory(uint64(target.IORate)), target.FDs)
+}
+
+// formatTimeline lists the latest events that fit in width, oldest first.
+func formatTimeline(events []WatchEvent, width int) string {
+	if len(events) == 0 {
+		return "no exits or restarts"
+	}
+
+	var parts []string
+	used := 0
+	for i := len(events) - 1; i >= 0; i-- {
+		text := formatWatchEvent(events[i])
+		length := tview.TaggedStringWidth(text) + 1
+		if used+length > width && len(parts) > 0 {
+			break
+		}
+		parts = append(parts, text)
+		used += length
+	}
+	slices.Reverse(parts)
+	return strings.Join(parts, " ")
+}
+
+func formatWatchEvent(event WatchEvent) string {
+	symbol := "[green]▲[-]"
+	if event.Kind == "exit" {
+		symbol = "[red]▼[-]"
+	}
+	return fmt.Sprintf("%s%s %d", symbol, event.Time.Local().Format("15:04:05"), event.PID)
+}
+
+// GetWatchFormattedInfo describes the selected watch entry with its processes
+// and full timeline.
+func GetWatchFormattedInfo(d *utils.Dashboard) string {
+	sample, ok := d.WatchData.(*WatchSample)
+	if !ok || len(sample.Targets) == 0 {
+		return "No watched processes.\n\nPress T on a process in the process list to watch its PID or name."
+	}
+	target := &sample.Targets[selectedTarget(d, sample)]
+
+	var info strings.Builder
+	fmt.Fprintf(&info, "=== Watch: %s ===\n\n", target.Entry)
+	if len(target.Processes) == 0 {
+		info.WriteString("No matching process is running.\n")
+	}
+	for _, p := range target.Processes {
+		fmt.Fprintf(&info, "%d %s: CPU %.1f%%, RSS %s, I/O %s/s, %d open files, started %s\n",
+			p.PID, p.Name, p.CPUPercent, formatMemory(p.RSS), formatMemory(uint64(p.IORate)), p.FDs,
+			p.StartTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	info.WriteString("\nTimeline:\n")
+	if len(target.Events) == 0 {
+		info.WriteString("No exits or restarts since SysPulse started.\n")
+	}
+	for i := len(target.Events) - 1; i >= 0; i-- {
+		event := target.Events[i]
+		fmt.Fprintf(&info, "%s  %-5s %d\n", event.Time.Local().Format("2006-01-02 15:04:05"), event.Kind, event.PID)
+	}
+	return info.String()
+}
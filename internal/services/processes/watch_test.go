@@ -0,0 +1,93 @@
+package processes
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"syspulse/internal/utils"
+
+	"github.com/rivo/tview"
+)
+
+func TestWatcherSample(t *testing.T) {
+	w := NewWatcherAt(t.TempDir())
+	boot := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	byPID := utils.WatchEntry{PID: 42}
+	byName := utils.WatchEntry{Pattern: "nginx*"}
+	entries := []utils.WatchEntry{byPID, byName}
+
+	first := newProcessSnapshot([]ProcessRow{
+		{PID: 42, Name: "postgres", CPUPercent: 5, RSS: 100, IOReadRate: 10, IOWriteRate: 5, StartTime: boot},
+		{PID: 100, Name: "nginx", CPUPercent: 1, RSS: 50, StartTime: boot},
+		{PID: 101, Name: "worker", Command: "/usr/sbin/nginx-worker -q", CPUPercent: 2, RSS: 60, StartTime: boot},
+		{PID: 200, Name: "bash", StartTime: boot},
+	}, boot.Add(time.Minute))
+
+	sample := w.Sample(first, entries)
+	if len(sample.Targets) != 2 {
+		t.Fatalf("Expected two targets, got %d", len(sample.Targets))
+	}
+	postgres, nginx := sample.Targets[0], sample.Targets[1]
+	if len(postgres.Processes) != 1 || postgres.CPUPercent != 5 || postgres.IORate != 15 {
+		t.Errorf("Unexpected PID target %+v", postgres)
+	}
+	if len(nginx.Processes) != 2 || nginx.CPUPercent != 3 || nginx.RSS != 110 {
+		t.Errorf("Expected nginx and its worker by command line, got %+v", nginx)
+	}
+	if len(postgres.Events) != 0 || len(nginx.Events) != 0 {
+		t.Error("Expected no events for processes already running")
+	}
+
+	// postgres exits, nginx restarts with a new PID and the worker's PID is
+	// reused by a newer process.
+	second := newProcessSnapshot([]ProcessRow{
+		{PID: 101, Name: "nginx", StartTime: boot.Add(90 * time.Second)},
+		{PID: 150, Name: "nginx", StartTime: boot.Add(90 * time.Second)},
+	}, boot.Add(2*time.Minute))
+
+	sample = w.Sample(second, entries)
+	postgres, nginx = sample.Targets[0], sample.Targets[1]
+	if len(postgres.Processes) != 0 || len(postgres.Events) != 1 || postgres.Events[0].Kind != "exit" {
+		t.Errorf("Expected postgres to have exited, got %+v", postgres)
+	}
+
+	var events []string
+	for _, event := range nginx.Events {
+		events = append(events, fmt.Sprintf("%s %d", event.Kind, event.PID))
+	}
+	if got := strings.Join(events, ", "); got != "exit 100, exit 101, start 101, start 150" {
+		t.Errorf("Unexpected nginx timeline %q", got)
+	}
+
+	// Removing an entry forgets its timeline.
+	w.Sample(second, []utils.WatchEntry{byName})
+	if sample = w.Sample(first, entries); len(sample.Targets[0].Events) != 0 {
+		t.Errorf("Expected a fresh timeline for a watch entry added again, got %+v", sample.Targets[0].Events)
+	}
+
+	points := sample.Points()
+	if len(points) != 10 || points[0].Labels["watch"] != "pid 42" || points[5].Labels["watch"] != "nginx*" {
+		t.Errorf("Unexpected points %+v", points)
+	}
+}
+
+func TestFormatTimeline(t *testing.T) {
+	at := time.Date(2025, 7, 15, 12, 0, 0, 0, time.Local)
+	events := []WatchEvent{
+		{Time: at, PID: 100, Kind: "exit"},
+		{Time: at.Add(time.Second), PID: 150, Kind: "start"},
+		{Time: at.Add(time.Minute), PID: 150, Kind: "exit"},
+	}
+
+	if got := formatTimeline(nil, 80); got != "no exits or restarts" {
+		t.Errorf("Unexpected empty timeline %q", got)
+	}
+	if got := formatTimeline(events, 80); !strings.HasPrefix(got, "[red]▼[-]12:00:00 100 ") || tview.TaggedStringWidth(got) != 41 {
+		t.Errorf("Expected all events, got %q", got)
+	}
+	if got := formatTimeline(events, 30); got != "[green]▲[-]12:00:01 150 [red]▼[-]12:01:00 150" {
+		t.Errorf("Expected the latest two events, got %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package sysinfo
+
+import (
+	"context"
+	"strconv"
+	"syspulse/internal/collector"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+type CPUSample struct {
+	PerCore []float64 `json:"per_core"`
+	Total   float64   `json:"total"`
+}
+
+func (s *CPUSample) Points() []collector.Point {
+	points := make([]collector.Point, 0, len(s.PerCore)+1)
+	points = append(points, collector.GaugePoint("cpu_usage_percent", s.Total, nil))
+	for i, p := range s.PerCore {
+		points = append(points, collector.GaugePoint("cpu_core_usage_percent", p, map[string]string{"core": strconv.Itoa(i)}))
+	}
+	return points
+}
+
+type CPUCollector struct{}
+
+func NewCPUCollector() *CPUCollector {
+	return &CPUCollector{}
+}
+
+func (c *CPUCollector) Name() string {
+	return collector.CPU
+}
+
+func (c *CPUCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetCPUSample(ctx)
+}
+
+func GetCPUSample(ctx context.Context) (*CPUSample, error) {
+	percents, err := cpu.PercentWithContext(ctx, 0, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &CPUSample{PerCore: percents}
+	if len(percents) > 0 {
+		var sum float64
+		for _, p := range percents {
+			sum += p
+		}
+		sample.Total = sum / float64(len(percents))
+	}
+	return sample, nil
+}
@@ -1,8 +1,11 @@
 package sysinfo
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -129,22 +132,28 @@ func UpdateCPU(d *utils.Dashboard) {
 		return
 	}
 
-	percents, err := cpu.Percent(0, true)
+	sample, err := GetCPUSample(context.Background())
 	if err != nil {
 		return
 	}
 
-	var totalUsage float64
-	if len(percents) > 0 {
-		var sum float64
-		for _, p := range percents {
-			sum += p
-		}
-		totalUsage = sum / float64(len(percents))
+	ApplyCPUSample(d, sample)
+}
+
+func ApplyCPUSample(d *utils.Dashboard, sample *CPUSample) {
+	d.CpuData = sample.PerCore
+	d.Samples.Set(collector.CPU, sample)
+	if d.CpuWidget == nil {
+		return
 	}
 
-	d.CpuData = percents
+	totalUsage := sample.Total
 	d.CpuWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		if utils.IsHistoryView(d.Theme.Layout.CPU.View) && d.History != nil {
+			drawCPUHistory(screen, d, sample, x, y, w, h)
+			return x, y, w, h
+		}
+
 		color := d.Theme.CPU.BarLow
 		if totalUsage > 80 {
 			color = d.Theme.CPU.BarHigh
@@ -155,7 +164,7 @@ func UpdateCPU(d *utils.Dashboard) {
 
 		currentY := y + 3
 
-		for i, p := range d.CpuData {
+		for i, p := range sample.PerCore {
 			color := d.Theme.CPU.BarLow
 			if p > 80 {
 				color = d.Theme.CPU.BarHigh
@@ -170,6 +179,60 @@ func UpdateCPU(d *utils.Dashboard) {
 	})
 }
 
+func drawCPUHistory(screen tcell.Screen, d *utils.Dashboard, sample *CPUSample, x, y, w, h int) {
+	foreground := utils.GetColorFromName(d.Theme.Layout.CPU.ForegroundColor)
+	interval := d.Theme.Layout.CPU.UpdateInterval
+	innerW := w - 4
+	available := h - 2
+	if innerW <= 0 || available <= 0 {
+		return
+	}
+
+	color := d.Theme.CPU.BarLow
+	if sample.Total > 80 {
+		color = d.Theme.CPU.BarHigh
+	}
+
+	totalText := fmt.Sprintf("Total: [%s]%.0f%%[-]", color, sample.Total)
+	tview.Print(screen, totalText, x+2, y+1, innerW, tview.AlignLeft, foreground)
+
+	coreRows := len(sample.PerCore)
+	if coreRows > available-4 {
+		coreRows = available - 4
+	}
+	if coreRows < 0 {
+		coreRows = 0
+	}
+	chartH := available - 1 - coreRows
+
+	values := utils.HistoryValues(d, "cpu_usage_percent", nil, innerW*2, interval)
+	utils.DrawLineChart(screen, []utils.ChartSeries{
+		{Label: "Total", Values: values, Color: utils.GetColorFromName(color)},
+	}, x+2, y+2, innerW, chartH, 0, 100)
+
+	currentY := y + 2 + chartH
+	for i := 0; i < coreRows; i++ {
+		p := sample.PerCore[i]
+		label := fmt.Sprintf("Core %-2d ", i)
+		value := fmt.Sprintf(" %3.0f%%", p)
+		sparkW := innerW - len(label) - len(value)
+		if sparkW <= 0 {
+			break
+		}
+
+		coreColor := d.Theme.CPU.BarLow
+		if p > 80 {
+			coreColor = d.Theme.CPU.BarHigh
+		}
+
+		coreValues := utils.HistoryValues(d, "cpu_core_usage_percent", map[string]string{"core": strconv.Itoa(i)}, sparkW, interval)
+		tview.Print(screen, label, x+2, currentY, len(label), tview.AlignLeft, foreground)
+		utils.DrawSparkline(screen, coreValues, x+2+len(label), currentY, sparkW, 0, 100, utils.GetColorFromName(coreColor))
+		tview.Print(screen, value, x+2+len(label)+sparkW, currentY, len(value), tview.AlignLeft, foreground)
+		currentY++
+	}
+}
+
 func GetCpuName() string {
 	data := GetCpuInfo()
 	if len(data) == 0 {
@@ -0,0 +1,44 @@
+package temperature
+
+import (
+	"context"
+	"syspulse/internal/collector"
+)
+
+func (t *TemperatureData) Points() []collector.Point {
+	points := []collector.Point{
+		collector.GaugePoint("temperature_max_celsius", t.MaxTemp, nil),
+		collector.GaugePoint("temperature_avg_celsius", t.AvgTemp, nil),
+	}
+	if t.CPUTemp > 0 {
+		points = append(points, collector.GaugePoint("temperature_cpu_celsius", t.CPUTemp, nil))
+	}
+	if t.GPUTemp > 0 {
+		points = append(points, collector.GaugePoint("temperature_gpu_celsius", t.GPUTemp, nil))
+	}
+	for _, sensor := range t.Sensors {
+		labels := map[string]string{"sensor": sensor.SensorKey}
+		points = append(points, collector.GaugePoint("temperature_celsius", sensor.Temperature, labels))
+		if sensor.High > 0 {
+			points = append(points, collector.GaugePoint("temperature_high_celsius", sensor.High, labels))
+		}
+		if sensor.Critical > 0 {
+			points = append(points, collector.GaugePoint("temperature_critical_celsius", sensor.Critical, labels))
+		}
+	}
+	return points
+}
+
+type Collector struct{}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func (c *Collector) Name() string {
+	return collector.Temperature
+}
+
+func (c *Collector) Collect(ctx context.Context) (collector.Sample, error) {
+	return GetTemperatures()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"syspulse/internal/collector"
 	"syspulse/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -127,6 +128,10 @@ func UpdateTemperatures(d *utils.Dashboard) {
 		return
 	}
 
+	ApplyTemperatureData(d, tempData)
+}
+
+func ApplyTemperatureData(d *utils.Dashboard, tempData *TemperatureData) {
 	d.TemperatureData = map[string]interface{}{
 		"cpu_temp": tempData.CPUTemp,
 		"gpu_temp": tempData.GPUTemp,
@@ -134,6 +139,11 @@ func UpdateTemperatures(d *utils.Dashboard) {
 		"avg_temp": tempData.AvgTemp,
 		"sensors":  tempData.Sensors,
 	}
+	d.Samples.Set(collector.Temperature, tempData)
+	if d.TemperatureWidget == nil {
+		return
+	}
+
 	d.TemperatureWidget.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
 		currentY := y + 1
 
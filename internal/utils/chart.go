@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	ViewBar     = "bar"
+	ViewHistory = "history"
+)
+
+var sparkBlocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// braille dot bits indexed by [column][row] inside a 2x4 cell
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+type ChartSeries struct {
+	Label  string
+	Values []float64
+	Color  tcell.Color
+}
+
+func IsHistoryView(view string) bool {
+	return view == ViewHistory
+}
+
+func ToggleView(view string) string {
+	if IsHistoryView(view) {
+		return ViewBar
+	}
+	return ViewHistory
+}
+
+// ChartBounds returns the value range of the given series. A fixed bound is
+// used when it is not NaN, otherwise it is derived from the data.
+func ChartBounds(series []ChartSeries, min, max float64) (float64, float64) {
+	autoMin, autoMax := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, v := range s.Values {
+			autoMin = math.Min(autoMin, v)
+			autoMax = math.Max(autoMax, v)
+		}
+	}
+	if math.IsInf(autoMin, 1) {
+		autoMin, autoMax = 0, 1
+	}
+	if autoMin > 0 {
+		autoMin = 0
+	}
+
+	if math.IsNaN(min) {
+		min = autoMin
+	}
+	if math.IsNaN(max) {
+		max = autoMax
+	}
+	if max <= min {
+		max = min + 1
+	}
+	return min, max
+}
+
+func Sparkline(values []float64, width int, min, max float64) string {
+	if width <= 0 {
+		return ""
+	}
+
+	min, max = ChartBounds([]ChartSeries{{Values: values}}, min, max)
+	values = lastN(values, width)
+
+	runes := make([]rune, 0, width)
+	for i := len(values); i < width; i++ {
+		runes = append(runes, sparkBlocks[0])
+	}
+	for _, v := range values {
+		level := int(math.Round(scale(v, min, max) * float64(len(sparkBlocks)-1)))
+		if level == 0 && v > min {
+			level = 1
+		}
+		runes = append(runes, sparkBlocks[level])
+	}
+	return string(runes)
+}
+
+func DrawSparkline(screen tcell.Screen, values []float64, x, y, w int, min, max float64, color tcell.Color) {
+	style := tcell.StyleDefault.Foreground(color)
+	for i, r := range []rune(Sparkline(values, w, min, max)) {
+		screen.SetContent(x+i, y, r, nil, style)
+	}
+}
+
+// DrawLineChart plots every series as a braille line chart in the w x h cell
+// area, giving a 2x4 dot resolution per cell. Later series are drawn on top.
+func DrawLineChart(screen tcell.Screen, series []ChartSeries, x, y, w, h int, min, max float64) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	min, max = ChartBounds(series, min, max)
+	dotsX, dotsY := w*2, h*4
+
+	cells := make([]rune, w*h)
+	colors := make([]tcell.Color, w*h)
+
+	set := func(dx, dy int, color tcell.Color) {
+		if dx < 0 || dx >= dotsX || dy < 0 || dy >= dotsY {
+			return
+		}
+		index := (dy/4)*w + dx/2
+		cells[index] |= brailleDots[dx%2][dy%4]
+		colors[index] = color
+	}
+
+	for _, s := range series {
+		values := lastN(s.Values, dotsX)
+		offset := dotsX - len(values)
+		prevY := -1
+		for i, v := range values {
+			dy := dotsY - 1 - int(math.Round(scale(v, min, max)*float64(dotsY-1)))
+			dx := offset + i
+			if prevY < 0 {
+				prevY = dy
+			}
+			from, to := prevY, dy
+			if from > to {
+				from, to = to, from
+			}
+			for fy := from; fy <= to; fy++ {
+				set(dx, fy, s.Color)
+			}
+			prevY = dy
+		}
+	}
+
+	for i, cell := range cells {
+		if cell == 0 {
+			continue
+		}
+		screen.SetContent(x+i%w, y+i/w, 0x2800+cell, nil, tcell.StyleDefault.Foreground(colors[i]))
+	}
+}
+
+// HistoryValues returns up to count of the most recent values of a series,
+// looking back far enough for one value per update interval.
+func HistoryValues(d *Dashboard, metric string, labels map[string]string, count int, interval int) []float64 {
+	if d.History == nil || count <= 0 {
+		return nil
+	}
+	if interval <= 0 {
+		interval = 1
+	}
+
+	now := d.Now()
+	window := time.Duration(count*interval) * time.Second
+	points := d.History.Range(metric, labels, now.Add(-window), now)
+
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return lastN(values, count)
+}
+
+func lastN(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+func scale(v, min, max float64) float64 {
+	ratio := (v - min) / (max - min)
+	if ratio < 0 {
+		return 0
+	}
+	if ratio > 1 {
+		return 1
+	}
+	return ratio
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		width    int
+		min      float64
+		max      float64
+		expected string
+	}{
+		{
+			name:     "fixed range",
+			values:   []float64{0, 50, 100},
+			width:    3,
+			min:      0,
+			max:      100,
+			expected: " ▄█",
+		},
+		{
+			name:     "pads short data on the left",
+			values:   []float64{100},
+			width:    3,
+			min:      0,
+			max:      100,
+			expected: "  █",
+		},
+		{
+			name:     "keeps the latest values",
+			values:   []float64{100, 100, 0, 0},
+			width:    2,
+			min:      0,
+			max:      100,
+			expected: "  ",
+		},
+		{
+			name:     "auto range",
+			values:   []float64{1, 2},
+			width:    2,
+			min:      math.NaN(),
+			max:      math.NaN(),
+			expected: "▄█",
+		},
+		{
+			name:     "small values stay visible",
+			values:   []float64{0.1},
+			width:    1,
+			min:      0,
+			max:      100,
+			expected: "▁",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Sparkline(tt.values, tt.width, tt.min, tt.max)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDrawLineChart(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(10, 5)
+
+	DrawLineChart(screen, []ChartSeries{
+		{Label: "flat low", Values: []float64{0, 0}, Color: tcell.ColorGreen},
+		{Label: "flat high", Values: []float64{100, 100}, Color: tcell.ColorRed},
+	}, 0, 0, 1, 2, 0, 100)
+
+	top, _, topStyle, _ := screen.GetContent(0, 0)
+	bottom, _, _, _ := screen.GetContent(0, 1)
+
+	if top != 0x2800+0x01+0x08 {
+		t.Errorf("Expected top dots in both columns, got %U", top)
+	}
+	if bottom != 0x2800+0x40+0x80 {
+		t.Errorf("Expected bottom dots in both columns, got %U", bottom)
+	}
+	if fg, _, _ := topStyle.Decompose(); fg != tcell.ColorRed {
+		t.Errorf("Expected top cell to use the last series color")
+	}
+}
+
+func TestChartBounds(t *testing.T) {
+	min, max := ChartBounds(nil, math.NaN(), math.NaN())
+	if min != 0 || max != 1 {
+		t.Errorf("Expected default bounds 0..1, got %v..%v", min, max)
+	}
+
+	min, max = ChartBounds([]ChartSeries{{Values: []float64{5, 20}}}, math.NaN(), math.NaN())
+	if min != 0 || max != 20 {
+		t.Errorf("Expected bounds 0..20, got %v..%v", min, max)
+	}
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"syspulse/internal/errors"
+)
+
+const (
+	ConfigFile        = "config.json"
+	DefaultConfigFile = "internal/services/UI/default.json"
+)
+
+// LoadTheme reads config.json, falling back to the bundled default.json, and
+// validates the result. It is shared by the UI and the headless commands.
+func LoadTheme() (Theme, error) {
+	var themeData Theme
+
+	dataFile, err := os.ReadFile(ConfigFile)
+	if err != nil {
+		dataFileBackup, err := os.ReadFile(DefaultConfigFile)
+		if err != nil {
+			return themeData, errors.NewAppError(errors.ConfigError,
+				"Cannot load neither 'config.json' nor 'default.json'", err)
+		}
+		if err = json.Unmarshal(dataFileBackup, &themeData); err != nil {
+			return themeData, errors.NewAppError(errors.ConfigError,
+				"Failed to parse default.json", err)
+		}
+	} else {
+		if err = json.Unmarshal(dataFile, &themeData); err != nil {
+			return themeData, errors.NewAppError(errors.ConfigError,
+				"Failed to parse config.json", err)
+		}
+	}
+
+	if err = Validate(themeData); err != nil {
+		return themeData, errors.Wrap(err, "Theme validation failed")
+	}
+
+	return themeData, nil
+}
+
+// SaveWatchList writes the watch list to config.json, creating it from
+// default.json if needed. The rest of the file is kept as it is.
+func SaveWatchList(entries []WatchEntry) error {
+	data, err := os.ReadFile(ConfigFile)
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(DefaultConfigFile)
+	}
+	if err != nil {
+		return errors.NewAppError(errors.ConfigError, "Failed to read the configuration", err)
+	}
+
+	if entries == nil {
+		entries = []WatchEntry{}
+	}
+	data, err = setConfigValue(data, "watch", entries)
+	if err != nil {
+		return errors.NewAppError(errors.ConfigError, "Failed to update the watch list", err)
+	}
+	if err := os.WriteFile(ConfigFile, data, 0644); err != nil {
+		return errors.NewAppError(errors.ConfigError, "Failed to write config.json", err)
+	}
+	return nil
+}
+
+// setConfigValue replaces the value of a top-level key of a JSON object, or
+// adds the key at the end, without reformatting anything else.
+func setConfigValue(data []byte, key string, value interface{}) ([]byte, error) {
+	encoded, err := json.MarshalIndent(value, "\t", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("configuration is not a JSON object")
+	}
+
+	lastEnd := -1
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		afterKey := int(decoder.InputOffset())
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		lastEnd = int(decoder.InputOffset())
+
+		if name == key {
+			start := afterKey + bytes.IndexFunc(data[afterKey:], func(r rune) bool {
+				return r != ':' && r != ' ' && r != '\t' && r != '\r' && r != '\n'
+			})
+			return slices.Concat(data[:start], encoded, data[lastEnd:]), nil
+		}
+	}
+
+	entry := fmt.Sprintf("\n\t%q: %s", key, encoded)
+	if lastEnd < 0 {
+		// An empty object; insert after its opening brace.
+		lastEnd = bytes.IndexByte(data, '{') + 1
+	} else {
+		entry = "," + entry
+	}
+	return slices.Concat(data[:lastEnd], []byte(entry), data[lastEnd:]), nil
+}
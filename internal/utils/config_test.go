@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetConfigValue(t *testing.T) {
+	entries := []WatchEntry{{PID: 42}, {Pattern: "nginx*"}}
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "replaces the existing value",
+			config: "{\n\t\"background\": \"black\",\n\t\"watch\": [],\n\t\"updatetime\": 1\n}\n",
+			want:   "{\n\t\"background\": \"black\",\n\t\"watch\": [\n\t\t{\n\t\t\t\"pid\": 42\n\t\t},\n\t\t{\n\t\t\t\"pattern\": \"nginx*\"\n\t\t}\n\t],\n\t\"updatetime\": 1\n}\n",
+		},
+		{
+			name:   "appends a missing key",
+			config: "{\n\t\"layout\": {\"watch\": {\"enabled\": true}},\n\t\"updatetime\": 1\n}\n",
+			want:   "{\n\t\"layout\": {\"watch\": {\"enabled\": true}},\n\t\"updatetime\": 1,\n\t\"watch\": [\n\t\t{\n\t\t\t\"pid\": 42\n\t\t},\n\t\t{\n\t\t\t\"pattern\": \"nginx*\"\n\t\t}\n\t]\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setConfigValue([]byte(tt.config), "watch", entries)
+			if err != nil {
+				t.Fatalf("setConfigValue failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+
+			var theme Theme
+			if err := json.Unmarshal(got, &theme); err != nil || len(theme.Watch) != 2 || theme.Watch[1].Pattern != "nginx*" {
+				t.Errorf("Expected the watch list to read back, got %+v (%v)", theme.Watch, err)
+			}
+		})
+	}
+
+	if _, err := setConfigValue([]byte("[]"), "watch", entries); err == nil {
+		t.Error("Expected an error for a configuration that is not an object")
+	}
+}
@@ -1,6 +1,12 @@
 package utils
 
 import (
+	"strconv"
+	"syspulse/internal/alerts"
+	"syspulse/internal/collector"
+	"syspulse/internal/history"
+	"time"
+
 	"github.com/rivo/tview"
 	"github.com/shirou/gopsutil/disk"
 	"github.com/shirou/gopsutil/mem"
@@ -27,11 +33,13 @@ type DISKModel struct {
 	BarEmpty  string `json:"bar_empty"`
 }
 
+// PerformanceConfig governs the process sampler. Zero values fall back to
+// DefaultPerformanceConfig.
 type PerformanceConfig struct {
-	ProcessCacheTTL       int `json:"process_cache_ttl"`
-	FullScanInterval      int `json:"full_scan_interval"`
-	SyscallBatchSize      int `json:"syscall_batch_size"`
-	ProcessUpdateInterval int `json:"process_update_interval"`
+	ProcessCacheTTL       int `json:"process_cache_ttl"`       // Seconds a command line and user are reused
+	FullScanInterval      int `json:"full_scan_interval"`      // Seconds between dropping all cached process data
+	SyscallBatchSize      int `json:"syscall_batch_size"`      // Processes read concurrently
+	ProcessUpdateInterval int `json:"process_update_interval"` // Seconds one scan is shared between its consumers
 }
 
 var (
@@ -49,24 +57,77 @@ type GPUModel struct {
 }
 
 type ExportConfig struct {
-	Enabled        bool     `json:"enabled"`
-	Interval       int      `json:"interval"`
-	Formats        []string `json:"formats"`
-	Directory      string   `json:"directory"`
-	FilenamePrefix string   `json:"filename_prefix"`
+	Enabled        bool       `json:"enabled"`
+	Interval       int        `json:"interval"`
+	Formats        []string   `json:"formats"`
+	Directory      string     `json:"directory"`
+	FilenamePrefix string     `json:"filename_prefix"`
+	MaxSizeMB      int        `json:"max_size_mb"`     // Rotate segments at this size, 0 disables
+	RotateInterval int        `json:"rotate_interval"` // Rotate segments after this many seconds, 0 disables
+	Compress       bool       `json:"compress"`        // Gzip rotated segments
+	Retention      int        `json:"retention"`       // Rotated segments to keep per format, 0 keeps all
+	CSVLayout      string     `json:"csv_layout"`      // wide (one row per sample) or long (one row per metric)
+	Push           PushConfig `json:"push"`
+}
+
+// PushConfig sends every export to a metrics backend as well as, or instead
+// of, writing files.
+type PushConfig struct {
+	Enabled   bool              `json:"enabled"`
+	URL       string            `json:"url"`               // tcp://, udp://, http:// or https:// endpoint
+	Format    string            `json:"format"`            // influx or graphite
+	BatchSize int               `json:"batch_size"`        // Lines per write, 0 uses the default
+	Retries   int               `json:"retries"`           // Attempts after the first, 0 uses the default
+	Timeout   int               `json:"timeout"`           // Seconds per attempt, 0 uses the default
+	Headers   map[string]string `json:"headers,omitempty"` // Extra HTTP headers, e.g. Authorization
+}
+
+type ServerConfig struct {
+	Listen      string `json:"listen"`
+	Interval    int    `json:"interval"`               // Collection interval in seconds
+	AllowRemote bool   `json:"allow_remote,omitempty"` // Allow listening on non-loopback addresses
+	TokenFile   string `json:"token_file,omitempty"`   // Enables POST actions when set
 }
 
+var (
+	DefaultServerConfig = ServerConfig{
+		Listen:   "127.0.0.1:9273",
+		Interval: 5,
+	}
+)
+
 type WidgetConfig struct {
-	Enabled         bool    `json:"enabled"`
-	Row             int     `json:"row"`
-	Column          int     `json:"column"`
-	RowSpan         int     `json:"rowSpan"`
-	ColSpan         int     `json:"colSpan"`
-	MinWidth        int     `json:"minWidth"`
-	Weight          float64 `json:"weight"`
-	BorderColor     string  `json:"border_color"`
-	ForegroundColor string  `json:"foreground_color"`
-	UpdateInterval  int     `json:"update_interval"` // Update interval in seconds
+	Enabled         bool     `json:"enabled"`
+	Row             int      `json:"row"`
+	Column          int      `json:"column"`
+	RowSpan         int      `json:"rowSpan"`
+	ColSpan         int      `json:"colSpan"`
+	MinWidth        int      `json:"minWidth"`
+	Weight          float64  `json:"weight"`
+	BorderColor     string   `json:"border_color"`
+	ForegroundColor string   `json:"foreground_color"`
+	UpdateInterval  int      `json:"update_interval"`   // Update interval in seconds
+	View            string   `json:"view,omitempty"`    // "bar" or "history"
+	Columns         []string `json:"columns,omitempty"` // Process table columns
+}
+
+// ProcessColumns lists the columns of the process table. They name the
+// process widget's columns and the processsort setting.
+var ProcessColumns = []string{"pid", "user", "name", "state", "cpu", "mem", "rss", "threads", "nice", "start", "io", "command"}
+
+// WatchEntry pins processes to the watch panel: one PID, or every process
+// whose name matches Pattern, which keeps following the program across
+// restarts.
+type WatchEntry struct {
+	PID     int32  `json:"pid,omitempty"`
+	Pattern string `json:"pattern,omitempty"` // Shell pattern, e.g. "nginx*"
+}
+
+func (e WatchEntry) String() string {
+	if e.Pattern != "" {
+		return e.Pattern
+	}
+	return "pid " + strconv.Itoa(int(e.PID))
 }
 
 type Widget struct {
@@ -90,24 +151,32 @@ type LayoutConfig struct {
 	DiskIO       WidgetConfig `json:"disk_io"`
 	ProcessTree  WidgetConfig `json:"process_tree"`
 	Battery      WidgetConfig `json:"battery"`
+	Cgroups      WidgetConfig `json:"cgroups"`
+	Pressure     WidgetConfig `json:"pressure"`
+	Watch        WidgetConfig `json:"watch"`
 	Rows         int          `json:"rows"`
 	Columns      int          `json:"columns"`
 	Spacing      int          `json:"spacing"`
 }
 
 type Theme struct {
-	Background    string       `json:"background"`
-	Foreground    string       `json:"foreground"`
-	Altforeground string       `json:"altforeground"`
-	CPU           CPUModel     `json:"cpu"`
-	Memory        MEMModel     `json:"memory"`
-	Network       NETModel     `json:"network"`
-	Disk          DISKModel    `json:"disk"`
-	GPU           GPUModel     `json:"gpu"`
-	Layout        LayoutConfig `json:"layout"`
-	Sorting       string       `json:"processsort"`
-	UpdateTime    int          `json:"updatetime"`
-	Export        ExportConfig `json:"export"`
+	Background    string            `json:"background"`
+	Foreground    string            `json:"foreground"`
+	Altforeground string            `json:"altforeground"`
+	CPU           CPUModel          `json:"cpu"`
+	Memory        MEMModel          `json:"memory"`
+	Network       NETModel          `json:"network"`
+	Disk          DISKModel         `json:"disk"`
+	GPU           GPUModel          `json:"gpu"`
+	Layout        LayoutConfig      `json:"layout"`
+	Sorting       string            `json:"processsort"`
+	UpdateTime    int               `json:"updatetime"`
+	Export        ExportConfig      `json:"export"`
+	History       history.Config    `json:"history"`
+	Server        ServerConfig      `json:"server"`
+	Alerts        alerts.Config     `json:"alerts"`
+	Performance   PerformanceConfig `json:"performance"`
+	Watch         []WatchEntry      `json:"watch"`
 }
 
 type Dashboard struct {
@@ -116,7 +185,7 @@ type Dashboard struct {
 	MemWidget          *tview.Box
 	DiskWidget         *tview.Box
 	NetWidget          *tview.Box
-	ProcessWidget      *tview.List
+	ProcessWidget      *tview.Table
 	GPUWidget          *tview.Box
 	LoadWidget         *tview.Box
 	TemperatureWidget  *tview.Box
@@ -124,6 +193,9 @@ type Dashboard struct {
 	DiskIOWidget       *tview.Box
 	ProcessTreeWidget  *tview.Box
 	BatteryWidget      *tview.Box
+	CgroupsWidget      *tview.TreeView
+	PressureWidget     *tview.Box
+	WatchWidget        *tview.Box
 	MainWidget         *tview.Flex
 	Theme              Theme
 	CpuData            []float64
@@ -138,16 +210,39 @@ type Dashboard struct {
 	TemperatureData    interface{}
 	NetworkConnsData   interface{}
 	DiskIOData         interface{}
+	ProcessData        interface{}
 	ProcessTreeData    interface{}
 	BatteryData        interface{}
+	CgroupsData        interface{}
+	PressureData       interface{}
+	WatchData          interface{}
 	GPUData            interface{}
+	Samples            *collector.Snapshot
+	History            *history.Store
+	Alerts             *alerts.Engine
+	AlertNotifier      *alerts.Dispatcher
+
+	// Clock replaces the wall clock for history lookups, for example with the
+	// playback position while replaying a recording.
+	Clock func() time.Time
 
 	ProcessFilterActive bool
 	ProcessFilterTerm   string
 	ProcessFilterType   string
+	ProcessSortReverse  bool
+	WatchSelected       int
 
 	InModalState bool
 
 	PluginManager interface{}
 	PluginWidgets map[string]tview.Primitive
 }
+
+// Now returns the dashboard's current time: the wall clock, or the playback
+// position during a replay.
+func (d *Dashboard) Now() time.Time {
+	if d.Clock != nil {
+		return d.Clock()
+	}
+	return time.Now()
+}
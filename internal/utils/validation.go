@@ -2,7 +2,13 @@ package utils
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"slices"
+	"syspulse/internal/alerts"
 	"syspulse/internal/errors"
+	"syspulse/internal/history"
 )
 
 func Validate(t Theme) error {
@@ -37,6 +43,9 @@ func Validate(t Theme) error {
 		{"DiskIO", t.Layout.DiskIO},
 		{"ProcessTree", t.Layout.ProcessTree},
 		{"Battery", t.Layout.Battery},
+		{"Cgroups", t.Layout.Cgroups},
+		{"Pressure", t.Layout.Pressure},
+		{"Watch", t.Layout.Watch},
 	}
 
 	for _, w := range widgets {
@@ -45,10 +54,34 @@ func Validate(t Theme) error {
 		}
 	}
 
+	if err := validateProcessConfig(t); err != nil {
+		return err
+	}
+
+	if err := validatePerformanceConfig(t.Performance); err != nil {
+		return err
+	}
+
+	if err := validateWatchList(t.Watch); err != nil {
+		return err
+	}
+
 	if err := validateExportConfig(t.Export); err != nil {
 		return err
 	}
 
+	if err := validateHistoryConfig(t.History); err != nil {
+		return err
+	}
+
+	if err := validateServerConfig(t.Server); err != nil {
+		return err
+	}
+
+	if err := validateAlertsConfig(t.Alerts); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -102,6 +135,64 @@ func validateWidgetConfig(name string, w WidgetConfig, maxRows, maxCols int) err
 			fmt.Sprintf("%s widget update interval cannot exceed 1800 seconds (30 minutes)", name), nil)
 	}
 
+	if w.View != "" && w.View != ViewBar && w.View != ViewHistory {
+		return errors.NewAppError(errors.ValidationError,
+			fmt.Sprintf("%s widget view must be 'bar' or 'history'", name), nil)
+	}
+
+	return nil
+}
+
+func validateProcessConfig(t Theme) error {
+	if t.Sorting != "" && !slices.Contains(ProcessColumns, t.Sorting) {
+		return errors.NewAppError(errors.ValidationError,
+			fmt.Sprintf("Unknown process sort column %q", t.Sorting), nil)
+	}
+
+	seen := make(map[string]bool)
+	for _, column := range t.Layout.Process.Columns {
+		if !slices.Contains(ProcessColumns, column) {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Unknown process column %q", column), nil)
+		}
+		if seen[column] {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Process column %q is listed twice", column), nil)
+		}
+		seen[column] = true
+	}
+	return nil
+}
+
+func validateWatchList(entries []WatchEntry) error {
+	seen := make(map[WatchEntry]bool)
+	for _, entry := range entries {
+		if (entry.PID > 0) == (entry.Pattern != "") {
+			return errors.NewAppError(errors.ValidationError,
+				"Watch entries need either a pid or a pattern", nil)
+		}
+		if _, err := path.Match(entry.Pattern, ""); err != nil {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Invalid watch pattern %q", entry.Pattern), err)
+		}
+		if seen[entry] {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Watch entry %q is listed twice", entry), nil)
+		}
+		seen[entry] = true
+	}
+	return nil
+}
+
+func validatePerformanceConfig(p PerformanceConfig) error {
+	if p.ProcessCacheTTL < 0 || p.FullScanInterval < 0 || p.ProcessUpdateInterval < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Performance intervals cannot be negative", nil)
+	}
+	if p.SyscallBatchSize < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Performance syscall batch size cannot be negative", nil)
+	}
 	return nil
 }
 
@@ -118,9 +209,11 @@ func validateExportConfig(e ExportConfig) error {
 		}
 
 		for _, format := range e.Formats {
-			if format != "csv" && format != "json" {
+			switch format {
+			case "csv", "json", "influx", "graphite":
+			default:
 				return errors.NewAppError(errors.ValidationError,
-					fmt.Sprintf("Unsupported export format: %s (must be 'csv' or 'json')", format), nil)
+					fmt.Sprintf("Unsupported export format: %s (must be 'csv', 'json', 'influx' or 'graphite')", format), nil)
 			}
 		}
 
@@ -133,6 +226,169 @@ func validateExportConfig(e ExportConfig) error {
 			return errors.NewAppError(errors.ValidationError,
 				"Export filename prefix must be specified", nil)
 		}
+
+		if e.MaxSizeMB < 0 {
+			return errors.NewAppError(errors.ValidationError,
+				"Export max size cannot be negative", nil)
+		}
+
+		if e.RotateInterval < 0 {
+			return errors.NewAppError(errors.ValidationError,
+				"Export rotate interval cannot be negative", nil)
+		}
+
+		if e.Retention < 0 {
+			return errors.NewAppError(errors.ValidationError,
+				"Export retention cannot be negative", nil)
+		}
+
+		if e.CSVLayout != "" && e.CSVLayout != "wide" && e.CSVLayout != "long" {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Unsupported CSV layout: %s (must be 'wide' or 'long')", e.CSVLayout), nil)
+		}
+	}
+
+	if e.Push.Enabled {
+		if e.Interval <= 0 {
+			return errors.NewAppError(errors.ValidationError,
+				"Export interval must be greater than 0", nil)
+		}
+		return validatePushConfig(e.Push)
+	}
+
+	return nil
+}
+
+func validatePushConfig(p PushConfig) error {
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Host == "" {
+		return errors.NewAppError(errors.ValidationError,
+			fmt.Sprintf("Invalid export push URL: %s", p.URL), err)
+	}
+
+	switch u.Scheme {
+	case "tcp", "udp", "http", "https":
+	default:
+		return errors.NewAppError(errors.ValidationError,
+			fmt.Sprintf("Unsupported export push protocol: %s (must be 'tcp', 'udp', 'http' or 'https')", u.Scheme), nil)
+	}
+
+	if p.Format != "influx" && p.Format != "graphite" {
+		return errors.NewAppError(errors.ValidationError,
+			fmt.Sprintf("Unsupported export push format: %s (must be 'influx' or 'graphite')", p.Format), nil)
+	}
+
+	if p.BatchSize < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Export push batch size cannot be negative", nil)
+	}
+
+	if p.Timeout < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Export push timeout cannot be negative", nil)
+	}
+
+	return nil
+}
+
+func validateHistoryConfig(h history.Config) error {
+	if !h.Enabled {
+		return nil
+	}
+
+	if h.MaxSeries < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"History max series cannot be negative", nil)
+	}
+
+	names := make(map[string]bool)
+	for _, tier := range h.Tiers {
+		if tier.Name == "" {
+			return errors.NewAppError(errors.ValidationError,
+				"History tier name must be specified", nil)
+		}
+		if names[tier.Name] {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Duplicate history tier: %s", tier.Name), nil)
+		}
+		names[tier.Name] = true
+
+		if tier.Resolution < 0 {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("History tier %s resolution cannot be negative", tier.Name), nil)
+		}
+		if tier.Retention <= 0 {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("History tier %s retention must be greater than 0", tier.Name), nil)
+		}
+		if tier.Retention < tier.Resolution {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("History tier %s retention must be at least its resolution", tier.Name), nil)
+		}
+	}
+
+	return nil
+}
+
+func validateServerConfig(s ServerConfig) error {
+	if s.Listen != "" {
+		if _, _, err := net.SplitHostPort(s.Listen); err != nil {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Invalid server listen address: %s", s.Listen), err)
+		}
+	}
+
+	if s.Interval < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Server interval cannot be negative", nil)
+	}
+
+	return nil
+}
+
+func validateAlertsConfig(a alerts.Config) error {
+	if !a.Enabled {
+		return nil
+	}
+
+	if a.Interval < 0 {
+		return errors.NewAppError(errors.ValidationError,
+			"Alerts interval cannot be negative", nil)
+	}
+
+	notifiers := make(map[string]bool)
+	for _, notifier := range a.Notifiers {
+		if notifiers[notifier.Name] {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Duplicate alert notifier: %s", notifier.Name), nil)
+		}
+		notifiers[notifier.Name] = true
+
+		if err := alerts.ValidateNotifier(notifier); err != nil {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Invalid alert notifier: %v", err), err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, rule := range a.Rules {
+		if names[rule.Name] {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Duplicate alert rule: %s", rule.Name), nil)
+		}
+		names[rule.Name] = true
+
+		if _, err := alerts.Compile(rule); err != nil {
+			return errors.NewAppError(errors.ValidationError,
+				fmt.Sprintf("Invalid alert rule: %v", err), err)
+		}
+
+		for _, name := range rule.Notify {
+			if !notifiers[name] {
+				return errors.NewAppError(errors.ValidationError,
+					fmt.Sprintf("Alert rule %s uses unknown notifier: %s", rule.Name, name), nil)
+			}
+		}
 	}
 
 	return nil
@@ -217,7 +473,7 @@ func ValidatePluginWidget(name string, config interface{}, maxRows, maxCols int)
 			fmt.Sprintf("Plugin %s widget title cannot exceed 50 characters", name), nil)
 	}
 
-	builtinWidgets := []string{"CPU", "Memory Usage", "Disk Usage", "Network Activity", "Processes", "GPU", "Load Average", "Temperature", "Network Connections", "DiskIO", "ProcessTree", "Battery"}
+	builtinWidgets := []string{"CPU", "Memory Usage", "Disk Usage", "Network Activity", "Processes", "GPU", "Load Average", "Temperature", "Network Connections", "DiskIO", "ProcessTree", "Battery", "Cgroups", "Pressure", "Watch"}
 	for _, builtinWidget := range builtinWidgets {
 		if w.Title == builtinWidget {
 			return errors.NewAppError(errors.ValidationError,
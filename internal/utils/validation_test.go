@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"strings"
+	"syspulse/internal/alerts"
+	"syspulse/internal/history"
 	"testing"
 )
 
@@ -226,6 +228,43 @@ func TestValidateWidgetConfig(t *testing.T) {
 			shouldError: true,
 			errorMsg:    "update interval cannot exceed 1800 seconds",
 		},
+		{
+			name:       "history view",
+			widgetName: "CPU",
+			config: WidgetConfig{
+				Enabled:        true,
+				Row:            0,
+				Column:         0,
+				RowSpan:        1,
+				ColSpan:        1,
+				MinWidth:       30,
+				Weight:         1.0,
+				UpdateInterval: 1,
+				View:           "history",
+			},
+			maxRows:     4,
+			maxCols:     2,
+			shouldError: false,
+		},
+		{
+			name:       "invalid view",
+			widgetName: "CPU",
+			config: WidgetConfig{
+				Enabled:        true,
+				Row:            0,
+				Column:         0,
+				RowSpan:        1,
+				ColSpan:        1,
+				MinWidth:       30,
+				Weight:         1.0,
+				UpdateInterval: 1,
+				View:           "graph",
+			},
+			maxRows:     4,
+			maxCols:     2,
+			shouldError: true,
+			errorMsg:    "view must be 'bar' or 'history'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -760,6 +799,91 @@ func TestValidateTheme(t *testing.T) {
 			shouldError: true,
 			errorMsg:    "Layout spacing cannot be negative",
 		},
+		{
+			name: "unknown process sort column",
+			theme: Theme{
+				UpdateTime: 1,
+				Sorting:    "size",
+				Layout:     LayoutConfig{Rows: 4, Columns: 2},
+			},
+			shouldError: true,
+			errorMsg:    "Unknown process sort column",
+		},
+		{
+			name: "unknown process column",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout: LayoutConfig{
+					Rows:    4,
+					Columns: 2,
+					Process: WidgetConfig{Columns: []string{"pid", "cmd"}},
+				},
+			},
+			shouldError: true,
+			errorMsg:    "Unknown process column",
+		},
+		{
+			name: "duplicate process column",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout: LayoutConfig{
+					Rows:    4,
+					Columns: 2,
+					Process: WidgetConfig{Columns: []string{"pid", "cpu", "pid"}},
+				},
+			},
+			shouldError: true,
+			errorMsg:    "listed twice",
+		},
+		{
+			name: "negative process update interval",
+			theme: Theme{
+				UpdateTime:  1,
+				Layout:      LayoutConfig{Rows: 4, Columns: 2},
+				Performance: PerformanceConfig{ProcessUpdateInterval: -1},
+			},
+			shouldError: true,
+			errorMsg:    "Performance intervals cannot be negative",
+		},
+		{
+			name: "watch list",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout:     LayoutConfig{Rows: 4, Columns: 2},
+				Watch:      []WatchEntry{{PID: 42}, {Pattern: "nginx*"}},
+			},
+			shouldError: false,
+		},
+		{
+			name: "watch entry with pid and pattern",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout:     LayoutConfig{Rows: 4, Columns: 2},
+				Watch:      []WatchEntry{{PID: 42, Pattern: "nginx"}},
+			},
+			shouldError: true,
+			errorMsg:    "either a pid or a pattern",
+		},
+		{
+			name: "invalid watch pattern",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout:     LayoutConfig{Rows: 4, Columns: 2},
+				Watch:      []WatchEntry{{Pattern: "nginx["}},
+			},
+			shouldError: true,
+			errorMsg:    "Invalid watch pattern",
+		},
+		{
+			name: "duplicate watch entry",
+			theme: Theme{
+				UpdateTime: 1,
+				Layout:     LayoutConfig{Rows: 4, Columns: 2},
+				Watch:      []WatchEntry{{PID: 42}, {PID: 42}},
+			},
+			shouldError: true,
+			errorMsg:    "listed twice",
+		},
 	}
 
 	for _, tt := range tests {
@@ -867,6 +991,126 @@ func TestValidateExportConfig(t *testing.T) {
 			shouldError: true,
 			errorMsg:    "Export filename prefix must be specified",
 		},
+		{
+			name: "rotation settings",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"json"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+				MaxSizeMB:      64,
+				RotateInterval: 86400,
+				Compress:       true,
+				Retention:      7,
+			},
+			shouldError: false,
+		},
+		{
+			name: "negative retention",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"json"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+				Retention:      -1,
+			},
+			shouldError: true,
+			errorMsg:    "Export retention cannot be negative",
+		},
+		{
+			name: "negative max size",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"csv"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+				MaxSizeMB:      -5,
+			},
+			shouldError: true,
+			errorMsg:    "Export max size cannot be negative",
+		},
+		{
+			name: "line protocol formats",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"influx", "graphite"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+			},
+			shouldError: false,
+		},
+		{
+			name: "long csv layout",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"csv"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+				CSVLayout:      "long",
+			},
+			shouldError: false,
+		},
+		{
+			name: "invalid csv layout",
+			config: ExportConfig{
+				Enabled:        true,
+				Interval:       60,
+				Formats:        []string{"csv"},
+				Directory:      "exports",
+				FilenamePrefix: "syspulse",
+				CSVLayout:      "tall",
+			},
+			shouldError: true,
+			errorMsg:    "Unsupported CSV layout",
+		},
+		{
+			name: "push without file export",
+			config: ExportConfig{
+				Interval: 10,
+				Push:     PushConfig{Enabled: true, URL: "udp://127.0.0.1:8089", Format: "influx"},
+			},
+			shouldError: false,
+		},
+		{
+			name: "push with unsupported protocol",
+			config: ExportConfig{
+				Interval: 10,
+				Push:     PushConfig{Enabled: true, URL: "ftp://metrics:21", Format: "graphite"},
+			},
+			shouldError: true,
+			errorMsg:    "Unsupported export push protocol",
+		},
+		{
+			name: "push with file format",
+			config: ExportConfig{
+				Interval: 10,
+				Push:     PushConfig{Enabled: true, URL: "tcp://graphite:2003", Format: "csv"},
+			},
+			shouldError: true,
+			errorMsg:    "Unsupported export push format",
+		},
+		{
+			name: "push without host",
+			config: ExportConfig{
+				Interval: 10,
+				Push:     PushConfig{Enabled: true, URL: "graphite:2003", Format: "graphite"},
+			},
+			shouldError: true,
+			errorMsg:    "Invalid export push URL",
+		},
+		{
+			name: "push without interval",
+			config: ExportConfig{
+				Push: PushConfig{Enabled: true, URL: "tcp://graphite:2003", Format: "graphite"},
+			},
+			shouldError: true,
+			errorMsg:    "Export interval must be greater than 0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -888,6 +1132,236 @@ func TestValidateExportConfig(t *testing.T) {
 	}
 }
 
+func TestValidateHistoryConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      history.Config
+		shouldError bool
+		errorMsg    string
+	}{
+		{
+			name:        "default history config",
+			config:      history.DefaultConfig,
+			shouldError: false,
+		},
+		{
+			name:        "disabled history should pass",
+			config:      history.Config{Enabled: false, MaxSeries: -1},
+			shouldError: false,
+		},
+		{
+			name:        "negative max series",
+			config:      history.Config{Enabled: true, MaxSeries: -1},
+			shouldError: true,
+			errorMsg:    "max series cannot be negative",
+		},
+		{
+			name: "duplicate tier",
+			config: history.Config{Enabled: true, Tiers: []history.Tier{
+				{Name: "raw", Retention: 60},
+				{Name: "raw", Resolution: 10, Retention: 600},
+			}},
+			shouldError: true,
+			errorMsg:    "Duplicate history tier",
+		},
+		{
+			name:        "zero retention",
+			config:      history.Config{Enabled: true, Tiers: []history.Tier{{Name: "raw"}}},
+			shouldError: true,
+			errorMsg:    "retention must be greater than 0",
+		},
+		{
+			name:        "retention shorter than resolution",
+			config:      history.Config{Enabled: true, Tiers: []history.Tier{{Name: "1m", Resolution: 60, Retention: 30}}},
+			shouldError: true,
+			errorMsg:    "retention must be at least its resolution",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHistoryConfig(tt.config)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got nil", tt.name)
+				} else if tt.errorMsg != "" && !containsString(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error message to contain '%s', but got '%s'", tt.errorMsg, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error for test case '%s', but got: %v", tt.name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateServerConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      ServerConfig
+		shouldError bool
+		errorMsg    string
+	}{
+		{
+			name:        "default server config",
+			config:      DefaultServerConfig,
+			shouldError: false,
+		},
+		{
+			name:        "empty listen address uses default",
+			config:      ServerConfig{},
+			shouldError: false,
+		},
+		{
+			name:        "all interfaces",
+			config:      ServerConfig{Listen: ":9273"},
+			shouldError: false,
+		},
+		{
+			name:        "missing port",
+			config:      ServerConfig{Listen: "localhost"},
+			shouldError: true,
+			errorMsg:    "Invalid server listen address",
+		},
+		{
+			name:        "negative interval",
+			config:      ServerConfig{Listen: "127.0.0.1:9273", Interval: -1},
+			shouldError: true,
+			errorMsg:    "interval cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateServerConfig(tt.config)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got nil", tt.name)
+				} else if tt.errorMsg != "" && !containsString(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error message to contain '%s', but got '%s'", tt.errorMsg, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error for test case '%s', but got: %v", tt.name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAlertsConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      alerts.Config
+		shouldError bool
+		errorMsg    string
+	}{
+		{
+			name:        "default alerts config",
+			config:      alerts.DefaultConfig,
+			shouldError: false,
+		},
+		{
+			name:        "disabled config is not checked",
+			config:      alerts.Config{Rules: []alerts.Rule{{Name: "bad", Expr: ">"}}},
+			shouldError: false,
+		},
+		{
+			name:        "negative interval",
+			config:      alerts.Config{Enabled: true, Interval: -1},
+			shouldError: true,
+			errorMsg:    "interval cannot be negative",
+		},
+		{
+			name: "duplicate rule",
+			config: alerts.Config{Enabled: true, Rules: []alerts.Rule{
+				{Name: "cpu", Expr: "cpu.total > 90"},
+				{Name: "cpu", Expr: "cpu.total > 95"},
+			}},
+			shouldError: true,
+			errorMsg:    "Duplicate alert rule",
+		},
+		{
+			name:        "invalid expression",
+			config:      alerts.Config{Enabled: true, Rules: []alerts.Rule{{Name: "cpu", Expr: "cpu.total >"}}},
+			shouldError: true,
+			errorMsg:    "Invalid alert rule",
+		},
+		{
+			name:        "invalid severity",
+			config:      alerts.Config{Enabled: true, Rules: []alerts.Rule{{Name: "cpu", Expr: "cpu.total > 90", Severity: "urgent"}}},
+			shouldError: true,
+			errorMsg:    "invalid severity",
+		},
+		{
+			name: "rule with notifiers",
+			config: alerts.Config{
+				Enabled:   true,
+				Rules:     []alerts.Rule{{Name: "cpu", Expr: "cpu.total > 90", Notify: []string{"hook", "log"}}},
+				Notifiers: []alerts.NotifierConfig{{Name: "hook", Type: "webhook", URL: "http://localhost:8080/alert"}, {Name: "log", Type: "log"}},
+			},
+			shouldError: false,
+		},
+		{
+			name: "unknown notifier",
+			config: alerts.Config{
+				Enabled: true,
+				Rules:   []alerts.Rule{{Name: "cpu", Expr: "cpu.total > 90", Notify: []string{"pager"}}},
+			},
+			shouldError: true,
+			errorMsg:    "unknown notifier: pager",
+		},
+		{
+			name: "duplicate notifier",
+			config: alerts.Config{
+				Enabled:   true,
+				Notifiers: []alerts.NotifierConfig{{Name: "log", Type: "log"}, {Name: "log", Type: "log"}},
+			},
+			shouldError: true,
+			errorMsg:    "Duplicate alert notifier",
+		},
+		{
+			name: "webhook without url",
+			config: alerts.Config{
+				Enabled:   true,
+				Notifiers: []alerts.NotifierConfig{{Name: "hook", Type: "webhook"}},
+			},
+			shouldError: true,
+			errorMsg:    "webhook url",
+		},
+		{
+			name: "unknown notifier type",
+			config: alerts.Config{
+				Enabled:   true,
+				Notifiers: []alerts.NotifierConfig{{Name: "pager", Type: "pagerduty"}},
+			},
+			shouldError: true,
+			errorMsg:    "unknown type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlertsConfig(tt.config)
+
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected error for test case '%s', but got nil", tt.name)
+				} else if tt.errorMsg != "" && !containsString(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error message to contain '%s', but got '%s'", tt.errorMsg, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error for test case '%s', but got: %v", tt.name, err)
+				}
+			}
+		})
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > len(substr) && s[:len(substr)] == substr) ||